@@ -5,33 +5,24 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
-	"strings"
+	"syscall"
 
 	"github.com/tacokumo/admin-api/internal/cmd"
+	"github.com/tacokumo/admin-api/pkg/config"
 )
 
 func main() {
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	level := os.Getenv("LOG_LEVEL")
-	var logLevel slog.Level
-
-	switch strings.ToLower(level) {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn", "warning":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
+	// An unknown level falls back to info; the server warns about it when it applies the config.
+	logLevel := new(slog.LevelVar)
+	if level, err := config.ParseLogLevel(os.Getenv("LOG_LEVEL")); err == nil {
+		logLevel.Set(level)
 	}
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
 
-	app := cmd.New(logger)
+	app := cmd.New(logger, logLevel)
 	if err := app.ExecuteContext(ctx); err != nil {
 		logger.ErrorContext(ctx, "failed to execute command", slog.String("error", err.Error()))
 		os.Exit(1)
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/tacokumo/admin-api/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// newConfigCommand returns the commands that inspect a configuration without starting the server.
+func newConfigCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the server configuration",
+	}
+	c.PersistentFlags().StringP("file", "f", "", "設定ファイル (省略時は環境変数 "+configFileEnv+")")
+
+	c.AddCommand(newConfigCheckCommand())
+	c.AddCommand(newConfigPrintCommand())
+	c.AddCommand(newConfigSchemaCommand())
+	return c
+}
+
+// newConfigCheckCommand returns the command that validates a config file with the environment
+// overrides applied, so that mistakes are found before a deployment.
+func newConfigCheckCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Validate the configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, file, err := loadConfigFromFlag(cmd)
+			if err != nil {
+				return err
+			}
+
+			err = cfg.Validate()
+			var verr *config.ValidationError
+			if errors.As(err, &verr) {
+				for _, fe := range verr.Errors {
+					if fe.Line > 0 {
+						fmt.Printf("❌ %s:%d: %s %s\n", file, fe.Line, fe.Path, fe.Message)
+					} else {
+						fmt.Printf("❌ %s %s\n", fe.Path, fe.Message)
+					}
+				}
+				return errors.Newf("config has %d problems", len(verr.Errors))
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ %s is valid\n", file)
+			return nil
+		},
+	}
+}
+
+// newConfigPrintCommand returns the command that prints the effective configuration, after
+// environment overrides, with secrets redacted.
+func newConfigPrintCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration with secrets redacted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get format flag")
+			}
+			cfg, _, err := loadConfigFromFlag(cmd)
+			if err != nil {
+				return err
+			}
+
+			var buf bytes.Buffer
+			enc := yaml.NewEncoder(&buf)
+			enc.SetIndent(2)
+			if err := enc.Encode(cfg.Redacted()); err != nil {
+				return errors.Wrapf(err, "failed to encode config as yaml")
+			}
+			if err := enc.Close(); err != nil {
+				return errors.Wrapf(err, "failed to encode config as yaml")
+			}
+			b := buf.Bytes()
+			switch format {
+			case "yaml":
+				_, err = os.Stdout.Write(b)
+				return err
+			case "json":
+				// Converting the YAML keeps the field names and duration format of the config file.
+				var v map[string]any
+				if err := yaml.Unmarshal(b, &v); err != nil {
+					return errors.Wrapf(err, "failed to decode config")
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(v); err != nil {
+					return errors.Wrapf(err, "failed to encode config as json")
+				}
+				return nil
+			default:
+				return errors.Newf("format must be json or yaml")
+			}
+		},
+	}
+
+	c.Flags().String("format", "yaml", "出力形式 (json | yaml)")
+	return c
+}
+
+// newConfigSchemaCommand returns the command that prints the JSON Schema of the config file, or
+// the table of environment variables, generated from the struct tags of the config.
+func newConfigSchemaCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the config file or the environment variables",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get output flag")
+			}
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get format flag")
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return errors.Wrapf(err, "failed to create output file")
+				}
+				defer func() {
+					if closeErr := f.Close(); closeErr != nil && err == nil {
+						err = errors.Wrapf(closeErr, "failed to close output file")
+					}
+				}()
+				w = f
+			}
+
+			switch format {
+			case "json":
+				return config.WriteSchema(w)
+			case "env", "markdown":
+				t := table.NewWriter()
+				t.SetOutputMirror(w)
+				t.AppendHeader(table.Row{"Variable", "Config", "Type", "Default", "Secret"})
+				for _, v := range config.EnvVars() {
+					secret := ""
+					if v.Secret {
+						secret = "yes"
+					}
+					t.AppendRow(table.Row{v.Name, v.Path, v.Type, v.Default, secret})
+				}
+				if format == "markdown" {
+					t.RenderMarkdown()
+				} else {
+					t.Render()
+				}
+				return nil
+			default:
+				return errors.Newf("format must be json, env or markdown")
+			}
+		},
+	}
+
+	c.Flags().StringP("output", "o", "", "出力先のファイル (省略時は標準出力)")
+	c.Flags().String("format", "json", "出力形式 (json | env | markdown)")
+	return c
+}
+
+// loadConfigFromFlag loads the config file of the file flag, or ADMIN_API_CONFIG_FILE, with the
+// environment overrides applied.
+func loadConfigFromFlag(cmd *cobra.Command) (config.Config, string, error) {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return config.Config{}, "", errors.Wrapf(err, "failed to get file flag")
+	}
+	if file == "" {
+		file = os.Getenv(configFileEnv)
+	}
+	if file == "" {
+		return config.Config{}, "", errors.Newf("file is required when %s is not set", configFileEnv)
+	}
+	cfg, err := loadConfigFile(file)
+	return cfg, file, err
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+	"github.com/tacokumo/admin-api/pkg/dataset"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/pg"
+)
+
+// newExportCommand returns the command that writes the dataset straight from the database,
+// for backups taken where the API is not reachable.
+func newExportCommand(logger *slog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "export",
+		Short: "Export the admin dataset from the database",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get output flag")
+			}
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get format flag")
+			}
+
+			conn, err := connectAdminDB(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := conn.Close(context.WithoutCancel(cmd.Context())); closeErr != nil && err == nil {
+					err = errors.Wrapf(closeErr, "failed to close database connection")
+				}
+			}()
+
+			tx, err := conn.BeginTx(cmd.Context(), pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+			if err != nil {
+				return errors.Wrapf(err, "failed to begin transaction")
+			}
+			defer func() {
+				if rbErr := tx.Rollback(context.WithoutCancel(cmd.Context())); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+					logger.ErrorContext(cmd.Context(), "failed to rollback transaction", slog.String("error", rbErr.Error()))
+				}
+			}()
+
+			d, err := dataset.Export(cmd.Context(), admindb.New(tx))
+			if err != nil {
+				return errors.Wrapf(err, "failed to export dataset")
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return errors.Wrapf(err, "failed to create output file")
+				}
+				defer func() {
+					if closeErr := f.Close(); closeErr != nil && err == nil {
+						err = errors.Wrapf(closeErr, "failed to close output file")
+					}
+				}()
+				w = f
+			}
+			if err := dataset.Encode(w, d, format); err != nil {
+				return err
+			}
+			logger.InfoContext(cmd.Context(), "exported dataset",
+				slog.Int("users", len(d.Users)),
+				slog.Int("projects", len(d.Projects)),
+			)
+			return nil
+		},
+	}
+
+	c.Flags().StringP("output", "o", "", "出力先のファイル (省略時は標準出力)")
+	c.Flags().String("format", dataset.FormatJSON, "出力形式 (json | yaml)")
+	return c
+}
+
+// newImportCommand returns the command that imports a dataset straight into the database.
+func newImportCommand(logger *slog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "import",
+		Short: "Import an admin dataset into the database",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			file, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get file flag")
+			}
+			if file == "" {
+				return errors.New("file is required")
+			}
+			mode, err := cmd.Flags().GetString("mode")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get mode flag")
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get dry-run flag")
+			}
+
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read dataset")
+			}
+			d, err := dataset.Parse(b)
+			if err != nil {
+				return err
+			}
+			if err := d.Validate(); err != nil {
+				return err
+			}
+
+			conn, err := connectAdminDB(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := conn.Close(context.WithoutCancel(cmd.Context())); closeErr != nil && err == nil {
+					err = errors.Wrapf(closeErr, "failed to close database connection")
+				}
+			}()
+
+			tx, err := conn.Begin(cmd.Context())
+			if err != nil {
+				return errors.Wrapf(err, "failed to begin transaction")
+			}
+			defer func() {
+				if rbErr := tx.Rollback(context.WithoutCancel(cmd.Context())); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+					logger.ErrorContext(cmd.Context(), "failed to rollback transaction", slog.String("error", rbErr.Error()))
+				}
+			}()
+
+			summary, err := dataset.Import(cmd.Context(), admindb.New(tx), d, mode)
+			if err != nil {
+				return errors.Wrapf(err, "failed to import dataset")
+			}
+			if !dryRun {
+				if err := tx.Commit(cmd.Context()); err != nil {
+					return errors.Wrapf(err, "failed to commit transaction")
+				}
+			}
+			printImportSummary(summary, dryRun)
+			return nil
+		},
+	}
+
+	c.Flags().StringP("file", "f", "", "インポートするデータセットのファイル (JSONまたはYAML)")
+	c.Flags().String("mode", dataset.ModeMerge, "インポートモード (merge | replace)")
+	c.Flags().Bool("dry-run", false, "変更をコミットせずに結果のみを表示する")
+	return c
+}
+
+// connectAdminDB opens a single connection to the admin database of the configuration.
+func connectAdminDB(ctx context.Context) (*pgx.Conn, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	dsn := pg.Config{
+		Host:     cfg.AdminDBConfig.Host,
+		Port:     cfg.AdminDBConfig.Port,
+		User:     cfg.AdminDBConfig.User,
+		Password: cfg.AdminDBConfig.Password,
+		DBName:   cfg.AdminDBConfig.DBName,
+	}.DSN()
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to admin database")
+	}
+	return conn, nil
+}
+
+func printImportSummary(s dataset.Summary, dryRun bool) {
+	if dryRun {
+		fmt.Println("📋 Dry run; nothing was changed")
+	}
+	fmt.Print(s)
+}
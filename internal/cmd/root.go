@@ -10,19 +10,24 @@ import (
 	"github.com/tacokumo/admin-api/pkg/server"
 )
 
-func New(logger *slog.Logger) *cobra.Command {
-	return &cobra.Command{
+func New(logger *slog.Logger, logLevel *slog.LevelVar) *cobra.Command {
+	c := &cobra.Command{
 		Use:           "api",
 		SilenceErrors: true,
 		SilenceUsage:  true,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			fp := os.Getenv("ADMIN_API_CONFIG_FILE")
-			cfg, err := config.LoadFromYAMLWithEnvOverride(fp)
+			cfg, err := loadConfig()
 			if err != nil {
-				return errors.Wrapf(err, "failed to load config from file: %s", fp)
+				return err
+			}
+			if err := cfg.Validate(); err != nil {
+				return err
 			}
 
-			srv, err := server.New(cmd.Context(), cfg, logger)
+			srv, err := server.New(cmd.Context(), cfg, logger,
+				server.WithLogLevel(logLevel),
+				server.WithConfigSource(os.Getenv(configFileEnv), loadConfig),
+			)
 			if err != nil {
 				return errors.Wrapf(err, "failed to create server")
 			}
@@ -34,4 +39,27 @@ func New(logger *slog.Logger) *cobra.Command {
 			return nil
 		},
 	}
+
+	c.AddCommand(newExportCommand(logger))
+	c.AddCommand(newImportCommand(logger))
+	c.AddCommand(newConfigCommand())
+
+	return c
+}
+
+// configFileEnv names the environment variable with the path of the config file.
+const configFileEnv = "ADMIN_API_CONFIG_FILE"
+
+// loadConfig loads the configuration from ADMIN_API_CONFIG_FILE and the environment.
+func loadConfig() (config.Config, error) {
+	return loadConfigFile(os.Getenv(configFileEnv))
+}
+
+// loadConfigFile loads the configuration from fp and the environment.
+func loadConfigFile(fp string) (config.Config, error) {
+	cfg, err := config.LoadFromYAMLWithEnvOverride(fp)
+	if err != nil {
+		return cfg, errors.Wrapf(err, "failed to load config from file: %s", fp)
+	}
+	return cfg, nil
 }
@@ -0,0 +1,297 @@
+// Package redistest provides an in-memory Redis server for tests of code that talks to Redis
+// through go-redis.
+package redistest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Handler serves a command. args holds the arguments after the command name. The reply can be
+// nil, a string, an int, an error, a []any of those or Status.
+type Handler func(args []string) any
+
+// Status is a simple string reply, such as OK.
+type Status string
+
+// Server speaks RESP2 and implements GET, SET (with NX, EX and PX) and DEL on strings. Other
+// commands can be added with Handle. Unknown commands, such as HELLO, are answered with an
+// error, which makes go-redis fall back to RESP2.
+type Server struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	values   map[string]string
+	expires  map[string]time.Time
+	handlers map[string]Handler
+	conns    map[net.Conn]struct{}
+}
+
+// NewServer starts a server that is closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &Server{
+		ln:       ln,
+		values:   map[string]string{},
+		expires:  map[string]time.Time{},
+		handlers: map[string]Handler{},
+		conns:    map[net.Conn]struct{}{},
+	}
+	go s.serve()
+	t.Cleanup(s.close)
+	return s
+}
+
+// Addr is the address the server listens on.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Client returns a client of the server that is closed when the test ends.
+func (s *Server) Client(t testing.TB) *redis.Client {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: s.Addr(), DisableIdentity: true})
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// Handle serves the command name, case-insensitively, with h.
+func (s *Server) Handle(name string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[strings.ToUpper(name)] = h
+}
+
+// Get returns the value of key, as a client would see it.
+func (s *Server) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(key)
+}
+
+// TTL returns the remaining time to live of key, or 0 if it does not expire.
+func (s *Server) TTL(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if at, ok := s.expires[key]; ok {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func (s *Server) close() {
+	_ = s.ln.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		writeReply(w, s.exec(args))
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) exec(args []string) any {
+	if len(args) == 0 {
+		return errors.New("empty command")
+	}
+	name := strings.ToUpper(args[0])
+	s.mu.Lock()
+	h, ok := s.handlers[name]
+	s.mu.Unlock()
+	if ok {
+		// Custom handlers may block, so they run without the lock.
+		return h(args[1:])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch name {
+	case "PING":
+		return Status("PONG")
+	case "GET":
+		if len(args) != 2 {
+			return errors.New("wrong number of arguments for 'get' command")
+		}
+		if v, ok := s.get(args[1]); ok {
+			return v
+		}
+		return nil
+	case "SET":
+		return s.set(args[1:])
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.get(key); ok {
+				deleted++
+			}
+			delete(s.values, key)
+			delete(s.expires, key)
+		}
+		return deleted
+	default:
+		return fmt.Errorf("unknown command '%s'", args[0])
+	}
+}
+
+func (s *Server) get(key string) (string, bool) {
+	if at, ok := s.expires[key]; ok && !time.Now().Before(at) {
+		delete(s.values, key)
+		delete(s.expires, key)
+	}
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func (s *Server) set(args []string) any {
+	if len(args) < 2 {
+		return errors.New("wrong number of arguments for 'set' command")
+	}
+	key, value := args[0], args[1]
+	var nx bool
+	var ttl time.Duration
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				return errors.New("syntax error")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n <= 0 {
+				return errors.New("invalid expire time in 'set' command")
+			}
+			unit := time.Second
+			if strings.ToUpper(args[i]) == "PX" {
+				unit = time.Millisecond
+			}
+			ttl = time.Duration(n) * unit
+			i++
+		default:
+			return errors.New("syntax error")
+		}
+	}
+
+	if _, ok := s.get(key); ok && nx {
+		return nil
+	}
+	s.values[key] = value
+	delete(s.expires, key)
+	if ttl > 0 {
+		s.expires[key] = time.Now().Add(ttl)
+	}
+	return Status("OK")
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for range n {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("unexpected line %q", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func writeReply(w *bufio.Writer, reply any) {
+	switch v := reply.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case Status:
+		fmt.Fprintf(w, "+%s\r\n", v)
+	case string:
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case int:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case error:
+		fmt.Fprintf(w, "-ERR %s\r\n", v)
+	case []any:
+		fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	default:
+		fmt.Fprintf(w, "-ERR unsupported reply %T\r\n", reply)
+	}
+}
@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/declarative"
+	"github.com/tacokumo/admin-api/pkg/events"
+)
+
+// maxManifestSize bounds the body of POST /apply.
+const maxManifestSize = 4 << 20
+
+// ApplyResult is the response of POST /apply.
+type ApplyResult struct {
+	Changes []declarative.Change `json:"changes"`
+	// Applied reports whether the changes were made. It is false for dry runs.
+	Applied bool `json:"applied"`
+}
+
+// applyManifest reconciles projects, roles and user groups with a YAML or JSON manifest.
+// With ?dryRun=true only the plan is returned, and with ?prune=true objects the manifest does
+// not list are deleted. The plan is computed again and applied in a single transaction, so the
+// changes are made entirely or not at all.
+func (s *Service) applyManifest(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	dryRun, err := boolQueryParam(c, "dryRun")
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	prune, err := boolQueryParam(c, "prune")
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxManifestSize+1))
+	if err != nil {
+		return s.writeError(c, errBadRequest("invalid request body"))
+	}
+	if len(body) > maxManifestSize {
+		return s.writeError(c, newAPIError(http.StatusRequestEntityTooLarge, "manifest must not exceed %d bytes", maxManifestSize))
+	}
+	m, err := declarative.Parse(body)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	if err := m.Validate(); err != nil {
+		return s.writeError(c, err)
+	}
+
+	if dryRun {
+		state, err := declarative.Load(ctx, s.queries, m, prune)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		plan, err := declarative.MakePlan(m, state, prune)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		return c.JSON(http.StatusOK, ApplyResult{Changes: plan.Changes})
+	}
+
+	var (
+		plan    declarative.Plan
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		state, err := declarative.Load(ctx, q, m, prune)
+		if err != nil {
+			return err
+		}
+		if plan, err = declarative.MakePlan(m, state, prune); err != nil {
+			return err
+		}
+		evs, err := declarative.Apply(ctx, q, plan)
+		if err != nil {
+			return err
+		}
+		pending, err = s.RecordEvents(ctx, q, evs...)
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.JSON(http.StatusOK, ApplyResult{Changes: plan.Changes, Applied: true})
+}
+
+// boolQueryParam reads an optional boolean query parameter.
+func boolQueryParam(c echo.Context, name string) (bool, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errBadRequest("%s must be true or false", name)
+	}
+	return b, nil
+}
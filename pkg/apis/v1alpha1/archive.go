@@ -0,0 +1,194 @@
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	adminv1alpha1 "github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// ProjectArchive is the archival state of a project.
+type ProjectArchive struct {
+	Project  adminv1alpha1.Project `json:"project"`
+	Archived bool                  `json:"archived"`
+	// ArchivedAt and ArchivedBy are omitted while the project is not archived.
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+	ArchivedBy *string    `json:"archivedBy,omitempty"`
+}
+
+func (s *Service) getProjectArchive(c echo.Context) error {
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	setETag(c.Request().Context(), proj.Version)
+	return c.JSON(http.StatusOK, toProjectArchive(proj))
+}
+
+// archiveProject makes the project read-only and hides it from ListProjects. Nothing is deleted
+// until the retention job purges it, so it can be restored with unarchiveProject until then.
+func (s *Service) archiveProject(c echo.Context) error {
+	return s.setProjectArchived(c, true)
+}
+
+func (s *Service) unarchiveProject(c echo.Context) error {
+	return s.setProjectArchived(c, false)
+}
+
+func (s *Service) setProjectArchived(c echo.Context, archived bool) error {
+	ctx := c.Request().Context()
+
+	versions, err := expectedVersions(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	if err := checkVersion("project", versions, proj.Version); err != nil {
+		return s.writeError(c, err)
+	}
+	if project.IsArchived(proj) == archived {
+		if archived {
+			return s.writeError(c, errConflict("project is already archived"))
+		}
+		return s.writeError(c, errConflict("project is not archived"))
+	}
+
+	var pending []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		var affected int64
+		if archived {
+			if err := q.LockProjectForArchival(ctx, proj.DisplayID); err != nil {
+				return errors.Wrapf(err, "failed to lock project")
+			}
+			affected, err = q.ArchiveProject(ctx, admindb.ArchiveProjectParams{
+				DisplayID:        proj.DisplayID,
+				ArchivedBy:       pgtype.Text{String: currentPrincipalName(ctx), Valid: true},
+				ExpectedVersions: versions,
+			})
+		} else {
+			affected, err = q.UnarchiveProject(ctx, admindb.UnarchiveProjectParams{
+				DisplayID:        proj.DisplayID,
+				ExpectedVersions: versions,
+			})
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to update project archival")
+		}
+		if affected == 0 {
+			// The project was changed between reading and updating it.
+			return errPreconditionFailed("project")
+		}
+
+		if proj, err = q.GetProjectByDisplayID(ctx, proj.DisplayID); err != nil {
+			return errors.Wrapf(err, "failed to get project by display id")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindProject,
+			Action:     events.ActionUpdated,
+			ResourceID: proj.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	setETag(ctx, proj.Version)
+	return c.JSON(http.StatusOK, toProjectArchive(proj))
+}
+
+// rejectArchivedProjectMutations makes archived projects read-only. Any request under
+// /projects/{projectId} other than GET, HEAD and OPTIONS is rejected while the project is
+// archived, except for unarchiving and cloning it, which leave it unchanged. It runs in front of
+// both the Echo handlers and the generated server, so new project scoped endpoints are covered
+// without further changes. The project may be archived after it was checked here, so the
+// transactions of the request check it again under project.Lock (see withTxOptions).
+func (s *Service) rejectArchivedProjectMutations(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return next(c)
+		}
+		projectId, rest, ok := splitProjectPath(req.URL.Path)
+		if !ok || rest == "unarchive" || rest == "clone" {
+			return next(c)
+		}
+		displayId := pgtype.UUID{}
+		if err := displayId.Scan(projectId); err != nil {
+			// Malformed IDs are reported by the handler.
+			return next(c)
+		}
+
+		proj, err := s.queries.GetProjectByDisplayID(req.Context(), displayId)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return next(c)
+			}
+			return s.writeError(c, errors.Wrapf(err, "failed to get project by display id"))
+		}
+		if err := project.EnsureActive(proj); err != nil {
+			return s.writeError(c, err)
+		}
+		if rest != "archive" {
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), activeProjectKey{}, proj.ID)))
+		}
+		return next(c)
+	}
+}
+
+// activeProjectKey is the context key of the ID of the project that a request changes.
+type activeProjectKey struct{}
+
+// activeProject returns the ID of the project that the current request changes, if it is
+// addressed under /projects/{projectId}.
+func activeProject(ctx context.Context) (int64, bool) {
+	projectID, ok := ctx.Value(activeProjectKey{}).(int64)
+	return projectID, ok
+}
+
+// splitProjectPath splits a path of the form ".../projects/{projectId}[/rest]".
+func splitProjectPath(path string) (projectId string, rest string, ok bool) {
+	_, after, found := strings.Cut(path, "/projects/")
+	if !found {
+		return "", "", false
+	}
+	projectId, rest, _ = strings.Cut(after, "/")
+	return projectId, rest, projectId != ""
+}
+
+// ensureProjectActive rejects changes to a resource of an archived project that is not
+// addressed under /projects/{projectId}, such as a project scoped service account. It must run
+// in the transaction of q that makes the change, which keeps the project from being archived
+// until it ends.
+func ensureProjectActive(ctx context.Context, q *admindb.Queries, projectID pgtype.Int8) error {
+	if !projectID.Valid {
+		return nil
+	}
+	return project.Lock(ctx, q, projectID.Int64)
+}
+
+func toProjectArchive(proj admindb.TacokumoAdminProject) ProjectArchive {
+	resp := ProjectArchive{
+		Project:    toProject(proj),
+		Archived:   project.IsArchived(proj),
+		ArchivedAt: timePtr(proj.ArchivedAt),
+	}
+	if proj.ArchivedBy.Valid {
+		resp.ArchivedBy = &proj.ArchivedBy.String
+	}
+	return resp
+}
@@ -0,0 +1,27 @@
+package v1alpha1
+
+import (
+	adminv1alpha1 "github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+func toProject(p admindb.TacokumoAdminProject) adminv1alpha1.Project {
+	return adminv1alpha1.Project{
+		ID:          p.DisplayID.String(),
+		Name:        p.Name,
+		Description: p.Description,
+		Kind:        adminv1alpha1.ProjectKind(p.Kind),
+		CreatedAt:   p.CreatedAt.Time,
+		UpdatedAt:   p.UpdatedAt.Time,
+	}
+}
+
+func toUser(u admindb.TacokumoAdminUser) adminv1alpha1.User {
+	return adminv1alpha1.User{
+		ID:        u.DisplayID.String(),
+		Email:     u.Email,
+		Roles:     []adminv1alpha1.Role{},
+		CreatedAt: u.CreatedAt.Time,
+		UpdatedAt: u.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,94 @@
+package v1alpha1
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/dataset"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+// maxDatasetSize bounds the body of POST /import.
+const maxDatasetSize = 64 << 20
+
+// errDryRun rolls back the transaction of a dry run import.
+var errDryRun = errors.New("dry run")
+
+// ImportResult is the response of POST /import.
+type ImportResult struct {
+	Mode    string          `json:"mode"`
+	Summary dataset.Summary `json:"summary"`
+	// Imported reports whether the changes were committed. It is false for dry runs.
+	Imported bool `json:"imported"`
+}
+
+// exportDataset returns the complete dataset, read from a single snapshot.
+func (s *Service) exportDataset(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var d dataset.Document
+	err := s.withTxOptions(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *admindb.Queries) error {
+		var err error
+		d, err = dataset.Export(ctx, q)
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, d)
+}
+
+// importDataset imports a JSON or YAML dataset with ?mode=merge (the default) or ?mode=replace.
+// The document is validated before anything is written, and the import runs in one transaction.
+// With ?dryRun=true the import is carried out and rolled back, so that the summary and any
+// conflict are reported exactly as they would be. No events are published, since an import
+// restores data rather than changing it.
+func (s *Service) importDataset(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	mode := c.QueryParam("mode")
+	if mode == "" {
+		mode = dataset.ModeMerge
+	}
+	if !dataset.ValidMode(mode) {
+		return s.writeError(c, errBadRequest("mode must be %s or %s", dataset.ModeMerge, dataset.ModeReplace))
+	}
+	dryRun, err := boolQueryParam(c, "dryRun")
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxDatasetSize+1))
+	if err != nil {
+		return s.writeError(c, errBadRequest("invalid request body"))
+	}
+	if len(body) > maxDatasetSize {
+		return s.writeError(c, newAPIError(http.StatusRequestEntityTooLarge, "dataset must not exceed %d bytes", maxDatasetSize))
+	}
+	d, err := dataset.Parse(body)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	if err := d.Validate(); err != nil {
+		return s.writeError(c, err)
+	}
+
+	var summary dataset.Summary
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		var err error
+		if summary, err = dataset.Import(ctx, q, d, mode); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, ImportResult{Mode: mode, Summary: summary, Imported: !dryRun})
+}
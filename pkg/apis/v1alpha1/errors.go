@@ -0,0 +1,127 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/ogen-go/ogen/ogenerrors"
+	adminv1alpha1 "github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
+	"github.com/tacokumo/admin-api/pkg/dataset"
+	"github.com/tacokumo/admin-api/pkg/declarative"
+	"github.com/tacokumo/admin-api/pkg/pg"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// apiError is an error that carries the HTTP status code it should be reported with.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// Code returns the HTTP status code of the error.
+func (e *apiError) Code() int {
+	return e.status
+}
+
+func newAPIError(status int, format string, args ...any) *apiError {
+	return &apiError{
+		status:  status,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func errBadRequest(format string, args ...any) *apiError {
+	return newAPIError(http.StatusBadRequest, format, args...)
+}
+
+func errNotFound(format string, args ...any) *apiError {
+	return newAPIError(http.StatusNotFound, format, args...)
+}
+
+func errConflict(format string, args ...any) *apiError {
+	return newAPIError(http.StatusConflict, format, args...)
+}
+
+func errUnprocessable(format string, args ...any) *apiError {
+	return newAPIError(http.StatusUnprocessableEntity, format, args...)
+}
+
+// statusFromError maps an error to an HTTP status code and a message that is safe to show to clients.
+func statusFromError(err error) (int, string) {
+	var ae *apiError
+	if errors.As(err, &ae) {
+		return ae.status, ae.message
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return http.StatusNotFound, "not found"
+	}
+
+	if errors.Is(err, project.ErrArchived) {
+		return http.StatusConflict, "project is archived; unarchive it before making changes"
+	}
+
+	var ie *project.InvariantError
+	if errors.As(err, &ie) {
+		return http.StatusUnprocessableEntity, ie.Error()
+	}
+
+	var te *project.TemplateError
+	if errors.As(err, &te) {
+		return http.StatusUnprocessableEntity, te.Error()
+	}
+
+	var ve *declarative.ValidationError
+	if errors.As(err, &ve) {
+		return http.StatusUnprocessableEntity, ve.Error()
+	}
+
+	var de *dataset.ValidationError
+	if errors.As(err, &de) {
+		return http.StatusUnprocessableEntity, de.Error()
+	}
+
+	if pg.IsUniqueViolation(err) {
+		return http.StatusConflict, "already exists"
+	}
+
+	return http.StatusInternalServerError, "internal error"
+}
+
+// writeError renders err as an ErrorResponse for handlers that are served by Echo directly.
+func (s *Service) writeError(c echo.Context, err error) error {
+	status, message := statusFromError(err)
+	if status == http.StatusInternalServerError {
+		s.logger.ErrorContext(c.Request().Context(), "request failed", slog.String("error", err.Error()))
+	}
+	return c.JSON(status, adminv1alpha1.ErrorResponse{Error: message})
+}
+
+// HandleError is used as the error handler of the generated server so that errors returned
+// by generated handlers are reported with the same status codes and body as the Echo handlers.
+func (s *Service) HandleError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	status := ogenerrors.ErrorCode(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		status, message = statusFromError(err)
+	}
+	if status == http.StatusInternalServerError {
+		s.logger.ErrorContext(ctx, "request failed", slog.String("error", err.Error()))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if encodeErr := json.NewEncoder(w).Encode(adminv1alpha1.ErrorResponse{Error: message}); encodeErr != nil {
+		s.logger.ErrorContext(ctx, "failed to write error response", slog.String("error", encodeErr.Error()))
+	}
+}
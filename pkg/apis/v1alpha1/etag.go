@@ -0,0 +1,72 @@
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tacokumo/admin-api/pkg/middleware"
+)
+
+const (
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+)
+
+// errPreconditionFailed is returned when an If-Match header does not match the current row version.
+func errPreconditionFailed(resource string) *apiError {
+	return newAPIError(http.StatusPreconditionFailed, "%s has been modified by someone else", resource)
+}
+
+// formatETag renders a row version as a strong entity tag.
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag emits the ETag of the resource that is being returned.
+func setETag(ctx context.Context, version int64) {
+	middleware.SetResponseHeader(ctx, headerETag, formatETag(version))
+}
+
+// parseIfMatch parses an If-Match header value into the row versions it accepts.
+// A nil slice means that the update is unconditional, either because the header is absent or because it is "*".
+// Weak tags never match for If-Match (RFC 9110 section 13.1.1), so they are ignored along with tags
+// that were not issued by this server.
+func parseIfMatch(value string) ([]int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	versions := []int64{}
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") || len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	if len(versions) == 0 {
+		return nil, newAPIError(http.StatusPreconditionFailed, "If-Match does not match any current representation")
+	}
+	return versions, nil
+}
+
+// expectedVersions returns the row versions accepted by the If-Match header of the current request.
+func expectedVersions(ctx context.Context) ([]int64, error) {
+	return parseIfMatch(middleware.RequestHeader(ctx, headerIfMatch))
+}
+
+// checkVersion enforces the versions accepted by If-Match against the version that was read.
+func checkVersion(resource string, versions []int64, current int64) error {
+	if versions != nil && !slices.Contains(versions, current) {
+		return errPreconditionFailed(resource)
+	}
+	return nil
+}
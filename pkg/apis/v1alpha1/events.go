@@ -0,0 +1,201 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/middleware"
+)
+
+const (
+	// eventHeartbeatInterval is how long a stream waits for events before sending a comment
+	// line, which keeps proxies from closing idle connections.
+	eventHeartbeatInterval = 15 * time.Second
+	// eventRetryInterval is the reconnection delay suggested to EventSource clients.
+	eventRetryInterval = 3 * time.Second
+)
+
+// RecordEvents stamps events with the session user and the current time and queues them for
+// the webhooks of their projects. q must be the transaction of the change, so that the
+// deliveries are stored if and only if the change is committed. The returned events are passed
+// to Publish once the transaction is committed.
+func (s *Service) RecordEvents(ctx context.Context, q *admindb.Queries, evs ...events.Event) ([]events.Event, error) {
+	sess := middleware.GetCurrentSession(ctx)
+	now := time.Now()
+	recorded := make([]events.Event, 0, len(evs))
+	for _, event := range evs {
+		if sess != nil {
+			event.Actor = sess.UserID
+		}
+		event.OccurredAt = now
+		if err := s.enqueueWebhookDeliveries(ctx, q, event); err != nil {
+			return nil, err
+		}
+		recorded = append(recorded, event)
+	}
+	return recorded, nil
+}
+
+// Publish sends a committed resource change to the event stream. Failures are only logged
+// because the change itself has already been committed.
+func (s *Service) Publish(ctx context.Context, event events.Event) {
+	if s.eventStream == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if _, err := s.eventStream.Publish(ctx, event); err != nil {
+		s.logger.ErrorContext(ctx, "failed to publish event",
+			slog.String("type", event.Type()),
+			slog.String("resource_id", event.ResourceID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// publishAll publishes the events returned by RecordEvents after their transaction is committed.
+func (s *Service) publishAll(ctx context.Context, evs []events.Event) {
+	for _, event := range evs {
+		s.Publish(ctx, event)
+	}
+}
+
+// streamEvents serves GET /events as Server-Sent Events.
+// Clients resume after a reconnect by sending the ID of the last event they received in the
+// Last-Event-ID header (or the lastEventId query parameter for clients that cannot set headers);
+// without it the stream starts at the current position. The stream ends when CloseStreams is
+// called on shutdown.
+func (s *Service) streamEvents(c echo.Context) error {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+	stop := context.AfterFunc(s.streams, cancel)
+	defer stop()
+	if s.eventStream == nil {
+		return s.writeError(c, newAPIError(http.StatusNotImplemented, "event stream is not available"))
+	}
+
+	filter, err := s.eventFilter(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	lastID := c.Request().Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.QueryParam("lastEventId")
+	}
+	if lastID == "" {
+		if lastID, err = s.eventStream.Latest(ctx); err != nil {
+			return s.writeError(c, errors.Wrapf(err, "failed to get latest event id"))
+		}
+	} else if !events.ValidID(lastID) {
+		return s.writeError(c, errBadRequest("invalid Last-Event-ID: %s", lastID))
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	// Disable response buffering in nginx based ingresses.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", eventRetryInterval.Milliseconds()); err != nil {
+		return nil
+	}
+	w.Flush()
+
+	for {
+		evs, err := s.eventStream.Read(ctx, lastID, eventHeartbeatInterval)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.ErrorContext(ctx, "failed to read events", slog.String("error", err.Error()))
+			}
+			// The response has already started, so the client only sees the stream end and reconnects.
+			return nil
+		}
+
+		if len(evs) == 0 {
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			w.Flush()
+			continue
+		}
+
+		for _, ev := range evs {
+			lastID = ev.ID
+			if !filter.Match(ev) {
+				continue
+			}
+			data, err := json.Marshal(&ev)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "failed to marshal event", slog.String("error", err.Error()))
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type(), data); err != nil {
+				return nil
+			}
+		}
+		w.Flush()
+	}
+}
+
+// eventFilter builds the filter of an event stream from the projectId and kind query parameters,
+// which accept comma separated lists. Project scoped service accounts only see their own project.
+func (s *Service) eventFilter(c echo.Context) (events.Filter, error) {
+	ctx := c.Request().Context()
+	filter := events.Filter{
+		ProjectIDs: splitQueryList(c.QueryParams()["projectId"]),
+	}
+	for _, kind := range splitQueryList(c.QueryParams()["kind"]) {
+		filter.Kinds = append(filter.Kinds, events.Kind(kind))
+	}
+
+	sess := middleware.GetCurrentSession(ctx)
+	if sess == nil || !sess.IsServiceAccount() {
+		return filter, nil
+	}
+
+	displayId, err := parseDisplayID("serviceAccountId", sess.ServiceAccountID)
+	if err != nil {
+		return filter, err
+	}
+	sa, err := s.queries.GetServiceAccountByDisplayID(ctx, displayId)
+	if err != nil {
+		return filter, errors.Wrapf(err, "failed to get service account by display id")
+	}
+	if !sa.ProjectDisplayID.Valid {
+		return filter, nil
+	}
+
+	projectId := sa.ProjectDisplayID.String()
+	for _, id := range filter.ProjectIDs {
+		if id != projectId {
+			return filter, newAPIError(http.StatusForbidden, "service account cannot watch project %s", id)
+		}
+	}
+	filter.ProjectIDs = []string{projectId}
+	return filter, nil
+}
+
+// splitQueryList flattens repeated and comma separated query parameter values.
+func splitQueryList(values []string) []string {
+	result := []string{}
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,660 @@
+package v1alpha1
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/invitation"
+	"github.com/tacokumo/admin-api/pkg/mail"
+	"github.com/tacokumo/admin-api/pkg/middleware"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// InvitationSettings configures how invitations are issued and delivered.
+type InvitationSettings struct {
+	// Mailer sends invitation emails. When nil, invitations are only returned to the caller.
+	Mailer mail.Sender
+	// TTL is how long an invitation can be accepted. Defaults to invitation.DefaultTTL.
+	TTL time.Duration
+	// AcceptURL is the page invitation links point to.
+	AcceptURL string
+}
+
+// Invitation invites an email address to a project with initial roles and user groups.
+type Invitation struct {
+	ID           string     `json:"id"`
+	ProjectID    string     `json:"projectId"`
+	Email        string     `json:"email"`
+	Status       string     `json:"status"`
+	InvitedBy    string     `json:"invitedBy"`
+	RoleIDs      []string   `json:"roleIds"`
+	UserGroupIDs []string   `json:"userGroupIds"`
+	ExpiresAt    time.Time  `json:"expiresAt"`
+	AcceptedAt   *time.Time `json:"acceptedAt,omitempty"`
+	// Token and AcceptURL are only returned when the invitation is created or resent.
+	Token     string `json:"token,omitempty"`
+	AcceptURL string `json:"acceptUrl,omitempty"`
+	// EmailSent reports whether the invitation email was sent when it was created or resent.
+	EmailSent *bool     `json:"emailSent,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateInvitationRequest struct {
+	Email        string   `json:"email"`
+	RoleIDs      []string `json:"roleIds"`
+	UserGroupIDs []string `json:"userGroupIds"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// createInvitation issues an invitation and emails its link to the invitee.
+// A pending invitation for the same email is revoked, so its old link stops working.
+func (s *Service) createInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var req CreateInvitationRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	email, err := invitation.NormalizeEmail(req.Email)
+	if err != nil {
+		return s.writeError(c, errBadRequest("%s", err.Error()))
+	}
+	token, err := invitation.GenerateToken()
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to generate invitation token"))
+	}
+
+	var (
+		inv     admindb.TacokumoAdminInvitation
+		roles   []admindb.TacokumoAdminRole
+		groups  []admindb.TacokumoAdminUsergroup
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		if roles, err = resolveInvitationRoles(ctx, q, proj, req.RoleIDs); err != nil {
+			return err
+		}
+		if groups, err = resolveInvitationUserGroups(ctx, q, proj, req.UserGroupIDs); err != nil {
+			return err
+		}
+		if _, err := q.RevokePendingInvitationsForEmail(ctx, admindb.RevokePendingInvitationsForEmailParams{
+			ProjectID: proj.ID,
+			Email:     email,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to revoke pending invitations")
+		}
+		inv, err = q.CreateInvitation(ctx, admindb.CreateInvitationParams{
+			ProjectID: proj.ID,
+			Email:     email,
+			TokenHash: token.Hash,
+			InvitedBy: currentPrincipalName(ctx),
+			ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(s.invitationTTL()), Valid: true},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create invitation")
+		}
+		for _, role := range roles {
+			if err := q.AddInvitationRole(ctx, admindb.AddInvitationRoleParams{InvitationID: inv.ID, RoleID: role.ID}); err != nil {
+				return errors.Wrapf(err, "failed to add invitation role")
+			}
+		}
+		for _, group := range groups {
+			if err := q.AddInvitationUserGroup(ctx, admindb.AddInvitationUserGroupParams{InvitationID: inv.ID, UsergroupID: group.ID}); err != nil {
+				return errors.Wrapf(err, "failed to add invitation user group")
+			}
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindInvitation,
+			Action:     events.ActionCreated,
+			ResourceID: inv.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+
+	resp := toInvitation(proj, inv, roles, groups)
+	if err := s.deliverInvitation(ctx, proj, inv, token.Plaintext, &resp); err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+func (s *Service) listInvitations(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	status := pgtype.Text{}
+	if v := c.QueryParam("status"); v != "" {
+		if !slices.Contains([]string{invitation.StatusPending, invitation.StatusAccepted, invitation.StatusRevoked, invitation.StatusExpired}, v) {
+			return s.writeError(c, errBadRequest("invalid status: %s", v))
+		}
+		status = pgtype.Text{String: v, Valid: true}
+	}
+
+	invs, err := s.queries.ListInvitationsWithPagination(ctx, admindb.ListInvitationsWithPaginationParams{
+		ProjectID: proj.ID,
+		Limit:     limit,
+		Offset:    offset,
+		Status:    status,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list invitations"))
+	}
+
+	resp := make([]Invitation, 0, len(invs))
+	for _, inv := range invs {
+		roles, groups, err := s.loadInvitationGrants(ctx, inv)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		resp = append(resp, toInvitation(proj, inv, roles, groups))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Service) getInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, inv, err := s.loadInvitation(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	roles, groups, err := s.loadInvitationGrants(ctx, inv)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, toInvitation(proj, inv, roles, groups))
+}
+
+// revokeInvitation invalidates a pending invitation. Accepted invitations cannot be revoked;
+// remove the memberships they granted instead.
+func (s *Service) revokeInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, inv, err := s.loadInvitation(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var pending []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		affected, err := q.RevokeInvitation(ctx, admindb.RevokeInvitationParams{
+			ProjectID: proj.ID,
+			DisplayID: inv.DisplayID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to revoke invitation")
+		}
+		if affected == 0 {
+			return errConflict("invitation is already %s", inv.Status)
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindInvitation,
+			Action:     events.ActionDeleted,
+			ResourceID: inv.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// resendInvitation issues a new token for a pending invitation, extends its expiry and
+// emails the new link. The previous link stops working.
+func (s *Service) resendInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, current, err := s.loadInvitation(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	if current.Status != invitation.StatusPending {
+		return s.writeError(c, errConflict("invitation is already %s", current.Status))
+	}
+	token, err := invitation.GenerateToken()
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to generate invitation token"))
+	}
+
+	var (
+		inv     admindb.TacokumoAdminInvitation
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		inv, err = q.RotateInvitationToken(ctx, admindb.RotateInvitationTokenParams{
+			ProjectID: proj.ID,
+			DisplayID: current.DisplayID,
+			TokenHash: token.Hash,
+			ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(s.invitationTTL()), Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errConflict("invitation is no longer pending")
+			}
+			return errors.Wrapf(err, "failed to rotate invitation token")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindInvitation,
+			Action:     events.ActionUpdated,
+			ResourceID: inv.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+
+	roles, groups, err := s.loadInvitationGrants(ctx, inv)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	resp := toInvitation(proj, inv, roles, groups)
+	if err := s.deliverInvitation(ctx, proj, inv, token.Plaintext, &resp); err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// acceptInvitation accepts the invitation of a token for the signed-in user.
+// Holding the token proves access to the invited mailbox, so the session email does not
+// have to match the invited one.
+func (s *Service) acceptInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	sess := middleware.GetCurrentSession(ctx)
+	if sess == nil {
+		return s.writeError(c, newAPIError(http.StatusUnauthorized, "not authenticated"))
+	}
+	if sess.IsServiceAccount() || sess.Email == "" {
+		return s.writeError(c, newAPIError(http.StatusForbidden, "invitations can only be accepted by users"))
+	}
+	var req AcceptInvitationRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if !strings.HasPrefix(req.Token, invitation.TokenPrefix) {
+		return s.writeError(c, errBadRequest("invalid invitation token"))
+	}
+
+	var (
+		proj   admindb.TacokumoAdminProject
+		inv    admindb.TacokumoAdminInvitation
+		roles  []admindb.TacokumoAdminRole
+		groups []admindb.TacokumoAdminUsergroup
+		evs    []events.Event
+	)
+	err := s.withTx(ctx, func(q *admindb.Queries) error {
+		var err error
+		inv, err = q.GetPendingInvitationByTokenHash(ctx, invitation.HashToken(req.Token))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errNotFound("invitation not found")
+			}
+			return errors.Wrapf(err, "failed to get invitation by token")
+		}
+		if !inv.ExpiresAt.Time.After(time.Now()) {
+			return newAPIError(http.StatusGone, "invitation has expired")
+		}
+		user, userEvents, err := ensureUser(ctx, q, sess.Email)
+		if err != nil {
+			return err
+		}
+		evs = append(evs, userEvents...)
+		if proj, roles, groups, userEvents, err = applyInvitation(ctx, q, inv, user); err != nil {
+			return err
+		}
+		evs, err = s.RecordEvents(ctx, q, append(evs, userEvents...)...)
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, evs)
+
+	inv.Status = invitation.StatusAccepted
+	now := time.Now()
+	inv.AcceptedAt = pgtype.Timestamptz{Time: now, Valid: true}
+	inv.UpdatedAt = pgtype.Timestamptz{Time: now, Valid: true}
+	return c.JSON(http.StatusOK, toInvitation(proj, inv, roles, groups))
+}
+
+// AcceptInvitationsForLogin accepts the pending invitations of any of the verified emails of
+// a GitHub login, as returned by GitHub's emails endpoint. Memberships are granted to the admin
+// user of the login's primary email, which is created when it does not exist yet, so nothing is
+// accepted unless the primary email is verified as well. actor is recorded on the published
+// events. It returns the number of accepted invitations.
+func (s *Service) AcceptInvitationsForLogin(ctx context.Context, primaryEmail string, verifiedEmails []string, actor string) (int, error) {
+	// The profile email of a GitHub account can be set to any address without verifying it.
+	emails := lo.Uniq(lo.Map(verifiedEmails, func(email string, _ int) string {
+		return strings.ToLower(email)
+	}))
+	if primaryEmail == "" || !lo.Contains(emails, strings.ToLower(primaryEmail)) {
+		return 0, nil
+	}
+
+	var (
+		accepted int
+		evs      []events.Event
+	)
+	err := s.withTx(ctx, func(q *admindb.Queries) error {
+		invs, err := q.ListPendingInvitationsByEmails(ctx, emails)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list pending invitations")
+		}
+		if len(invs) == 0 {
+			return nil
+		}
+		user, userEvents, err := ensureUser(ctx, q, primaryEmail)
+		if err != nil {
+			return err
+		}
+		evs = append(evs, userEvents...)
+		for _, inv := range invs {
+			_, _, _, invEvents, err := applyInvitation(ctx, q, inv, user)
+			if err != nil {
+				return err
+			}
+			evs = append(evs, invEvents...)
+			accepted++
+		}
+		for i := range evs {
+			evs[i].Actor = actor
+		}
+		evs, err = s.RecordEvents(ctx, q, evs...)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.publishAll(ctx, evs)
+	return accepted, nil
+}
+
+// ensureUser returns the admin user of an email, creating it when it does not exist.
+func ensureUser(ctx context.Context, q *admindb.Queries, email string) (admindb.TacokumoAdminUser, []events.Event, error) {
+	user, err := q.GetUserByEmailFold(ctx, email)
+	if err == nil {
+		return user, nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return user, nil, errors.Wrapf(err, "failed to get user by email")
+	}
+
+	if err := q.CreateUser(ctx, email); err != nil {
+		return user, nil, errors.Wrapf(err, "failed to create user")
+	}
+	user, err = q.GetUserByEmail(ctx, email)
+	if err != nil {
+		return user, nil, errors.Wrapf(err, "failed to get user by email")
+	}
+	return user, []events.Event{{
+		Kind:       events.KindUser,
+		Action:     events.ActionCreated,
+		ResourceID: user.DisplayID.String(),
+	}}, nil
+}
+
+// applyInvitation grants the roles and user groups of a pending invitation to user and marks
+// it accepted. It returns the events to publish once the transaction is committed.
+func applyInvitation(ctx context.Context, q *admindb.Queries, inv admindb.TacokumoAdminInvitation, user admindb.TacokumoAdminUser) (
+	admindb.TacokumoAdminProject, []admindb.TacokumoAdminRole, []admindb.TacokumoAdminUsergroup, []events.Event, error,
+) {
+	if err := project.Lock(ctx, q, inv.ProjectID); err != nil {
+		return admindb.TacokumoAdminProject{}, nil, nil, nil, err
+	}
+	proj, err := q.GetProjectByID(ctx, inv.ProjectID)
+	if err != nil {
+		return proj, nil, nil, nil, errors.Wrapf(err, "failed to get project by id")
+	}
+	roles, err := q.ListInvitationRoles(ctx, inv.ID)
+	if err != nil {
+		return proj, nil, nil, nil, errors.Wrapf(err, "failed to list invitation roles")
+	}
+	groups, err := q.ListInvitationUserGroups(ctx, inv.ID)
+	if err != nil {
+		return proj, nil, nil, nil, errors.Wrapf(err, "failed to list invitation user groups")
+	}
+
+	var evs []events.Event
+	for _, group := range groups {
+		if err := q.AddUserToUserGroup(ctx, admindb.AddUserToUserGroupParams{
+			UserID:      user.ID,
+			UsergroupID: group.ID,
+		}); err != nil {
+			return proj, nil, nil, nil, errors.Wrapf(err, "failed to add user to user group")
+		}
+		evs = append(evs, events.Event{
+			Kind:       events.KindUserGroupMember,
+			Action:     events.ActionCreated,
+			ResourceID: group.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+			MemberID:   user.DisplayID.String(),
+			MemberKind: events.MemberKindUser,
+		})
+	}
+	for _, role := range roles {
+		affected, err := q.AssignRoleToUser(ctx, admindb.AssignRoleToUserParams{
+			UserID: user.ID,
+			RoleID: role.ID,
+		})
+		if err != nil {
+			return proj, nil, nil, nil, errors.Wrapf(err, "failed to assign role to user")
+		}
+		if affected > 0 {
+			evs = append(evs, events.Event{
+				Kind:       events.KindRoleAssignment,
+				Action:     events.ActionCreated,
+				ResourceID: role.DisplayID.String(),
+				ProjectID:  proj.DisplayID.String(),
+				MemberID:   user.DisplayID.String(),
+				MemberKind: events.MemberKindUser,
+			})
+		}
+	}
+
+	affected, err := q.MarkInvitationAccepted(ctx, admindb.MarkInvitationAcceptedParams{
+		ID:             inv.ID,
+		AcceptedUserID: pgtype.Int8{Int64: user.ID, Valid: true},
+	})
+	if err != nil {
+		return proj, nil, nil, nil, errors.Wrapf(err, "failed to mark invitation accepted")
+	}
+	if affected == 0 {
+		return proj, nil, nil, nil, errConflict("invitation is no longer pending")
+	}
+	evs = append(evs, events.Event{
+		Kind:       events.KindInvitation,
+		Action:     events.ActionUpdated,
+		ResourceID: inv.DisplayID.String(),
+		ProjectID:  proj.DisplayID.String(),
+	})
+	return proj, roles, groups, evs, nil
+}
+
+// deliverInvitation fills in the token and link of a newly issued invitation and emails it.
+// Mail failures are only logged; the caller still receives the link and can share it.
+func (s *Service) deliverInvitation(ctx context.Context, proj admindb.TacokumoAdminProject, inv admindb.TacokumoAdminInvitation, token string, resp *Invitation) error {
+	acceptURL, err := invitation.AcceptURL(s.invitations.AcceptURL, token)
+	if err != nil {
+		return err
+	}
+	resp.Token = token
+	resp.AcceptURL = acceptURL
+	resp.EmailSent = lo.ToPtr(false)
+	if s.invitations.Mailer == nil {
+		return nil
+	}
+
+	msg := invitation.NewMessage(invitation.MessageParams{
+		Email:       inv.Email,
+		ProjectName: proj.Name,
+		InvitedBy:   inv.InvitedBy,
+		AcceptURL:   acceptURL,
+		ExpiresAt:   inv.ExpiresAt.Time,
+	})
+	if err := s.invitations.Mailer.Send(ctx, msg); err != nil {
+		s.logger.ErrorContext(ctx, "failed to send invitation email",
+			slog.String("invitation_id", inv.DisplayID.String()),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	resp.EmailSent = lo.ToPtr(true)
+	return nil
+}
+
+func (s *Service) invitationTTL() time.Duration {
+	if s.invitations.TTL <= 0 {
+		return invitation.DefaultTTL
+	}
+	return s.invitations.TTL
+}
+
+func (s *Service) loadInvitation(c echo.Context) (admindb.TacokumoAdminProject, admindb.TacokumoAdminInvitation, error) {
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return proj, admindb.TacokumoAdminInvitation{}, err
+	}
+	invitationId, err := parseDisplayID("invitationId", c.Param("invitationId"))
+	if err != nil {
+		return proj, admindb.TacokumoAdminInvitation{}, err
+	}
+	inv, err := s.queries.GetInvitationByDisplayID(c.Request().Context(), admindb.GetInvitationByDisplayIDParams{
+		ProjectID: proj.ID,
+		DisplayID: invitationId,
+	})
+	if err != nil {
+		return proj, inv, errors.Wrapf(err, "failed to get invitation by display id")
+	}
+	return proj, inv, nil
+}
+
+func (s *Service) loadInvitationGrants(ctx context.Context, inv admindb.TacokumoAdminInvitation) ([]admindb.TacokumoAdminRole, []admindb.TacokumoAdminUsergroup, error) {
+	roles, err := s.queries.ListInvitationRoles(ctx, inv.ID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to list invitation roles")
+	}
+	groups, err := s.queries.ListInvitationUserGroups(ctx, inv.ID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to list invitation user groups")
+	}
+	return roles, groups, nil
+}
+
+// resolveInvitationRoles looks up the roles of an invitation, which must belong to the project.
+func resolveInvitationRoles(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, ids []string) ([]admindb.TacokumoAdminRole, error) {
+	roles := make([]admindb.TacokumoAdminRole, 0, len(ids))
+	for _, id := range lo.Uniq(ids) {
+		roleId, err := parseDisplayID("roleId", id)
+		if err != nil {
+			return nil, err
+		}
+		role, err := q.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{
+			ProjectID: proj.ID,
+			DisplayID: roleId,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errUnprocessable("role %s does not exist in the project", id)
+			}
+			return nil, errors.Wrapf(err, "failed to get role by display id")
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// resolveInvitationUserGroups looks up the user groups of an invitation, which must belong to the project.
+func resolveInvitationUserGroups(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, ids []string) ([]admindb.TacokumoAdminUsergroup, error) {
+	groups := make([]admindb.TacokumoAdminUsergroup, 0, len(ids))
+	for _, id := range lo.Uniq(ids) {
+		groupId, err := parseDisplayID("userGroupId", id)
+		if err != nil {
+			return nil, err
+		}
+		group, err := q.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{
+			ProjectID: proj.ID,
+			DisplayID: groupId,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errUnprocessable("user group %s does not exist in the project", id)
+			}
+			return nil, errors.Wrapf(err, "failed to get user group by display id")
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// currentPrincipalName describes the authenticated principal for audit fields.
+func currentPrincipalName(ctx context.Context) string {
+	sess := middleware.GetCurrentSession(ctx)
+	if sess == nil {
+		return ""
+	}
+	if sess.GitHubUsername != "" {
+		return sess.GitHubUsername
+	}
+	return sess.UserID
+}
+
+func toInvitation(proj admindb.TacokumoAdminProject, inv admindb.TacokumoAdminInvitation, roles []admindb.TacokumoAdminRole, groups []admindb.TacokumoAdminUsergroup) Invitation {
+	status := inv.Status
+	if status == invitation.StatusPending && !inv.ExpiresAt.Time.After(time.Now()) {
+		status = invitation.StatusExpired
+	}
+	return Invitation{
+		ID:        inv.DisplayID.String(),
+		ProjectID: proj.DisplayID.String(),
+		Email:     inv.Email,
+		Status:    status,
+		InvitedBy: inv.InvitedBy,
+		RoleIDs: lo.Map(roles, func(role admindb.TacokumoAdminRole, _ int) string {
+			return role.DisplayID.String()
+		}),
+		UserGroupIDs: lo.Map(groups, func(group admindb.TacokumoAdminUsergroup, _ int) string {
+			return group.DisplayID.String()
+		}),
+		ExpiresAt:  inv.ExpiresAt.Time,
+		AcceptedAt: timePtr(inv.AcceptedAt),
+		CreatedAt:  inv.CreatedAt.Time,
+		UpdatedAt:  inv.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,153 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/jobs"
+)
+
+var jobStatuses = []string{jobs.StatusPending, jobs.StatusRunning, jobs.StatusSucceeded, jobs.StatusFailed}
+
+// Job is a background job and the state of its latest attempt.
+type Job struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"maxAttempts"`
+	RunAt       time.Time       `json:"runAt"`
+	LockedBy    *string         `json:"lockedBy,omitempty"`
+	LockedUntil *time.Time      `json:"lockedUntil,omitempty"`
+	LastError   *string         `json:"lastError,omitempty"`
+	StartedAt   *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time      `json:"finishedAt,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// JobCount is the number of jobs of a kind in a status.
+type JobCount struct {
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+func (s *Service) listJobs(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	status := pgtype.Text{}
+	if v := c.QueryParam("status"); v != "" {
+		if !slices.Contains(jobStatuses, v) {
+			return s.writeError(c, errBadRequest("status must be one of %s, %s, %s, %s", jobStatuses[0], jobStatuses[1], jobStatuses[2], jobStatuses[3]))
+		}
+		status = pgtype.Text{String: v, Valid: true}
+	}
+	kind := pgtype.Text{}
+	if v := c.QueryParam("kind"); v != "" {
+		kind = pgtype.Text{String: v, Valid: true}
+	}
+
+	rows, err := s.queries.ListJobsWithPagination(ctx, admindb.ListJobsWithPaginationParams{
+		Status: status,
+		Kind:   kind,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list jobs"))
+	}
+	return c.JSON(http.StatusOK, lo.Map(rows, func(row admindb.TacokumoAdminJob, _ int) Job {
+		return toJob(row)
+	}))
+}
+
+// countJobs reports the number of jobs per kind and status.
+func (s *Service) countJobs(c echo.Context) error {
+	rows, err := s.queries.CountJobsByStatus(c.Request().Context())
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to count jobs"))
+	}
+	return c.JSON(http.StatusOK, lo.Map(rows, func(row admindb.CountJobsByStatusRow, _ int) JobCount {
+		return JobCount{Kind: row.Kind, Status: row.Status, Count: row.Count}
+	}))
+}
+
+func (s *Service) getJob(c echo.Context) error {
+	job, err := s.loadJob(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, toJob(job))
+}
+
+// retryJob queues a failed job again with its attempts reset.
+func (s *Service) retryJob(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	job, err := s.loadJob(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	affected, err := s.queries.RequeueFailedJob(ctx, job.DisplayID)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to requeue job"))
+	}
+	if affected == 0 {
+		return s.writeError(c, errConflict("only failed jobs can be retried, job is %s", job.Status))
+	}
+
+	job, err = s.queries.GetJobByDisplayID(ctx, job.DisplayID)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get job by display id"))
+	}
+	return c.JSON(http.StatusAccepted, toJob(job))
+}
+
+func (s *Service) loadJob(c echo.Context) (admindb.TacokumoAdminJob, error) {
+	jobId, err := parseDisplayID("jobId", c.Param("jobId"))
+	if err != nil {
+		return admindb.TacokumoAdminJob{}, err
+	}
+	job, err := s.queries.GetJobByDisplayID(c.Request().Context(), jobId)
+	if err != nil {
+		return job, errors.Wrapf(err, "failed to get job by display id")
+	}
+	return job, nil
+}
+
+func toJob(row admindb.TacokumoAdminJob) Job {
+	job := Job{
+		ID:          row.DisplayID.String(),
+		Kind:        row.Kind,
+		Status:      row.Status,
+		Attempts:    row.Attempts,
+		MaxAttempts: row.MaxAttempts,
+		RunAt:       row.RunAt.Time,
+		LockedUntil: timePtr(row.LockedUntil),
+		StartedAt:   timePtr(row.StartedAt),
+		FinishedAt:  timePtr(row.FinishedAt),
+		Payload:     row.Payload,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+	if row.LockedBy.Valid {
+		job.LockedBy = &row.LockedBy.String
+	}
+	if row.LastError.Valid {
+		job.LastError = &row.LastError.String
+	}
+	return job
+}
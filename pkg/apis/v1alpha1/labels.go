@@ -0,0 +1,175 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/labels"
+	"github.com/tacokumo/admin-api/pkg/middleware"
+)
+
+// Metadata is the labels and annotations of a project, role or user group.
+// They are not part of the generated resource types, so they are read here and written with
+// the labels and annotations members of the PATCH documents.
+type Metadata struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// metadataPatch is the labels and annotations members of a merge patch document.
+// A null key removes that key, and a null member removes all of them.
+type metadataPatch struct {
+	Labels      patchField[map[string]*string] `json:"labels"`
+	Annotations patchField[map[string]*string] `json:"annotations"`
+}
+
+func (p metadataPatch) validate() error {
+	for k, v := range p.Labels.Value {
+		if err := labels.ValidateKey(k); err != nil {
+			return errUnprocessable("%s", err.Error())
+		}
+		if v != nil {
+			if err := labels.ValidateValue(*v); err != nil {
+				return errUnprocessable("label %q: %s", k, err.Error())
+			}
+		}
+	}
+	for k := range p.Annotations.Value {
+		if err := labels.ValidateKey(k); err != nil {
+			return errUnprocessable("%s", err.Error())
+		}
+	}
+	return nil
+}
+
+// apply returns the patched labels and annotations as JSON, or nil for those that the patch
+// leaves unchanged.
+func (p metadataPatch) apply(currentLabels, currentAnnotations []byte) (newLabels []byte, newAnnotations []byte, err error) {
+	if p.Labels.Set {
+		if newLabels, err = mergeMetadata(currentLabels, p.Labels); err != nil {
+			return nil, nil, err
+		}
+	}
+	if p.Annotations.Set {
+		if newAnnotations, err = mergeMetadata(currentAnnotations, p.Annotations); err != nil {
+			return nil, nil, err
+		}
+		annotations, err := decodeMetadata(newAnnotations)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := labels.ValidateAnnotations(annotations); err != nil {
+			return nil, nil, errUnprocessable("%s", err.Error())
+		}
+	}
+	return newLabels, newAnnotations, nil
+}
+
+func mergeMetadata(current []byte, patch patchField[map[string]*string]) ([]byte, error) {
+	merged := map[string]string{}
+	if !patch.Null {
+		m, err := decodeMetadata(current)
+		if err != nil {
+			return nil, err
+		}
+		merged = labels.Merge(m, patch.Value)
+	}
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal metadata")
+	}
+	return b, nil
+}
+
+// decodeMetadata decodes a labels or annotations column.
+func decodeMetadata(b []byte) (map[string]string, error) {
+	m := map[string]string{}
+	if len(b) == 0 {
+		return m, nil
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal metadata")
+	}
+	return m, nil
+}
+
+func toMetadata(labelsJSON, annotationsJSON []byte) (Metadata, error) {
+	l, err := decodeMetadata(labelsJSON)
+	if err != nil {
+		return Metadata{}, err
+	}
+	a, err := decodeMetadata(annotationsJSON)
+	if err != nil {
+		return Metadata{}, err
+	}
+	return Metadata{Labels: l, Annotations: a}, nil
+}
+
+// labelFilter reads the labelSelector query parameter of a list request.
+// It is not part of the generated parameters, so it is read from the request.
+func labelFilter(ctx context.Context) (labels.Filter, error) {
+	selector, err := labels.ParseSelector(middleware.QueryParam(ctx, "labelSelector"))
+	if err != nil {
+		return labels.Filter{}, errBadRequest("%s", err.Error())
+	}
+	return selector.Filter()
+}
+
+func (s *Service) getProjectMetadata(c echo.Context) error {
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return s.writeMetadata(c, proj.Version, proj.Labels, proj.Annotations)
+}
+
+func (s *Service) getRoleMetadata(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	roleId, err := parseDisplayID("roleId", c.Param("roleId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	role, err := s.queries.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{ProjectID: proj.ID, DisplayID: roleId})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get role by display id"))
+	}
+	return s.writeMetadata(c, role.Version, role.Labels, role.Annotations)
+}
+
+func (s *Service) getUserGroupMetadata(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	groupId, err := parseDisplayID("groupId", c.Param("groupId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	group, err := s.queries.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{ProjectID: proj.ID, DisplayID: groupId})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get user group by display id"))
+	}
+	return s.writeMetadata(c, group.Version, group.Labels, group.Annotations)
+}
+
+// writeMetadata responds with the metadata of a resource, whose version is sent as the ETag
+// so that a following PATCH can be made conditional on it.
+func (s *Service) writeMetadata(c echo.Context, version int64, labelsJSON, annotationsJSON []byte) error {
+	metadata, err := toMetadata(labelsJSON, annotationsJSON)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	setETag(c.Request().Context(), version)
+	return c.JSON(http.StatusOK, metadata)
+}
@@ -0,0 +1,523 @@
+package v1alpha1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	adminv1alpha1 "github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// mimeMergePatch is the media type of JSON Merge Patch documents (RFC 7396).
+const mimeMergePatch = "application/merge-patch+json"
+
+// patchField is a member of a JSON Merge Patch document.
+// Set reports whether the member was present at all and Null whether it was an explicit null,
+// which distinguishes "keep the current value" from "clear the value".
+type patchField[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It is only called for members that are present.
+func (f *patchField[T]) UnmarshalJSON(b []byte) error {
+	f.Set = true
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(b, &f.Value)
+}
+
+// apply returns the patched value of a field whose current value is current.
+// An explicit null resets the field to its zero value.
+func (f patchField[T]) apply(current T) T {
+	if !f.Set {
+		return current
+	}
+	if f.Null {
+		var zero T
+		return zero
+	}
+	return f.Value
+}
+
+// required rejects an explicit null for a field that cannot be cleared.
+func (f patchField[T]) required(name string) error {
+	if f.Set && f.Null {
+		return errUnprocessable("%s cannot be null", name)
+	}
+	return nil
+}
+
+// decodeMergePatch decodes a JSON Merge Patch document into v.
+// Only JSON objects are accepted as patches, and members that v does not know are rejected so that
+// typos are not silently ignored.
+func decodeMergePatch(c echo.Context, v any) error {
+	if ct := c.Request().Header.Get(echo.HeaderContentType); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mediaType != mimeMergePatch && mediaType != echo.MIMEApplicationJSON) {
+			return newAPIError(http.StatusUnsupportedMediaType, "content type must be %s", mimeMergePatch)
+		}
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return errBadRequest("failed to read request body")
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '{' {
+		return errBadRequest("merge patch must be a JSON object")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errBadRequest("invalid merge patch: %s", err.Error())
+	}
+	return nil
+}
+
+// ProjectPatch is the merge patch document accepted by PATCH /projects/{projectId}.
+type ProjectPatch struct {
+	Name        patchField[string]   `json:"name"`
+	Description patchField[string]   `json:"description"`
+	Kind        patchField[string]   `json:"kind"`
+	OwnerIds    patchField[[]string] `json:"ownerIds"`
+	// OwnerGroupIds replaces the owner groups of a shared project.
+	OwnerGroupIds patchField[[]string] `json:"ownerGroupIds"`
+	metadataPatch
+}
+
+func (p ProjectPatch) validate() error {
+	if err := p.Name.required("name"); err != nil {
+		return err
+	}
+	if p.Name.Set && p.Name.Value == "" {
+		return errUnprocessable("name must not be empty")
+	}
+	if err := p.Kind.required("kind"); err != nil {
+		return err
+	}
+	if p.Kind.Set {
+		if err := adminv1alpha1.ProjectKind(p.Kind.Value).Validate(); err != nil {
+			return errUnprocessable("kind must be one of %s, %s", adminv1alpha1.ProjectKindPersonal, adminv1alpha1.ProjectKindShared)
+		}
+	}
+	return p.metadataPatch.validate()
+}
+
+// RolePatch is the merge patch document accepted by PATCH /projects/{projectId}/roles/{roleId}.
+type RolePatch struct {
+	Name        patchField[string] `json:"name"`
+	Description patchField[string] `json:"description"`
+	metadataPatch
+}
+
+func (p RolePatch) validate() error {
+	if err := p.Name.required("name"); err != nil {
+		return err
+	}
+	if p.Name.Set && p.Name.Value == "" {
+		return errUnprocessable("name must not be empty")
+	}
+	return p.metadataPatch.validate()
+}
+
+// UserGroupPatch is the merge patch document accepted by PATCH /projects/{projectId}/usergroups/{groupId}.
+type UserGroupPatch struct {
+	Name        patchField[string]   `json:"name"`
+	Description patchField[string]   `json:"description"`
+	MemberIds   patchField[[]string] `json:"memberIds"`
+	metadataPatch
+}
+
+func (p UserGroupPatch) validate() error {
+	if err := p.Name.required("name"); err != nil {
+		return err
+	}
+	if p.Name.Set && p.Name.Value == "" {
+		return errUnprocessable("name must not be empty")
+	}
+	return p.metadataPatch.validate()
+}
+
+func (s *Service) patchProject(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	displayId, err := parseDisplayID("projectId", c.Param("projectId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var patch ProjectPatch
+	if err := decodeMergePatch(c, &patch); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := patch.validate(); err != nil {
+		return s.writeError(c, err)
+	}
+	versions, err := expectedVersions(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var (
+		proj    admindb.TacokumoAdminProject
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		current, err := q.GetProjectByDisplayID(ctx, displayId)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get project by display id")
+		}
+		if err := checkVersion("project", versions, current.Version); err != nil {
+			return err
+		}
+		if patch.Kind.Set && patch.Kind.Value != current.Kind {
+			return errUnprocessable("kind cannot be changed by a patch because memberships have to be migrated; use POST /v1alpha1/projects/%s/convert", current.DisplayID.String())
+		}
+
+		owners := []admindb.TacokumoAdminUser{}
+		if patch.OwnerIds.Set {
+			if owners, err = resolveUsers(ctx, q, lo.Uniq(patch.OwnerIds.apply(nil))); err != nil {
+				return err
+			}
+		}
+		ownerGroups := []admindb.TacokumoAdminUsergroup{}
+		if patch.OwnerGroupIds.Set {
+			if ownerGroups, err = resolveOwnerGroups(ctx, q, current, patch.OwnerGroupIds.apply(nil)); err != nil {
+				return err
+			}
+		}
+
+		newLabels, newAnnotations, err := patch.metadataPatch.apply(current.Labels, current.Annotations)
+		if err != nil {
+			return err
+		}
+		affected, err := q.UpdateProject(ctx, admindb.UpdateProjectParams{
+			DisplayID:   displayId,
+			Name:        patch.Name.apply(current.Name),
+			Description: patch.Description.apply(current.Description),
+			Labels:      newLabels,
+			Annotations: newAnnotations,
+			// Fields that are not in the patch are carried over from the row read above,
+			// so the update must not win over a concurrent one.
+			ExpectedVersions: []int64{current.Version},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update project")
+		}
+		if affected == 0 {
+			return errConflict("project was modified concurrently, please retry")
+		}
+
+		if patch.OwnerIds.Set {
+			if err := q.DeleteProjectOwners(ctx, current.ID); err != nil {
+				return errors.Wrapf(err, "failed to delete project owners")
+			}
+			for _, owner := range owners {
+				if err := q.AddProjectOwner(ctx, admindb.AddProjectOwnerParams{ProjectID: current.ID, UserID: owner.ID}); err != nil {
+					return errors.Wrapf(err, "failed to add project owner")
+				}
+			}
+		}
+		if patch.OwnerGroupIds.Set {
+			if err := q.DeleteProjectOwnerGroups(ctx, current.ID); err != nil {
+				return errors.Wrapf(err, "failed to delete project owner groups")
+			}
+			for _, group := range ownerGroups {
+				if err := q.AddProjectOwnerGroup(ctx, admindb.AddProjectOwnerGroupParams{ProjectID: current.ID, UsergroupID: group.ID}); err != nil {
+					return errors.Wrapf(err, "failed to add project owner group")
+				}
+			}
+		}
+		if err := project.Check(ctx, q, current.ID); err != nil {
+			return err
+		}
+
+		proj, err = q.GetProjectByDisplayID(ctx, displayId)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get project by display id")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindProject,
+			Action:     events.ActionUpdated,
+			ResourceID: proj.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, pending)
+	setETag(ctx, proj.Version)
+	resp := toProject(proj)
+	return c.JSON(http.StatusOK, &resp)
+}
+
+func (s *Service) patchRole(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	projectId, err := parseDisplayID("projectId", c.Param("projectId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	roleId, err := parseDisplayID("roleId", c.Param("roleId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var patch RolePatch
+	if err := decodeMergePatch(c, &patch); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := patch.validate(); err != nil {
+		return s.writeError(c, err)
+	}
+	versions, err := expectedVersions(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var (
+		proj    admindb.TacokumoAdminProject
+		role    admindb.TacokumoAdminRole
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		proj, err = q.GetProjectByDisplayID(ctx, projectId)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get project by display id")
+		}
+		current, err := q.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{ProjectID: proj.ID, DisplayID: roleId})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get role by display id")
+		}
+		if err := checkVersion("role", versions, current.Version); err != nil {
+			return err
+		}
+
+		newLabels, newAnnotations, err := patch.metadataPatch.apply(current.Labels, current.Annotations)
+		if err != nil {
+			return err
+		}
+		affected, err := q.UpdateRole(ctx, admindb.UpdateRoleParams{
+			ProjectID:        proj.ID,
+			DisplayID:        roleId,
+			Name:             patch.Name.apply(current.Name),
+			Description:      patch.Description.apply(current.Description),
+			Labels:           newLabels,
+			Annotations:      newAnnotations,
+			ExpectedVersions: []int64{current.Version},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update role")
+		}
+		if affected == 0 {
+			return errConflict("role was modified concurrently, please retry")
+		}
+
+		role, err = q.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{ProjectID: proj.ID, DisplayID: roleId})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get role by display id")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindRole,
+			Action:     events.ActionUpdated,
+			ResourceID: role.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, pending)
+	setETag(ctx, role.Version)
+	return c.JSON(http.StatusOK, &adminv1alpha1.Role{
+		ID:          role.DisplayID.String(),
+		Name:        role.Name,
+		Description: role.Description,
+		Project:     toProject(proj),
+		CreatedAt:   role.CreatedAt.Time,
+		UpdatedAt:   role.UpdatedAt.Time,
+	})
+}
+
+func (s *Service) patchUserGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	projectId, err := parseDisplayID("projectId", c.Param("projectId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	groupId, err := parseDisplayID("groupId", c.Param("groupId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var patch UserGroupPatch
+	if err := decodeMergePatch(c, &patch); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := patch.validate(); err != nil {
+		return s.writeError(c, err)
+	}
+	versions, err := expectedVersions(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var (
+		proj      admindb.TacokumoAdminProject
+		userGroup admindb.TacokumoAdminUsergroup
+		members   []admindb.TacokumoAdminUser
+		pending   []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		var added, removed []admindb.TacokumoAdminUser
+		proj, err = q.GetProjectByDisplayID(ctx, projectId)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get project by display id")
+		}
+		current, err := q.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{ProjectID: proj.ID, DisplayID: groupId})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get user group by display id")
+		}
+		if err := checkVersion("user group", versions, current.Version); err != nil {
+			return err
+		}
+
+		newMembers := []admindb.TacokumoAdminUser{}
+		if patch.MemberIds.Set {
+			if newMembers, err = resolveUsers(ctx, q, patch.MemberIds.apply(nil)); err != nil {
+				return err
+			}
+		}
+
+		newLabels, newAnnotations, err := patch.metadataPatch.apply(current.Labels, current.Annotations)
+		if err != nil {
+			return err
+		}
+		affected, err := q.UpdateUserGroup(ctx, admindb.UpdateUserGroupParams{
+			ProjectID:        proj.ID,
+			DisplayID:        groupId,
+			Name:             patch.Name.apply(current.Name),
+			Description:      patch.Description.apply(current.Description),
+			Labels:           newLabels,
+			Annotations:      newAnnotations,
+			ExpectedVersions: []int64{current.Version},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update user group")
+		}
+		if affected == 0 {
+			return errConflict("user group was modified concurrently, please retry")
+		}
+
+		if patch.MemberIds.Set {
+			oldMembers, err := q.ListUserGroupMembers(ctx, current.ID)
+			if err != nil {
+				return errors.Wrapf(err, "failed to list user group members")
+			}
+			removed = lo.Filter(oldMembers, func(u admindb.TacokumoAdminUser, _ int) bool {
+				return !lo.ContainsBy(newMembers, func(n admindb.TacokumoAdminUser) bool { return n.ID == u.ID })
+			})
+			added = lo.UniqBy(lo.Filter(newMembers, func(u admindb.TacokumoAdminUser, _ int) bool {
+				return !lo.ContainsBy(oldMembers, func(o admindb.TacokumoAdminUser) bool { return o.ID == u.ID })
+			}), func(u admindb.TacokumoAdminUser) int64 { return u.ID })
+
+			if err := q.DeleteUserGroupMembers(ctx, current.ID); err != nil {
+				return errors.Wrapf(err, "failed to delete user group members")
+			}
+			for _, user := range newMembers {
+				if err := q.AddUserToUserGroup(ctx, admindb.AddUserToUserGroupParams{UserID: user.ID, UsergroupID: current.ID}); err != nil {
+					return errors.Wrapf(err, "failed to add user to user group")
+				}
+			}
+		}
+
+		userGroup, err = q.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{ProjectID: proj.ID, DisplayID: groupId})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get user group by display id")
+		}
+		members, err = q.ListUserGroupMembers(ctx, userGroup.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list user group members")
+		}
+
+		evs := []events.Event{{
+			Kind:       events.KindUserGroup,
+			Action:     events.ActionUpdated,
+			ResourceID: userGroup.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		}}
+		for _, user := range added {
+			evs = append(evs, userGroupMemberEvent(events.ActionCreated, proj, userGroup, user))
+		}
+		for _, user := range removed {
+			evs = append(evs, userGroupMemberEvent(events.ActionDeleted, proj, userGroup, user))
+		}
+		pending, err = s.RecordEvents(ctx, q, evs...)
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, pending)
+	setETag(ctx, userGroup.Version)
+	return c.JSON(http.StatusOK, &adminv1alpha1.UserGroup{
+		ID:          userGroup.DisplayID.String(),
+		Name:        userGroup.Name,
+		Description: userGroup.Description,
+		Project:     toProject(proj),
+		Members:     lo.Map(members, func(u admindb.TacokumoAdminUser, _ int) adminv1alpha1.User { return toUser(u) }),
+		CreatedAt:   userGroup.CreatedAt.Time,
+		UpdatedAt:   userGroup.UpdatedAt.Time,
+	})
+}
+
+// resolveUsers looks up users by display ID. Unknown users are reported as unprocessable.
+// A user listed more than once, also with a differently written ID, is returned once.
+func resolveUsers(ctx context.Context, q *admindb.Queries, displayIds []string) ([]admindb.TacokumoAdminUser, error) {
+	users := make([]admindb.TacokumoAdminUser, 0, len(displayIds))
+	for _, v := range displayIds {
+		displayId, err := parseDisplayID("user id", v)
+		if err != nil {
+			return nil, err
+		}
+		user, err := q.GetUserByDisplayID(ctx, displayId)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errUnprocessable("user %s not found", v)
+			}
+			return nil, errors.Wrapf(err, "failed to get user by display id")
+		}
+		users = append(users, user)
+	}
+	return lo.UniqBy(users, func(u admindb.TacokumoAdminUser) int64 { return u.ID }), nil
+}
+
+func userGroupMemberEvent(action events.Action, proj admindb.TacokumoAdminProject, userGroup admindb.TacokumoAdminUsergroup, user admindb.TacokumoAdminUser) events.Event {
+	return events.Event{
+		Kind:       events.KindUserGroupMember,
+		Action:     action,
+		ResourceID: userGroup.DisplayID.String(),
+		ProjectID:  proj.DisplayID.String(),
+		MemberID:   user.DisplayID.String(),
+		MemberKind: events.MemberKindUser,
+	}
+}
@@ -0,0 +1,35 @@
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/middleware"
+)
+
+// errNoAdminUser is returned when the authenticated principal has no row in tacokumo_admin.users.
+var errNoAdminUser = errors.New("no admin user is associated with the current principal")
+
+// currentUser returns the admin user that corresponds to the authenticated GitHub login.
+// Service accounts never resolve to a user.
+func (s *Service) currentUser(ctx context.Context) (admindb.TacokumoAdminUser, error) {
+	sess := middleware.GetCurrentSession(ctx)
+	if sess == nil {
+		return admindb.TacokumoAdminUser{}, newAPIError(http.StatusUnauthorized, "not authenticated")
+	}
+	if sess.IsServiceAccount() || sess.Email == "" {
+		return admindb.TacokumoAdminUser{}, errNoAdminUser
+	}
+
+	user, err := s.queries.GetUserByEmail(ctx, sess.Email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return admindb.TacokumoAdminUser{}, errNoAdminUser
+		}
+		return admindb.TacokumoAdminUser{}, errors.Wrapf(err, "failed to get user by email")
+	}
+	return user, nil
+}
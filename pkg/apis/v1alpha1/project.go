@@ -0,0 +1,375 @@
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	adminv1alpha1 "github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// ProjectOwners lists who owns a project.
+type ProjectOwners struct {
+	Project     adminv1alpha1.Project `json:"project"`
+	Owners      []adminv1alpha1.User  `json:"owners"`
+	OwnerGroups []ProjectOwnerGroup   `json:"ownerGroups"`
+}
+
+type ProjectOwnerGroup struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ConvertProjectRequest is the body of POST /projects/{projectId}/convert.
+type ConvertProjectRequest struct {
+	Kind string `json:"kind"`
+	// OwnerID is the owner of the project when converting to personal. It may be omitted when
+	// the project has exactly one owner, counting the members of its owner groups.
+	OwnerID string `json:"ownerId"`
+	// OwnerIDs are the members of the owner group created when converting to shared.
+	// Defaults to the current owner.
+	OwnerIDs []string `json:"ownerIds"`
+	// OwnerGroupName is the name of the owner group created when converting to shared.
+	OwnerGroupName string `json:"ownerGroupName"`
+	// RemoveMembers allows converting to personal when user groups have members other than
+	// the new owner. Their memberships are removed along with the user groups.
+	RemoveMembers bool `json:"removeMembers"`
+}
+
+func (s *Service) getProjectOwners(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	resp, err := loadProjectOwners(ctx, s.queries, proj)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	setETag(ctx, proj.Version)
+	return c.JSON(http.StatusOK, resp)
+}
+
+// convertProject changes the kind of a project and migrates its memberships.
+//
+// Converting to shared moves the owners into a new owner group. Converting to personal keeps a
+// single owner, assigns them the roles they held through user groups directly and deletes the
+// user groups, which personal projects cannot have.
+func (s *Service) convertProject(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	displayId, err := parseDisplayID("projectId", c.Param("projectId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var req ConvertProjectRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if !project.ValidKind(req.Kind) {
+		return s.writeError(c, errUnprocessable("kind must be one of %s, %s", project.KindPersonal, project.KindShared))
+	}
+	versions, err := expectedVersions(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var (
+		proj admindb.TacokumoAdminProject
+		resp ProjectOwners
+		evs  []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		current, err := q.GetProjectByDisplayID(ctx, displayId)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get project by display id")
+		}
+		if err := checkVersion("project", versions, current.Version); err != nil {
+			return err
+		}
+		if current.Kind == req.Kind {
+			return errConflict("project is already %s", req.Kind)
+		}
+
+		switch req.Kind {
+		case project.KindShared:
+			evs, err = s.convertToShared(ctx, q, current, req)
+		case project.KindPersonal:
+			evs, err = convertToPersonal(ctx, q, current, req)
+		}
+		if err != nil {
+			return err
+		}
+
+		affected, err := q.UpdateProject(ctx, admindb.UpdateProjectParams{
+			DisplayID:        displayId,
+			Name:             current.Name,
+			Description:      current.Description,
+			Kind:             pgtype.Text{String: req.Kind, Valid: true},
+			ExpectedVersions: []int64{current.Version},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update project")
+		}
+		if affected == 0 {
+			return errConflict("project was modified concurrently, please retry")
+		}
+		if err := project.Check(ctx, q, current.ID); err != nil {
+			return err
+		}
+
+		if proj, err = q.GetProjectByDisplayID(ctx, displayId); err != nil {
+			return errors.Wrapf(err, "failed to get project by display id")
+		}
+		if resp, err = loadProjectOwners(ctx, q, proj); err != nil {
+			return err
+		}
+		evs, err = s.RecordEvents(ctx, q, append([]events.Event{{
+			Kind:       events.KindProject,
+			Action:     events.ActionUpdated,
+			ResourceID: proj.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		}}, evs...)...)
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, evs)
+	setETag(ctx, proj.Version)
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Service) convertToShared(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, req ConvertProjectRequest) ([]events.Event, error) {
+	if req.OwnerID != "" || req.RemoveMembers {
+		return nil, errUnprocessable("ownerId and removeMembers only apply when converting to %s", project.KindPersonal)
+	}
+
+	var owners []admindb.TacokumoAdminUser
+	var err error
+	if len(req.OwnerIDs) > 0 {
+		owners, err = resolveUsers(ctx, q, lo.Uniq(req.OwnerIDs))
+	} else {
+		owners, err = q.ListProjectOwners(ctx, proj.ID)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve owners")
+	}
+	if len(owners) == 0 {
+		return nil, errUnprocessable("the project has no owner; specify ownerIds")
+	}
+
+	name := req.OwnerGroupName
+	if name == "" {
+		name = project.DefaultOwnerGroupName
+	}
+	groupId, err := createOwnerGroup(ctx, q, proj, name, owners)
+	if err != nil {
+		return nil, err
+	}
+	return ownerGroupCreatedEvents(proj, groupId, owners), nil
+}
+
+func convertToPersonal(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, req ConvertProjectRequest) ([]events.Event, error) {
+	if len(req.OwnerIDs) > 0 || req.OwnerGroupName != "" {
+		return nil, errUnprocessable("ownerIds and ownerGroupName only apply when converting to %s", project.KindShared)
+	}
+
+	owner, err := personalOwner(ctx, q, proj, req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := q.ListProjectUserGroupMembers(ctx, proj.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list user group members")
+	}
+	others := lo.Filter(members, func(u admindb.TacokumoAdminUser, _ int) bool { return u.ID != owner.ID })
+	serviceAccounts, err := q.CountProjectUserGroupServiceAccounts(ctx, proj.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to count user group service accounts")
+	}
+	if (len(others) > 0 || serviceAccounts > 0) && !req.RemoveMembers {
+		return nil, errUnprocessable(
+			"personal projects cannot have user groups, and %d other users and %d service accounts are members of them; set removeMembers to remove them",
+			len(others), serviceAccounts,
+		)
+	}
+
+	var evs []events.Event
+	roleIds, err := q.CopyUserGroupRolesToUser(ctx, admindb.CopyUserGroupRolesToUserParams{ProjectID: proj.ID, UserID: owner.ID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to copy user group roles to owner")
+	}
+	for _, roleId := range roleIds {
+		evs = append(evs, events.Event{
+			Kind:       events.KindRoleAssignment,
+			Action:     events.ActionCreated,
+			ResourceID: roleId.String(),
+			ProjectID:  proj.DisplayID.String(),
+			MemberID:   owner.DisplayID.String(),
+			MemberKind: events.MemberKindUser,
+		})
+	}
+
+	if err := q.DeleteProjectOwners(ctx, proj.ID); err != nil {
+		return nil, errors.Wrapf(err, "failed to delete project owners")
+	}
+	if err := q.AddProjectOwner(ctx, admindb.AddProjectOwnerParams{ProjectID: proj.ID, UserID: owner.ID}); err != nil {
+		return nil, errors.Wrapf(err, "failed to add project owner")
+	}
+	// Owner groups and memberships are removed with the user groups by cascade.
+	groupIds, err := q.DeleteUserGroupsByProject(ctx, proj.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to delete user groups")
+	}
+	for _, groupId := range groupIds {
+		evs = append(evs, events.Event{
+			Kind:       events.KindUserGroup,
+			Action:     events.ActionDeleted,
+			ResourceID: groupId.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+	}
+	return evs, nil
+}
+
+// personalOwner picks the owner of a project that is converted to personal: the requested user,
+// or the only user among the owners and the members of the owner groups.
+func personalOwner(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, ownerId string) (admindb.TacokumoAdminUser, error) {
+	if ownerId != "" {
+		users, err := resolveUsers(ctx, q, []string{ownerId})
+		if err != nil {
+			return admindb.TacokumoAdminUser{}, err
+		}
+		return users[0], nil
+	}
+
+	candidates, err := q.ListProjectOwners(ctx, proj.ID)
+	if err != nil {
+		return admindb.TacokumoAdminUser{}, errors.Wrapf(err, "failed to list project owners")
+	}
+	groups, err := q.ListProjectOwnerGroups(ctx, proj.ID)
+	if err != nil {
+		return admindb.TacokumoAdminUser{}, errors.Wrapf(err, "failed to list project owner groups")
+	}
+	for _, group := range groups {
+		members, err := q.ListUserGroupMembers(ctx, group.ID)
+		if err != nil {
+			return admindb.TacokumoAdminUser{}, errors.Wrapf(err, "failed to list user group members")
+		}
+		candidates = append(candidates, members...)
+	}
+	candidates = lo.UniqBy(candidates, func(u admindb.TacokumoAdminUser) int64 { return u.ID })
+	if len(candidates) != 1 {
+		return admindb.TacokumoAdminUser{}, errUnprocessable("personal projects have exactly one owner, but the project has %d; specify ownerId", len(candidates))
+	}
+	return candidates[0], nil
+}
+
+// resolveOwners resolves the requested owners of a project, defaulting to the calling user.
+func (s *Service) resolveOwners(ctx context.Context, q *admindb.Queries, ownerIds []string) ([]admindb.TacokumoAdminUser, error) {
+	if len(ownerIds) > 0 {
+		return resolveUsers(ctx, q, lo.Uniq(ownerIds))
+	}
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		if errors.Is(err, errNoAdminUser) {
+			return nil, errUnprocessable("ownerIds is required when the caller is not an admin user")
+		}
+		return nil, err
+	}
+	return []admindb.TacokumoAdminUser{user}, nil
+}
+
+// createOwnerGroup creates a user group with members and makes it an owner group of the project.
+func createOwnerGroup(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, name string, members []admindb.TacokumoAdminUser) (pgtype.UUID, error) {
+	groupId, err := q.CreateUserGroup(ctx, admindb.CreateUserGroupParams{
+		ProjectID:   proj.ID,
+		Name:        name,
+		Description: "Owners of the project",
+	})
+	if err != nil {
+		return groupId, errors.Wrapf(err, "failed to create owner group")
+	}
+	group, err := q.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{ProjectID: proj.ID, DisplayID: groupId})
+	if err != nil {
+		return groupId, errors.Wrapf(err, "failed to get user group by display id")
+	}
+	for _, member := range members {
+		if err := q.AddUserToUserGroup(ctx, admindb.AddUserToUserGroupParams{UserID: member.ID, UsergroupID: group.ID}); err != nil {
+			return groupId, errors.Wrapf(err, "failed to add user to user group")
+		}
+	}
+	if err := q.AddProjectOwnerGroup(ctx, admindb.AddProjectOwnerGroupParams{ProjectID: proj.ID, UsergroupID: group.ID}); err != nil {
+		return groupId, errors.Wrapf(err, "failed to add project owner group")
+	}
+	return groupId, nil
+}
+
+func ownerGroupCreatedEvents(proj admindb.TacokumoAdminProject, groupId pgtype.UUID, members []admindb.TacokumoAdminUser) []events.Event {
+	evs := []events.Event{{
+		Kind:       events.KindUserGroup,
+		Action:     events.ActionCreated,
+		ResourceID: groupId.String(),
+		ProjectID:  proj.DisplayID.String(),
+	}}
+	for _, member := range members {
+		evs = append(evs, events.Event{
+			Kind:       events.KindUserGroupMember,
+			Action:     events.ActionCreated,
+			ResourceID: groupId.String(),
+			ProjectID:  proj.DisplayID.String(),
+			MemberID:   member.DisplayID.String(),
+			MemberKind: events.MemberKindUser,
+		})
+	}
+	return evs
+}
+
+// resolveOwnerGroups looks up the owner groups requested for a project, which must belong to it.
+func resolveOwnerGroups(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, ids []string) ([]admindb.TacokumoAdminUsergroup, error) {
+	groups := make([]admindb.TacokumoAdminUsergroup, 0, len(ids))
+	for _, id := range lo.Uniq(ids) {
+		groupId, err := parseDisplayID("owner group id", id)
+		if err != nil {
+			return nil, err
+		}
+		group, err := q.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{ProjectID: proj.ID, DisplayID: groupId})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errUnprocessable("user group %s not found in the project", id)
+			}
+			return nil, errors.Wrapf(err, "failed to get user group by display id")
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func loadProjectOwners(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject) (ProjectOwners, error) {
+	owners, err := q.ListProjectOwners(ctx, proj.ID)
+	if err != nil {
+		return ProjectOwners{}, errors.Wrapf(err, "failed to list project owners")
+	}
+	groups, err := q.ListProjectOwnerGroups(ctx, proj.ID)
+	if err != nil {
+		return ProjectOwners{}, errors.Wrapf(err, "failed to list project owner groups")
+	}
+	return ProjectOwners{
+		Project: toProject(proj),
+		Owners:  lo.Map(owners, func(u admindb.TacokumoAdminUser, _ int) adminv1alpha1.User { return toUser(u) }),
+		OwnerGroups: lo.Map(groups, func(g admindb.TacokumoAdminUsergroup, _ int) ProjectOwnerGroup {
+			return ProjectOwnerGroup{ID: g.DisplayID.String(), Name: g.Name}
+		}),
+	}, nil
+}
@@ -0,0 +1,136 @@
+package v1alpha1
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultListLimit = 10
+	maxListLimit     = 100
+)
+
+// RegisterRoutes registers the endpoints that are served by Echo directly rather than
+// through the generated ogen server. They are registered on the same group as the
+// generated server and take precedence over its catch-all route. Middleware added here
+// also applies to the generated server, which is registered on the group afterwards.
+func (s *Service) RegisterRoutes(g *echo.Group) {
+	g.Use(s.rejectArchivedProjectMutations)
+	g.GET("/events", s.streamEvents)
+	g.POST("/apply", s.applyManifest)
+	g.GET("/export", s.exportDataset)
+	g.POST("/import", s.importDataset)
+	g.POST("/invitations/accept", s.acceptInvitation)
+	g.GET("/jobs", s.listJobs)
+	g.GET("/jobs/counts", s.countJobs)
+	g.GET("/jobs/:jobId", s.getJob)
+	g.POST("/jobs/:jobId/retry", s.retryJob)
+	g.POST("/serviceaccounts", s.createServiceAccount)
+	g.GET("/serviceaccounts", s.listServiceAccounts)
+	g.GET("/serviceaccounts/:serviceAccountId", s.getServiceAccount)
+	g.PUT("/serviceaccounts/:serviceAccountId", s.updateServiceAccount)
+	g.PATCH("/serviceaccounts/:serviceAccountId", s.patchServiceAccount)
+	g.DELETE("/serviceaccounts/:serviceAccountId", s.deleteServiceAccount)
+	g.POST("/serviceaccounts/:serviceAccountId/tokens", s.createServiceAccountToken)
+	g.GET("/serviceaccounts/:serviceAccountId/tokens", s.listServiceAccountTokens)
+	g.DELETE("/serviceaccounts/:serviceAccountId/tokens/:tokenId", s.revokeServiceAccountToken)
+	g.POST("/projecttemplates", s.createProjectTemplate)
+	g.GET("/projecttemplates", s.listProjectTemplates)
+	g.GET("/projecttemplates/:templateId", s.getProjectTemplate)
+	g.DELETE("/projecttemplates/:templateId", s.deleteProjectTemplate)
+	g.POST("/projecttemplates/:templateId/projects", s.createProjectFromTemplate)
+	g.PATCH("/projects/:projectId", s.patchProject)
+	g.GET("/projects/:projectId/owners", s.getProjectOwners)
+	g.GET("/projects/:projectId/metadata", s.getProjectMetadata)
+	g.POST("/projects/:projectId/convert", s.convertProject)
+	g.POST("/projects/:projectId/clone", s.cloneProject)
+	g.GET("/projects/:projectId/archive", s.getProjectArchive)
+	g.POST("/projects/:projectId/archive", s.archiveProject)
+	g.POST("/projects/:projectId/unarchive", s.unarchiveProject)
+	g.POST("/projects/:projectId/transfers", s.createProjectTransfer)
+	g.GET("/projects/:projectId/transfers", s.listProjectTransfers)
+	g.GET("/projects/:projectId/transfers/audit", s.listProjectTransferAudit)
+	g.GET("/projects/:projectId/transfers/:transferId", s.getProjectTransfer)
+	g.POST("/projects/:projectId/transfers/:transferId/accept", s.acceptProjectTransfer)
+	g.POST("/projects/:projectId/transfers/:transferId/cancel", s.cancelProjectTransfer)
+	g.PATCH("/projects/:projectId/roles/:roleId", s.patchRole)
+	g.GET("/projects/:projectId/roles/:roleId/metadata", s.getRoleMetadata)
+	g.PATCH("/projects/:projectId/usergroups/:groupId", s.patchUserGroup)
+	g.GET("/projects/:projectId/usergroups/:groupId/metadata", s.getUserGroupMetadata)
+	g.POST("/projects/:projectId/webhooks", s.createWebhook)
+	g.GET("/projects/:projectId/webhooks", s.listWebhooks)
+	g.GET("/projects/:projectId/webhooks/:webhookId", s.getWebhook)
+	g.PATCH("/projects/:projectId/webhooks/:webhookId", s.patchWebhook)
+	g.DELETE("/projects/:projectId/webhooks/:webhookId", s.deleteWebhook)
+	g.POST("/projects/:projectId/webhooks/:webhookId/secret", s.rotateWebhookSecret)
+	g.GET("/projects/:projectId/webhooks/:webhookId/deliveries", s.listWebhookDeliveries)
+	g.GET("/projects/:projectId/webhooks/:webhookId/deliveries/:deliveryId", s.getWebhookDelivery)
+	g.POST("/projects/:projectId/webhooks/:webhookId/deliveries/:deliveryId/redeliver", s.redeliverWebhookDelivery)
+	g.POST("/projects/:projectId/invitations", s.createInvitation)
+	g.GET("/projects/:projectId/invitations", s.listInvitations)
+	g.GET("/projects/:projectId/invitations/:invitationId", s.getInvitation)
+	g.DELETE("/projects/:projectId/invitations/:invitationId", s.revokeInvitation)
+	g.POST("/projects/:projectId/invitations/:invitationId/resend", s.resendInvitation)
+	g.PUT("/projects/:projectId/usergroups/:groupId/serviceaccounts/:serviceAccountId", s.addServiceAccountToUserGroup)
+	g.DELETE("/projects/:projectId/usergroups/:groupId/serviceaccounts/:serviceAccountId", s.removeServiceAccountFromUserGroup)
+	g.PUT("/projects/:projectId/roles/:roleId/serviceaccounts/:serviceAccountId", s.assignRoleToServiceAccount)
+	g.DELETE("/projects/:projectId/roles/:roleId/serviceaccounts/:serviceAccountId", s.unassignRoleFromServiceAccount)
+}
+
+// parseDisplayID parses a display ID taken from a path or query parameter.
+func parseDisplayID(name, value string) (pgtype.UUID, error) {
+	id := pgtype.UUID{}
+	if err := id.Scan(value); err != nil {
+		return id, errBadRequest("invalid %s: %s", name, value)
+	}
+	return id, nil
+}
+
+// parsePagination reads limit and offset query parameters with the same defaults and
+// bounds as the generated list operations.
+func parsePagination(c echo.Context) (limit int32, offset int32, err error) {
+	limit = defaultListLimit
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListLimit {
+			return 0, 0, errBadRequest("limit must be between 1 and %d", maxListLimit)
+		}
+		limit = int32(n)
+	}
+	if v := c.QueryParam("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, errBadRequest("offset must not be negative")
+		}
+		offset = int32(n)
+	}
+	return limit, offset, nil
+}
+
+// bindJSON decodes the request body, reporting malformed bodies as bad requests.
+func bindJSON(c echo.Context, v any) error {
+	if err := c.Bind(v); err != nil {
+		return errBadRequest("invalid request body")
+	}
+	return nil
+}
+
+// uuidPtr returns the string form of a nullable UUID.
+func uuidPtr(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
+
+// timePtr returns the time of a nullable timestamp.
+func timePtr(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
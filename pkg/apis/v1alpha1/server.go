@@ -4,26 +4,39 @@ import (
 	"context"
 	"log/slog"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/samber/lo"
 	adminv1alpha1 "github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
 	"github.com/tacokumo/admin-api/pkg/auth/oauth"
 	"github.com/tacokumo/admin-api/pkg/auth/session"
 	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/health"
 	"github.com/tacokumo/admin-api/pkg/middleware"
+	"github.com/tacokumo/admin-api/pkg/project"
 )
 
 type Service struct {
 	logger       *slog.Logger
+	pool         *pgxpool.Pool
 	queries      *admindb.Queries
 	githubClient *oauth.GitHubClient
 	sessionStore session.Store
 	stateStore   session.Store
 	frontendURL  string
 	sessionTTL   time.Duration
+	eventStream  events.Stream
+	invitations  InvitationSettings
+	projects     ProjectSettings
+	health       *health.Registry
+	// streams is cancelled by CloseStreams to end the open event streams.
+	streams      context.Context
+	closeStreams context.CancelFunc
 }
 
 // CreateRole implements generated.Handler.
@@ -37,16 +50,34 @@ func (s *Service) CreateRole(ctx context.Context, req *adminv1alpha1.CreateRoleR
 		return nil, errors.Wrapf(err, "failed to get project by display id")
 	}
 
-	err = s.queries.CreateRole(ctx, admindb.CreateRoleParams{
-		ProjectID:   proj.ID,
-		Name:        req.Name,
-		Description: req.Description,
+	var (
+		roleId  pgtype.UUID
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		roleId, err = q.CreateRole(ctx, admindb.CreateRoleParams{
+			ProjectID:   proj.ID,
+			Name:        req.Name,
+			Description: req.Description,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create role")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindRole,
+			Action:     events.ActionCreated,
+			ResourceID: roleId.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
 	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create role")
+		return nil, err
 	}
+	s.publishAll(ctx, pending)
 
 	return &adminv1alpha1.Role{
+		ID:          roleId.String(),
 		Name:        req.Name,
 		Description: req.Description,
 		Project: adminv1alpha1.Project{
@@ -62,14 +93,29 @@ func (s *Service) CreateRole(ctx context.Context, req *adminv1alpha1.CreateRoleR
 
 // CreateUser implements generated.Handler.
 func (s *Service) CreateUser(ctx context.Context, req *adminv1alpha1.CreateUserRequest) (adminv1alpha1.CreateUserRes, error) {
-	if err := s.queries.CreateUser(ctx, req.Email); err != nil {
-		return nil, errors.Wrapf(err, "failed to create user")
-	}
-
-	user, err := s.queries.GetUserByEmail(ctx, req.Email)
+	var (
+		user    admindb.TacokumoAdminUser
+		pending []events.Event
+	)
+	err := s.withTx(ctx, func(q *admindb.Queries) error {
+		if err := q.CreateUser(ctx, req.Email); err != nil {
+			return errors.Wrapf(err, "failed to create user")
+		}
+		var err error
+		if user, err = q.GetUserByEmail(ctx, req.Email); err != nil {
+			return errors.Wrapf(err, "failed to get user by email")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindUser,
+			Action:     events.ActionCreated,
+			ResourceID: user.DisplayID.String(),
+		})
+		return err
+	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get user by email")
+		return nil, err
 	}
+	s.publishAll(ctx, pending)
 
 	return &adminv1alpha1.User{
 		ID:        user.DisplayID.String(),
@@ -91,16 +137,38 @@ func (s *Service) CreateUserGroup(ctx context.Context, req *adminv1alpha1.Create
 		return nil, errors.Wrapf(err, "failed to get project by display id")
 	}
 
-	err = s.queries.CreateUserGroup(ctx, admindb.CreateUserGroupParams{
-		ProjectID:   proj.ID,
-		Name:        req.Name,
-		Description: req.Description,
+	// Personal projects cannot have user groups, which project.Check rejects.
+	var (
+		groupId pgtype.UUID
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		groupId, err = q.CreateUserGroup(ctx, admindb.CreateUserGroupParams{
+			ProjectID:   proj.ID,
+			Name:        req.Name,
+			Description: req.Description,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create user group")
+		}
+		if err := project.Check(ctx, q, proj.ID); err != nil {
+			return err
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindUserGroup,
+			Action:     events.ActionCreated,
+			ResourceID: groupId.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
 	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create user group")
+		return nil, err
 	}
+	s.publishAll(ctx, pending)
 
 	return &adminv1alpha1.UserGroup{
+		ID:          groupId.String(),
 		Name:        req.Name,
 		Description: req.Description,
 		Project: adminv1alpha1.Project{
@@ -127,6 +195,7 @@ func (s *Service) GetProject(ctx context.Context, params adminv1alpha1.GetProjec
 		return nil, errors.Wrapf(err, "failed to get project by display id")
 	}
 
+	setETag(ctx, proj.Version)
 	return &adminv1alpha1.Project{
 		ID:          proj.DisplayID.String(),
 		Name:        proj.Name,
@@ -162,6 +231,7 @@ func (s *Service) GetRole(ctx context.Context, params adminv1alpha1.GetRoleParam
 		return nil, errors.Wrapf(err, "failed to get role by display id")
 	}
 
+	setETag(ctx, role.Version)
 	return &adminv1alpha1.Role{
 		ID:          role.DisplayID.String(),
 		Name:        role.Name,
@@ -203,6 +273,7 @@ func (s *Service) GetUserGroup(ctx context.Context, params adminv1alpha1.GetUser
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get user group by display id")
 	}
+	setETag(ctx, userGroup.Version)
 
 	userRecords, err := s.queries.ListUserGroupMembers(ctx, userGroup.ID)
 	if err != nil {
@@ -238,10 +309,30 @@ func (s *Service) GetUserGroup(ctx context.Context, params adminv1alpha1.GetUser
 }
 
 // ListRoles implements generated.Handler.
+// Roles can be filtered with ?labelSelector=.
 func (s *Service) ListRoles(ctx context.Context, params adminv1alpha1.ListRolesParams) (adminv1alpha1.ListRolesRes, error) {
+	projectId := pgtype.UUID{}
+	if err := projectId.Scan(params.ProjectId); err != nil {
+		return nil, errors.Wrapf(err, "failed to scan project id")
+	}
+	proj, err := s.queries.GetProjectByDisplayID(ctx, projectId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get project by display id")
+	}
+	filter, err := labelFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	roleRecords, err := s.queries.ListRolesWithPagination(ctx, admindb.ListRolesWithPaginationParams{
-		Limit:  int32(params.Limit),
-		Offset: int32(params.Offset),
+		ProjectID:      proj.ID,
+		Limit:          int32(params.Limit),
+		Offset:         int32(params.Offset),
+		LabelEquals:    filter.Equals,
+		LabelExists:    filter.Exists,
+		LabelNotExists: filter.NotExists,
+		LabelIn:        filter.In,
+		LabelNotIn:     filter.NotIn,
 	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list roles with pagination")
@@ -261,10 +352,30 @@ func (s *Service) ListRoles(ctx context.Context, params adminv1alpha1.ListRolesP
 }
 
 // ListUserGroups implements generated.Handler.
+// User groups can be filtered with ?labelSelector=.
 func (s *Service) ListUserGroups(ctx context.Context, params adminv1alpha1.ListUserGroupsParams) (adminv1alpha1.ListUserGroupsRes, error) {
+	projectId := pgtype.UUID{}
+	if err := projectId.Scan(params.ProjectId); err != nil {
+		return nil, errors.Wrapf(err, "failed to scan project id")
+	}
+	proj, err := s.queries.GetProjectByDisplayID(ctx, projectId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get project by display id")
+	}
+	filter, err := labelFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	userGroupRecords, err := s.queries.ListUserGroupsWithPagination(ctx, admindb.ListUserGroupsWithPaginationParams{
-		Limit:  int32(params.Limit),
-		Offset: int32(params.Offset),
+		ProjectID:      proj.ID,
+		Limit:          int32(params.Limit),
+		Offset:         int32(params.Offset),
+		LabelEquals:    filter.Equals,
+		LabelExists:    filter.Exists,
+		LabelNotExists: filter.NotExists,
+		LabelIn:        filter.In,
+		LabelNotIn:     filter.NotIn,
 	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list user groups with pagination")
@@ -312,19 +423,44 @@ func (s *Service) UpdateProject(ctx context.Context, req *adminv1alpha1.UpdatePr
 	if err := projectId.Scan(params.ProjectId); err != nil {
 		return nil, errors.Wrapf(err, "failed to scan project id")
 	}
-	err := s.queries.UpdateProject(ctx, admindb.UpdateProjectParams{
-		DisplayID:   projectId,
-		Name:        req.Name,
-		Description: req.Description,
-	})
+	versions, err := expectedVersions(ctx)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to update project")
-	}
+		return nil, err
+	}
+	var (
+		proj    admindb.TacokumoAdminProject
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		affected, err := q.UpdateProject(ctx, admindb.UpdateProjectParams{
+			DisplayID:        projectId,
+			Name:             req.Name,
+			Description:      req.Description,
+			ExpectedVersions: versions,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update project")
+		}
 
-	proj, err := s.queries.GetProjectByDisplayID(ctx, projectId)
+		if proj, err = q.GetProjectByDisplayID(ctx, projectId); err != nil {
+			return errors.Wrapf(err, "failed to get project by display id")
+		}
+		if affected == 0 {
+			return errPreconditionFailed("project")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindProject,
+			Action:     events.ActionUpdated,
+			ResourceID: proj.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get project by display id")
+		return nil, err
 	}
+	s.publishAll(ctx, pending)
+	setETag(ctx, proj.Version)
 	return &adminv1alpha1.Project{
 		ID:          proj.DisplayID.String(),
 		Name:        proj.Name,
@@ -349,23 +485,49 @@ func (s *Service) UpdateRole(ctx context.Context, req *adminv1alpha1.UpdateRoleR
 	if err := roleId.Scan(params.RoleId); err != nil {
 		return nil, errors.Wrapf(err, "failed to scan role id")
 	}
-	err = s.queries.UpdateRole(ctx, admindb.UpdateRoleParams{
-		ProjectID:   project.ID,
-		DisplayID:   roleId,
-		Name:        req.Name,
-		Description: req.Description,
-	})
+	versions, err := expectedVersions(ctx)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to update role")
-	}
+		return nil, err
+	}
+	var (
+		role    admindb.TacokumoAdminRole
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		affected, err := q.UpdateRole(ctx, admindb.UpdateRoleParams{
+			ProjectID:        project.ID,
+			DisplayID:        roleId,
+			Name:             req.Name,
+			Description:      req.Description,
+			ExpectedVersions: versions,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update role")
+		}
 
-	role, err := s.queries.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{
-		ProjectID: project.ID,
-		DisplayID: roleId,
+		role, err = q.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{
+			ProjectID: project.ID,
+			DisplayID: roleId,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get role by display id")
+		}
+		if affected == 0 {
+			return errPreconditionFailed("role")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindRole,
+			Action:     events.ActionUpdated,
+			ResourceID: role.DisplayID.String(),
+			ProjectID:  project.DisplayID.String(),
+		})
+		return err
 	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get role by display id")
+		return nil, err
 	}
+	s.publishAll(ctx, pending)
+	setETag(ctx, role.Version)
 
 	return &adminv1alpha1.Role{
 		ID:          role.DisplayID.String(),
@@ -398,23 +560,49 @@ func (s *Service) UpdateUserGroup(ctx context.Context, req *adminv1alpha1.Update
 	if err := userGroupId.Scan(params.GroupId); err != nil {
 		return nil, errors.Wrapf(err, "failed to scan group id")
 	}
-	err = s.queries.UpdateUserGroup(ctx, admindb.UpdateUserGroupParams{
-		ProjectID:   project.ID,
-		DisplayID:   userGroupId,
-		Name:        req.Name,
-		Description: req.Description,
-	})
+	versions, err := expectedVersions(ctx)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to update user group")
-	}
+		return nil, err
+	}
+	var (
+		userGroup admindb.TacokumoAdminUsergroup
+		pending   []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		affected, err := q.UpdateUserGroup(ctx, admindb.UpdateUserGroupParams{
+			ProjectID:        project.ID,
+			DisplayID:        userGroupId,
+			Name:             req.Name,
+			Description:      req.Description,
+			ExpectedVersions: versions,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update user group")
+		}
 
-	userGroup, err := s.queries.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{
-		ProjectID: project.ID,
-		DisplayID: userGroupId,
+		userGroup, err = q.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{
+			ProjectID: project.ID,
+			DisplayID: userGroupId,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get user group by display id")
+		}
+		if affected == 0 {
+			return errPreconditionFailed("user group")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindUserGroup,
+			Action:     events.ActionUpdated,
+			ResourceID: userGroup.DisplayID.String(),
+			ProjectID:  project.DisplayID.String(),
+		})
+		return err
 	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get user group by display id")
+		return nil, err
 	}
+	s.publishAll(ctx, pending)
+	setETag(ctx, userGroup.Version)
 
 	return &adminv1alpha1.UserGroup{
 		ID:          userGroup.DisplayID.String(),
@@ -434,20 +622,69 @@ func (s *Service) UpdateUserGroup(ctx context.Context, req *adminv1alpha1.Update
 }
 
 // CreateProject implements generated.Handler.
+// Owners default to the calling user. A personal project is owned by exactly one user, and a
+// shared project gets an owner group named "owners" whose members are the owners.
 func (s *Service) CreateProject(ctx context.Context, req *adminv1alpha1.CreateProjectRequest) (adminv1alpha1.CreateProjectRes, error) {
-	createProjectParams := admindb.CreateProjectParams{
-		Name:        req.Name,
-		Description: req.Description,
-		Kind:        string(req.Kind),
-	}
-	if err := s.queries.CreateProject(ctx, createProjectParams); err != nil {
-		return nil, errors.Wrapf(err, "failed to create project")
-	}
+	if len(req.OwnerGroupIds) > 0 {
+		return nil, errUnprocessable("ownerGroupIds cannot be set on creation because user groups belong to a project; specify ownerIds instead")
+	}
+
+	var (
+		proj    admindb.TacokumoAdminProject
+		pending []events.Event
+	)
+	err := s.withTx(ctx, func(q *admindb.Queries) error {
+		var ownerGroup pgtype.UUID
+		owners, err := s.resolveOwners(ctx, q, req.OwnerIds)
+		if err != nil {
+			return err
+		}
+		if err := q.CreateProject(ctx, admindb.CreateProjectParams{
+			Name:        req.Name,
+			Description: req.Description,
+			Kind:        string(req.Kind),
+		}); err != nil {
+			return errors.Wrapf(err, "failed to create project")
+		}
+		proj, err = q.GetProjectByName(ctx, req.Name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get project by name")
+		}
+
+		switch proj.Kind {
+		case project.KindPersonal:
+			for _, owner := range owners {
+				if err := q.AddProjectOwner(ctx, admindb.AddProjectOwnerParams{ProjectID: proj.ID, UserID: owner.ID}); err != nil {
+					return errors.Wrapf(err, "failed to add project owner")
+				}
+			}
+		case project.KindShared:
+			if ownerGroup, err = createOwnerGroup(ctx, q, proj, project.DefaultOwnerGroupName, owners); err != nil {
+				return err
+			}
+		}
+		if err := project.Check(ctx, q, proj.ID); err != nil {
+			return err
+		}
 
-	proj, err := s.queries.GetProjectByName(ctx, req.Name)
+		evs := []events.Event{{
+			Kind:       events.KindProject,
+			Action:     events.ActionCreated,
+			ResourceID: proj.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		}}
+		if ownerGroup.Valid {
+			evs = append(evs, ownerGroupCreatedEvents(proj, ownerGroup, owners)...)
+		}
+		pending, err = s.RecordEvents(ctx, q, evs...)
+		return err
+	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get project by name")
+		return nil, err
 	}
+
+	s.publishAll(ctx, pending)
+	setETag(ctx, proj.Version)
 	return &adminv1alpha1.Project{
 		ID:          proj.DisplayID.String(),
 		Name:        proj.Name,
@@ -459,10 +696,32 @@ func (s *Service) CreateProject(ctx context.Context, req *adminv1alpha1.CreatePr
 }
 
 // ListProjects implements generated.Handler.
+// Archived projects are only listed with ?includeArchived=true, and projects can be filtered
+// with ?labelSelector=.
 func (s *Service) ListProjects(ctx context.Context, params adminv1alpha1.ListProjectsParams) (adminv1alpha1.ListProjectsRes, error) {
+	// includeArchived is not part of the generated parameters, so it is read from the request.
+	includeArchived := false
+	if v := middleware.QueryParam(ctx, "includeArchived"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errBadRequest("includeArchived must be true or false")
+		}
+		includeArchived = b
+	}
+	filter, err := labelFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	projectRecords, err := s.queries.ListProjectsWithPagination(ctx, admindb.ListProjectsWithPaginationParams{
-		Limit:  int32(params.Limit),
-		Offset: int32(params.Offset),
+		Limit:           int32(params.Limit),
+		Offset:          int32(params.Offset),
+		IncludeArchived: includeArchived,
+		LabelEquals:     filter.Equals,
+		LabelExists:     filter.Exists,
+		LabelNotExists:  filter.NotExists,
+		LabelIn:         filter.In,
+		LabelNotIn:      filter.NotIn,
 	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list projects with pagination")
@@ -483,45 +742,73 @@ func (s *Service) ListProjects(ctx context.Context, params adminv1alpha1.ListPro
 }
 
 // GetReadinessCheck implements generated.Handler.
+// The probes are served with per-component reports by health.Registry.RegisterRoutes, which
+// takes precedence over the generated routes. This keeps the generated handler consistent.
 func (s *Service) GetReadinessCheck(ctx context.Context) (*adminv1alpha1.HealthResponse, error) {
-	_, err := s.queries.CheckDBConnection(ctx)
-	if err != nil {
+	report := s.health.Readiness(ctx)
+	if !report.OK() {
+		for _, c := range report.Components {
+			if c.Status != health.StatusOK && !c.Optional {
+				return &adminv1alpha1.HealthResponse{
+					Status: string(report.Status),
+				}, errors.Newf("%s check failed: %s", c.Name, c.Error)
+			}
+		}
 		return &adminv1alpha1.HealthResponse{
-			Status: "ng",
-		}, errors.Wrapf(err, "failed to check DB connection")
+			Status: string(report.Status),
+		}, errors.Newf("server is not ready: %s", report.Reason)
 	}
 	return &adminv1alpha1.HealthResponse{
-		Status: "ok",
+		Status: string(report.Status),
 	}, nil
 }
 
 // GetLivenessCheck implements generated.Handler.
 func (s *Service) GetLivenessCheck(ctx context.Context) (*adminv1alpha1.HealthResponse, error) {
 	return &adminv1alpha1.HealthResponse{
-		Status: "ok",
+		Status: string(s.health.Liveness(ctx).Status),
 	}, nil
 }
 
 func NewService(
 	logger *slog.Logger,
+	pool *pgxpool.Pool,
 	queries *admindb.Queries,
 	githubClient *oauth.GitHubClient,
 	sessionStore session.Store,
 	stateStore session.Store,
 	frontendURL string,
 	sessionTTL time.Duration,
+	eventStream events.Stream,
+	invitations InvitationSettings,
+	projects ProjectSettings,
+	healthRegistry *health.Registry,
 ) *Service {
+	streams, closeStreams := context.WithCancel(context.Background())
 	return &Service{
 		logger:       logger,
+		pool:         pool,
 		queries:      queries,
 		githubClient: githubClient,
 		sessionStore: sessionStore,
 		stateStore:   stateStore,
 		frontendURL:  frontendURL,
 		sessionTTL:   sessionTTL,
+		eventStream:  eventStream,
+		invitations:  invitations,
+		projects:     projects,
+		health:       healthRegistry,
+		streams:      streams,
+		closeStreams: closeStreams,
 	}
 }
 
+// CloseStreams ends the open event streams, and the ones opened later right away, so that they
+// do not hold up a graceful shutdown. Clients reconnect to another instance with Last-Event-ID.
+func (s *Service) CloseStreams() {
+	s.closeStreams()
+}
+
 // InitiateLogin implements generated.Handler.
 // Note: This returns a 302 status but ogen doesn't support Location header in response.
 // The actual redirect should be handled by Echo middleware or a custom handler.
@@ -580,7 +867,7 @@ func (s *Service) GetCurrentUser(ctx context.Context) (adminv1alpha1.GetCurrentU
 			Name:      sess.Name,
 			AvatarURL: *avatarURL,
 		},
-		BearerToken:     sess.ID,
+		BearerToken:     bearerToken(sess),
 		TeamMemberships: teamMemberships,
 	}, nil
 }
@@ -619,11 +906,20 @@ func (s *Service) RefreshToken(ctx context.Context) (adminv1alpha1.RefreshTokenR
 			Name:      sess.Name,
 			AvatarURL: *avatarURL,
 		},
-		BearerToken:     sess.ID,
+		BearerToken:     bearerToken(sess),
 		TeamMemberships: teamMemberships,
 	}, nil
 }
 
+// bearerToken returns the token of a login session. Service account sessions are not stored,
+// and their ID is derived from the credential instead of being one, so none is returned.
+func bearerToken(sess *session.Session) string {
+	if sess.ServiceAccountID != "" {
+		return ""
+	}
+	return sess.ID
+}
+
 // GetGitHubClient returns the GitHub OAuth client for use in Echo handlers.
 func (s *Service) GetGitHubClient() *oauth.GitHubClient {
 	return s.githubClient
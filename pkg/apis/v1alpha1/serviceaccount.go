@@ -0,0 +1,821 @@
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	"github.com/tacokumo/admin-api/pkg/auth/serviceaccount"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+)
+
+const (
+	maxServiceAccountNameLength        = 64
+	maxServiceAccountDescriptionLength = 256
+)
+
+// ServiceAccount is a non-human principal that can be put into user groups and granted roles.
+type ServiceAccount struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// ProjectID is omitted for global service accounts.
+	ProjectID *string       `json:"projectId,omitempty"`
+	OwnerID   *string       `json:"ownerId,omitempty"`
+	Disabled  bool          `json:"disabled"`
+	Roles     []ResourceRef `json:"roles"`
+	Groups    []ResourceRef `json:"groups"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// ResourceRef identifies a project scoped resource a principal is bound to.
+type ResourceRef struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ProjectID string `json:"projectId"`
+}
+
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// ProjectID scopes the service account to a project. Global if omitted.
+	ProjectID string `json:"projectId"`
+	// OwnerID defaults to the caller.
+	OwnerID string `json:"ownerId"`
+}
+
+type UpdateServiceAccountRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	OwnerID     string `json:"ownerId"`
+	Disabled    bool   `json:"disabled"`
+}
+
+// ServiceAccountToken is a credential of a service account.
+// Token is only returned once, in the response of the creation request.
+type ServiceAccountToken struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	Prefix      string     `json:"prefix"`
+	Token       string     `json:"token,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+type CreateServiceAccountTokenRequest struct {
+	Description string     `json:"description"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+func validateServiceAccountFields(name, description string) error {
+	if name == "" || len(name) > maxServiceAccountNameLength {
+		return errBadRequest("name must be between 1 and %d characters", maxServiceAccountNameLength)
+	}
+	if len(description) > maxServiceAccountDescriptionLength {
+		return errBadRequest("description must be at most %d characters", maxServiceAccountDescriptionLength)
+	}
+	return nil
+}
+
+func (s *Service) createServiceAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req CreateServiceAccountRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := validateServiceAccountFields(req.Name, req.Description); err != nil {
+		return s.writeError(c, err)
+	}
+
+	params := admindb.CreateServiceAccountParams{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	projectDisplayId := ""
+
+	if req.ProjectID != "" {
+		projectId, err := parseDisplayID("projectId", req.ProjectID)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		proj, err := s.queries.GetProjectByDisplayID(ctx, projectId)
+		if err != nil {
+			return s.writeError(c, errors.Wrapf(err, "failed to get project by display id"))
+		}
+		params.ProjectID = pgtype.Int8{Int64: proj.ID, Valid: true}
+		projectDisplayId = proj.DisplayID.String()
+	}
+
+	ownerID, err := s.resolveServiceAccountOwner(ctx, req.OwnerID, true)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	params.OwnerUserID = ownerID
+
+	var (
+		displayId pgtype.UUID
+		pending   []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		if err := ensureProjectActive(ctx, q, params.ProjectID); err != nil {
+			return err
+		}
+		var err error
+		if displayId, err = q.CreateServiceAccount(ctx, params); err != nil {
+			return errors.Wrapf(err, "failed to create service account")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindServiceAccount,
+			Action:     events.ActionCreated,
+			ResourceID: displayId.String(),
+			ProjectID:  projectDisplayId,
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+
+	sa, err := s.loadServiceAccount(ctx, displayId)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, sa)
+}
+
+func (s *Service) listServiceAccounts(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	params := admindb.ListServiceAccountsWithPaginationParams{
+		Limit:  limit,
+		Offset: offset,
+	}
+	if v := c.QueryParam("projectId"); v != "" {
+		projectId, err := parseDisplayID("projectId", v)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		proj, err := s.queries.GetProjectByDisplayID(ctx, projectId)
+		if err != nil {
+			return s.writeError(c, errors.Wrapf(err, "failed to get project by display id"))
+		}
+		params.ProjectID = pgtype.Int8{Int64: proj.ID, Valid: true}
+	}
+
+	records, err := s.queries.ListServiceAccountsWithPagination(ctx, params)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list service accounts with pagination"))
+	}
+
+	serviceAccounts := lo.Map(records, func(r admindb.ListServiceAccountsWithPaginationRow, _ int) ServiceAccount {
+		return ServiceAccount{
+			ID:          r.DisplayID.String(),
+			Name:        r.Name,
+			Description: r.Description,
+			ProjectID:   uuidPtr(r.ProjectDisplayID),
+			OwnerID:     uuidPtr(r.OwnerDisplayID),
+			Disabled:    r.Disabled,
+			Roles:       []ResourceRef{},
+			Groups:      []ResourceRef{},
+			CreatedAt:   r.CreatedAt.Time,
+			UpdatedAt:   r.UpdatedAt.Time,
+		}
+	})
+	return c.JSON(http.StatusOK, serviceAccounts)
+}
+
+func (s *Service) getServiceAccount(c echo.Context) error {
+	displayId, err := parseDisplayID("serviceAccountId", c.Param("serviceAccountId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	sa, err := s.loadServiceAccount(c.Request().Context(), displayId)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, sa)
+}
+
+func (s *Service) updateServiceAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	displayId, err := parseDisplayID("serviceAccountId", c.Param("serviceAccountId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var req UpdateServiceAccountRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := validateServiceAccountFields(req.Name, req.Description); err != nil {
+		return s.writeError(c, err)
+	}
+
+	current, err := s.queries.GetServiceAccountByDisplayID(ctx, displayId)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get service account by display id"))
+	}
+
+	ownerID, err := s.resolveServiceAccountOwner(ctx, req.OwnerID, false)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	pending, err := s.updateServiceAccountRecord(ctx, current, admindb.UpdateServiceAccountParams{
+		DisplayID:   displayId,
+		Name:        req.Name,
+		Description: req.Description,
+		OwnerUserID: ownerID,
+		Disabled:    req.Disabled,
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+
+	sa, err := s.loadServiceAccount(ctx, displayId)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, sa)
+}
+
+// ServiceAccountPatch is the merge patch document accepted by PATCH /serviceaccounts/{serviceAccountId}.
+// An explicit null ownerId removes the owner.
+type ServiceAccountPatch struct {
+	Name        patchField[string] `json:"name"`
+	Description patchField[string] `json:"description"`
+	OwnerID     patchField[string] `json:"ownerId"`
+	Disabled    patchField[bool]   `json:"disabled"`
+}
+
+func (s *Service) patchServiceAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	displayId, err := parseDisplayID("serviceAccountId", c.Param("serviceAccountId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var patch ServiceAccountPatch
+	if err := decodeMergePatch(c, &patch); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := patch.Name.required("name"); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := patch.Disabled.required("disabled"); err != nil {
+		return s.writeError(c, err)
+	}
+
+	current, err := s.queries.GetServiceAccountByDisplayID(ctx, displayId)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get service account by display id"))
+	}
+
+	name := patch.Name.apply(current.Name)
+	description := patch.Description.apply(current.Description)
+	if err := validateServiceAccountFields(name, description); err != nil {
+		return s.writeError(c, err)
+	}
+
+	ownerID := current.OwnerUserID
+	if patch.OwnerID.Set {
+		if ownerID, err = s.resolveServiceAccountOwner(ctx, patch.OwnerID.apply(""), false); err != nil {
+			return s.writeError(c, err)
+		}
+	}
+
+	pending, err := s.updateServiceAccountRecord(ctx, current, admindb.UpdateServiceAccountParams{
+		DisplayID:   displayId,
+		Name:        name,
+		Description: description,
+		OwnerUserID: ownerID,
+		Disabled:    patch.Disabled.apply(current.Disabled),
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+
+	sa, err := s.loadServiceAccount(ctx, displayId)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, sa)
+}
+
+// updateServiceAccountRecord updates a service account and records its updated event in the
+// same transaction.
+func (s *Service) updateServiceAccountRecord(ctx context.Context, current admindb.GetServiceAccountByDisplayIDRow, params admindb.UpdateServiceAccountParams) ([]events.Event, error) {
+	var pending []events.Event
+	err := s.withTx(ctx, func(q *admindb.Queries) error {
+		if err := ensureProjectActive(ctx, q, current.ProjectID); err != nil {
+			return err
+		}
+		affected, err := q.UpdateServiceAccount(ctx, params)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update service account")
+		}
+		if affected == 0 {
+			return errNotFound("service account not found")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindServiceAccount,
+			Action:     events.ActionUpdated,
+			ResourceID: current.DisplayID.String(),
+			ProjectID:  lo.FromPtr(uuidPtr(current.ProjectDisplayID)),
+		})
+		return err
+	})
+	return pending, err
+}
+
+func (s *Service) deleteServiceAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	displayId, err := parseDisplayID("serviceAccountId", c.Param("serviceAccountId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	sa, err := s.queries.GetServiceAccountByDisplayID(ctx, displayId)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get service account by display id"))
+	}
+	var pending []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		if err := ensureProjectActive(ctx, q, sa.ProjectID); err != nil {
+			return err
+		}
+		affected, err := q.DeleteServiceAccount(ctx, displayId)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete service account")
+		}
+		if affected == 0 {
+			return errNotFound("service account not found")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindServiceAccount,
+			Action:     events.ActionDeleted,
+			ResourceID: displayId.String(),
+			ProjectID:  lo.FromPtr(uuidPtr(sa.ProjectDisplayID)),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Service) createServiceAccountToken(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	displayId, err := parseDisplayID("serviceAccountId", c.Param("serviceAccountId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var req CreateServiceAccountTokenRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if len(req.Description) > maxServiceAccountDescriptionLength {
+		return s.writeError(c, errBadRequest("description must be at most %d characters", maxServiceAccountDescriptionLength))
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return s.writeError(c, errBadRequest("expiresAt must be in the future"))
+	}
+
+	sa, err := s.queries.GetServiceAccountByDisplayID(ctx, displayId)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get service account by display id"))
+	}
+	if sa.Disabled {
+		return s.writeError(c, errUnprocessable("cannot create a token for a disabled service account"))
+	}
+
+	token, err := serviceaccount.GenerateToken()
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to generate service account token"))
+	}
+
+	params := admindb.CreateServiceAccountTokenParams{
+		ServiceAccountID: sa.ID,
+		Description:      req.Description,
+		TokenPrefix:      token.Prefix,
+		TokenHash:        token.Hash,
+	}
+	if req.ExpiresAt != nil {
+		params.ExpiresAt = pgtype.Timestamptz{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	var record admindb.TacokumoAdminServiceAccountToken
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		if err := ensureProjectActive(ctx, q, sa.ProjectID); err != nil {
+			return err
+		}
+		var err error
+		if record, err = q.CreateServiceAccountToken(ctx, params); err != nil {
+			return errors.Wrapf(err, "failed to create service account token")
+		}
+		return nil
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	resp := toServiceAccountToken(record)
+	resp.Token = token.Plaintext
+	return c.JSON(http.StatusCreated, resp)
+}
+
+func (s *Service) listServiceAccountTokens(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	displayId, err := parseDisplayID("serviceAccountId", c.Param("serviceAccountId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	sa, err := s.queries.GetServiceAccountByDisplayID(ctx, displayId)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get service account by display id"))
+	}
+
+	records, err := s.queries.ListServiceAccountTokens(ctx, sa.ID)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list service account tokens"))
+	}
+
+	return c.JSON(http.StatusOK, lo.Map(records, func(t admindb.TacokumoAdminServiceAccountToken, _ int) ServiceAccountToken {
+		return toServiceAccountToken(t)
+	}))
+}
+
+func (s *Service) revokeServiceAccountToken(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	displayId, err := parseDisplayID("serviceAccountId", c.Param("serviceAccountId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	tokenId, err := parseDisplayID("tokenId", c.Param("tokenId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	sa, err := s.queries.GetServiceAccountByDisplayID(ctx, displayId)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get service account by display id"))
+	}
+
+	// Tokens can be revoked while the project is archived, so that a leaked token does not stay
+	// valid until the project is unarchived.
+	affected, err := s.queries.RevokeServiceAccountToken(ctx, admindb.RevokeServiceAccountTokenParams{
+		ServiceAccountID: sa.ID,
+		DisplayID:        tokenId,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to revoke service account token"))
+	}
+	if affected == 0 {
+		return s.writeError(c, errNotFound("active token not found"))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Service) addServiceAccountToUserGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, sa, err := s.loadProjectAndServiceAccount(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	groupId, err := parseDisplayID("groupId", c.Param("groupId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	userGroup, err := s.queries.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{
+		ProjectID: proj.ID,
+		DisplayID: groupId,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get user group by display id"))
+	}
+
+	var pending []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		affected, err := q.AddServiceAccountToUserGroup(ctx, admindb.AddServiceAccountToUserGroupParams{
+			ServiceAccountID: sa.ID,
+			UsergroupID:      userGroup.ID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to add service account to user group")
+		}
+		if affected == 0 {
+			return nil
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindUserGroupMember,
+			Action:     events.ActionCreated,
+			ResourceID: userGroup.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+			MemberID:   sa.DisplayID.String(),
+			MemberKind: events.MemberKindServiceAccount,
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Service) removeServiceAccountFromUserGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, sa, err := s.loadProjectAndServiceAccount(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	groupId, err := parseDisplayID("groupId", c.Param("groupId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	userGroup, err := s.queries.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{
+		ProjectID: proj.ID,
+		DisplayID: groupId,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get user group by display id"))
+	}
+
+	var pending []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		affected, err := q.RemoveServiceAccountFromUserGroup(ctx, admindb.RemoveServiceAccountFromUserGroupParams{
+			ServiceAccountID: sa.ID,
+			UsergroupID:      userGroup.ID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove service account from user group")
+		}
+		if affected == 0 {
+			return errNotFound("service account is not a member of the user group")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindUserGroupMember,
+			Action:     events.ActionDeleted,
+			ResourceID: userGroup.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+			MemberID:   sa.DisplayID.String(),
+			MemberKind: events.MemberKindServiceAccount,
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Service) assignRoleToServiceAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, sa, err := s.loadProjectAndServiceAccount(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	roleId, err := parseDisplayID("roleId", c.Param("roleId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	role, err := s.queries.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{
+		ProjectID: proj.ID,
+		DisplayID: roleId,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get role by display id"))
+	}
+
+	var pending []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		affected, err := q.AssignRoleToServiceAccount(ctx, admindb.AssignRoleToServiceAccountParams{
+			ServiceAccountID: sa.ID,
+			RoleID:           role.ID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to assign role to service account")
+		}
+		if affected == 0 {
+			return nil
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindRoleAssignment,
+			Action:     events.ActionCreated,
+			ResourceID: role.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+			MemberID:   sa.DisplayID.String(),
+			MemberKind: events.MemberKindServiceAccount,
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Service) unassignRoleFromServiceAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, sa, err := s.loadProjectAndServiceAccount(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	roleId, err := parseDisplayID("roleId", c.Param("roleId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	role, err := s.queries.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{
+		ProjectID: proj.ID,
+		DisplayID: roleId,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get role by display id"))
+	}
+
+	var pending []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		affected, err := q.UnassignRoleFromServiceAccount(ctx, admindb.UnassignRoleFromServiceAccountParams{
+			ServiceAccountID: sa.ID,
+			RoleID:           role.ID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to unassign role from service account")
+		}
+		if affected == 0 {
+			return errNotFound("role is not assigned to the service account")
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindRoleAssignment,
+			Action:     events.ActionDeleted,
+			ResourceID: role.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+			MemberID:   sa.DisplayID.String(),
+			MemberKind: events.MemberKindServiceAccount,
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// loadProjectAndServiceAccount resolves the project and service account of a binding request.
+// Project scoped service accounts can only be bound to roles and groups of their own project.
+func (s *Service) loadProjectAndServiceAccount(c echo.Context) (admindb.TacokumoAdminProject, admindb.GetServiceAccountByDisplayIDRow, error) {
+	ctx := c.Request().Context()
+
+	projectId, err := parseDisplayID("projectId", c.Param("projectId"))
+	if err != nil {
+		return admindb.TacokumoAdminProject{}, admindb.GetServiceAccountByDisplayIDRow{}, err
+	}
+	proj, err := s.queries.GetProjectByDisplayID(ctx, projectId)
+	if err != nil {
+		return admindb.TacokumoAdminProject{}, admindb.GetServiceAccountByDisplayIDRow{}, errors.Wrapf(err, "failed to get project by display id")
+	}
+
+	saId, err := parseDisplayID("serviceAccountId", c.Param("serviceAccountId"))
+	if err != nil {
+		return admindb.TacokumoAdminProject{}, admindb.GetServiceAccountByDisplayIDRow{}, err
+	}
+	sa, err := s.queries.GetServiceAccountByDisplayID(ctx, saId)
+	if err != nil {
+		return admindb.TacokumoAdminProject{}, admindb.GetServiceAccountByDisplayIDRow{}, errors.Wrapf(err, "failed to get service account by display id")
+	}
+
+	if sa.ProjectID.Valid && sa.ProjectID.Int64 != proj.ID {
+		return admindb.TacokumoAdminProject{}, admindb.GetServiceAccountByDisplayIDRow{}, errUnprocessable("service account belongs to another project")
+	}
+	return proj, sa, nil
+}
+
+// resolveServiceAccountOwner resolves the owner of a service account from a user display ID.
+// When ownerId is empty and defaultToCaller is set, the calling user becomes the owner if it has an admin user.
+func (s *Service) resolveServiceAccountOwner(ctx context.Context, ownerId string, defaultToCaller bool) (pgtype.Int8, error) {
+	if ownerId == "" {
+		if !defaultToCaller {
+			return pgtype.Int8{}, nil
+		}
+		user, err := s.currentUser(ctx)
+		if err != nil {
+			if errors.Is(err, errNoAdminUser) {
+				return pgtype.Int8{}, nil
+			}
+			return pgtype.Int8{}, err
+		}
+		return pgtype.Int8{Int64: user.ID, Valid: true}, nil
+	}
+
+	userId, err := parseDisplayID("ownerId", ownerId)
+	if err != nil {
+		return pgtype.Int8{}, err
+	}
+	user, err := s.queries.GetUserByDisplayID(ctx, userId)
+	if err != nil {
+		return pgtype.Int8{}, errors.Wrapf(err, "failed to get owner by display id")
+	}
+	return pgtype.Int8{Int64: user.ID, Valid: true}, nil
+}
+
+// loadServiceAccount fetches a service account together with its role and group bindings.
+func (s *Service) loadServiceAccount(ctx context.Context, displayId pgtype.UUID) (ServiceAccount, error) {
+	sa, err := s.queries.GetServiceAccountByDisplayID(ctx, displayId)
+	if err != nil {
+		return ServiceAccount{}, errors.Wrapf(err, "failed to get service account by display id")
+	}
+
+	roles, err := s.queries.ListServiceAccountRoles(ctx, sa.ID)
+	if err != nil {
+		return ServiceAccount{}, errors.Wrapf(err, "failed to list service account roles")
+	}
+	groups, err := s.queries.ListServiceAccountUserGroups(ctx, sa.ID)
+	if err != nil {
+		return ServiceAccount{}, errors.Wrapf(err, "failed to list service account user groups")
+	}
+
+	projectIds, err := s.projectDisplayIDs(ctx, append(
+		lo.Map(roles, func(r admindb.TacokumoAdminRole, _ int) int64 { return r.ProjectID }),
+		lo.Map(groups, func(g admindb.TacokumoAdminUsergroup, _ int) int64 { return g.ProjectID })...,
+	))
+	if err != nil {
+		return ServiceAccount{}, err
+	}
+
+	return ServiceAccount{
+		ID:          sa.DisplayID.String(),
+		Name:        sa.Name,
+		Description: sa.Description,
+		ProjectID:   uuidPtr(sa.ProjectDisplayID),
+		OwnerID:     uuidPtr(sa.OwnerDisplayID),
+		Disabled:    sa.Disabled,
+		Roles: lo.Map(roles, func(r admindb.TacokumoAdminRole, _ int) ResourceRef {
+			return ResourceRef{ID: r.DisplayID.String(), Name: r.Name, ProjectID: projectIds[r.ProjectID]}
+		}),
+		Groups: lo.Map(groups, func(g admindb.TacokumoAdminUsergroup, _ int) ResourceRef {
+			return ResourceRef{ID: g.DisplayID.String(), Name: g.Name, ProjectID: projectIds[g.ProjectID]}
+		}),
+		CreatedAt: sa.CreatedAt.Time,
+		UpdatedAt: sa.UpdatedAt.Time,
+	}, nil
+}
+
+// projectDisplayIDs maps internal project IDs to their display IDs.
+func (s *Service) projectDisplayIDs(ctx context.Context, ids []int64) (map[int64]string, error) {
+	result := make(map[int64]string)
+	for _, id := range lo.Uniq(ids) {
+		proj, err := s.queries.GetProjectByID(ctx, id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get project by id")
+		}
+		result[id] = proj.DisplayID.String()
+	}
+	return result, nil
+}
+
+func toServiceAccountToken(t admindb.TacokumoAdminServiceAccountToken) ServiceAccountToken {
+	return ServiceAccountToken{
+		ID:          t.DisplayID.String(),
+		Description: t.Description,
+		Prefix:      t.TokenPrefix,
+		ExpiresAt:   timePtr(t.ExpiresAt),
+		LastUsedAt:  timePtr(t.LastUsedAt),
+		RevokedAt:   timePtr(t.RevokedAt),
+		CreatedAt:   t.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,350 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	adminv1alpha1 "github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// ProjectTemplate is a named role and user group structure that new projects can be created from.
+type ProjectTemplate struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Kind        string           `json:"kind"`
+	Spec        project.Template `json:"spec"`
+	// SourceProjectID is the project the template was saved from. It is omitted for templates
+	// created from a spec and once the project is deleted.
+	SourceProjectID *string   `json:"sourceProjectId,omitempty"`
+	CreatedBy       string    `json:"createdBy"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// CreateProjectTemplateRequest saves a template, either from the current structure of the
+// project ProjectID or from Kind and Spec.
+type CreateProjectTemplateRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	ProjectID   string            `json:"projectId,omitempty"`
+	Kind        string            `json:"kind,omitempty"`
+	Spec        *project.Template `json:"spec,omitempty"`
+}
+
+// CreateProjectFromTemplateRequest is the body of POST /projecttemplates/{templateId}/projects
+// and POST /projects/{projectId}/clone.
+type CreateProjectFromTemplateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// OwnerIDs become the owners of a personal project or the members of the owner groups of a
+	// shared project. They default to the calling user.
+	OwnerIDs []string `json:"ownerIds,omitempty"`
+}
+
+// ProjectFromTemplate is a project created from a template together with what was created in it.
+type ProjectFromTemplate struct {
+	Project      adminv1alpha1.Project `json:"project"`
+	RoleIDs      []string              `json:"roleIds"`
+	UserGroupIDs []string              `json:"userGroupIds"`
+}
+
+func (s *Service) createProjectTemplate(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req CreateProjectTemplateRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if req.Name == "" {
+		return s.writeError(c, errUnprocessable("name is required"))
+	}
+	if (req.ProjectID == "") == (req.Spec == nil) {
+		return s.writeError(c, errUnprocessable("exactly one of projectId and spec is required"))
+	}
+
+	params := admindb.CreateProjectTemplateParams{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   currentPrincipalName(ctx),
+	}
+	var spec project.Template
+	if req.ProjectID != "" {
+		if req.Kind != "" {
+			return s.writeError(c, errUnprocessable("kind is taken from the project and cannot be set with projectId"))
+		}
+		projectId, err := parseDisplayID("projectId", req.ProjectID)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		proj, err := s.queries.GetProjectByDisplayID(ctx, projectId)
+		if err != nil {
+			return s.writeError(c, errors.Wrapf(err, "failed to get project by display id"))
+		}
+		if spec, err = project.Capture(ctx, s.queries, proj.ID); err != nil {
+			return s.writeError(c, err)
+		}
+		params.Kind = proj.Kind
+		params.SourceProjectID = pgtype.Int8{Int64: proj.ID, Valid: true}
+	} else {
+		spec = *req.Spec
+		params.Kind = req.Kind
+	}
+	if err := spec.Validate(params.Kind); err != nil {
+		return s.writeError(c, err)
+	}
+
+	var err error
+	if params.Spec, err = json.Marshal(spec); err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to marshal project template"))
+	}
+	templateId, err := s.queries.CreateProjectTemplate(ctx, params)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to create project template"))
+	}
+	tmpl, err := s.queries.GetProjectTemplateByDisplayID(ctx, templateId)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get project template by display id"))
+	}
+	resp, err := s.toProjectTemplate(ctx, tmpl)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+func (s *Service) listProjectTemplates(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	tmpls, err := s.queries.ListProjectTemplatesWithPagination(ctx, admindb.ListProjectTemplatesWithPaginationParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list project templates"))
+	}
+	resp := make([]ProjectTemplate, 0, len(tmpls))
+	for _, tmpl := range tmpls {
+		t, err := s.toProjectTemplate(ctx, tmpl)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		resp = append(resp, t)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Service) getProjectTemplate(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	tmpl, err := s.loadProjectTemplate(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	resp, err := s.toProjectTemplate(ctx, tmpl)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Service) deleteProjectTemplate(c echo.Context) error {
+	templateId, err := parseDisplayID("templateId", c.Param("templateId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	affected, err := s.queries.DeleteProjectTemplate(c.Request().Context(), templateId)
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to delete project template"))
+	}
+	if affected == 0 {
+		return s.writeError(c, errNotFound("project template not found"))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// createProjectFromTemplate creates a project with the roles and user groups of a template.
+func (s *Service) createProjectFromTemplate(c echo.Context) error {
+	tmpl, err := s.loadProjectTemplate(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var spec project.Template
+	if err := json.Unmarshal(tmpl.Spec, &spec); err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to unmarshal project template"))
+	}
+	return s.instantiateProject(c, tmpl.Kind, spec)
+}
+
+// cloneProject creates a project with the roles and user groups of an existing project, as if
+// it was saved as a template first. Members, owners and service accounts are not copied.
+func (s *Service) cloneProject(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	source, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	spec, err := project.Capture(ctx, s.queries, source.ID)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return s.instantiateProject(c, source.Kind, spec)
+}
+
+func (s *Service) instantiateProject(c echo.Context, kind string, spec project.Template) error {
+	ctx := c.Request().Context()
+
+	var req CreateProjectFromTemplateRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if req.Name == "" {
+		return s.writeError(c, errUnprocessable("name is required"))
+	}
+	if err := spec.Validate(kind); err != nil {
+		return s.writeError(c, err)
+	}
+
+	var (
+		proj       admindb.TacokumoAdminProject
+		owners     []admindb.TacokumoAdminUser
+		inst       project.Instance
+		ownerGroup pgtype.UUID
+		pending    []events.Event
+	)
+	// Everything is created in one transaction, so a failure leaves no partial project behind.
+	err := s.withTx(ctx, func(q *admindb.Queries) error {
+		var err error
+		if owners, err = s.resolveOwners(ctx, q, req.OwnerIDs); err != nil {
+			return err
+		}
+		if err := q.CreateProject(ctx, admindb.CreateProjectParams{
+			Name:        req.Name,
+			Description: req.Description,
+			Kind:        kind,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to create project")
+		}
+		proj, err = q.GetProjectByName(ctx, req.Name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get project by name")
+		}
+
+		if proj.Kind == project.KindPersonal {
+			for _, owner := range owners {
+				if err := q.AddProjectOwner(ctx, admindb.AddProjectOwnerParams{ProjectID: proj.ID, UserID: owner.ID}); err != nil {
+					return errors.Wrapf(err, "failed to add project owner")
+				}
+			}
+		}
+		if inst, err = project.Instantiate(ctx, q, proj, spec, owners); err != nil {
+			return err
+		}
+		if proj.Kind == project.KindShared && !spec.HasOwnerGroup() {
+			if ownerGroup, err = createOwnerGroup(ctx, q, proj, project.DefaultOwnerGroupName, owners); err != nil {
+				return err
+			}
+		}
+		if err := project.Check(ctx, q, proj.ID); err != nil {
+			return err
+		}
+
+		evs := instanceEvents(proj, inst, owners)
+		if ownerGroup.Valid {
+			evs = append(evs, ownerGroupCreatedEvents(proj, ownerGroup, owners)...)
+		}
+		pending, err = s.RecordEvents(ctx, q, evs...)
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, pending)
+	setETag(ctx, proj.Version)
+	return c.JSON(http.StatusCreated, ProjectFromTemplate{
+		Project:      toProject(proj),
+		RoleIDs:      lo.Map(inst.Roles, func(id pgtype.UUID, _ int) string { return id.String() }),
+		UserGroupIDs: lo.Map(inst.UserGroups, func(id pgtype.UUID, _ int) string { return id.String() }),
+	})
+}
+
+// instanceEvents returns the created events of a project instantiated from a template.
+func instanceEvents(proj admindb.TacokumoAdminProject, inst project.Instance, owners []admindb.TacokumoAdminUser) []events.Event {
+	evs := []events.Event{{
+		Kind:       events.KindProject,
+		Action:     events.ActionCreated,
+		ResourceID: proj.DisplayID.String(),
+		ProjectID:  proj.DisplayID.String(),
+	}}
+	for _, roleId := range inst.Roles {
+		evs = append(evs, events.Event{
+			Kind:       events.KindRole,
+			Action:     events.ActionCreated,
+			ResourceID: roleId.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+	}
+	for _, groupId := range inst.UserGroups {
+		if lo.Contains(inst.OwnerGroups, groupId) {
+			evs = append(evs, ownerGroupCreatedEvents(proj, groupId, owners)...)
+			continue
+		}
+		evs = append(evs, events.Event{
+			Kind:       events.KindUserGroup,
+			Action:     events.ActionCreated,
+			ResourceID: groupId.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+	}
+	return evs
+}
+
+func (s *Service) loadProjectTemplate(c echo.Context) (admindb.TacokumoAdminProjectTemplate, error) {
+	templateId, err := parseDisplayID("templateId", c.Param("templateId"))
+	if err != nil {
+		return admindb.TacokumoAdminProjectTemplate{}, err
+	}
+	tmpl, err := s.queries.GetProjectTemplateByDisplayID(c.Request().Context(), templateId)
+	if err != nil {
+		return admindb.TacokumoAdminProjectTemplate{}, errors.Wrapf(err, "failed to get project template by display id")
+	}
+	return tmpl, nil
+}
+
+func (s *Service) toProjectTemplate(ctx context.Context, tmpl admindb.TacokumoAdminProjectTemplate) (ProjectTemplate, error) {
+	resp := ProjectTemplate{
+		ID:          tmpl.DisplayID.String(),
+		Name:        tmpl.Name,
+		Description: tmpl.Description,
+		Kind:        tmpl.Kind,
+		CreatedBy:   tmpl.CreatedBy,
+		CreatedAt:   tmpl.CreatedAt.Time,
+		UpdatedAt:   tmpl.UpdatedAt.Time,
+	}
+	if err := json.Unmarshal(tmpl.Spec, &resp.Spec); err != nil {
+		return resp, errors.Wrapf(err, "failed to unmarshal project template")
+	}
+	if tmpl.SourceProjectID.Valid {
+		source, err := s.queries.GetProjectByID(ctx, tmpl.SourceProjectID.Int64)
+		if err != nil {
+			return resp, errors.Wrapf(err, "failed to get project by id")
+		}
+		resp.SourceProjectID = uuidPtr(source.DisplayID)
+	}
+	return resp, nil
+}
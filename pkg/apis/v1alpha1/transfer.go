@@ -0,0 +1,471 @@
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/pg"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// ProjectSettings configures project lifecycle operations.
+type ProjectSettings struct {
+	// TransferTTL is how long the recipient has to accept an ownership transfer.
+	// Defaults to project.DefaultTransferTTL.
+	TransferTTL time.Duration
+}
+
+// ProjectTransfer hands the ownership of a project from one owner to another user.
+// The recipient must accept it before it expires; until then the initiator can cancel it.
+type ProjectTransfer struct {
+	ID          string     `json:"id"`
+	ProjectID   string     `json:"projectId"`
+	FromUserID  string     `json:"fromUserId"`
+	ToUserID    string     `json:"toUserId"`
+	Status      string     `json:"status"`
+	InitiatedBy string     `json:"initiatedBy"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// CreateProjectTransferRequest is the body of POST /projects/{projectId}/transfers.
+type CreateProjectTransferRequest struct {
+	// ToUserID is the user that becomes an owner when the transfer is accepted.
+	ToUserID string `json:"toUserId"`
+	// FromUserID is the owner that hands over the project. Defaults to the current user.
+	FromUserID string `json:"fromUserId"`
+}
+
+// ProjectTransferAuditEntry records an action taken on an ownership transfer. Entries are kept
+// after the project or the users are deleted.
+type ProjectTransferAuditEntry struct {
+	TransferID string    `json:"transferId"`
+	Action     string    `json:"action"`
+	FromUserID string    `json:"fromUserId"`
+	ToUserID   string    `json:"toUserId"`
+	Actor      string    `json:"actor"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func (s *Service) createProjectTransfer(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var req CreateProjectTransferRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if req.ToUserID == "" {
+		return s.writeError(c, errBadRequest("toUserId is required"))
+	}
+
+	var (
+		transfer admindb.TacokumoAdminProjectTransfer
+		from, to admindb.TacokumoAdminUser
+		pending  []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		var err error
+		if from, err = s.resolveTransferSender(ctx, q, req.FromUserID); err != nil {
+			return err
+		}
+		users, err := resolveUsers(ctx, q, []string{req.ToUserID})
+		if err != nil {
+			return err
+		}
+		to = users[0]
+		if from.ID == to.ID {
+			return errUnprocessable("cannot transfer a project to its current owner")
+		}
+
+		isOwner, err := q.IsProjectOwner(ctx, admindb.IsProjectOwnerParams{ProjectID: proj.ID, UserID: from.ID})
+		if err != nil {
+			return errors.Wrapf(err, "failed to check project owner")
+		}
+		if !isOwner {
+			return errUnprocessable("user %s is not an owner of the project", from.DisplayID.String())
+		}
+		isOwner, err = q.IsProjectOwner(ctx, admindb.IsProjectOwnerParams{ProjectID: proj.ID, UserID: to.ID})
+		if err != nil {
+			return errors.Wrapf(err, "failed to check project owner")
+		}
+		if isOwner {
+			return errUnprocessable("user %s is already an owner of the project", to.DisplayID.String())
+		}
+
+		if _, err := q.ExpireProjectTransfers(ctx, proj.ID); err != nil {
+			return errors.Wrapf(err, "failed to expire project transfers")
+		}
+		transfer, err = q.CreateProjectTransfer(ctx, admindb.CreateProjectTransferParams{
+			ProjectID:   proj.ID,
+			FromUserID:  from.ID,
+			ToUserID:    to.ID,
+			InitiatedBy: currentPrincipalName(ctx),
+			ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(s.transferTTL()), Valid: true},
+		})
+		if err != nil {
+			if pg.IsUniqueViolation(err) {
+				return errConflict("another transfer of this project is pending; cancel it first")
+			}
+			return errors.Wrapf(err, "failed to create project transfer")
+		}
+		if err := recordTransferAudit(ctx, q, proj, transfer, from, to, project.TransferActionInitiated); err != nil {
+			return err
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindProjectTransfer,
+			Action:     events.ActionCreated,
+			ResourceID: transfer.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.JSON(http.StatusCreated, toProjectTransfer(proj, transfer, from, to))
+}
+
+func (s *Service) listProjectTransfers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	status := pgtype.Text{}
+	if v := c.QueryParam("status"); v != "" {
+		if !slices.Contains(project.TransferStatuses, v) {
+			return s.writeError(c, errBadRequest("invalid status: %s", v))
+		}
+		status = pgtype.Text{String: v, Valid: true}
+	}
+
+	transfers, err := s.queries.ListProjectTransfersWithPagination(ctx, admindb.ListProjectTransfersWithPaginationParams{
+		ProjectID: proj.ID,
+		Limit:     limit,
+		Offset:    offset,
+		Status:    status,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list project transfers"))
+	}
+
+	resp := make([]ProjectTransfer, 0, len(transfers))
+	for _, t := range transfers {
+		from, to, err := loadTransferUsers(ctx, s.queries, t)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		resp = append(resp, toProjectTransfer(proj, t, from, to))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Service) getProjectTransfer(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, transferId, err := s.loadProjectAndTransferID(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	t, err := s.queries.GetProjectTransferByDisplayID(ctx, admindb.GetProjectTransferByDisplayIDParams{
+		ProjectID: proj.ID,
+		DisplayID: transferId,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get project transfer by display id"))
+	}
+	from, to, err := loadTransferUsers(ctx, s.queries, t)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, toProjectTransfer(proj, t, from, to))
+}
+
+// acceptProjectTransfer swaps the sender for the recipient in the owners of the project.
+// Only the recipient can accept, and only while the sender still owns the project.
+func (s *Service) acceptProjectTransfer(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, transferId, err := s.loadProjectAndTransferID(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		if errors.Is(err, errNoAdminUser) {
+			return s.writeError(c, newAPIError(http.StatusForbidden, "only the recipient can accept the transfer"))
+		}
+		return s.writeError(c, err)
+	}
+
+	var (
+		transfer admindb.TacokumoAdminProjectTransfer
+		from, to admindb.TacokumoAdminUser
+		pending  []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		var err error
+		if transfer, err = lockPendingTransfer(ctx, q, proj, transferId); err != nil {
+			return err
+		}
+		if transfer.ToUserID != user.ID {
+			return newAPIError(http.StatusForbidden, "only the recipient can accept the transfer")
+		}
+		if from, to, err = loadTransferUsers(ctx, q, transfer); err != nil {
+			return err
+		}
+
+		removed, err := q.DeleteProjectOwner(ctx, admindb.DeleteProjectOwnerParams{ProjectID: proj.ID, UserID: from.ID})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete project owner")
+		}
+		if removed == 0 {
+			return errConflict("user %s no longer owns the project", from.DisplayID.String())
+		}
+		if err := q.AddProjectOwner(ctx, admindb.AddProjectOwnerParams{ProjectID: proj.ID, UserID: to.ID}); err != nil {
+			return errors.Wrapf(err, "failed to add project owner")
+		}
+		if err := project.Check(ctx, q, proj.ID); err != nil {
+			return err
+		}
+		if err := completeTransfer(ctx, q, &transfer, project.TransferStatusAccepted); err != nil {
+			return err
+		}
+		if err := recordTransferAudit(ctx, q, proj, transfer, from, to, project.TransferActionAccepted); err != nil {
+			return err
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindProjectTransfer,
+			Action:     events.ActionUpdated,
+			ResourceID: transfer.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		}, events.Event{
+			Kind:       events.KindProject,
+			Action:     events.ActionUpdated,
+			ResourceID: proj.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.JSON(http.StatusOK, toProjectTransfer(proj, transfer, from, to))
+}
+
+// cancelProjectTransfer withdraws a pending transfer. It can be cancelled by the principal
+// that initiated it or by the sending owner.
+func (s *Service) cancelProjectTransfer(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, transferId, err := s.loadProjectAndTransferID(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var (
+		transfer admindb.TacokumoAdminProjectTransfer
+		from, to admindb.TacokumoAdminUser
+		pending  []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		var err error
+		if transfer, err = lockPendingTransfer(ctx, q, proj, transferId); err != nil {
+			return err
+		}
+		if from, to, err = loadTransferUsers(ctx, q, transfer); err != nil {
+			return err
+		}
+		if transfer.InitiatedBy != currentPrincipalName(ctx) {
+			user, err := s.currentUser(ctx)
+			if err != nil && !errors.Is(err, errNoAdminUser) {
+				return err
+			}
+			if err != nil || user.ID != from.ID {
+				return newAPIError(http.StatusForbidden, "only the initiator can cancel the transfer")
+			}
+		}
+		if err := completeTransfer(ctx, q, &transfer, project.TransferStatusCancelled); err != nil {
+			return err
+		}
+		if err := recordTransferAudit(ctx, q, proj, transfer, from, to, project.TransferActionCancelled); err != nil {
+			return err
+		}
+		pending, err = s.RecordEvents(ctx, q, events.Event{
+			Kind:       events.KindProjectTransfer,
+			Action:     events.ActionUpdated,
+			ResourceID: transfer.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	s.publishAll(ctx, pending)
+	return c.JSON(http.StatusOK, toProjectTransfer(proj, transfer, from, to))
+}
+
+func (s *Service) listProjectTransferAudit(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	logs, err := s.queries.ListProjectTransferAuditLogs(ctx, admindb.ListProjectTransferAuditLogsParams{
+		ProjectDisplayID: proj.DisplayID,
+		Limit:            limit,
+		Offset:           offset,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list project transfer audit logs"))
+	}
+	return c.JSON(http.StatusOK, lo.Map(logs, func(l admindb.TacokumoAdminProjectTransferAuditLog, _ int) ProjectTransferAuditEntry {
+		return ProjectTransferAuditEntry{
+			TransferID: l.TransferDisplayID.String(),
+			Action:     l.Action,
+			FromUserID: l.FromUserDisplayID.String(),
+			ToUserID:   l.ToUserDisplayID.String(),
+			Actor:      l.Actor,
+			CreatedAt:  l.CreatedAt.Time,
+		}
+	}))
+}
+
+// resolveTransferSender returns the owner a transfer is started for, defaulting to the caller.
+func (s *Service) resolveTransferSender(ctx context.Context, q *admindb.Queries, fromUserId string) (admindb.TacokumoAdminUser, error) {
+	if fromUserId != "" {
+		users, err := resolveUsers(ctx, q, []string{fromUserId})
+		if err != nil {
+			return admindb.TacokumoAdminUser{}, err
+		}
+		return users[0], nil
+	}
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		if errors.Is(err, errNoAdminUser) {
+			return user, errUnprocessable("fromUserId is required when the caller is not an admin user")
+		}
+		return user, err
+	}
+	return user, nil
+}
+
+func (s *Service) transferTTL() time.Duration {
+	if s.projects.TransferTTL <= 0 {
+		return project.DefaultTransferTTL
+	}
+	return s.projects.TransferTTL
+}
+
+func (s *Service) loadProjectAndTransferID(c echo.Context) (admindb.TacokumoAdminProject, pgtype.UUID, error) {
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return proj, pgtype.UUID{}, err
+	}
+	transferId, err := parseDisplayID("transferId", c.Param("transferId"))
+	if err != nil {
+		return proj, transferId, err
+	}
+	return proj, transferId, nil
+}
+
+// lockPendingTransfer locks a transfer for the rest of the transaction and checks that it can
+// still be accepted or cancelled.
+func lockPendingTransfer(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, transferId pgtype.UUID) (admindb.TacokumoAdminProjectTransfer, error) {
+	t, err := q.GetProjectTransferByDisplayIDForUpdate(ctx, admindb.GetProjectTransferByDisplayIDForUpdateParams{
+		ProjectID: proj.ID,
+		DisplayID: transferId,
+	})
+	if err != nil {
+		return t, errors.Wrapf(err, "failed to get project transfer by display id")
+	}
+	switch status := project.TransferStatus(t, time.Now()); status {
+	case project.TransferStatusPending:
+		return t, nil
+	case project.TransferStatusExpired:
+		return t, newAPIError(http.StatusGone, "transfer has expired")
+	default:
+		return t, errConflict("transfer is already %s", status)
+	}
+}
+
+func completeTransfer(ctx context.Context, q *admindb.Queries, t *admindb.TacokumoAdminProjectTransfer, status string) error {
+	affected, err := q.CompleteProjectTransfer(ctx, admindb.CompleteProjectTransferParams{ID: t.ID, Status: status})
+	if err != nil {
+		return errors.Wrapf(err, "failed to complete project transfer")
+	}
+	if affected == 0 {
+		return errConflict("transfer is no longer pending")
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	t.Status = status
+	t.CompletedAt = now
+	t.UpdatedAt = now
+	return nil
+}
+
+func recordTransferAudit(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, t admindb.TacokumoAdminProjectTransfer, from, to admindb.TacokumoAdminUser, action string) error {
+	if err := q.CreateProjectTransferAuditLog(ctx, admindb.CreateProjectTransferAuditLogParams{
+		ProjectDisplayID:  proj.DisplayID,
+		TransferDisplayID: t.DisplayID,
+		Action:            action,
+		FromUserDisplayID: from.DisplayID,
+		ToUserDisplayID:   to.DisplayID,
+		Actor:             currentPrincipalName(ctx),
+	}); err != nil {
+		return errors.Wrapf(err, "failed to create project transfer audit log")
+	}
+	return nil
+}
+
+func loadTransferUsers(ctx context.Context, q *admindb.Queries, t admindb.TacokumoAdminProjectTransfer) (from admindb.TacokumoAdminUser, to admindb.TacokumoAdminUser, err error) {
+	if from, err = q.GetUserByID(ctx, t.FromUserID); err != nil {
+		return from, to, errors.Wrapf(err, "failed to get user by id")
+	}
+	if to, err = q.GetUserByID(ctx, t.ToUserID); err != nil {
+		return from, to, errors.Wrapf(err, "failed to get user by id")
+	}
+	return from, to, nil
+}
+
+func toProjectTransfer(proj admindb.TacokumoAdminProject, t admindb.TacokumoAdminProjectTransfer, from, to admindb.TacokumoAdminUser) ProjectTransfer {
+	return ProjectTransfer{
+		ID:          t.DisplayID.String(),
+		ProjectID:   proj.DisplayID.String(),
+		FromUserID:  from.DisplayID.String(),
+		ToUserID:    to.DisplayID.String(),
+		Status:      project.TransferStatus(t, time.Now()),
+		InitiatedBy: t.InitiatedBy,
+		ExpiresAt:   t.ExpiresAt.Time,
+		CompletedAt: timePtr(t.CompletedAt),
+		CreatedAt:   t.CreatedAt.Time,
+		UpdatedAt:   t.UpdatedAt.Time,
+	}
+}
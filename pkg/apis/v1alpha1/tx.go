@@ -0,0 +1,30 @@
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/pg"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// withTx runs fn in a transaction. The transaction is committed if fn returns nil and rolled back otherwise.
+func (s *Service) withTx(ctx context.Context, fn func(q *admindb.Queries) error) error {
+	return s.withTxOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// withTxOptions is withTx with a transaction of the given isolation level and access mode.
+// A transaction that may write to a project addressed by the request locks the project first,
+// so that the project cannot be archived while the change is in progress.
+func (s *Service) withTxOptions(ctx context.Context, opts pgx.TxOptions, fn func(q *admindb.Queries) error) error {
+	return pg.WithTx(ctx, s.pool, s.logger, opts, func(tx pgx.Tx) error {
+		q := s.queries.WithTx(tx)
+		if projectID, ok := activeProject(ctx); ok && opts.AccessMode != pgx.ReadOnly {
+			if err := project.Lock(ctx, q, projectID); err != nil {
+				return err
+			}
+		}
+		return fn(q)
+	})
+}
@@ -0,0 +1,476 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/webhook"
+)
+
+const (
+	maxWebhookURLLength         = 2048
+	maxWebhookDescriptionLength = 256
+	// Secrets chosen by the caller must be long enough to resist guessing the signature key
+	// and fit webhook_subscriptions.secret.
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 128
+)
+
+// Webhook is a subscription that delivers the events of a project to a URL.
+type Webhook struct {
+	ID          string   `json:"id"`
+	ProjectID   string   `json:"projectId"`
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"eventTypes"`
+	Disabled    bool     `json:"disabled"`
+	// Secret is only returned when the webhook is created or its secret is rotated.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateWebhookRequest struct {
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	// EventTypes are exact event types or "<kind>.*". Empty subscribes to all events.
+	EventTypes []string `json:"eventTypes"`
+	// Secret is generated when empty. A chosen secret must be 16 to 128 characters.
+	Secret string `json:"secret"`
+}
+
+// WebhookPatch is the merge patch document accepted by PATCH /projects/{projectId}/webhooks/{webhookId}.
+type WebhookPatch struct {
+	URL         patchField[string]   `json:"url"`
+	Description patchField[string]   `json:"description"`
+	EventTypes  patchField[[]string] `json:"eventTypes"`
+	Disabled    patchField[bool]     `json:"disabled"`
+}
+
+// WebhookDelivery is one delivery of an event to a webhook and the outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhookId"`
+	EventType      string          `json:"eventType"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt,omitempty"`
+	LastStatusCode *int32          `json:"lastStatusCode,omitempty"`
+	LastError      *string         `json:"lastError,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+func validateWebhookFields(rawURL, description string, eventTypes []string) error {
+	if len(rawURL) > maxWebhookURLLength {
+		return errBadRequest("url must be at most %d characters", maxWebhookURLLength)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errBadRequest("url must be an absolute http or https URL")
+	}
+	if len(description) > maxWebhookDescriptionLength {
+		return errBadRequest("description must be at most %d characters", maxWebhookDescriptionLength)
+	}
+	for _, eventType := range eventTypes {
+		if err := webhook.ValidateEventType(eventType); err != nil {
+			return errBadRequest("%s", err.Error())
+		}
+	}
+	return nil
+}
+
+func validateWebhookSecret(secret string) error {
+	n := utf8.RuneCountInString(secret)
+	if n < minWebhookSecretLength || n > maxWebhookSecretLength {
+		return errUnprocessable("secret must be between %d and %d characters", minWebhookSecretLength, maxWebhookSecretLength)
+	}
+	return nil
+}
+
+func (s *Service) createWebhook(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var req CreateWebhookRequest
+	if err := bindJSON(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if req.EventTypes == nil {
+		req.EventTypes = []string{}
+	}
+	if err := validateWebhookFields(req.URL, req.Description, req.EventTypes); err != nil {
+		return s.writeError(c, err)
+	}
+	if req.Secret == "" {
+		if req.Secret, err = webhook.GenerateSecret(); err != nil {
+			return s.writeError(c, errors.Wrapf(err, "failed to generate webhook secret"))
+		}
+	} else if err := validateWebhookSecret(req.Secret); err != nil {
+		return s.writeError(c, err)
+	}
+
+	sub, err := s.queries.CreateWebhookSubscription(ctx, admindb.CreateWebhookSubscriptionParams{
+		ProjectID:   proj.ID,
+		Url:         req.URL,
+		Description: req.Description,
+		EventTypes:  req.EventTypes,
+		Secret:      req.Secret,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to create webhook subscription"))
+	}
+
+	resp := toWebhook(proj, sub)
+	resp.Secret = sub.Secret
+	return c.JSON(http.StatusCreated, resp)
+}
+
+func (s *Service) listWebhooks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	subs, err := s.queries.ListWebhookSubscriptionsWithPagination(ctx, admindb.ListWebhookSubscriptionsWithPaginationParams{
+		ProjectID: proj.ID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list webhook subscriptions"))
+	}
+	return c.JSON(http.StatusOK, lo.Map(subs, func(sub admindb.TacokumoAdminWebhookSubscription, _ int) Webhook {
+		return toWebhook(proj, sub)
+	}))
+}
+
+func (s *Service) getWebhook(c echo.Context) error {
+	proj, sub, err := s.loadWebhook(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, toWebhook(proj, sub))
+}
+
+func (s *Service) patchWebhook(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, current, err := s.loadWebhook(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var patch WebhookPatch
+	if err := decodeMergePatch(c, &patch); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := patch.URL.required("url"); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := patch.Disabled.required("disabled"); err != nil {
+		return s.writeError(c, err)
+	}
+
+	rawURL := patch.URL.apply(current.Url)
+	description := patch.Description.apply(current.Description)
+	eventTypes := patch.EventTypes.apply(current.EventTypes)
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	if err := validateWebhookFields(rawURL, description, eventTypes); err != nil {
+		return s.writeError(c, err)
+	}
+
+	affected, err := s.queries.UpdateWebhookSubscription(ctx, admindb.UpdateWebhookSubscriptionParams{
+		ProjectID:   proj.ID,
+		DisplayID:   current.DisplayID,
+		Url:         rawURL,
+		Description: description,
+		EventTypes:  eventTypes,
+		Disabled:    patch.Disabled.apply(current.Disabled),
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to update webhook subscription"))
+	}
+	if affected == 0 {
+		return s.writeError(c, errNotFound("webhook not found"))
+	}
+
+	sub, err := s.queries.GetWebhookSubscriptionByDisplayID(ctx, admindb.GetWebhookSubscriptionByDisplayIDParams{
+		ProjectID: proj.ID,
+		DisplayID: current.DisplayID,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to get webhook subscription by display id"))
+	}
+	return c.JSON(http.StatusOK, toWebhook(proj, sub))
+}
+
+// rotateWebhookSecret replaces the signing secret with a newly generated one and returns it.
+func (s *Service) rotateWebhookSecret(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, sub, err := s.loadWebhook(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to generate webhook secret"))
+	}
+
+	sub, err = s.queries.RotateWebhookSecret(ctx, admindb.RotateWebhookSecretParams{
+		ProjectID: proj.ID,
+		DisplayID: sub.DisplayID,
+		Secret:    secret,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return s.writeError(c, errNotFound("webhook not found"))
+		}
+		return s.writeError(c, errors.Wrapf(err, "failed to rotate webhook secret"))
+	}
+
+	resp := toWebhook(proj, sub)
+	resp.Secret = secret
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Service) deleteWebhook(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	webhookId, err := parseDisplayID("webhookId", c.Param("webhookId"))
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	affected, err := s.queries.DeleteWebhookSubscription(ctx, admindb.DeleteWebhookSubscriptionParams{
+		ProjectID: proj.ID,
+		DisplayID: webhookId,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to delete webhook subscription"))
+	}
+	if affected == 0 {
+		return s.writeError(c, errNotFound("webhook not found"))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Service) listWebhookDeliveries(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	_, sub, err := s.loadWebhook(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	status := pgtype.Text{}
+	if v := c.QueryParam("status"); v != "" {
+		if v != webhook.StatusPending && v != webhook.StatusSucceeded && v != webhook.StatusDead {
+			return s.writeError(c, errBadRequest("status must be one of %s, %s, %s", webhook.StatusPending, webhook.StatusSucceeded, webhook.StatusDead))
+		}
+		status = pgtype.Text{String: v, Valid: true}
+	}
+
+	deliveries, err := s.queries.ListWebhookDeliveriesWithPagination(ctx, admindb.ListWebhookDeliveriesWithPaginationParams{
+		SubscriptionID: sub.ID,
+		Status:         status,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list webhook deliveries"))
+	}
+	return c.JSON(http.StatusOK, lo.Map(deliveries, func(d admindb.TacokumoAdminWebhookDelivery, _ int) WebhookDelivery {
+		return toWebhookDelivery(sub, d)
+	}))
+}
+
+func (s *Service) getWebhookDelivery(c echo.Context) error {
+	_, sub, delivery, err := s.loadWebhookDelivery(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, toWebhookDelivery(sub, delivery))
+}
+
+// redeliverWebhookDelivery queues the payload of an earlier delivery again as a new delivery.
+// Disabled webhooks are not delivered to, so redelivery is rejected until they are enabled.
+func (s *Service) redeliverWebhookDelivery(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	_, sub, delivery, err := s.loadWebhookDelivery(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	if sub.Disabled {
+		return s.writeError(c, errConflict("webhook is disabled"))
+	}
+
+	redelivery, err := s.queries.CreateWebhookDelivery(ctx, admindb.CreateWebhookDeliveryParams{
+		SubscriptionID: sub.ID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to create webhook delivery"))
+	}
+	return c.JSON(http.StatusAccepted, toWebhookDelivery(sub, redelivery))
+}
+
+// enqueueWebhookDeliveries queues a delivery of the event for every matching webhook of its
+// project. The event is not on the stream yet, so the payload has no event ID; receivers tell
+// deliveries apart by the delivery ID.
+func (s *Service) enqueueWebhookDeliveries(ctx context.Context, q *admindb.Queries, event events.Event) error {
+	if event.ProjectID == "" {
+		return nil
+	}
+	projectId, err := parseDisplayID("projectId", event.ProjectID)
+	if err != nil {
+		return nil
+	}
+
+	subs, err := q.ListActiveWebhookSubscriptionsByProject(ctx, projectId)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list webhook subscriptions")
+	}
+	subs = lo.Filter(subs, func(sub admindb.TacokumoAdminWebhookSubscription, _ int) bool {
+		return webhook.MatchEventType(sub.EventTypes, event.Type())
+	})
+	if len(subs) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(webhook.NewPayload(event))
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal webhook payload")
+	}
+	for _, sub := range subs {
+		if _, err := q.CreateWebhookDelivery(ctx, admindb.CreateWebhookDeliveryParams{
+			SubscriptionID: sub.ID,
+			EventType:      event.Type(),
+			Payload:        payload,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to enqueue webhook delivery for webhook %s", sub.DisplayID.String())
+		}
+	}
+	return nil
+}
+
+// loadProject resolves the projectId path parameter.
+func (s *Service) loadProject(c echo.Context) (admindb.TacokumoAdminProject, error) {
+	projectId, err := parseDisplayID("projectId", c.Param("projectId"))
+	if err != nil {
+		return admindb.TacokumoAdminProject{}, err
+	}
+	proj, err := s.queries.GetProjectByDisplayID(c.Request().Context(), projectId)
+	if err != nil {
+		return admindb.TacokumoAdminProject{}, errors.Wrapf(err, "failed to get project by display id")
+	}
+	return proj, nil
+}
+
+func (s *Service) loadWebhook(c echo.Context) (admindb.TacokumoAdminProject, admindb.TacokumoAdminWebhookSubscription, error) {
+	proj, err := s.loadProject(c)
+	if err != nil {
+		return proj, admindb.TacokumoAdminWebhookSubscription{}, err
+	}
+	webhookId, err := parseDisplayID("webhookId", c.Param("webhookId"))
+	if err != nil {
+		return proj, admindb.TacokumoAdminWebhookSubscription{}, err
+	}
+	sub, err := s.queries.GetWebhookSubscriptionByDisplayID(c.Request().Context(), admindb.GetWebhookSubscriptionByDisplayIDParams{
+		ProjectID: proj.ID,
+		DisplayID: webhookId,
+	})
+	if err != nil {
+		return proj, sub, errors.Wrapf(err, "failed to get webhook subscription by display id")
+	}
+	return proj, sub, nil
+}
+
+func (s *Service) loadWebhookDelivery(c echo.Context) (admindb.TacokumoAdminProject, admindb.TacokumoAdminWebhookSubscription, admindb.TacokumoAdminWebhookDelivery, error) {
+	proj, sub, err := s.loadWebhook(c)
+	if err != nil {
+		return proj, sub, admindb.TacokumoAdminWebhookDelivery{}, err
+	}
+	deliveryId, err := parseDisplayID("deliveryId", c.Param("deliveryId"))
+	if err != nil {
+		return proj, sub, admindb.TacokumoAdminWebhookDelivery{}, err
+	}
+	delivery, err := s.queries.GetWebhookDeliveryByDisplayID(c.Request().Context(), admindb.GetWebhookDeliveryByDisplayIDParams{
+		SubscriptionID: sub.ID,
+		DisplayID:      deliveryId,
+	})
+	if err != nil {
+		return proj, sub, delivery, errors.Wrapf(err, "failed to get webhook delivery by display id")
+	}
+	return proj, sub, delivery, nil
+}
+
+func toWebhook(proj admindb.TacokumoAdminProject, sub admindb.TacokumoAdminWebhookSubscription) Webhook {
+	return Webhook{
+		ID:          sub.DisplayID.String(),
+		ProjectID:   proj.DisplayID.String(),
+		URL:         sub.Url,
+		Description: sub.Description,
+		EventTypes:  sub.EventTypes,
+		Disabled:    sub.Disabled,
+		CreatedAt:   sub.CreatedAt.Time,
+		UpdatedAt:   sub.UpdatedAt.Time,
+	}
+}
+
+func toWebhookDelivery(sub admindb.TacokumoAdminWebhookSubscription, d admindb.TacokumoAdminWebhookDelivery) WebhookDelivery {
+	delivery := WebhookDelivery{
+		ID:            d.DisplayID.String(),
+		WebhookID:     sub.DisplayID.String(),
+		EventType:     d.EventType,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		LastAttemptAt: timePtr(d.LastAttemptAt),
+		DeliveredAt:   timePtr(d.DeliveredAt),
+		Payload:       d.Payload,
+		CreatedAt:     d.CreatedAt.Time,
+	}
+	if d.Status == webhook.StatusPending {
+		delivery.NextAttemptAt = timePtr(d.NextAttemptAt)
+	}
+	if d.LastStatusCode.Valid {
+		delivery.LastStatusCode = &d.LastStatusCode.Int32
+	}
+	if d.LastError.Valid {
+		delivery.LastError = &d.LastError.String
+	}
+	return delivery
+}
@@ -0,0 +1,189 @@
+// Package clientcert authenticates requests made with TLS client certificates.
+//
+// Client certificates are verified by the TLS listener against a CA bundle. A verified
+// certificate whose subject or subject alternative name matches a configured Identity
+// authenticates the request as the service account of the identity.
+package clientcert
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/auth/session"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+// Client authentication modes of the TLS listener.
+const (
+	// ModeNone does not ask for client certificates.
+	ModeNone = "none"
+	// ModeOptional verifies client certificates when they are presented. Route groups that
+	// need a certificate are enforced by middleware.
+	ModeOptional = "optional"
+	// ModeRequire rejects connections without a verified client certificate.
+	ModeRequire = "require"
+)
+
+// ClientAuthType returns the tls.ClientAuthType of a mode. An empty mode is ModeNone.
+func ClientAuthType(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", ModeNone:
+		return tls.NoClientCert, nil
+	case ModeOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ModeRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, errors.Newf("client auth must be %s, %s or %s, got %q", ModeNone, ModeOptional, ModeRequire, mode)
+	}
+}
+
+// LoadCAPool reads a PEM bundle of CA certificates.
+func LoadCAPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read client CA file: %s", path)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Newf("no certificates found in client CA file: %s", path)
+	}
+	return pool, nil
+}
+
+// Identity maps client certificates to a service account. Exactly one of Subject and SAN is set.
+type Identity struct {
+	// Subject matches the subject distinguished name, e.g. "CN=deployer,O=TacoKumo", or just
+	// its common name.
+	Subject string
+	// SAN matches any DNS name, URI, email address or IP address of the certificate.
+	SAN string
+	// ServiceAccountID is the display ID of the service account.
+	ServiceAccountID string
+}
+
+// Validate checks that the identity has exactly one matcher and a valid service account ID.
+func (i Identity) Validate() error {
+	if (i.Subject == "") == (i.SAN == "") {
+		return errors.New("client certificate identity must set exactly one of subject and san")
+	}
+	id := pgtype.UUID{}
+	if err := id.Scan(i.ServiceAccountID); err != nil {
+		return errors.Newf("client certificate identity has invalid service account id %q", i.ServiceAccountID)
+	}
+	return nil
+}
+
+// Matches reports whether the identity matches cert.
+func (i Identity) Matches(cert *x509.Certificate) bool {
+	if i.Subject != "" {
+		return i.Subject == cert.Subject.String() || i.Subject == cert.Subject.CommonName
+	}
+	for _, name := range cert.DNSNames {
+		if name == i.SAN {
+			return true
+		}
+	}
+	for _, uri := range cert.URIs {
+		if uri.String() == i.SAN {
+			return true
+		}
+	}
+	for _, email := range cert.EmailAddresses {
+		if email == i.SAN {
+			return true
+		}
+	}
+	for _, ip := range cert.IPAddresses {
+		if ip.String() == i.SAN {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the first identity that matches cert.
+func Match(identities []Identity, cert *x509.Certificate) (Identity, bool) {
+	for _, i := range identities {
+		if i.Matches(cert) {
+			return i, true
+		}
+	}
+	return Identity{}, false
+}
+
+// Authenticator resolves verified client certificates into service account sessions.
+type Authenticator struct {
+	logger     *slog.Logger
+	queries    *admindb.Queries
+	ttl        time.Duration
+	identities []Identity
+}
+
+func NewAuthenticator(logger *slog.Logger, queries *admindb.Queries, ttl time.Duration, identities []Identity) *Authenticator {
+	return &Authenticator{
+		logger:     logger,
+		queries:    queries,
+		ttl:        ttl,
+		identities: identities,
+	}
+}
+
+// Authenticate implements middleware.CertificateAuthenticator.
+// It returns session.ErrSessionNotFound for certificates that match no identity and for
+// unknown or disabled service accounts.
+func (a *Authenticator) Authenticate(ctx context.Context, cert *x509.Certificate) (*session.Session, error) {
+	identity, ok := Match(a.identities, cert)
+	if !ok {
+		a.logger.DebugContext(ctx, "client certificate matches no identity", slog.String("subject", cert.Subject.String()))
+		return nil, session.ErrSessionNotFound
+	}
+
+	id := pgtype.UUID{}
+	if err := id.Scan(identity.ServiceAccountID); err != nil {
+		return nil, errors.Wrapf(err, "failed to scan service account id")
+	}
+	row, err := a.queries.GetServiceAccountByDisplayID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			a.logger.WarnContext(ctx, "client certificate identity refers to unknown service account", slog.String("service_account_id", identity.ServiceAccountID))
+			return nil, session.ErrSessionNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get service account by display id")
+	}
+	if row.Disabled {
+		a.logger.DebugContext(ctx, "service account is disabled", slog.String("service_account_id", row.DisplayID.String()))
+		return nil, session.ErrSessionNotFound
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(a.ttl)
+	if cert.NotAfter.Before(expiresAt) {
+		expiresAt = cert.NotAfter
+	}
+
+	return &session.Session{
+		ID:               "clientcert:" + Fingerprint(cert),
+		UserID:           "serviceaccount:" + row.DisplayID.String(),
+		Name:             row.Name,
+		ServiceAccountID: row.DisplayID.String(),
+		TeamMemberships:  []session.TeamMembership{},
+		ExpiresAt:        expiresAt,
+		CreatedAt:        now,
+	}, nil
+}
+
+// Fingerprint returns the hex SHA-256 fingerprint of cert.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
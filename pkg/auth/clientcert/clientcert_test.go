@@ -0,0 +1,194 @@
+package clientcert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClientAuthType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mode    string
+		want    tls.ClientAuthType
+		wantErr bool
+	}{
+		{"", tls.NoClientCert, false},
+		{ModeNone, tls.NoClientCert, false},
+		{ModeOptional, tls.VerifyClientCertIfGiven, false},
+		{ModeRequire, tls.RequireAndVerifyClientCert, false},
+		{"always", tls.NoClientCert, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ClientAuthType(tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ClientAuthType(%q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ClientAuthType(%q) = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentityValidate(t *testing.T) {
+	t.Parallel()
+
+	const id = "0190d3b4-7b1c-7c2e-8a6f-4f0e5b1c2d3e"
+	tests := []struct {
+		name     string
+		identity Identity
+		wantErr  bool
+	}{
+		{"subject", Identity{Subject: "deployer", ServiceAccountID: id}, false},
+		{"san", Identity{SAN: "deployer.internal", ServiceAccountID: id}, false},
+		{"no matcher", Identity{ServiceAccountID: id}, true},
+		{"both matchers", Identity{Subject: "deployer", SAN: "deployer.internal", ServiceAccountID: id}, true},
+		{"invalid service account id", Identity{Subject: "deployer", ServiceAccountID: "deployer"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.identity.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIdentityMatches(t *testing.T) {
+	t.Parallel()
+
+	spiffe, err := url.Parse("spiffe://tacokumo.internal/deployer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "deployer", Organization: []string{"TacoKumo"}},
+		DNSNames:       []string{"deployer.internal"},
+		URIs:           []*url.URL{spiffe},
+		EmailAddresses: []string{"deployer@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.5")},
+	}
+
+	tests := []struct {
+		name     string
+		identity Identity
+		want     bool
+	}{
+		{"common name", Identity{Subject: "deployer"}, true},
+		{"distinguished name", Identity{Subject: "CN=deployer,O=TacoKumo"}, true},
+		{"other subject", Identity{Subject: "CN=deployer,O=Other"}, false},
+		{"dns name", Identity{SAN: "deployer.internal"}, true},
+		{"uri", Identity{SAN: "spiffe://tacokumo.internal/deployer"}, true},
+		{"email", Identity{SAN: "deployer@example.com"}, true},
+		{"ip address", Identity{SAN: "10.0.0.5"}, true},
+		{"san does not match the common name", Identity{SAN: "deployer"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.identity.Matches(cert); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("match returns the first matching identity", func(t *testing.T) {
+		t.Parallel()
+
+		identities := []Identity{
+			{SAN: "other.internal", ServiceAccountID: "a"},
+			{SAN: "deployer.internal", ServiceAccountID: "b"},
+			{Subject: "deployer", ServiceAccountID: "c"},
+		}
+		got, ok := Match(identities, cert)
+		if !ok || got.ServiceAccountID != "b" {
+			t.Errorf("Match() = %+v, %v, want identity b", got, ok)
+		}
+		if _, ok := Match(identities[:1], cert); ok {
+			t.Error("Match() should not match unrelated identities")
+		}
+	})
+}
+
+func TestLoadCAPool(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	t.Run("loads a PEM bundle", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(dir, "ca.crt")
+		if err := os.WriteFile(path, newCAPEM(t), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCAPool(path); err != nil {
+			t.Errorf("LoadCAPool() error = %v", err)
+		}
+	})
+
+	t.Run("rejects a file without certificates", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(dir, "empty.crt")
+		if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCAPool(path); err == nil {
+			t.Error("LoadCAPool() should fail for a file without certificates")
+		}
+	})
+
+	t.Run("rejects a missing file", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := LoadCAPool(filepath.Join(dir, "missing.crt")); err == nil {
+			t.Error("LoadCAPool() should fail for a missing file")
+		}
+	})
+}
+
+func newCAPEM(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
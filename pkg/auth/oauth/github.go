@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -13,8 +14,11 @@ import (
 )
 
 type GitHubClient struct {
-	config      *oauth2.Config
-	httpClient  *http.Client
+	config     *oauth2.Config
+	httpClient *http.Client
+
+	// mu guards allowedOrgs, which can be changed by a config reload.
+	mu          sync.RWMutex
 	allowedOrgs []string
 }
 
@@ -101,10 +105,47 @@ func (c *GitHubClient) GetUser(ctx context.Context, token *oauth2.Token) (*GitHu
 	return &user, nil
 }
 
+// GitHubEmail is an email address registered to a GitHub account.
+type GitHubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// GetVerifiedEmails returns every verified email address of the authenticated user.
+func (c *GitHubClient) GetVerifiedEmails(ctx context.Context, token *oauth2.Token) ([]string, error) {
+	emails, err := c.getEmails(ctx, c.config.Client(ctx, token))
+	if err != nil {
+		return nil, err
+	}
+	verified := []string{}
+	for _, e := range emails {
+		if e.Verified {
+			verified = append(verified, e.Email)
+		}
+	}
+	return verified, nil
+}
+
 func (c *GitHubClient) getPrimaryEmail(ctx context.Context, client *http.Client) (string, error) {
+	emails, err := c.getEmails(ctx, client)
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			return e.Email, nil
+		}
+	}
+
+	return "", errors.New("no primary verified email found")
+}
+
+func (c *GitHubClient) getEmails(ctx context.Context, client *http.Client) (emails []GitHubEmail, err error) {
 	resp, err := client.Get("https://api.github.com/user/emails")
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get user emails")
+		return nil, errors.Wrap(err, "failed to get user emails")
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -113,25 +154,13 @@ func (c *GitHubClient) getPrimaryEmail(ctx context.Context, client *http.Client)
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", errors.Newf("github api returned status %d for emails", resp.StatusCode)
+		return nil, errors.Newf("github api returned status %d for emails", resp.StatusCode)
 	}
 
-	var emails []struct {
-		Email    string `json:"email"`
-		Primary  bool   `json:"primary"`
-		Verified bool   `json:"verified"`
-	}
 	if err := json.NewDecoder(resp.Body).Decode(&emails); err != nil {
-		return "", errors.Wrap(err, "failed to decode emails")
+		return nil, errors.Wrap(err, "failed to decode emails")
 	}
-
-	for _, e := range emails {
-		if e.Primary && e.Verified {
-			return e.Email, nil
-		}
-	}
-
-	return "", errors.New("no primary verified email found")
+	return emails, nil
 }
 
 func (c *GitHubClient) GetUserOrgs(ctx context.Context, token *oauth2.Token) ([]GitHubOrg, error) {
@@ -159,13 +188,25 @@ func (c *GitHubClient) GetUserOrgs(ctx context.Context, token *oauth2.Token) ([]
 	return orgs, nil
 }
 
+// SetAllowedOrgs replaces the organizations whose members may log in. It affects logins that
+// have not been validated yet.
+func (c *GitHubClient) SetAllowedOrgs(allowedOrgs []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.allowedOrgs = allowedOrgs
+}
+
 func (c *GitHubClient) ValidateOrgMembership(orgs []GitHubOrg) bool {
-	if len(c.allowedOrgs) == 0 {
+	c.mu.RLock()
+	allowedOrgs := c.allowedOrgs
+	c.mu.RUnlock()
+
+	if len(allowedOrgs) == 0 {
 		return true
 	}
 
 	for _, org := range orgs {
-		if lo.Contains(c.allowedOrgs, org.Login) {
+		if lo.Contains(allowedOrgs, org.Login) {
 			return true
 		}
 	}
@@ -162,6 +162,24 @@ func TestGitHubClient_ValidateOrgMembership(t *testing.T) {
 	}
 }
 
+func TestGitHubClient_SetAllowedOrgs(t *testing.T) {
+	t.Parallel()
+
+	client := NewGitHubClient("client-id", "client-secret", "callback-url", []string{"old-org"})
+	orgs := []GitHubOrg{{Login: "new-org"}}
+	if client.ValidateOrgMembership(orgs) {
+		t.Fatal("ValidateOrgMembership() should reject orgs that are not allowed yet")
+	}
+
+	client.SetAllowedOrgs([]string{"new-org"})
+	if !client.ValidateOrgMembership(orgs) {
+		t.Error("ValidateOrgMembership() should accept orgs allowed by SetAllowedOrgs")
+	}
+	if client.ValidateOrgMembership([]GitHubOrg{{Login: "old-org"}}) {
+		t.Error("ValidateOrgMembership() should reject orgs removed by SetAllowedOrgs")
+	}
+}
+
 // Helper function to check if a string contains a substring
 func containsString(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
@@ -0,0 +1,52 @@
+package serviceaccount
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// TokenPrefix marks bearer tokens issued to service accounts so that they can be
+// told apart from session IDs without a lookup.
+const TokenPrefix = "tksa_"
+
+// displayPrefixLength is the number of random characters kept in plain text so
+// that operators can recognize a token in listings.
+const displayPrefixLength = 8
+
+// Token is a freshly minted service account credential.
+// Plaintext is only available at creation time; only Hash is persisted.
+type Token struct {
+	Plaintext string
+	Prefix    string
+	Hash      string
+}
+
+// GenerateToken creates a new random service account token.
+func GenerateToken() (Token, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return Token{}, errors.Wrap(err, "failed to generate random bytes")
+	}
+	secret := hex.EncodeToString(bytes)
+	plaintext := TokenPrefix + secret
+	return Token{
+		Plaintext: plaintext,
+		Prefix:    TokenPrefix + secret[:displayPrefixLength],
+		Hash:      HashToken(plaintext),
+	}, nil
+}
+
+// HashToken returns the hex encoded SHA-256 hash of a token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsServiceAccountToken reports whether the bearer token looks like a service account token.
+func IsServiceAccountToken(token string) bool {
+	return strings.HasPrefix(token, TokenPrefix) && len(token) > len(TokenPrefix)
+}
@@ -0,0 +1,76 @@
+package serviceaccount
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateToken(t *testing.T) {
+	t.Parallel()
+
+	t.Run("generates token with prefix and matching hash", func(t *testing.T) {
+		t.Parallel()
+
+		token, err := GenerateToken()
+		if err != nil {
+			t.Fatalf("GenerateToken() failed: %v", err)
+		}
+
+		if !strings.HasPrefix(token.Plaintext, TokenPrefix) {
+			t.Errorf("Plaintext %q does not start with %q", token.Plaintext, TokenPrefix)
+		}
+
+		// 32 bytes encoded as hex = 64 characters
+		if len(token.Plaintext) != len(TokenPrefix)+64 {
+			t.Errorf("Plaintext has wrong length: got %d, want %d", len(token.Plaintext), len(TokenPrefix)+64)
+		}
+
+		if !strings.HasPrefix(token.Plaintext, token.Prefix) {
+			t.Errorf("Prefix %q is not a prefix of the token", token.Prefix)
+		}
+
+		if token.Hash != HashToken(token.Plaintext) {
+			t.Error("Hash does not match HashToken(Plaintext)")
+		}
+	})
+
+	t.Run("generates unique tokens", func(t *testing.T) {
+		t.Parallel()
+
+		seen := make(map[string]bool)
+		for i := 0; i < 100; i++ {
+			token, err := GenerateToken()
+			if err != nil {
+				t.Fatalf("GenerateToken() failed: %v", err)
+			}
+			if seen[token.Plaintext] {
+				t.Errorf("GenerateToken() generated duplicate token")
+			}
+			seen[token.Plaintext] = true
+		}
+	})
+}
+
+func TestIsServiceAccountToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		token    string
+		expected bool
+	}{
+		{"tksa_0123456789abcdef", true},
+		{"tksa_", false},
+		{"0123456789abcdef", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run("token: "+tt.token, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsServiceAccountToken(tt.token); got != tt.expected {
+				t.Errorf("IsServiceAccountToken(%q) = %v, want %v", tt.token, got, tt.expected)
+			}
+		})
+	}
+}
@@ -22,8 +22,16 @@ type Session struct {
 	AccessToken     string           `json:"access_token"`
 	RefreshToken    string           `json:"refresh_token"`
 	TeamMemberships []TeamMembership `json:"team_memberships"`
-	ExpiresAt       time.Time        `json:"expires_at"`
-	CreatedAt       time.Time        `json:"created_at"`
+	// ServiceAccountID is set when the request is authenticated with a service account token
+	// instead of a GitHub login.
+	ServiceAccountID string    `json:"service_account_id,omitempty"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// IsServiceAccount reports whether the session belongs to a service account.
+func (s *Session) IsServiceAccount() bool {
+	return s.ServiceAccountID != ""
 }
 
 type TeamMembership struct {
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+	"github.com/tacokumo/admin-api/pkg/client/v1alpha1"
+	"github.com/tacokumo/admin-api/pkg/declarative"
+)
+
+// newApplyCommand returns the apply command, or the read-only diff command when apply is false.
+func newApplyCommand(logger *slog.Logger, apply bool) *cobra.Command {
+	use := "apply"
+	if !apply {
+		use = "diff"
+	}
+	c := &cobra.Command{
+		Use: use,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			transport := &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			}
+			httpClient := http.Client{
+				Transport: transport,
+				Timeout:   30 * time.Second, // 30 second timeout
+			}
+			client := v1alpha1.NewDefaultClient(logger, httpClient)
+
+			file, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get file flag")
+			}
+			if file == "" {
+				return errors.New("file is required")
+			}
+			prune, err := cmd.Flags().GetBool("prune")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get prune flag")
+			}
+
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read manifest")
+			}
+			m, err := declarative.Parse(b)
+			if err != nil {
+				return err
+			}
+			if err := m.Validate(); err != nil {
+				return err
+			}
+
+			plan, err := client.Apply(cmd.Context(), m, v1alpha1.ApplyOptions{Prune: prune, DryRun: true})
+			if err != nil {
+				fmt.Printf("❌ Failed to plan manifest: %v\n", err)
+				return errors.Wrapf(err, "failed to plan manifest")
+			}
+			if len(plan.Changes) == 0 {
+				fmt.Println("✅ No changes; the projects match the manifest")
+				return nil
+			}
+			fmt.Printf("📋 %d change(s):\n", len(plan.Changes))
+			for _, change := range plan.Changes {
+				fmt.Printf("  %s\n", change)
+			}
+			if !apply {
+				return nil
+			}
+
+			yes, err := cmd.Flags().GetBool("yes")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get yes flag")
+			}
+			if !yes {
+				fmt.Print("Apply these changes? [y/N]: ")
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+					fmt.Println("Apply cancelled")
+					return nil
+				}
+			}
+
+			// The plan is made again on the server, so changes made in the meantime are taken into account.
+			result, err := client.Apply(cmd.Context(), m, v1alpha1.ApplyOptions{Prune: prune})
+			if err != nil {
+				fmt.Printf("❌ Failed to apply manifest: %v\n", err)
+				return errors.Wrapf(err, "failed to apply manifest")
+			}
+			fmt.Printf("✅ Applied %d change(s)\n", len(result.Changes))
+			return nil
+		},
+	}
+
+	c.Flags().StringP("file", "f", "", "マニフェストファイルのパス (YAMLまたはJSON)")
+	c.Flags().Bool("prune", false, "マニフェストにないロール・ユーザグループと管理対象プロジェクトを削除する")
+	if apply {
+		c.Flags().BoolP("yes", "y", false, "確認せずに適用する")
+	}
+	return c
+}
@@ -25,6 +25,12 @@ func newProjectCommand(logger *slog.Logger) *cobra.Command {
 	}
 	c.AddCommand(newProjectCreateCommand(logger))
 	c.AddCommand(newProjectListCommand(logger))
+	c.AddCommand(newProjectGetCommand(logger))
+	c.AddCommand(newProjectUpdateCommand(logger))
+	c.AddCommand(newProjectConvertCommand(logger))
+	c.AddCommand(newProjectCloneCommand(logger))
+	c.AddCommand(newProjectArchiveCommand(logger, true))
+	c.AddCommand(newProjectArchiveCommand(logger, false))
 	return c
 }
 
@@ -62,16 +68,11 @@ func newProjectCreateCommand(logger *slog.Logger) *cobra.Command {
 			if err != nil {
 				return errors.Wrapf(err, "failed to get owner-ids flag")
 			}
-			ownerGroupIds, err := cmd.Flags().GetStringSlice("owner-group-ids")
-			if err != nil {
-				return errors.Wrapf(err, "failed to get owner-group-ids flag")
-			}
 			reqBody := generated.CreateProjectRequest{
-				Name:          name,
-				Description:   description,
-				Kind:          generated.CreateProjectRequestKind(kind),
-				OwnerIds:      ownerIds,
-				OwnerGroupIds: ownerGroupIds,
+				Name:        name,
+				Description: description,
+				Kind:        generated.CreateProjectRequestKind(kind),
+				OwnerIds:    ownerIds,
 			}
 
 			if err := client.CreateProject(cmd.Context(), &reqBody); err != nil {
@@ -86,8 +87,7 @@ func newProjectCreateCommand(logger *slog.Logger) *cobra.Command {
 
 	c.Flags().String("name", "project", "プロジェクト名")
 	c.Flags().String("description", "the sample project", "プロジェクトの説明")
-	c.Flags().StringSlice("owner-ids", []string{}, "プロジェクトのオーナーID")
-	c.Flags().StringSlice("owner-group-ids", []string{}, "プロジェクトのオーナーグループID")
+	c.Flags().StringSlice("owner-ids", []string{}, "プロジェクトのオーナーID (sharedの場合はオーナーグループのメンバー)")
 	c.Flags().String("kind", "personal", "プロジェクトの種類 (personal | shared)")
 	return c
 }
@@ -107,8 +107,20 @@ func newProjectListCommand(logger *slog.Logger) *cobra.Command {
 			}
 			client := v1alpha1.NewDefaultClient(logger, httpClient)
 
+			includeArchived, err := cmd.Flags().GetBool("include-archived")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get include-archived flag")
+			}
+			selector, err := cmd.Flags().GetString("selector")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get selector flag")
+			}
+
 			fmt.Println("Fetching projects...")
-			projects, err := client.ListProjects(cmd.Context())
+			projects, err := client.ListProjects(cmd.Context(), v1alpha1.ListProjectsOptions{
+				IncludeArchived: includeArchived,
+				LabelSelector:   selector,
+			})
 			if err != nil {
 				fmt.Printf("❌ Failed to list projects: %v\n", err)
 				return errors.Wrapf(err, "failed to list projects")
@@ -130,5 +142,339 @@ func newProjectListCommand(logger *slog.Logger) *cobra.Command {
 			return nil
 		},
 	}
+	c.Flags().Bool("include-archived", false, "アーカイブ済みのプロジェクトも表示する")
+	c.Flags().StringP("selector", "l", "", "ラベルセレクタで絞り込む (例: env=prod,team!=infra)")
+	return c
+}
+
+func newProjectGetCommand(logger *slog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use: "get",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			transport := &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			}
+			httpClient := http.Client{
+				Transport: transport,
+				Timeout:   30 * time.Second, // 30 second timeout
+			}
+			client := v1alpha1.NewDefaultClient(logger, httpClient)
+
+			id, err := cmd.Flags().GetString("id")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get id flag")
+			}
+			if id == "" {
+				return errors.New("id is required")
+			}
+
+			project, etag, err := client.GetProject(cmd.Context(), id)
+			if err != nil {
+				fmt.Printf("❌ Failed to get project: %v\n", err)
+				return errors.Wrapf(err, "failed to get project")
+			}
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"ID", "Name", "Description", "Kind", "ETag"})
+			t.AppendRow(table.Row{project.ID, project.Name, project.Description, project.Kind, etag})
+			t.Render()
+			return nil
+		},
+	}
+
+	c.Flags().String("id", "", "プロジェクトID")
+	return c
+}
+
+func newProjectUpdateCommand(logger *slog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use: "update",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			transport := &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			}
+			httpClient := http.Client{
+				Transport: transport,
+				Timeout:   30 * time.Second, // 30 second timeout
+			}
+			client := v1alpha1.NewDefaultClient(logger, httpClient)
+
+			id, err := cmd.Flags().GetString("id")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get id flag")
+			}
+			if id == "" {
+				return errors.New("id is required")
+			}
+			ifMatch, err := cmd.Flags().GetString("if-match")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get if-match flag")
+			}
+
+			// Only the flags that were given are sent, so other fields keep their current values.
+			patch := map[string]any{}
+			for _, name := range []string{"name", "description"} {
+				if !cmd.Flags().Changed(name) {
+					continue
+				}
+				v, err := cmd.Flags().GetString(name)
+				if err != nil {
+					return errors.Wrapf(err, "failed to get %s flag", name)
+				}
+				patch[name] = v
+			}
+			if cmd.Flags().Changed("owner-ids") {
+				ownerIds, err := cmd.Flags().GetStringSlice("owner-ids")
+				if err != nil {
+					return errors.Wrapf(err, "failed to get owner-ids flag")
+				}
+				patch["ownerIds"] = ownerIds
+			}
+			if cmd.Flags().Changed("owner-group-ids") {
+				ownerGroupIds, err := cmd.Flags().GetStringSlice("owner-group-ids")
+				if err != nil {
+					return errors.Wrapf(err, "failed to get owner-group-ids flag")
+				}
+				patch["ownerGroupIds"] = ownerGroupIds
+			}
+			if len(patch) == 0 {
+				return errors.New("nothing to update")
+			}
+
+			if _, err := client.PatchProject(cmd.Context(), id, patch, ifMatch); err != nil {
+				if errors.Is(err, v1alpha1.ErrConflict) {
+					fmt.Println("❌ Project was modified by someone else since it was fetched. Run `project get` to see the latest state and try again.")
+					return err
+				}
+				fmt.Printf("❌ Failed to update project: %v\n", err)
+				return errors.Wrapf(err, "failed to update project")
+			}
+
+			fmt.Println("✅ Project updated successfully")
+			return nil
+		},
+	}
+
+	c.Flags().String("id", "", "プロジェクトID")
+	c.Flags().String("name", "", "新しいプロジェクト名")
+	c.Flags().String("description", "", "新しいプロジェクトの説明")
+	c.Flags().StringSlice("owner-ids", []string{}, "新しいプロジェクトのオーナーID")
+	c.Flags().StringSlice("owner-group-ids", []string{}, "新しいプロジェクトのオーナーグループID (sharedのみ)")
+	c.Flags().String("if-match", "", "更新の前提とするETag (project getで取得した値。省略時は無条件に更新)")
+	return c
+}
+
+func newProjectConvertCommand(logger *slog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use: "convert",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			transport := &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			}
+			httpClient := http.Client{
+				Transport: transport,
+				Timeout:   30 * time.Second, // 30 second timeout
+			}
+			client := v1alpha1.NewDefaultClient(logger, httpClient)
+
+			id, err := cmd.Flags().GetString("id")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get id flag")
+			}
+			if id == "" {
+				return errors.New("id is required")
+			}
+			kind, err := cmd.Flags().GetString("kind")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get kind flag")
+			}
+			if kind == "" {
+				return errors.New("kind is required")
+			}
+			ownerID, err := cmd.Flags().GetString("owner-id")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get owner-id flag")
+			}
+			ownerIds, err := cmd.Flags().GetStringSlice("owner-ids")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get owner-ids flag")
+			}
+			ownerGroupName, err := cmd.Flags().GetString("owner-group-name")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get owner-group-name flag")
+			}
+			removeMembers, err := cmd.Flags().GetBool("remove-members")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get remove-members flag")
+			}
+			ifMatch, err := cmd.Flags().GetString("if-match")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get if-match flag")
+			}
+
+			reqBody := v1alpha1.ConvertProjectRequest{
+				Kind:           kind,
+				OwnerID:        ownerID,
+				OwnerIDs:       ownerIds,
+				OwnerGroupName: ownerGroupName,
+				RemoveMembers:  removeMembers,
+			}
+			project, err := client.ConvertProject(cmd.Context(), id, &reqBody, ifMatch)
+			if err != nil {
+				if errors.Is(err, v1alpha1.ErrConflict) {
+					fmt.Println("❌ Project was modified by someone else since it was fetched. Run `project get` to see the latest state and try again.")
+					return err
+				}
+				fmt.Printf("❌ Failed to convert project: %v\n", err)
+				return errors.Wrapf(err, "failed to convert project")
+			}
+
+			fmt.Printf("✅ Project converted to %s successfully\n", project.Kind)
+			return nil
+		},
+	}
+
+	c.Flags().String("id", "", "プロジェクトID")
+	c.Flags().String("kind", "", "変換後のプロジェクトの種類 (personal | shared)")
+	c.Flags().String("owner-id", "", "personalに変換する場合のオーナーID (オーナーが1人の場合は省略可)")
+	c.Flags().StringSlice("owner-ids", []string{}, "sharedに変換する場合のオーナーグループのメンバーID (省略時は現在のオーナー)")
+	c.Flags().String("owner-group-name", "", "sharedに変換する場合に作成するオーナーグループ名 (省略時はowners)")
+	c.Flags().Bool("remove-members", false, "personalに変換する場合にオーナー以外のメンバーを削除する")
+	c.Flags().String("if-match", "", "変換の前提とするETag (project getで取得した値。省略時は無条件に変換)")
+	return c
+}
+
+func newProjectCloneCommand(logger *slog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use: "clone",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			transport := &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			}
+			httpClient := http.Client{
+				Transport: transport,
+				Timeout:   30 * time.Second, // 30 second timeout
+			}
+			client := v1alpha1.NewDefaultClient(logger, httpClient)
+
+			sourceID, err := cmd.Flags().GetString("source-id")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get source-id flag")
+			}
+			templateID, err := cmd.Flags().GetString("template-id")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get template-id flag")
+			}
+			if (sourceID == "") == (templateID == "") {
+				return errors.New("exactly one of source-id and template-id is required")
+			}
+			name, err := cmd.Flags().GetString("name")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get name flag")
+			}
+			if name == "" {
+				return errors.New("name is required")
+			}
+			description, err := cmd.Flags().GetString("description")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get description flag")
+			}
+			ownerIds, err := cmd.Flags().GetStringSlice("owner-ids")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get owner-ids flag")
+			}
+
+			reqBody := v1alpha1.CreateProjectFromTemplateRequest{
+				Name:        name,
+				Description: description,
+				OwnerIDs:    ownerIds,
+			}
+			var created *v1alpha1.ProjectFromTemplate
+			if sourceID != "" {
+				created, err = client.CloneProject(cmd.Context(), sourceID, &reqBody)
+			} else {
+				created, err = client.CreateProjectFromTemplate(cmd.Context(), templateID, &reqBody)
+			}
+			if err != nil {
+				fmt.Printf("❌ Failed to create project: %v\n", err)
+				return errors.Wrapf(err, "failed to create project")
+			}
+
+			fmt.Printf("✅ Project %s created with %d role(s) and %d user group(s)\n",
+				created.Project.ID, len(created.RoleIDs), len(created.UserGroupIDs))
+			return nil
+		},
+	}
+
+	c.Flags().String("source-id", "", "複製元のプロジェクトID")
+	c.Flags().String("template-id", "", "作成に利用するプロジェクトテンプレートID")
+	c.Flags().String("name", "", "作成するプロジェクト名")
+	c.Flags().String("description", "", "作成するプロジェクトの説明")
+	c.Flags().StringSlice("owner-ids", []string{}, "オーナー (sharedの場合はオーナーグループのメンバー) のユーザID (省略時は自分)")
+	return c
+}
+
+// newProjectArchiveCommand returns the archive command, or the unarchive command when archive is false.
+func newProjectArchiveCommand(logger *slog.Logger, archive bool) *cobra.Command {
+	use, verb := "archive", "archived"
+	if !archive {
+		use, verb = "unarchive", "unarchived"
+	}
+	c := &cobra.Command{
+		Use: use,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			transport := &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			}
+			httpClient := http.Client{
+				Transport: transport,
+				Timeout:   30 * time.Second, // 30 second timeout
+			}
+			client := v1alpha1.NewDefaultClient(logger, httpClient)
+
+			id, err := cmd.Flags().GetString("id")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get id flag")
+			}
+			if id == "" {
+				return errors.New("id is required")
+			}
+			ifMatch, err := cmd.Flags().GetString("if-match")
+			if err != nil {
+				return errors.Wrapf(err, "failed to get if-match flag")
+			}
+
+			if archive {
+				_, err = client.ArchiveProject(cmd.Context(), id, ifMatch)
+			} else {
+				_, err = client.UnarchiveProject(cmd.Context(), id, ifMatch)
+			}
+			if err != nil {
+				if errors.Is(err, v1alpha1.ErrConflict) {
+					fmt.Println("❌ Project was modified by someone else since it was fetched. Run `project get` to see the latest state and try again.")
+					return err
+				}
+				fmt.Printf("❌ Failed to %s project: %v\n", use, err)
+				return errors.Wrapf(err, "failed to %s project", use)
+			}
+
+			fmt.Printf("✅ Project %s successfully\n", verb)
+			return nil
+		},
+	}
+
+	c.Flags().String("id", "", "プロジェクトID")
+	c.Flags().String("if-match", "", "前提とするETag (project getで取得した値。省略時は無条件に実行)")
 	return c
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
 	"github.com/tacokumo/admin-api/pkg/client/v1alpha1"
+	"github.com/tacokumo/admin-api/pkg/health"
 )
 
 func New(logger *slog.Logger) *cobra.Command {
@@ -27,6 +28,10 @@ func New(logger *slog.Logger) *cobra.Command {
 	}
 
 	c.AddCommand(newProjectCommand(logger))
+	c.AddCommand(newApplyCommand(logger, true))
+	c.AddCommand(newApplyCommand(logger, false))
+	c.AddCommand(newExportCommand(logger))
+	c.AddCommand(newImportCommand(logger))
 	c.AddCommand(newPingCommand(logger))
 	c.AddCommand(newAuthCommand(logger))
 	c.AddCommand(newInteractiveCommand(logger))
@@ -53,7 +58,9 @@ func newPingCommand(logger *slog.Logger) *cobra.Command {
 			fmt.Println("✅ Liveness check succeeded")
 
 			fmt.Println("Checking readiness...")
-			if err := client.ReadinessCheck(cmd.Context()); err != nil {
+			report, err := client.ReadinessCheck(cmd.Context())
+			printHealthComponents(report)
+			if err != nil {
 				fmt.Printf("❌ Readiness check failed: %v\n", err)
 				return errors.Wrap(err, "readiness check failed")
 			}
@@ -119,7 +126,7 @@ func newAuthMeCommand(logger *slog.Logger) *cobra.Command {
 			client := v1alpha1.NewDefaultClient(logger, http.Client{Transport: transport})
 
 			// This will trigger authentication if needed
-			_, err := client.ListProjects(cmd.Context())
+			_, err := client.ListProjects(cmd.Context(), v1alpha1.ListProjectsOptions{})
 			if err != nil {
 				return err
 			}
@@ -261,7 +268,9 @@ func runPingCommand(ctx context.Context, client v1alpha1.Client, logger *slog.Lo
 	fmt.Println("✅ Liveness check succeeded")
 
 	fmt.Println("Checking readiness...")
-	if err := client.ReadinessCheck(ctx); err != nil {
+	report, err := client.ReadinessCheck(ctx)
+	printHealthComponents(report)
+	if err != nil {
 		return errors.Wrap(err, "readiness check failed")
 	}
 	fmt.Println("✅ Readiness check succeeded")
@@ -269,6 +278,24 @@ func runPingCommand(ctx context.Context, client v1alpha1.Client, logger *slog.Lo
 	return nil
 }
 
+// printHealthComponents prints the status and latency of each component of a readiness report.
+func printHealthComponents(report health.Report) {
+	for _, c := range report.Components {
+		mark := "✅"
+		if c.Status != health.StatusOK {
+			mark = "❌"
+			if c.Optional {
+				mark = "⚠️"
+			}
+		}
+		line := fmt.Sprintf("  %s %-10s %8.2fms", mark, c.Name, c.LatencyMS)
+		if c.Error != "" {
+			line += "  " + c.Error
+		}
+		fmt.Println(line)
+	}
+}
+
 func runLoginCommand(ctx context.Context, client v1alpha1.Client, logger *slog.Logger) error {
 	if err := client.Authenticate(ctx); err != nil {
 		return errors.Wrap(err, "authentication failed")
@@ -286,7 +313,7 @@ func runLogoutCommand(ctx context.Context, client v1alpha1.Client, logger *slog.
 }
 
 func runProjectsListCommand(ctx context.Context, client v1alpha1.Client, logger *slog.Logger) error {
-	projects, err := client.ListProjects(ctx)
+	projects, err := client.ListProjects(ctx, v1alpha1.ListProjectsOptions{})
 	if err != nil {
 		return errors.Wrap(err, "failed to list projects")
 	}
@@ -342,25 +369,11 @@ func runProjectsCreateCommand(ctx context.Context, client v1alpha1.Client, logge
 		}
 	}
 
-	fmt.Print("Owner group IDs (comma-separated, optional): ")
-	if !scanner.Scan() {
-		return errors.New("failed to read owner group IDs")
-	}
-	ownerGroupIdsInput := strings.TrimSpace(scanner.Text())
-	var ownerGroupIds []string
-	if ownerGroupIdsInput != "" {
-		ownerGroupIds = strings.Split(ownerGroupIdsInput, ",")
-		for i := range ownerGroupIds {
-			ownerGroupIds[i] = strings.TrimSpace(ownerGroupIds[i])
-		}
-	}
-
 	reqBody := generated.CreateProjectRequest{
-		Name:          name,
-		Description:   description,
-		Kind:          generated.CreateProjectRequestKind(kind),
-		OwnerIds:      ownerIds,
-		OwnerGroupIds: ownerGroupIds,
+		Name:        name,
+		Description: description,
+		Kind:        generated.CreateProjectRequestKind(kind),
+		OwnerIds:    ownerIds,
 	}
 
 	if err := client.CreateProject(ctx, &reqBody); err != nil {
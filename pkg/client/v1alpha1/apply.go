@@ -0,0 +1,54 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/tacokumo/admin-api/pkg/declarative"
+)
+
+// ApplyOptions controls how Apply reconciles a manifest.
+type ApplyOptions struct {
+	// Prune deletes the roles and user groups of the listed projects that the manifest does not
+	// list, and the managed projects that it does not list.
+	Prune bool
+	// DryRun only computes the plan.
+	DryRun bool
+}
+
+// ApplyResult is the plan of a manifest and whether it was applied.
+type ApplyResult struct {
+	Changes []declarative.Change `json:"changes"`
+	Applied bool                 `json:"applied"`
+}
+
+// Apply reconciles the projects, roles and user groups with the manifest. The changes are made
+// in a single transaction on the server.
+func (c *DefaultClient) Apply(ctx context.Context, m declarative.Manifest, opts ApplyOptions) (result *ApplyResult, err error) {
+	q := url.Values{}
+	q.Set("dryRun", strconv.FormatBool(opts.DryRun))
+	q.Set("prune", strconv.FormatBool(opts.Prune))
+	resp, err := c.post(ctx, "/v1alpha1/apply?"+q.Encode(), m)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to apply manifest")
+	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readResponseError(resp)
+	}
+
+	result = &ApplyResult{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode apply response")
+	}
+	return result, nil
+}
@@ -3,26 +3,47 @@ package v1alpha1
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
 	"github.com/tacokumo/admin-api/pkg/client/auth"
+	"github.com/tacokumo/admin-api/pkg/dataset"
+	"github.com/tacokumo/admin-api/pkg/declarative"
+	"github.com/tacokumo/admin-api/pkg/health"
 )
 
 type Client interface {
 	CreateProject(ctx context.Context, req *generated.CreateProjectRequest) error
-	ListProjects(ctx context.Context) ([]generated.Project, error)
+	ListProjects(ctx context.Context, opts ListProjectsOptions) ([]generated.Project, error)
+	GetProject(ctx context.Context, projectID string) (*generated.Project, string, error)
+	UpdateProject(ctx context.Context, projectID string, req *generated.UpdateProjectRequest, etag string) (*generated.Project, error)
+	PatchProject(ctx context.Context, projectID string, patch map[string]any, etag string) (*generated.Project, error)
+	ConvertProject(ctx context.Context, projectID string, req *ConvertProjectRequest, etag string) (*generated.Project, error)
+	CloneProject(ctx context.Context, sourceID string, req *CreateProjectFromTemplateRequest) (*ProjectFromTemplate, error)
+	CreateProjectFromTemplate(ctx context.Context, templateID string, req *CreateProjectFromTemplateRequest) (*ProjectFromTemplate, error)
+	ArchiveProject(ctx context.Context, projectID string, etag string) (*ProjectArchive, error)
+	UnarchiveProject(ctx context.Context, projectID string, etag string) (*ProjectArchive, error)
+	Apply(ctx context.Context, m declarative.Manifest, opts ApplyOptions) (*ApplyResult, error)
+	ExportDataset(ctx context.Context) (*dataset.Document, error)
+	ImportDataset(ctx context.Context, d dataset.Document, opts ImportOptions) (*ImportResult, error)
 	LivenessCheck(ctx context.Context) error
-	ReadinessCheck(ctx context.Context) error
+	ReadinessCheck(ctx context.Context) (health.Report, error)
 	Authenticate(ctx context.Context) error
 	Logout(ctx context.Context) error
 }
 
+// maxPostAttempts is the number of times a POST request is sent before giving up on timeouts.
+const maxPostAttempts = 3
+
 type DefaultClient struct {
 	c             http.Client
 	logger        *slog.Logger
@@ -93,16 +114,91 @@ func (c *DefaultClient) post(
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to marshal request body")
 	}
+
+	// Every POST carries an idempotency key so that retrying after a timeout
+	// cannot create the same resource twice.
+	idempotencyKey, err := newIdempotencyKey()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to generate idempotency key")
+	}
+
+	uri := fmt.Sprintf("%s%s", c.serverBaseURL, endpoint)
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewReader(reqBodyBytes))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create POST request")
+		}
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.bearerToken))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Idempotency-Key", idempotencyKey)
+
+		resp, err := c.c.Do(req)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= maxPostAttempts || !isTimeout(err) || ctx.Err() != nil {
+			return nil, errors.Wrapf(err, "failed to send POST request")
+		}
+		c.logger.WarnContext(ctx, "POST request timed out, retrying with the same idempotency key",
+			slog.String("endpoint", endpoint),
+			slog.Int("attempt", attempt),
+		)
+	}
+}
+
+// put sends a PUT request. If etag is not empty it is sent as If-Match so that the
+// update is rejected when the resource has been modified since it was read.
+func (c *DefaultClient) put(
+	ctx context.Context,
+	endpoint string,
+	reqBody any,
+	etag string,
+) (*http.Response, error) {
+	return c.sendWithIfMatch(ctx, http.MethodPut, "application/json", endpoint, reqBody, etag)
+}
+
+// patch sends a JSON Merge Patch request. Members that are not in reqBody are left unchanged
+// by the server and members set to nil are cleared.
+func (c *DefaultClient) patch(
+	ctx context.Context,
+	endpoint string,
+	reqBody any,
+	etag string,
+) (*http.Response, error) {
+	return c.sendWithIfMatch(ctx, http.MethodPatch, "application/merge-patch+json", endpoint, reqBody, etag)
+}
+
+func (c *DefaultClient) sendWithIfMatch(
+	ctx context.Context,
+	method string,
+	contentType string,
+	endpoint string,
+	reqBody any,
+	etag string,
+) (*http.Response, error) {
+	if err := c.ensureAuthenticated(ctx); err != nil {
+		return nil, errors.Wrap(err, "authentication failed")
+	}
+
+	reqBodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal request body")
+	}
+
 	uri := fmt.Sprintf("%s%s", c.serverBaseURL, endpoint)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewReader(reqBodyBytes))
+	req, err := http.NewRequestWithContext(ctx, method, uri, bytes.NewReader(reqBodyBytes))
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create POST request")
+		return nil, errors.Wrapf(err, "failed to create %s request", method)
 	}
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.bearerToken))
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", contentType)
+	if etag != "" {
+		req.Header.Add("If-Match", etag)
+	}
+
 	resp, err := c.c.Do(req)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to send POST request")
+		return nil, errors.Wrapf(err, "failed to send %s request", method)
 	}
 
 	return resp, nil
@@ -137,20 +233,55 @@ func (c *DefaultClient) get(
 	return resp, nil
 }
 
-func (c *DefaultClient) LivenessCheck(ctx context.Context) error {
-	_, err := c.get(ctx, "/v1alpha1/health/liveness", nil)
+func (c *DefaultClient) LivenessCheck(ctx context.Context) (err error) {
+	resp, err := c.get(ctx, "/v1alpha1/health/liveness", nil)
 	if err != nil {
 		return errors.Wrapf(err, "failed to check liveness")
 	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return readResponseError(resp)
+	}
 	return nil
 }
 
-func (c *DefaultClient) ReadinessCheck(ctx context.Context) error {
-	_, err := c.get(ctx, "/v1alpha1/health/readiness", nil)
+// ReadinessCheck returns the readiness report of the server. A report of a server that is not
+// ready is returned together with an error that names the failing components.
+func (c *DefaultClient) ReadinessCheck(ctx context.Context) (report health.Report, err error) {
+	resp, err := c.get(ctx, "/v1alpha1/health/readiness", nil)
 	if err != nil {
-		return errors.Wrapf(err, "failed to check readiness")
+		return report, errors.Wrapf(err, "failed to check readiness")
 	}
-	return nil
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return report, readResponseError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return report, errors.Wrapf(err, "failed to decode readiness report")
+	}
+	if !report.OK() {
+		var failed []string
+		for _, component := range report.Components {
+			if component.Status != health.StatusOK && !component.Optional {
+				failed = append(failed, component.Name)
+			}
+		}
+		if len(failed) == 0 {
+			return report, errors.Newf("server is not ready: %s", report.Reason)
+		}
+		return report, errors.Newf("failing components: %s", strings.Join(failed, ", "))
+	}
+	return report, nil
 }
 
 // ensureAuthenticated checks if the client has a valid bearer token and initiates OAuth if needed
@@ -254,3 +385,16 @@ func (c *DefaultClient) Logout(ctx context.Context) error {
 
 	return nil
 }
+
+func newIdempotencyKey() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.Wrap(err, "failed to generate random bytes")
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,78 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/tacokumo/admin-api/pkg/dataset"
+)
+
+// ImportOptions controls how ImportDataset writes a dataset.
+type ImportOptions struct {
+	// Mode is dataset.ModeMerge or dataset.ModeReplace. It defaults to merge.
+	Mode string
+	// DryRun reports what the import would do without committing it.
+	DryRun bool
+}
+
+// ImportResult is what an import did, or would do for a dry run.
+type ImportResult struct {
+	Mode     string          `json:"mode"`
+	Summary  dataset.Summary `json:"summary"`
+	Imported bool            `json:"imported"`
+}
+
+// ExportDataset downloads the complete admin dataset.
+func (c *DefaultClient) ExportDataset(ctx context.Context) (d *dataset.Document, err error) {
+	resp, err := c.get(ctx, "/v1alpha1/export", nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to export dataset")
+	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readResponseError(resp)
+	}
+
+	d = &dataset.Document{}
+	if err := json.NewDecoder(resp.Body).Decode(d); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode export response")
+	}
+	return d, nil
+}
+
+// ImportDataset uploads a dataset. The server validates it and imports it in a single transaction.
+func (c *DefaultClient) ImportDataset(ctx context.Context, d dataset.Document, opts ImportOptions) (result *ImportResult, err error) {
+	q := url.Values{}
+	if opts.Mode != "" {
+		q.Set("mode", opts.Mode)
+	}
+	q.Set("dryRun", strconv.FormatBool(opts.DryRun))
+	resp, err := c.post(ctx, "/v1alpha1/import?"+q.Encode(), d)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to import dataset")
+	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readResponseError(resp)
+	}
+
+	result = &ImportResult{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode import response")
+	}
+	return result, nil
+}
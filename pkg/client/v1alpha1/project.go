@@ -3,13 +3,20 @@ package v1alpha1
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
 )
 
+// ErrConflict is returned when an update is rejected because the resource was modified
+// by someone else after it was read.
+var ErrConflict = errors.New("the resource was modified by someone else; fetch it again and retry")
+
 func readResponseError(resp *http.Response) error {
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -42,13 +49,28 @@ func (c *DefaultClient) CreateProject(ctx context.Context, req *generated.Create
 	return nil
 }
 
+// ListProjectsOptions narrows down the projects returned by ListProjects.
+type ListProjectsOptions struct {
+	// IncludeArchived also lists archived projects.
+	IncludeArchived bool
+	// LabelSelector filters projects by their labels, e.g. "env=prod,team!=infra".
+	LabelSelector string
+}
+
+// ListProjects lists the projects matching opts.
 func (c *DefaultClient) ListProjects(
 	ctx context.Context,
+	opts ListProjectsOptions,
 ) (projects []generated.Project, err error) {
-	resp, err := c.get(ctx, "/v1alpha1/projects", map[string]string{
-		"limit":  "100",
-		"offset": "0",
-	})
+	query := map[string]string{
+		"limit":           "100",
+		"offset":          "0",
+		"includeArchived": strconv.FormatBool(opts.IncludeArchived),
+	}
+	if opts.LabelSelector != "" {
+		query["labelSelector"] = opts.LabelSelector
+	}
+	resp, err := c.get(ctx, "/v1alpha1/projects", query)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list projects")
 	}
@@ -69,3 +91,230 @@ func (c *DefaultClient) ListProjects(
 	}
 	return listResp, nil
 }
+
+// GetProject returns the project together with its ETag.
+func (c *DefaultClient) GetProject(
+	ctx context.Context,
+	projectID string,
+) (project *generated.Project, etag string, err error) {
+	resp, err := c.get(ctx, fmt.Sprintf("/v1alpha1/projects/%s", projectID), nil)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to get project")
+	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", readResponseError(resp)
+	}
+
+	var p generated.Project
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return nil, "", errors.Wrapf(err, "failed to decode get project response")
+	}
+	return &p, resp.Header.Get("ETag"), nil
+}
+
+// UpdateProject updates the project. When etag is not empty the update only succeeds if
+// the project has not changed since the ETag was obtained; otherwise ErrConflict is returned.
+func (c *DefaultClient) UpdateProject(
+	ctx context.Context,
+	projectID string,
+	req *generated.UpdateProjectRequest,
+	etag string,
+) (project *generated.Project, err error) {
+	resp, err := c.put(ctx, fmt.Sprintf("/v1alpha1/projects/%s", projectID), req, etag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update project")
+	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, ErrConflict
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, readResponseError(resp)
+	}
+
+	var p generated.Project
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode update project response")
+	}
+	return &p, nil
+}
+
+// PatchProject applies a JSON Merge Patch to the project. When etag is not empty the patch only
+// succeeds if the project has not changed since the ETag was obtained; otherwise ErrConflict is returned.
+func (c *DefaultClient) PatchProject(
+	ctx context.Context,
+	projectID string,
+	patch map[string]any,
+	etag string,
+) (project *generated.Project, err error) {
+	resp, err := c.patch(ctx, fmt.Sprintf("/v1alpha1/projects/%s", projectID), patch, etag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to patch project")
+	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, ErrConflict
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, readResponseError(resp)
+	}
+
+	var p generated.Project
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode patch project response")
+	}
+	return &p, nil
+}
+
+// ConvertProjectRequest is the body of POST /v1alpha1/projects/{projectId}/convert.
+type ConvertProjectRequest struct {
+	Kind           string   `json:"kind"`
+	OwnerID        string   `json:"ownerId,omitempty"`
+	OwnerIDs       []string `json:"ownerIds,omitempty"`
+	OwnerGroupName string   `json:"ownerGroupName,omitempty"`
+	RemoveMembers  bool     `json:"removeMembers,omitempty"`
+}
+
+// ConvertProject converts the project to another kind, migrating its owners and memberships.
+// When etag is not empty the conversion only succeeds if the project has not changed since
+// the ETag was obtained; otherwise ErrConflict is returned.
+func (c *DefaultClient) ConvertProject(
+	ctx context.Context,
+	projectID string,
+	req *ConvertProjectRequest,
+	etag string,
+) (project *generated.Project, err error) {
+	resp, err := c.sendWithIfMatch(ctx, http.MethodPost, "application/json",
+		fmt.Sprintf("/v1alpha1/projects/%s/convert", projectID), req, etag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert project")
+	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, ErrConflict
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, readResponseError(resp)
+	}
+
+	var body struct {
+		Project generated.Project `json:"project"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode convert project response")
+	}
+	return &body.Project, nil
+}
+
+// CreateProjectFromTemplateRequest is the body of POST /v1alpha1/projects/{projectId}/clone and
+// POST /v1alpha1/projecttemplates/{templateId}/projects.
+type CreateProjectFromTemplateRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	OwnerIDs    []string `json:"ownerIds,omitempty"`
+}
+
+// ProjectFromTemplate is a project created from a template or another project.
+type ProjectFromTemplate struct {
+	Project      generated.Project `json:"project"`
+	RoleIDs      []string          `json:"roleIds"`
+	UserGroupIDs []string          `json:"userGroupIds"`
+}
+
+// CloneProject creates a project with the roles and user groups of the project sourceID.
+func (c *DefaultClient) CloneProject(ctx context.Context, sourceID string, req *CreateProjectFromTemplateRequest) (*ProjectFromTemplate, error) {
+	return c.createProjectFromTemplate(ctx, fmt.Sprintf("/v1alpha1/projects/%s/clone", sourceID), req)
+}
+
+// CreateProjectFromTemplate creates a project with the roles and user groups of a project template.
+func (c *DefaultClient) CreateProjectFromTemplate(ctx context.Context, templateID string, req *CreateProjectFromTemplateRequest) (*ProjectFromTemplate, error) {
+	return c.createProjectFromTemplate(ctx, fmt.Sprintf("/v1alpha1/projecttemplates/%s/projects", templateID), req)
+}
+
+func (c *DefaultClient) createProjectFromTemplate(ctx context.Context, endpoint string, req *CreateProjectFromTemplateRequest) (created *ProjectFromTemplate, err error) {
+	resp, err := c.post(ctx, endpoint, req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create project from template")
+	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, readResponseError(resp)
+	}
+
+	created = &ProjectFromTemplate{}
+	if err := json.NewDecoder(resp.Body).Decode(created); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode create project from template response")
+	}
+	return created, nil
+}
+
+// ProjectArchive is the archival state of a project.
+type ProjectArchive struct {
+	Project    generated.Project `json:"project"`
+	Archived   bool              `json:"archived"`
+	ArchivedAt *time.Time        `json:"archivedAt,omitempty"`
+	ArchivedBy *string           `json:"archivedBy,omitempty"`
+}
+
+// ArchiveProject archives the project, making it read-only until it is unarchived. When etag is
+// not empty the project is only archived if it has not changed since the ETag was obtained;
+// otherwise ErrConflict is returned.
+func (c *DefaultClient) ArchiveProject(ctx context.Context, projectID string, etag string) (*ProjectArchive, error) {
+	return c.setProjectArchived(ctx, fmt.Sprintf("/v1alpha1/projects/%s/archive", projectID), etag)
+}
+
+// UnarchiveProject restores an archived project.
+func (c *DefaultClient) UnarchiveProject(ctx context.Context, projectID string, etag string) (*ProjectArchive, error) {
+	return c.setProjectArchived(ctx, fmt.Sprintf("/v1alpha1/projects/%s/unarchive", projectID), etag)
+}
+
+func (c *DefaultClient) setProjectArchived(ctx context.Context, endpoint string, etag string) (archive *ProjectArchive, err error) {
+	resp, err := c.sendWithIfMatch(ctx, http.MethodPost, "application/json", endpoint, struct{}{}, etag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update project archival")
+	}
+	defer func() {
+		if err == nil {
+			err = resp.Body.Close()
+		}
+	}()
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, ErrConflict
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, readResponseError(resp)
+	}
+
+	var a ProjectArchive
+	if err := json.NewDecoder(resp.Body).Decode(&a); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode project archive response")
+	}
+	return &a, nil
+}
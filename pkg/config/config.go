@@ -1,7 +1,9 @@
 package config
 
 import (
+	"log/slog"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -10,29 +12,50 @@ import (
 )
 
 type Config struct {
-	Addr          string          `env:"ADDR" yaml:"addr"`
-	Port          string          `env:"PORT" yaml:"port"`
-	AdminDBConfig AdminDBConfig   `yaml:"admin_db"`
-	Auth          AuthConfig      `yaml:"auth"`
-	Redis         RedisConfig     `yaml:"redis"`
-	CORS          CORSConfig      `yaml:"cors"`
-	TLS           TLSConfig       `yaml:"tls"`
-	Telemetry     TelemetryConfig `yaml:"telemetry"`
+	Addr          string            `env:"ADDR" yaml:"addr"`
+	Port          string            `env:"PORT" yaml:"port"`
+	LogLevel      string            `env:"LOG_LEVEL" yaml:"log_level" default:"info"`
+	AdminDBConfig AdminDBConfig     `yaml:"admin_db"`
+	Auth          AuthConfig        `yaml:"auth"`
+	Redis         RedisConfig       `yaml:"redis"`
+	CORS          CORSConfig        `yaml:"cors"`
+	TLS           TLSConfig         `yaml:"tls"`
+	Telemetry     TelemetryConfig   `yaml:"telemetry"`
+	RateLimit     RateLimitConfig   `yaml:"rate_limit"`
+	Idempotency   IdempotencyConfig `yaml:"idempotency"`
+	Events        EventsConfig      `yaml:"events"`
+	Webhook       WebhookConfig     `yaml:"webhook"`
+	Jobs          JobsConfig        `yaml:"jobs"`
+	SCIM          SCIMConfig        `yaml:"scim"`
+	Mail          MailConfig        `yaml:"mail"`
+	Invitation    InvitationConfig  `yaml:"invitation"`
+	Project       ProjectConfig     `yaml:"project"`
+	Health        HealthConfig      `yaml:"health"`
+	Shutdown      ShutdownConfig    `yaml:"shutdown"`
+	Reload        ReloadConfig      `yaml:"reload"`
+
+	// TrustedProxies are the CIDR ranges of the proxies in front of the server, such as a load
+	// balancer, whose X-Forwarded-For header is trusted for the client IP that rate limits and
+	// logs use. Without any, the client IP is the remote address of the connection.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" yaml:"trusted_proxies"`
+
+	// source is set when the config is loaded from a file, for the line numbers of Validate.
+	source *source
 }
 
 type AuthConfig struct {
 	GitHubClientID     string        `env:"GITHUB_CLIENT_ID" yaml:"client_id"`
-	GitHubClientSecret string        `env:"GITHUB_CLIENT_SECRET" yaml:"client_secret"`
+	GitHubClientSecret string        `env:"GITHUB_CLIENT_SECRET" yaml:"client_secret" secret:"true"`
 	CallbackURL        string        `env:"GITHUB_CALLBACK_URL" yaml:"callback_url"`
 	FrontendURL        string        `env:"FRONTEND_URL" yaml:"frontend_url"`
 	AllowedOrgs        []string      `env:"GITHUB_ALLOWED_ORGS" yaml:"allowed_orgs"`
-	SessionTTL         time.Duration `env:"SESSION_TTL" yaml:"session_ttl"`
+	SessionTTL         time.Duration `env:"SESSION_TTL" yaml:"session_ttl" default:"24h"`
 }
 
 type RedisConfig struct {
 	Host     string `env:"REDIS_HOST" yaml:"host"`
 	Port     int    `env:"REDIS_PORT" yaml:"port"`
-	Password string `env:"REDIS_PASSWORD" yaml:"password"`
+	Password string `env:"REDIS_PASSWORD" yaml:"password" secret:"true"`
 	DB       int    `env:"REDIS_DB" yaml:"db"`
 }
 
@@ -40,7 +63,7 @@ type AdminDBConfig struct {
 	Host             string `env:"ADMIN_DB_HOST" yaml:"host"`
 	Port             int    `env:"ADMIN_DB_PORT" yaml:"port"`
 	User             string `env:"ADMIN_DB_USER" yaml:"user"`
-	Password         string `env:"ADMIN_DB_PASSWORD" yaml:"password"`
+	Password         string `env:"ADMIN_DB_PASSWORD" yaml:"password" secret:"true"`
 	DBName           string `env:"ADMIN_DB_NAME" yaml:"db_name"`
 	InitialConnRetry int    `env:"ADMIN_DB_INITIAL_CONN_RETRY" yaml:"initial_conn_retry"`
 }
@@ -58,6 +81,26 @@ type TLSConfig struct {
 	Enabled  bool   `env:"TLS_ENABLED" yaml:"enabled"`
 	CertFile string `env:"TLS_CERT_FILE" yaml:"cert_file"`
 	KeyFile  string `env:"TLS_KEY_FILE" yaml:"key_file"`
+	// ClientAuth is "none", "optional" or "require". With "optional", client certificates are
+	// verified when presented and required only under ClientCertPaths.
+	ClientAuth string `env:"TLS_CLIENT_AUTH" yaml:"client_auth" default:"none"`
+	// ClientCAFile is the PEM bundle of the CAs that client certificates are verified against.
+	ClientCAFile string `env:"TLS_CLIENT_CA_FILE" yaml:"client_ca_file"`
+	// ClientCertPaths are path prefixes that only accept requests with a verified client
+	// certificate, such as internal service-to-service endpoints.
+	ClientCertPaths []string `yaml:"client_cert_paths"`
+	// ClientCertIdentities map client certificates to the service accounts that requests made
+	// with them act as.
+	ClientCertIdentities []ClientCertIdentity `yaml:"client_cert_identities"`
+}
+
+type ClientCertIdentity struct {
+	// Subject matches the subject distinguished name, e.g. "CN=deployer,O=TacoKumo", or its common name.
+	Subject string `yaml:"subject"`
+	// SAN matches any DNS name, URI, email address or IP address of the certificate.
+	SAN string `yaml:"san"`
+	// ServiceAccountID is the display ID of the service account.
+	ServiceAccountID string `yaml:"service_account_id"`
 }
 
 type TelemetryConfig struct {
@@ -66,11 +109,185 @@ type TelemetryConfig struct {
 	Timeout      time.Duration `env:"TELEMETRY_TIMEOUT" yaml:"timeout"`
 }
 
+type RateLimitConfig struct {
+	Enabled bool `env:"RATE_LIMIT_ENABLED" yaml:"enabled"`
+	// Default applies to requests that match no route group.
+	Default RateLimitRule `yaml:"default"`
+	// Groups are matched by the longest path prefix.
+	Groups []RateLimitRule `yaml:"groups"`
+	// BypassPaths are path prefixes that are never limited, such as health checks.
+	BypassPaths []string `yaml:"bypass_paths"`
+	// PerIP limits all requests per client IP before they are authenticated, so that requests
+	// with guessed tokens are limited as well. Disabled when its limit is zero.
+	PerIP IPRateLimitRule `yaml:"per_ip"`
+}
+
+type RateLimitRule struct {
+	Name       string        `yaml:"name"`
+	PathPrefix string        `yaml:"path_prefix"`
+	Limit      int           `yaml:"limit"`
+	Window     time.Duration `yaml:"window"`
+}
+
+// IPRateLimitRule is the per IP rule, which applies to every path.
+type IPRateLimitRule struct {
+	Limit  int           `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+}
+
+type IdempotencyConfig struct {
+	Enabled bool `env:"IDEMPOTENCY_ENABLED" yaml:"enabled"`
+	// TTL is how long responses are remembered for replay.
+	TTL time.Duration `env:"IDEMPOTENCY_TTL" yaml:"ttl" default:"24h"`
+}
+
+type EventsConfig struct {
+	// StreamMaxLen is the approximate number of events kept for Last-Event-ID resume.
+	StreamMaxLen int `env:"EVENTS_STREAM_MAX_LEN" yaml:"stream_max_len" default:"10000"`
+	// ReadPoolSize is the number of Redis connections for event subscribers, which is the
+	// number of event streams that can wait for events at once across this replica. They
+	// are separate from the connections used for sessions and rate limiting.
+	ReadPoolSize int `env:"EVENTS_READ_POOL_SIZE" yaml:"read_pool_size" default:"100"`
+}
+
+type WebhookConfig struct {
+	// Enabled schedules the job that sends queued deliveries. Deliveries are queued regardless,
+	// and the job runs on whichever replica runs the job runner.
+	Enabled bool `env:"WEBHOOK_ENABLED" yaml:"enabled"`
+	// PollInterval is how often queued deliveries are checked.
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" yaml:"poll_interval" default:"5s"`
+	// BatchSize is the number of deliveries sent per poll.
+	BatchSize int `env:"WEBHOOK_BATCH_SIZE" yaml:"batch_size" default:"20"`
+	// MaxAttempts is the number of attempts before a delivery is marked dead.
+	MaxAttempts int `env:"WEBHOOK_MAX_ATTEMPTS" yaml:"max_attempts" default:"8"`
+	// Timeout is the timeout of a single delivery request.
+	Timeout time.Duration `env:"WEBHOOK_TIMEOUT" yaml:"timeout" default:"10s"`
+	// AllowedNetworks are the CIDR ranges of internal receivers. Deliveries to loopback,
+	// private and link-local addresses are refused unless they are in one of them, so that
+	// project editors cannot make the server call internal services.
+	AllowedNetworks []string `env:"WEBHOOK_ALLOWED_NETWORKS" yaml:"allowed_networks"`
+}
+
+type JobsConfig struct {
+	// Enabled runs the background job runner on this replica. Jobs are queued regardless,
+	// so at least one replica should run the runner.
+	Enabled bool `env:"JOBS_ENABLED" yaml:"enabled"`
+	// Concurrency is the number of jobs run at the same time.
+	Concurrency int `env:"JOBS_CONCURRENCY" yaml:"concurrency" default:"4"`
+	// PollInterval is how often due jobs are claimed.
+	PollInterval time.Duration `env:"JOBS_POLL_INTERVAL" yaml:"poll_interval" default:"1s"`
+	// LeaseDuration is how long a job may run before another replica retries it.
+	LeaseDuration time.Duration `env:"JOBS_LEASE_DURATION" yaml:"lease_duration" default:"5m"`
+	// Retention is how long finished jobs are kept.
+	Retention time.Duration `env:"JOBS_RETENTION" yaml:"retention" default:"72h"`
+}
+
+type SCIMConfig struct {
+	// Enabled serves the SCIM 2.0 provisioning endpoints under /scim/v2.
+	Enabled bool `env:"SCIM_ENABLED" yaml:"enabled"`
+	// Token is the bearer token the identity provider authenticates with.
+	Token string `env:"SCIM_TOKEN" yaml:"token" secret:"true"`
+	// ProjectID is the display ID of the project that provisioned user groups belong to.
+	ProjectID string `env:"SCIM_PROJECT_ID" yaml:"project_id"`
+}
+
+type MailConfig struct {
+	// Driver selects how mail is sent: "smtp", "log" or "file".
+	Driver string `env:"MAIL_DRIVER" yaml:"driver" default:"log"`
+	// From is the sender address, e.g. "TacoKumo <noreply@example.com>".
+	From string         `env:"MAIL_FROM" yaml:"from"`
+	SMTP SMTPMailConfig `yaml:"smtp"`
+	// FileDir is the directory the "file" driver writes .eml files to.
+	FileDir string `env:"MAIL_FILE_DIR" yaml:"file_dir"`
+}
+
+type SMTPMailConfig struct {
+	Host     string `env:"MAIL_SMTP_HOST" yaml:"host"`
+	Port     int    `env:"MAIL_SMTP_PORT" yaml:"port"`
+	Username string `env:"MAIL_SMTP_USERNAME" yaml:"username"`
+	Password string `env:"MAIL_SMTP_PASSWORD" yaml:"password" secret:"true"`
+	// TLS is "starttls", "tls" or "none".
+	TLS string `env:"MAIL_SMTP_TLS" yaml:"tls" default:"starttls"`
+}
+
+type InvitationConfig struct {
+	// TTL is how long an invitation can be accepted.
+	TTL time.Duration `env:"INVITATION_TTL" yaml:"ttl" default:"168h"`
+	// AcceptURL is the frontend page invitation links point to. The token is appended as a
+	// query parameter. Defaults to the frontend URL followed by /invitations/accept.
+	AcceptURL string `env:"INVITATION_ACCEPT_URL" yaml:"accept_url"`
+}
+
+type ProjectConfig struct {
+	// ArchiveRetention is how long archived projects are kept before the retention job deletes
+	// them. The job runs on replicas with jobs enabled.
+	ArchiveRetention time.Duration `env:"PROJECT_ARCHIVE_RETENTION" yaml:"archive_retention" default:"720h"`
+	// TransferTTL is how long the recipient of an ownership transfer has to accept it.
+	TransferTTL time.Duration `env:"PROJECT_TRANSFER_TTL" yaml:"transfer_ttl" default:"168h"`
+}
+
+type HealthConfig struct {
+	// Timeout is the timeout of a single dependency check.
+	Timeout time.Duration `env:"HEALTH_TIMEOUT" yaml:"timeout" default:"2s"`
+	// CacheTTL is how long check results are reused, so that frequent probes do not put load on
+	// the dependencies.
+	CacheTTL time.Duration `env:"HEALTH_CACHE_TTL" yaml:"cache_ttl" default:"5s"`
+	// OTLP checks that the OTLP endpoint accepts connections when telemetry is enabled.
+	// The check is reported but does not fail readiness.
+	OTLP bool `env:"HEALTH_CHECK_OTLP" yaml:"otlp"`
+	// GitHub checks that the GitHub API is reachable. The check is reported but does not fail
+	// readiness.
+	GitHub bool `env:"HEALTH_CHECK_GITHUB" yaml:"github"`
+	// GitHubCacheTTL is how long the result of the GitHub check is reused. Unauthenticated
+	// requests to the GitHub API are rate limited, so it is longer than CacheTTL.
+	GitHubCacheTTL time.Duration `env:"HEALTH_GITHUB_CACHE_TTL" yaml:"github_cache_ttl" default:"1m"`
+}
+
+type ShutdownConfig struct {
+	// DrainPeriod is how long the server keeps serving after readiness starts failing, so that
+	// load balancers stop sending requests before connections are closed. A negative value
+	// disables draining.
+	DrainPeriod time.Duration `env:"SHUTDOWN_DRAIN_PERIOD" yaml:"drain_period" default:"5s"`
+	// Timeout is how long in-flight requests may take to finish after the drain period.
+	Timeout time.Duration `env:"SHUTDOWN_TIMEOUT" yaml:"timeout" default:"10s"`
+	// CleanupTimeout bounds each cleanup step, such as closing the database pool or flushing
+	// telemetry.
+	CleanupTimeout time.Duration `env:"SHUTDOWN_CLEANUP_TIMEOUT" yaml:"cleanup_timeout" default:"5s"`
+}
+
+// ReloadConfig configures reloading while the server is running. The config is reloaded on
+// SIGHUP and when the config file changes, and TLS certificates when their files change. Only
+// cors, auth.allowed_orgs, log_level and rate_limit are applied; other changes need a restart.
+type ReloadConfig struct {
+	// WatchInterval is how often the config and certificate files are checked for changes.
+	// A negative value disables watching, leaving SIGHUP.
+	WatchInterval time.Duration `env:"RELOAD_WATCH_INTERVAL" yaml:"watch_interval" default:"10s"`
+}
+
+// ParseLogLevel parses "debug", "info", "warn" (or "warning") and "error", ignoring case.
+// An empty level is info.
+func ParseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, errors.Newf("unknown log level %q", level)
+	}
+}
+
 func LoadFromEnv() (Config, error) {
 	return envconfig.LoadFromEnv[Config]()
 }
 
-// LoadFromYAML loads configuration from a YAML file.
+// LoadFromYAML loads configuration from a YAML file. Values can refer to files and environment
+// variables with ${file:/path} and ${env:NAME}. Fields that are not set, or set to their zero
+// value, get the defaults of their `default` tags.
 func LoadFromYAML(path string) (Config, error) {
 	var cfg Config
 
@@ -79,9 +296,22 @@ func LoadFromYAML(path string) (Config, error) {
 		return cfg, errors.Wrapf(err, "failed to read config file: %s", path)
 	}
 
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
 		return cfg, errors.Wrapf(err, "failed to unmarshal yaml config")
 	}
+	if err := interpolate(&root); err != nil {
+		return cfg, err
+	}
+	if root.Kind != 0 {
+		if err := root.Decode(&cfg); err != nil {
+			return cfg, errors.Wrapf(err, "failed to unmarshal yaml config")
+		}
+	}
+	if err := envconfig.ApplyDefaults(&cfg); err != nil {
+		return cfg, err
+	}
+	cfg.source = newSource(path, &root)
 
 	return cfg, nil
 }
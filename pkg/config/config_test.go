@@ -1,6 +1,7 @@
 package config
 
 import (
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
@@ -113,6 +114,34 @@ tls:
 		}
 	})
 
+	t.Run("applies defaults to fields that are not set or zero", func(t *testing.T) {
+		t.Parallel()
+
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		yamlContent := `
+shutdown:
+  drain_period: 0s
+  timeout: 30s
+`
+		if err := os.WriteFile(configFile, []byte(yamlContent), 0644); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+
+		cfg, err := LoadFromYAML(configFile)
+		if err != nil {
+			t.Fatalf("LoadFromYAML() failed: %v", err)
+		}
+		if cfg.LogLevel != "info" {
+			t.Errorf("LogLevel = %q, want the default info", cfg.LogLevel)
+		}
+		if cfg.Shutdown.DrainPeriod != 5*time.Second {
+			t.Errorf("Shutdown.DrainPeriod = %v, want the default 5s for an explicit 0", cfg.Shutdown.DrainPeriod)
+		}
+		if cfg.Shutdown.Timeout != 30*time.Second {
+			t.Errorf("Shutdown.Timeout = %v, want 30s", cfg.Shutdown.Timeout)
+		}
+	})
+
 	t.Run("returns error for non-existent file", func(t *testing.T) {
 		t.Parallel()
 
@@ -224,3 +253,35 @@ auth:
 		}
 	})
 }
+
+func TestParseLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		level   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseLogLevel(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLogLevel(%q) error = %v, wantErr %v", tt.level, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLogLevel(%q) = %v, want %v", tt.level, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Diff returns the YAML paths of the fields that differ between a and b, such as
+// "cors.allow_origins". Slices and maps are compared as a whole. Values are not included, so
+// the result can be logged without leaking secrets.
+func Diff(a, b Config) []string {
+	var paths []string
+	diffValues("", reflect.ValueOf(a), reflect.ValueOf(b), &paths)
+	return paths
+}
+
+func diffValues(path string, a, b reflect.Value, paths *[]string) {
+	if a.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*paths = append(*paths, path)
+		}
+		return
+	}
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		diffValues(joinPath(path, yamlName(field)), a.Field(i), b.Field(i), paths)
+	}
+}
+
+// yamlName returns the key of a field in the YAML file.
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
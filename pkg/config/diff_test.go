@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	base := Config{
+		Port: "8080",
+		Auth: AuthConfig{AllowedOrgs: []string{"tacokumo"}, SessionTTL: time.Hour},
+		CORS: CORSConfig{AllowOrigins: "http://localhost:3000"},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Groups:  []RateLimitRule{{Name: "auth", Limit: 60, Window: time.Minute}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{"no changes", func(c *Config) {}, nil},
+		{"top-level field", func(c *Config) { c.Port = "9090" }, []string{"port"}},
+		{"nested fields", func(c *Config) {
+			c.CORS.AllowOrigins = "*"
+			c.CORS.MaxAge = 60
+		}, []string{"cors.allow_origins", "cors.max_age"}},
+		{"slice", func(c *Config) { c.Auth.AllowedOrgs = []string{"tacokumo", "other"} }, []string{"auth.allowed_orgs"}},
+		{"slice of structs", func(c *Config) {
+			c.RateLimit.Groups = []RateLimitRule{{Name: "auth", Limit: 30, Window: time.Minute}}
+		}, []string{"rate_limit.groups"}},
+		{"secret", func(c *Config) { c.Redis.Password = "secret" }, []string{"redis.password"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			next := base
+			next.Auth.AllowedOrgs = append([]string(nil), base.Auth.AllowedOrgs...)
+			next.RateLimit.Groups = append([]RateLimitRule(nil), base.RateLimit.Groups...)
+			tt.modify(&next)
+
+			if got := Diff(base, next); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
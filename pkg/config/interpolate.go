@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// referencePattern matches ${file:/path} and ${env:NAME} in YAML values.
+var referencePattern = regexp.MustCompile(`\$\{(file|env):([^}]*)\}`)
+
+// interpolate replaces the references in the scalar values of node, so that secrets can be kept
+// out of the config file: ${file:/path} is replaced by the content of the file without a trailing
+// newline, and ${env:NAME} by the value of the environment variable. Every reference that cannot
+// be resolved is reported.
+func interpolate(node *yaml.Node) error {
+	var problems []string
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.ScalarNode && strings.Contains(n.Value, "${") {
+			n.Value = referencePattern.ReplaceAllStringFunc(n.Value, func(ref string) string {
+				m := referencePattern.FindStringSubmatch(ref)
+				value, err := resolveReference(m[1], m[2])
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("line %d: %s", n.Line, err))
+				}
+				return value
+			})
+			if n.Style == 0 {
+				// Resolve the tag again, so that an unquoted reference can set a number or bool.
+				n.Tag = ""
+			}
+			return
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(node)
+
+	if len(problems) > 0 {
+		return errors.Newf("failed to resolve config references: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func resolveReference(kind, name string) (string, error) {
+	if name == "" {
+		return "", errors.Newf("${%s:} needs a name", kind)
+	}
+	switch kind {
+	case "file":
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read file")
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	default:
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.Newf("environment variable %s is not set", name)
+		}
+		return value, nil
+	}
+}
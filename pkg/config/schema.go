@@ -0,0 +1,33 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/cockroachdb/errors"
+	"github.com/tacokumo/admin-api/pkg/envconfig"
+)
+
+// Schema returns the JSON Schema of the config file, which editors can use to validate it.
+func Schema() *envconfig.JSONSchema {
+	s := envconfig.Schema[Config]()
+	s.Title = "TacoKumo admin-api server configuration"
+	s.Description = "Values can refer to files and environment variables with ${file:/path} and ${env:NAME}."
+	return s
+}
+
+// WriteSchema writes the JSON Schema of the config file as indented JSON.
+func WriteSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Schema()); err != nil {
+		return errors.Wrapf(err, "failed to encode config schema")
+	}
+	return nil
+}
+
+// EnvVars returns the environment variables that override the config file. Each can also be
+// read from a file with the _FILE suffix.
+func EnvVars() []envconfig.Var {
+	return envconfig.Vars[Config]()
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestSchemaIsUpToDate(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := WriteSchema(&buf); err != nil {
+		t.Fatalf("WriteSchema() error = %v", err)
+	}
+	checkedIn, err := os.ReadFile("../../develop/server.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to read the checked-in schema: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), checkedIn) {
+		t.Error("develop/server.schema.json is out of date; run make generate")
+	}
+}
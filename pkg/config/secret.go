@@ -0,0 +1,73 @@
+package config
+
+import (
+	"log/slog"
+
+	"github.com/tacokumo/admin-api/pkg/envconfig"
+)
+
+// Fields tagged `secret:"true"` are redacted when a config is printed with %v or logged. The
+// types with secret fields, and the types that contain them, implement fmt.Stringer and
+// slog.LogValuer for this.
+
+// Redacted returns a copy of the config with its secrets replaced, so that it can be printed.
+// Secrets that are not set stay empty.
+func (c Config) Redacted() Config {
+	return envconfig.Redact(c)
+}
+
+func (c Config) String() string {
+	return envconfig.Format(c)
+}
+
+func (c Config) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+func (c AdminDBConfig) String() string {
+	return envconfig.Format(c)
+}
+
+func (c AdminDBConfig) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+func (c AuthConfig) String() string {
+	return envconfig.Format(c)
+}
+
+func (c AuthConfig) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+func (c RedisConfig) String() string {
+	return envconfig.Format(c)
+}
+
+func (c RedisConfig) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+func (c SCIMConfig) String() string {
+	return envconfig.Format(c)
+}
+
+func (c SCIMConfig) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+func (c MailConfig) String() string {
+	return envconfig.Format(c)
+}
+
+func (c MailConfig) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+func (c SMTPMailConfig) String() string {
+	return envconfig.Format(c)
+}
+
+func (c SMTPMailConfig) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests in this file set environment variables, so they do not run in parallel.
+
+func TestLoadSecretsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "db_password"), "file-db-password\n")
+	writeTestFile(t, filepath.Join(dir, "client_secret"), "file-client-secret\n")
+	configFile := filepath.Join(dir, "config.yaml")
+	writeTestFile(t, configFile, fmt.Sprintf(`
+admin_db:
+  password: "${file:%s}"
+  port: ${env:TEST_ADMIN_DB_PORT}
+redis:
+  host: "redis-${env:TEST_REDIS_SUFFIX}"
+`, filepath.Join(dir, "db_password")))
+
+	t.Setenv("TEST_ADMIN_DB_PORT", "15432")
+	t.Setenv("TEST_REDIS_SUFFIX", "prod")
+	t.Setenv("GITHUB_CLIENT_SECRET_FILE", filepath.Join(dir, "client_secret"))
+
+	cfg, err := LoadFromYAMLWithEnvOverride(configFile)
+	if err != nil {
+		t.Fatalf("LoadFromYAMLWithEnvOverride() error = %v", err)
+	}
+	if cfg.AdminDBConfig.Password != "file-db-password" {
+		t.Errorf("admin_db.password = %q, want the content of the file", cfg.AdminDBConfig.Password)
+	}
+	if cfg.AdminDBConfig.Port != 15432 {
+		t.Errorf("admin_db.port = %d, want 15432", cfg.AdminDBConfig.Port)
+	}
+	if cfg.Redis.Host != "redis-prod" {
+		t.Errorf("redis.host = %q, want redis-prod", cfg.Redis.Host)
+	}
+	if cfg.Auth.GitHubClientSecret != "file-client-secret" {
+		t.Errorf("auth.client_secret = %q, want the content of GITHUB_CLIENT_SECRET_FILE", cfg.Auth.GitHubClientSecret)
+	}
+
+	// A variable that is set takes precedence over its _FILE variant.
+	t.Setenv("GITHUB_CLIENT_SECRET", "env-client-secret")
+	cfg, err = LoadFromYAMLWithEnvOverride(configFile)
+	if err != nil {
+		t.Fatalf("LoadFromYAMLWithEnvOverride() error = %v", err)
+	}
+	if cfg.Auth.GitHubClientSecret != "env-client-secret" {
+		t.Errorf("auth.client_secret = %q, want env-client-secret", cfg.Auth.GitHubClientSecret)
+	}
+}
+
+func TestLoadUnresolvedReferences(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	writeTestFile(t, configFile, `admin_db:
+  password: "${file:/nonexistent/password}"
+redis:
+  password: "${env:TEST_UNSET_REDIS_PASSWORD}"
+`)
+
+	_, err := LoadFromYAML(configFile)
+	if err == nil {
+		t.Fatal("LoadFromYAML() should fail for unresolved references")
+	}
+	for _, want := range []string{"line 2", "line 4", "TEST_UNSET_REDIS_PASSWORD"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should contain %q", err, want)
+		}
+	}
+}
+
+func TestSecretsAreNotFormatted(t *testing.T) {
+	cfg := validConfig()
+	cfg.AdminDBConfig.Password = "db-password"
+	cfg.Mail.SMTP.Password = "smtp-password"
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	logger.Info("config", slog.Any("config", cfg), slog.Any("auth", cfg.Auth))
+
+	outputs := map[string]string{
+		"%v":              fmt.Sprintf("%v", cfg),
+		"%+v":             fmt.Sprintf("%+v", cfg),
+		"%v of a section": fmt.Sprintf("%v", cfg.Mail),
+		"slog":            logs.String(),
+	}
+	for name, out := range outputs {
+		for _, secret := range []string{"db-password", "client-secret", "smtp-password"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s output contains secret %q: %s", name, secret, out)
+			}
+		}
+	}
+	if !strings.Contains(outputs["%v"], "Host:localhost") {
+		t.Errorf("%%v output should contain other fields: %s", outputs["%v"])
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
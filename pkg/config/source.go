@@ -0,0 +1,76 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// source records where the fields of a config were read from, so that problems can be reported
+// with the line of the YAML file they are on.
+type source struct {
+	file  string
+	lines map[string]int
+	// unknown are the paths of keys that match no field. They are ignored when the file is
+	// loaded and reported by Validate.
+	unknown []string
+}
+
+func newSource(file string, root *yaml.Node) *source {
+	s := &source{file: file, lines: map[string]int{}}
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		s.collect(root.Content[0], reflect.TypeOf(Config{}), "")
+	}
+	return s
+}
+
+// collect records the lines of the keys of node, which is decoded into a value of type t.
+func (s *source) collect(node *yaml.Node, t reflect.Type, path string) {
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := map[string]reflect.StructField{}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				fields[yamlName(f)] = f
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := joinPath(path, key.Value)
+			s.lines[childPath] = key.Line
+			f, ok := fields[key.Value]
+			if !ok {
+				s.unknown = append(s.unknown, childPath)
+				continue
+			}
+			s.collect(value, f.Type, childPath)
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			itemPath := path + "[" + strconv.Itoa(i) + "]"
+			s.lines[itemPath] = item.Line
+			s.collect(item, t.Elem(), itemPath)
+		}
+	}
+}
+
+// line returns the line of path, or of its closest ancestor in the file if path is not set
+// there. It returns 0 if neither is in the file.
+func (s *source) line(path string) int {
+	if s == nil {
+		return 0
+	}
+	for path != "" {
+		if line, ok := s.lines[path]; ok {
+			return line
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			return 0
+		}
+		path = path[:i]
+	}
+	return 0
+}
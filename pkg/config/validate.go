@@ -0,0 +1,268 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldError is a problem with a single field of a config.
+type FieldError struct {
+	// Path is the YAML path of the field, such as "admin_db.host".
+	Path string
+	// Line is the line of the field in the config file, or of its closest ancestor if the field
+	// is not set there. It is 0 if the config was not loaded from a file.
+	Line    int
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s (line %d): %s", e.Path, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationError lists every problem found by Config.Validate.
+type ValidationError struct {
+	// File is the config file, if the config was loaded from one.
+	File   string
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	prefix := "invalid config"
+	if e.File != "" {
+		prefix = fmt.Sprintf("invalid config file %s", e.File)
+	}
+	return prefix + ": " + strings.Join(msgs, "; ")
+}
+
+// negativeDurationPaths are the durations where a negative value has a meaning.
+var negativeDurationPaths = []string{"shutdown.drain_period", "reload.watch_interval"}
+
+// Validate checks the config for problems that would otherwise only be found at runtime, such
+// as missing required fields, and returns a *ValidationError listing all of them.
+func (c Config) Validate() error {
+	v := &validator{src: c.source}
+	if c.source != nil {
+		for _, path := range c.source.unknown {
+			v.add(path, "unknown field")
+		}
+	}
+
+	v.port("port", c.Port, true)
+	if _, err := ParseLogLevel(c.LogLevel); err != nil {
+		v.add("log_level", "must be one of debug, info, warn or error")
+	}
+
+	v.cidrs("trusted_proxies", c.TrustedProxies)
+
+	v.required("admin_db.host", c.AdminDBConfig.Host)
+	v.port("admin_db.port", strconv.Itoa(c.AdminDBConfig.Port), true)
+	v.required("admin_db.user", c.AdminDBConfig.User)
+	v.required("admin_db.db_name", c.AdminDBConfig.DBName)
+	if c.AdminDBConfig.InitialConnRetry < 0 {
+		v.add("admin_db.initial_conn_retry", "must not be negative")
+	}
+
+	v.required("auth.client_id", c.Auth.GitHubClientID)
+	v.required("auth.client_secret", c.Auth.GitHubClientSecret)
+	v.url("auth.callback_url", c.Auth.CallbackURL, true)
+	v.url("auth.frontend_url", c.Auth.FrontendURL, true)
+
+	v.required("redis.host", c.Redis.Host)
+	v.port("redis.port", strconv.Itoa(c.Redis.Port), true)
+	if c.Redis.DB < 0 {
+		v.add("redis.db", "must not be negative")
+	}
+	if c.Events.ReadPoolSize < 0 {
+		v.add("events.read_pool_size", "must not be negative")
+	}
+	v.cidrs("webhook.allowed_networks", c.Webhook.AllowedNetworks)
+
+	if c.CORS.AllowCredentials && slices.Contains(strings.Split(c.CORS.AllowOrigins, ","), "*") {
+		v.add("cors.allow_origins", "must not be * when allow_credentials is true")
+	}
+
+	v.validateTLS(c.TLS)
+	v.validateRateLimit(c.RateLimit)
+	v.validateMail(c.Mail)
+
+	if c.SCIM.Enabled {
+		v.required("scim.token", c.SCIM.Token)
+		if err := uuid.Validate(c.SCIM.ProjectID); err != nil {
+			v.add("scim.project_id", "must be a project ID when scim is enabled")
+		}
+	}
+	v.url("invitation.accept_url", c.Invitation.AcceptURL, false)
+
+	v.validateDurations("", reflect.ValueOf(c))
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	e := &ValidationError{Errors: v.errs}
+	if c.source != nil {
+		e.File = c.source.file
+	}
+	return e
+}
+
+func (v *validator) validateTLS(c TLSConfig) {
+	if c.Enabled {
+		v.required("tls.cert_file", c.CertFile)
+		v.required("tls.key_file", c.KeyFile)
+	}
+	switch c.ClientAuth {
+	case "", "none":
+		if len(c.ClientCertPaths) > 0 {
+			v.add("tls.client_cert_paths", "requires client_auth to be optional or require")
+		}
+		if len(c.ClientCertIdentities) > 0 {
+			v.add("tls.client_cert_identities", "requires client_auth to be optional or require")
+		}
+	case "optional", "require":
+		if !c.Enabled {
+			v.add("tls.client_auth", "requires tls to be enabled")
+		}
+		if c.ClientCAFile == "" {
+			v.add("tls.client_ca_file", "is required when client_auth is "+c.ClientAuth)
+		}
+	default:
+		v.add("tls.client_auth", "must be one of none, optional or require")
+	}
+	for i, id := range c.ClientCertIdentities {
+		path := fmt.Sprintf("tls.client_cert_identities[%d]", i)
+		if (id.Subject == "") == (id.SAN == "") {
+			v.add(path, "must set exactly one of subject and san")
+		}
+		if err := uuid.Validate(id.ServiceAccountID); err != nil {
+			v.add(path+".service_account_id", "must be a service account ID")
+		}
+	}
+}
+
+func (v *validator) validateRateLimit(c RateLimitConfig) {
+	v.validateRateLimitRule("rate_limit.default", c.Default)
+	v.validateRateLimitWindow("rate_limit.per_ip", c.PerIP.Limit, c.PerIP.Window)
+	for i, g := range c.Groups {
+		path := fmt.Sprintf("rate_limit.groups[%d]", i)
+		v.validateRateLimitRule(path, g)
+		if g.PathPrefix == "" {
+			v.add(path+".path_prefix", "is required")
+		}
+	}
+}
+
+func (v *validator) validateRateLimitRule(path string, r RateLimitRule) {
+	v.validateRateLimitWindow(path, r.Limit, r.Window)
+}
+
+func (v *validator) validateRateLimitWindow(path string, limit int, window time.Duration) {
+	if limit < 0 {
+		v.add(path+".limit", "must not be negative")
+	}
+	if limit > 0 && window == 0 {
+		v.add(path+".window", "is required when limit is set")
+	}
+}
+
+func (v *validator) validateMail(c MailConfig) {
+	switch c.Driver {
+	case "", "log":
+	case "file":
+		v.required("mail.file_dir", c.FileDir)
+	case "smtp":
+		v.required("mail.smtp.host", c.SMTP.Host)
+		v.port("mail.smtp.port", strconv.Itoa(c.SMTP.Port), true)
+		v.required("mail.from", c.From)
+		switch c.SMTP.TLS {
+		case "", "starttls", "tls", "none":
+		default:
+			v.add("mail.smtp.tls", "must be one of starttls, tls or none")
+		}
+	default:
+		v.add("mail.driver", "must be one of smtp, log or file")
+	}
+}
+
+// validateDurations checks that durations are not negative, except where that has a meaning.
+func (v *validator) validateDurations(path string, value reflect.Value) {
+	switch {
+	case value.Type() == reflect.TypeOf(time.Duration(0)):
+		if value.Int() < 0 && !slices.Contains(negativeDurationPaths, path) {
+			v.add(path, "must not be negative")
+		}
+	case value.Kind() == reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			v.validateDurations(joinPath(path, yamlName(field)), value.Field(i))
+		}
+	case value.Kind() == reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			v.validateDurations(path+"["+strconv.Itoa(i)+"]", value.Index(i))
+		}
+	}
+}
+
+type validator struct {
+	src  *source
+	errs []FieldError
+}
+
+func (v *validator) add(path, message string) {
+	v.errs = append(v.errs, FieldError{Path: path, Line: v.src.line(path), Message: message})
+}
+
+func (v *validator) required(path, value string) {
+	if value == "" {
+		v.add(path, "is required")
+	}
+}
+
+func (v *validator) port(path, value string, required bool) {
+	if value == "" || value == "0" {
+		if required {
+			v.add(path, "is required")
+		}
+		return
+	}
+	if p, err := strconv.Atoi(value); err != nil || p < 1 || p > 65535 {
+		v.add(path, "must be a port number between 1 and 65535")
+	}
+}
+
+func (v *validator) cidrs(path string, values []string) {
+	for i, cidr := range values {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			v.add(fmt.Sprintf("%s[%d]", path, i), "must be a CIDR range such as 10.0.0.0/8")
+		}
+	}
+}
+
+func (v *validator) url(path, value string, required bool) {
+	if value == "" {
+		if required {
+			v.add(path, "is required")
+		}
+		return
+	}
+	if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+		v.add(path, "must be an absolute URL")
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	return Config{
+		Port:          "8080",
+		AdminDBConfig: AdminDBConfig{Host: "localhost", Port: 5432, User: "admin_api", DBName: "tacokumo_admin_db"},
+		Auth: AuthConfig{
+			GitHubClientID:     "client-id",
+			GitHubClientSecret: "client-secret",
+			CallbackURL:        "http://localhost:8080/v1alpha1/auth/callback",
+			FrontendURL:        "http://localhost:3000",
+		},
+		Redis: RedisConfig{Host: "localhost", Port: 6379},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{"missing required fields", func(c *Config) {
+			c.Port = ""
+			c.AdminDBConfig.Host = ""
+			c.Auth.GitHubClientID = ""
+		}, []string{"port", "admin_db.host", "auth.client_id"}},
+		{"invalid port", func(c *Config) { c.Port = "http" }, []string{"port"}},
+		{"relative url", func(c *Config) { c.Auth.FrontendURL = "/app" }, []string{"auth.frontend_url"}},
+		{"cors wildcard with credentials", func(c *Config) {
+			c.CORS.AllowOrigins = "http://localhost:3000,*"
+			c.CORS.AllowCredentials = true
+		}, []string{"cors.allow_origins"}},
+		{"cors wildcard without credentials", func(c *Config) { c.CORS.AllowOrigins = "*" }, nil},
+		{"log level", func(c *Config) { c.LogLevel = "verbose" }, []string{"log_level"}},
+		{"trusted proxies", func(c *Config) {
+			c.TrustedProxies = []string{"10.0.0.0/8", "10.0.0.1"}
+		}, []string{"trusted_proxies[1]"}},
+		{"webhook allowed networks", func(c *Config) {
+			c.Webhook.AllowedNetworks = []string{"internal"}
+		}, []string{"webhook.allowed_networks[0]"}},
+		{"tls without cert", func(c *Config) { c.TLS.Enabled = true }, []string{"tls.cert_file", "tls.key_file"}},
+		{"client certificates without client auth", func(c *Config) {
+			c.TLS.ClientCertPaths = []string{"/internal/"}
+		}, []string{"tls.client_cert_paths"}},
+		{"client auth without ca", func(c *Config) {
+			c.TLS = TLSConfig{Enabled: true, CertFile: "tls.crt", KeyFile: "tls.key", ClientAuth: "require"}
+		}, []string{"tls.client_ca_file"}},
+		{"client certificate identity", func(c *Config) {
+			c.TLS = TLSConfig{
+				Enabled: true, CertFile: "tls.crt", KeyFile: "tls.key", ClientAuth: "optional", ClientCAFile: "ca.crt",
+				ClientCertIdentities: []ClientCertIdentity{{Subject: "deployer", SAN: "deployer", ServiceAccountID: "sa"}},
+			}
+		}, []string{"tls.client_cert_identities[0]", "tls.client_cert_identities[0].service_account_id"}},
+		{"rate limit rule", func(c *Config) {
+			c.RateLimit.Groups = []RateLimitRule{{Name: "auth", Limit: 60}}
+		}, []string{"rate_limit.groups[0].window", "rate_limit.groups[0].path_prefix"}},
+		{"mail driver", func(c *Config) { c.Mail.Driver = "sendmail" }, []string{"mail.driver"}},
+		{"smtp", func(c *Config) { c.Mail.Driver = "smtp" }, []string{"mail.smtp.host", "mail.smtp.port", "mail.from"}},
+		{"scim", func(c *Config) {
+			c.SCIM = SCIMConfig{Enabled: true, ProjectID: "tacokumo"}
+		}, []string{"scim.token", "scim.project_id"}},
+		{"negative durations", func(c *Config) {
+			c.Auth.SessionTTL = -time.Hour
+			c.RateLimit.Default = RateLimitRule{Limit: 1, Window: -time.Second}
+			c.Shutdown.DrainPeriod = -1
+			c.Reload.WatchInterval = -1
+		}, []string{"auth.session_ttl", "rate_limit.default.window"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := validConfig()
+			tt.modify(&cfg)
+			if got := errorPaths(t, cfg.Validate()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() problems = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLines(t *testing.T) {
+	t.Parallel()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	yamlContent := `port: "8080"
+admin_db:
+  host: ""
+  port: 5432
+  user: "admin_api"
+  db_name: "tacokumo_admin_db"
+auth:
+  client_id: "client-id"
+  client_secret: "client-secret"
+  callback_url: "http://localhost:8080/v1alpha1/auth/callback"
+  frontend_url: "http://localhost:3000"
+  unknown_option: true
+redis:
+  port: 6379
+`
+	if err := os.WriteFile(configFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	cfg, err := LoadFromYAML(configFile)
+	if err != nil {
+		t.Fatalf("LoadFromYAML() error = %v", err)
+	}
+
+	var verr *ValidationError
+	if !errors.As(cfg.Validate(), &verr) {
+		t.Fatalf("Validate() error = %v, want *ValidationError", err)
+	}
+	if verr.File != configFile {
+		t.Errorf("File = %q, want %q", verr.File, configFile)
+	}
+	want := []FieldError{
+		{Path: "auth.unknown_option", Line: 12, Message: "unknown field"},
+		{Path: "admin_db.host", Line: 3, Message: "is required"},
+		// Fields that are not in the file are reported at their parent.
+		{Path: "redis.host", Line: 13, Message: "is required"},
+	}
+	if !reflect.DeepEqual(verr.Errors, want) {
+		t.Errorf("Errors = %v, want %v", verr.Errors, want)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	t.Parallel()
+
+	cfg := validConfig()
+	cfg.Redis.Password = "redispass"
+	redacted := cfg.Redacted()
+
+	if redacted.Auth.GitHubClientSecret != "[REDACTED]" || redacted.Redis.Password != "[REDACTED]" {
+		t.Errorf("Redacted() did not replace secrets: %+v", redacted)
+	}
+	if redacted.AdminDBConfig.Password != "" {
+		t.Errorf("Redacted() admin_db.password = %q, want unset secrets to stay empty", redacted.AdminDBConfig.Password)
+	}
+	if cfg.Auth.GitHubClientSecret != "client-secret" {
+		t.Error("Redacted() modified the original config")
+	}
+}
+
+func errorPaths(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() error = %v, want *ValidationError", err)
+	}
+	var paths []string
+	for _, fe := range verr.Errors {
+		paths = append(paths, fe.Path)
+	}
+	return paths
+}
@@ -0,0 +1,299 @@
+// Package dataset exports and imports the complete admin dataset.
+//
+// A Document holds the users, role attributes and projects together with their roles, user
+// groups, owners and memberships. Every object is keyed by its display ID, so that a document
+// exported from one environment can be imported into another and keeps the IDs that clients
+// refer to. Service accounts, tokens, webhooks, invitations and jobs are not part of it. This
+// includes the user group memberships and role assignments of service accounts: user groups and
+// roles list users only. An import leaves them in place, but the memberships and assignments of
+// a user group, role or project that a replace import deletes are deleted with it.
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/labels"
+	"github.com/tacokumo/admin-api/pkg/project"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the version of the document format. Documents of other versions are rejected.
+const Version = 1
+
+// Document is an export of the admin dataset.
+type Document struct {
+	Version    int         `json:"version" yaml:"version"`
+	ExportedAt time.Time   `json:"exportedAt" yaml:"exportedAt"`
+	Users      []User      `json:"users" yaml:"users"`
+	Attributes []Attribute `json:"attributes" yaml:"attributes"`
+	Projects   []Project   `json:"projects" yaml:"projects"`
+}
+
+// User is a user account.
+type User struct {
+	ID    string `json:"id" yaml:"id"`
+	Email string `json:"email" yaml:"email"`
+}
+
+// Attribute is a role attribute definition.
+type Attribute struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// Project is a project with everything that belongs to it.
+type Project struct {
+	ID          string            `json:"id" yaml:"id"`
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description" yaml:"description"`
+	Kind        string            `json:"kind" yaml:"kind"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	ArchivedAt  *time.Time        `json:"archivedAt,omitempty" yaml:"archivedAt,omitempty"`
+	ArchivedBy  *string           `json:"archivedBy,omitempty" yaml:"archivedBy,omitempty"`
+	// Owners are the IDs of the owners of a personal project.
+	Owners     []string    `json:"owners,omitempty" yaml:"owners,omitempty"`
+	Roles      []Role      `json:"roles,omitempty" yaml:"roles,omitempty"`
+	UserGroups []UserGroup `json:"userGroups,omitempty" yaml:"userGroups,omitempty"`
+}
+
+// Role is a role of a project.
+type Role struct {
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	// Attributes are the names of the attributes of the role.
+	Attributes []string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	// Users are the IDs of the users the role is assigned to directly.
+	Users       []string          `json:"users,omitempty" yaml:"users,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// UserGroup is a user group of a project.
+type UserGroup struct {
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	// Owner makes the group an owner group of the project.
+	Owner bool `json:"owner,omitempty" yaml:"owner,omitempty"`
+	// Roles are the IDs of the roles of the project assigned to the group.
+	Roles []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+	// Members are the IDs of the users in the group.
+	Members     []string          `json:"members,omitempty" yaml:"members,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// ValidationError reports a document that cannot be imported.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid dataset: " + e.Message
+}
+
+func validationErrorf(format string, args ...any) *ValidationError {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Parse decodes a JSON or YAML document. Unknown fields are rejected.
+func Parse(b []byte) (Document, error) {
+	var d Document
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&d); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Document{}, validationErrorf("document is empty")
+		}
+		return Document{}, validationErrorf("%s", err.Error())
+	}
+	return d, nil
+}
+
+// Validate checks that every reference in the document resolves to an object of the document
+// and that every project follows the rules of its kind. It does not look at the database.
+func (d Document) Validate() error {
+	if d.Version != Version {
+		return validationErrorf("version %d is not supported; expected %d", d.Version, Version)
+	}
+
+	ids := map[string]bool{}
+	checkID := func(kind, id string) error {
+		if _, err := parseID(kind, id); err != nil {
+			return err
+		}
+		if ids[id] {
+			return validationErrorf("%s ID %s is used more than once", kind, id)
+		}
+		ids[id] = true
+		return nil
+	}
+
+	users := map[string]bool{}
+	emails := map[string]bool{}
+	for _, u := range d.Users {
+		if err := checkID("user", u.ID); err != nil {
+			return err
+		}
+		email := strings.ToLower(u.Email)
+		if email == "" {
+			return validationErrorf("user %s has no email", u.ID)
+		}
+		if emails[email] {
+			return validationErrorf("email %q is used by more than one user", u.Email)
+		}
+		users[u.ID], emails[email] = true, true
+	}
+
+	attributes := map[string]bool{}
+	for _, a := range d.Attributes {
+		if a.Name == "" {
+			return validationErrorf("attribute name is required")
+		}
+		if attributes[a.Name] {
+			return validationErrorf("attribute %q is defined more than once", a.Name)
+		}
+		attributes[a.Name] = true
+	}
+
+	names := map[string]bool{}
+	for _, p := range d.Projects {
+		if err := checkID("project", p.ID); err != nil {
+			return err
+		}
+		if p.Name == "" {
+			return validationErrorf("project %s has no name", p.ID)
+		}
+		if names[p.Name] {
+			return validationErrorf("project name %q is used more than once", p.Name)
+		}
+		names[p.Name] = true
+		if err := p.validate(checkID, users, attributes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Project) validate(checkID func(kind, id string) error, users, attributes map[string]bool) error {
+	if err := labels.Validate(p.Labels); err != nil {
+		return validationErrorf("project %q: %s", p.Name, err)
+	}
+	if err := labels.ValidateAnnotations(p.Annotations); err != nil {
+		return validationErrorf("project %q: %s", p.Name, err)
+	}
+	for _, owner := range p.Owners {
+		if !users[owner] {
+			return validationErrorf("project %q refers to unknown owner %s", p.Name, owner)
+		}
+	}
+
+	// The structure is checked with the same rules as project templates, which refer to roles by name.
+	t := project.Template{}
+	roleNames := map[string]string{}
+	for _, r := range p.Roles {
+		if err := checkID("role", r.ID); err != nil {
+			return err
+		}
+		roleNames[r.ID] = r.Name
+		for _, a := range r.Attributes {
+			if !attributes[a] {
+				return validationErrorf("role %q of project %q refers to unknown attribute %q", r.Name, p.Name, a)
+			}
+		}
+		for _, u := range r.Users {
+			if !users[u] {
+				return validationErrorf("role %q of project %q refers to unknown user %s", r.Name, p.Name, u)
+			}
+		}
+		t.Roles = append(t.Roles, project.TemplateRole{Name: r.Name, Description: r.Description, Labels: r.Labels, Annotations: r.Annotations})
+	}
+	ownerGroups := 0
+	for _, g := range p.UserGroups {
+		if err := checkID("user group", g.ID); err != nil {
+			return err
+		}
+		tg := project.TemplateUserGroup{Name: g.Name, Description: g.Description, Owner: g.Owner, Labels: g.Labels, Annotations: g.Annotations}
+		for _, role := range g.Roles {
+			name, ok := roleNames[role]
+			if !ok {
+				return validationErrorf("user group %q of project %q refers to unknown role %s", g.Name, p.Name, role)
+			}
+			tg.Roles = append(tg.Roles, name)
+		}
+		for _, m := range g.Members {
+			if !users[m] {
+				return validationErrorf("user group %q of project %q refers to unknown user %s", g.Name, p.Name, m)
+			}
+		}
+		if g.Owner {
+			ownerGroups++
+		}
+		t.UserGroups = append(t.UserGroups, tg)
+	}
+
+	if err := (project.Composition{
+		Kind:        p.Kind,
+		Owners:      int64(len(p.Owners)),
+		OwnerGroups: int64(ownerGroups),
+		UserGroups:  int64(len(p.UserGroups)),
+	}).Validate(); err != nil {
+		return validationErrorf("project %q: %s", p.Name, err)
+	}
+	if err := t.Validate(p.Kind); err != nil {
+		var te *project.TemplateError
+		if errors.As(err, &te) {
+			return validationErrorf("project %q: %s", p.Name, te.Message)
+		}
+		return err
+	}
+	return nil
+}
+
+// parseID parses the display ID of an object of the document.
+func parseID(kind, id string) (pgtype.UUID, error) {
+	u := pgtype.UUID{}
+	if err := u.Scan(id); err != nil {
+		return u, validationErrorf("invalid %s ID %q", kind, id)
+	}
+	return u, nil
+}
+
+// Document encodings.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Encode writes the document as JSON or YAML.
+func Encode(w io.Writer, d Document, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(d); err != nil {
+			return errors.Wrapf(err, "failed to encode dataset as json")
+		}
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(d); err != nil {
+			return errors.Wrapf(err, "failed to encode dataset as yaml")
+		}
+		if err := enc.Close(); err != nil {
+			return errors.Wrapf(err, "failed to encode dataset as yaml")
+		}
+	default:
+		return errors.Newf("format must be %s or %s", FormatJSON, FormatYAML)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package dataset
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	alice   = "0190f5c2-0000-7000-8000-000000000001"
+	bob     = "0190f5c2-0000-7000-8000-000000000002"
+	web     = "0190f5c2-0000-7000-8000-000000000010"
+	home    = "0190f5c2-0000-7000-8000-000000000011"
+	admin   = "0190f5c2-0000-7000-8000-000000000020"
+	owners  = "0190f5c2-0000-7000-8000-000000000030"
+	unknown = "0190f5c2-0000-7000-8000-0000000000ff"
+)
+
+func testDocument() Document {
+	return Document{
+		Version:    Version,
+		ExportedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Users:      []User{{ID: alice, Email: "alice@example.com"}, {ID: bob, Email: "bob@example.com"}},
+		Attributes: []Attribute{{Name: "write", Description: "Write access"}},
+		Projects: []Project{
+			{
+				ID:   web,
+				Name: "web",
+				Kind: "shared",
+				Roles: []Role{
+					{ID: admin, Name: "admin", Attributes: []string{"write"}, Users: []string{bob}},
+				},
+				UserGroups: []UserGroup{
+					{ID: owners, Name: "owners", Owner: true, Roles: []string{admin}, Members: []string{alice}},
+				},
+			},
+			{ID: home, Name: "home", Kind: "personal", Owners: []string{alice}, Labels: map[string]string{"env": "dev"}},
+		},
+	}
+}
+
+func TestDocumentValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		modify  func(d *Document)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			modify: func(d *Document) {},
+		},
+		{
+			name:    "unsupported version",
+			modify:  func(d *Document) { d.Version = 2 },
+			wantErr: true,
+		},
+		{
+			name:    "invalid id",
+			modify:  func(d *Document) { d.Users[0].ID = "alice" },
+			wantErr: true,
+		},
+		{
+			name:    "duplicate id",
+			modify:  func(d *Document) { d.Projects[1].ID = web },
+			wantErr: true,
+		},
+		{
+			name:    "duplicate email",
+			modify:  func(d *Document) { d.Users[1].Email = "Alice@example.com" },
+			wantErr: true,
+		},
+		{
+			name:    "unknown owner",
+			modify:  func(d *Document) { d.Projects[1].Owners = []string{unknown} },
+			wantErr: true,
+		},
+		{
+			name:    "unknown member",
+			modify:  func(d *Document) { d.Projects[0].UserGroups[0].Members = []string{unknown} },
+			wantErr: true,
+		},
+		{
+			name:    "unknown role in group",
+			modify:  func(d *Document) { d.Projects[0].UserGroups[0].Roles = []string{unknown} },
+			wantErr: true,
+		},
+		{
+			name:    "unknown attribute",
+			modify:  func(d *Document) { d.Projects[0].Roles[0].Attributes = []string{"delete"} },
+			wantErr: true,
+		},
+		{
+			name:    "shared without owner group",
+			modify:  func(d *Document) { d.Projects[0].UserGroups[0].Owner = false },
+			wantErr: true,
+		},
+		{
+			name:    "personal with two owners",
+			modify:  func(d *Document) { d.Projects[1].Owners = []string{alice, bob} },
+			wantErr: true,
+		},
+		{
+			name:    "invalid label",
+			modify:  func(d *Document) { d.Projects[1].Labels = map[string]string{"env": "not valid"} },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := testDocument()
+			tt.modify(&d)
+			err := d.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ve *ValidationError
+			if err != nil && !errors.As(err, &ve) {
+				t.Errorf("Validate() error = %T, want *ValidationError", err)
+			}
+		})
+	}
+}
+
+func TestEncodeParse(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			if err := Encode(&buf, testDocument(), format); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			d, err := Parse(buf.Bytes())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if err := d.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if !d.ExportedAt.Equal(testDocument().ExportedAt) {
+				t.Errorf("ExportedAt = %v, want %v", d.ExportedAt, testDocument().ExportedAt)
+			}
+			if got := d.Projects[0].UserGroups[0].Roles[0]; got != admin {
+				t.Errorf("group role = %s, want %s", got, admin)
+			}
+		})
+	}
+}
+
+func TestParseUnknownField(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse([]byte("version: 1\nusers: []\ngroups: []\n"))
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Parse() error = %v, want *ValidationError", err)
+	}
+}
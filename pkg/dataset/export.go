@@ -0,0 +1,167 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+// Export reads the complete dataset. It should run in a repeatable read transaction so that
+// the document is a consistent snapshot.
+func Export(ctx context.Context, q *admindb.Queries) (Document, error) {
+	d := Document{
+		Version:    Version,
+		ExportedAt: time.Now().UTC(),
+		Users:      []User{},
+		Attributes: []Attribute{},
+		Projects:   []Project{},
+	}
+
+	users, err := q.ListAllUsers(ctx)
+	if err != nil {
+		return d, errors.Wrapf(err, "failed to list users")
+	}
+	for _, u := range users {
+		d.Users = append(d.Users, User{ID: u.DisplayID.String(), Email: u.Email})
+	}
+
+	attributes, err := q.ListRoleAttributes(ctx)
+	if err != nil {
+		return d, errors.Wrapf(err, "failed to list role attributes")
+	}
+	for _, a := range attributes {
+		d.Attributes = append(d.Attributes, Attribute{Name: a.Name, Description: a.Description})
+	}
+
+	projects, err := q.ListAllProjects(ctx)
+	if err != nil {
+		return d, errors.Wrapf(err, "failed to list projects")
+	}
+	for _, proj := range projects {
+		p, err := exportProject(ctx, q, proj)
+		if err != nil {
+			return d, err
+		}
+		d.Projects = append(d.Projects, p)
+	}
+	return d, nil
+}
+
+func exportProject(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject) (Project, error) {
+	p := Project{
+		ID:          proj.DisplayID.String(),
+		Name:        proj.Name,
+		Description: proj.Description,
+		Kind:        proj.Kind,
+	}
+	if proj.ArchivedAt.Valid {
+		p.ArchivedAt = &proj.ArchivedAt.Time
+	}
+	if proj.ArchivedBy.Valid {
+		p.ArchivedBy = &proj.ArchivedBy.String
+	}
+	var err error
+	if p.Labels, p.Annotations, err = decodeMetadata(proj.Labels, proj.Annotations); err != nil {
+		return p, err
+	}
+
+	owners, err := q.ListProjectOwners(ctx, proj.ID)
+	if err != nil {
+		return p, errors.Wrapf(err, "failed to list project owners")
+	}
+	for _, owner := range owners {
+		p.Owners = append(p.Owners, owner.DisplayID.String())
+	}
+
+	roles, err := q.ListRolesByProject(ctx, proj.ID)
+	if err != nil {
+		return p, errors.Wrapf(err, "failed to list roles by project")
+	}
+	roleIDs := map[string]string{}
+	for _, r := range roles {
+		role := Role{ID: r.DisplayID.String(), Name: r.Name, Description: r.Description}
+		roleIDs[r.Name] = role.ID
+		if role.Attributes, err = q.ListRoleAttributeNames(ctx, r.ID); err != nil {
+			return p, errors.Wrapf(err, "failed to list role attribute names")
+		}
+		users, err := q.ListRoleUsers(ctx, r.ID)
+		if err != nil {
+			return p, errors.Wrapf(err, "failed to list role users")
+		}
+		for _, u := range users {
+			role.Users = append(role.Users, u.DisplayID.String())
+		}
+		if role.Labels, role.Annotations, err = decodeMetadata(r.Labels, r.Annotations); err != nil {
+			return p, err
+		}
+		p.Roles = append(p.Roles, role)
+	}
+
+	ownerGroups, err := q.ListProjectOwnerGroups(ctx, proj.ID)
+	if err != nil {
+		return p, errors.Wrapf(err, "failed to list project owner groups")
+	}
+	owner := map[int64]bool{}
+	for _, g := range ownerGroups {
+		owner[g.ID] = true
+	}
+	groups, err := q.ListUserGroupsByProject(ctx, proj.ID)
+	if err != nil {
+		return p, errors.Wrapf(err, "failed to list user groups by project")
+	}
+	for _, g := range groups {
+		group := UserGroup{ID: g.DisplayID.String(), Name: g.Name, Description: g.Description, Owner: owner[g.ID]}
+		names, err := q.ListUserGroupRoleNames(ctx, g.ID)
+		if err != nil {
+			return p, errors.Wrapf(err, "failed to list user group role names")
+		}
+		for _, name := range names {
+			group.Roles = append(group.Roles, roleIDs[name])
+		}
+		members, err := q.ListUserGroupMembers(ctx, g.ID)
+		if err != nil {
+			return p, errors.Wrapf(err, "failed to list user group members")
+		}
+		for _, m := range members {
+			group.Members = append(group.Members, m.DisplayID.String())
+		}
+		if group.Labels, group.Annotations, err = decodeMetadata(g.Labels, g.Annotations); err != nil {
+			return p, err
+		}
+		p.UserGroups = append(p.UserGroups, group)
+	}
+	return p, nil
+}
+
+func decodeMetadata(labelsJSON, annotationsJSON []byte) (l map[string]string, a map[string]string, err error) {
+	if len(labelsJSON) > 0 {
+		if err := json.Unmarshal(labelsJSON, &l); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to unmarshal labels")
+		}
+	}
+	if len(annotationsJSON) > 0 {
+		if err := json.Unmarshal(annotationsJSON, &a); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to unmarshal annotations")
+		}
+	}
+	return l, a, nil
+}
+
+func encodeMetadata(l, a map[string]string) (labelsJSON []byte, annotationsJSON []byte, err error) {
+	if l == nil {
+		l = map[string]string{}
+	}
+	if a == nil {
+		a = map[string]string{}
+	}
+	if labelsJSON, err = json.Marshal(l); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to marshal labels")
+	}
+	if annotationsJSON, err = json.Marshal(a); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to marshal annotations")
+	}
+	return labelsJSON, annotationsJSON, nil
+}
@@ -0,0 +1,446 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// Import modes.
+const (
+	// ModeMerge creates the objects of the document and overwrites the existing ones. Objects that
+	// are not in the document are kept, and so are owners, memberships and role assignments.
+	ModeMerge = "merge"
+	// ModeReplace makes the projects match the document: projects, roles and user groups that are
+	// not in it are deleted, and the owners, memberships and role assignments of the imported
+	// objects are replaced. Users are never deleted, since they are shared by all projects and
+	// keep the identities they sign in with. The memberships and role assignments of service
+	// accounts are kept unless their user group, role or project is deleted.
+	ModeReplace = "replace"
+)
+
+// ValidMode reports whether mode is a known import mode.
+func ValidMode(mode string) bool {
+	return mode == ModeMerge || mode == ModeReplace
+}
+
+// Counts is what an import did to one kind of object.
+type Counts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+}
+
+// Summary is what an import did.
+type Summary struct {
+	Users      Counts `json:"users"`
+	Attributes Counts `json:"attributes"`
+	Projects   Counts `json:"projects"`
+	Roles      Counts `json:"roles"`
+	UserGroups Counts `json:"userGroups"`
+}
+
+// String renders the summary as one line per kind of object.
+func (s Summary) String() string {
+	var b strings.Builder
+	for _, row := range []struct {
+		kind   string
+		counts Counts
+	}{
+		{"users", s.Users},
+		{"attributes", s.Attributes},
+		{"projects", s.Projects},
+		{"roles", s.Roles},
+		{"user groups", s.UserGroups},
+	} {
+		fmt.Fprintf(&b, "  %-12s created %d, updated %d, deleted %d\n", row.kind, row.counts.Created, row.counts.Updated, row.counts.Deleted)
+	}
+	return b.String()
+}
+
+// importer carries the internal IDs of the objects written so far.
+type importer struct {
+	q       *admindb.Queries
+	mode    string
+	users   map[string]int64
+	summary Summary
+}
+
+// Import writes a validated document. Conflicts with the existing data, such as an email or a
+// project name that belongs to an object with another ID, are reported as a ValidationError
+// before anything is written. Import is meant to run in a transaction, so that a failure, or a
+// project that ends up violating the rules of its kind, leaves the database unchanged.
+func Import(ctx context.Context, q *admindb.Queries, d Document, mode string) (Summary, error) {
+	if !ValidMode(mode) {
+		return Summary{}, validationErrorf("mode must be %s or %s", ModeMerge, ModeReplace)
+	}
+	im := &importer{q: q, mode: mode, users: map[string]int64{}}
+	if err := im.checkConflicts(ctx, d); err != nil {
+		return Summary{}, err
+	}
+
+	for _, u := range d.Users {
+		if err := im.importUser(ctx, u); err != nil {
+			return Summary{}, err
+		}
+	}
+	if err := im.importAttributes(ctx, d.Attributes); err != nil {
+		return Summary{}, err
+	}
+	if mode == ModeReplace {
+		if err := im.deleteMissing(ctx, d); err != nil {
+			return Summary{}, err
+		}
+	}
+	for _, p := range d.Projects {
+		projectID, err := im.importProject(ctx, p)
+		if err != nil {
+			return Summary{}, errors.Wrapf(err, "failed to import project %q", p.Name)
+		}
+		if err := project.Check(ctx, q, projectID); err != nil {
+			return Summary{}, errors.Wrapf(err, "failed to import project %q", p.Name)
+		}
+	}
+	return im.summary, nil
+}
+
+// checkConflicts looks for existing objects that would collide with the document.
+func (im *importer) checkConflicts(ctx context.Context, d Document) error {
+	for _, u := range d.Users {
+		existing, err := im.q.GetUserByEmailFold(ctx, u.Email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get user by email")
+		}
+		if !strings.EqualFold(existing.DisplayID.String(), u.ID) {
+			return validationErrorf("email %q belongs to user %s, not %s", u.Email, existing.DisplayID.String(), u.ID)
+		}
+	}
+
+	inDocument := func(id pgtype.UUID) bool {
+		return slices.ContainsFunc(d.Projects, func(p Project) bool { return strings.EqualFold(p.ID, id.String()) })
+	}
+	for _, p := range d.Projects {
+		existing, err := im.q.GetProjectByName(ctx, p.Name)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get project by name")
+		}
+		if strings.EqualFold(existing.DisplayID.String(), p.ID) {
+			if im.mode == ModeMerge {
+				if err := im.checkProjectConflicts(ctx, existing.ID, p); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		// With replace, a project that is not in the document is deleted before the import.
+		if im.mode == ModeMerge || inDocument(existing.DisplayID) {
+			return validationErrorf("project name %q belongs to project %s, not %s", p.Name, existing.DisplayID.String(), p.ID)
+		}
+	}
+	return nil
+}
+
+// checkProjectConflicts looks for roles and user groups of an existing project that have the
+// name of another role or user group of the document. With replace they are deleted instead.
+func (im *importer) checkProjectConflicts(ctx context.Context, projectID int64, p Project) error {
+	roles, err := im.q.ListRolesByProject(ctx, projectID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list roles by project")
+	}
+	for _, r := range p.Roles {
+		for _, existing := range roles {
+			if existing.Name == r.Name && !strings.EqualFold(existing.DisplayID.String(), r.ID) {
+				return validationErrorf("role name %q of project %q belongs to role %s, not %s", r.Name, p.Name, existing.DisplayID.String(), r.ID)
+			}
+		}
+	}
+	groups, err := im.q.ListUserGroupsByProject(ctx, projectID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list user groups by project")
+	}
+	for _, g := range p.UserGroups {
+		for _, existing := range groups {
+			if existing.Name == g.Name && !strings.EqualFold(existing.DisplayID.String(), g.ID) {
+				return validationErrorf("user group name %q of project %q belongs to user group %s, not %s", g.Name, p.Name, existing.DisplayID.String(), g.ID)
+			}
+		}
+	}
+	return nil
+}
+
+func (im *importer) importUser(ctx context.Context, u User) error {
+	displayID, err := parseID("user", u.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := im.q.GetUserByDisplayID(ctx, displayID); err == nil {
+		im.summary.Users.Updated++
+	} else if errors.Is(err, pgx.ErrNoRows) {
+		im.summary.Users.Created++
+	} else {
+		return errors.Wrapf(err, "failed to get user by display id")
+	}
+	id, err := im.q.ImportUser(ctx, admindb.ImportUserParams{DisplayID: displayID, Email: u.Email})
+	if err != nil {
+		return errors.Wrapf(err, "failed to import user")
+	}
+	im.users[u.ID] = id
+	return nil
+}
+
+func (im *importer) importAttributes(ctx context.Context, attributes []Attribute) error {
+	existing, err := im.q.ListRoleAttributes(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list role attributes")
+	}
+	for _, a := range attributes {
+		if slices.ContainsFunc(existing, func(e admindb.TacokumoAdminRoleAttribute) bool { return e.Name == a.Name }) {
+			im.summary.Attributes.Updated++
+		} else {
+			im.summary.Attributes.Created++
+		}
+		if err := im.q.ImportRoleAttribute(ctx, admindb.ImportRoleAttributeParams{Name: a.Name, Description: a.Description}); err != nil {
+			return errors.Wrapf(err, "failed to import role attribute")
+		}
+	}
+	return nil
+}
+
+// deleteMissing deletes the projects that are not in the document, and the roles and user
+// groups of the other projects that are not in it.
+func (im *importer) deleteMissing(ctx context.Context, d Document) error {
+	projects, err := im.q.ListAllProjects(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list projects")
+	}
+	for _, proj := range projects {
+		i := slices.IndexFunc(d.Projects, func(p Project) bool { return strings.EqualFold(p.ID, proj.DisplayID.String()) })
+		if i < 0 {
+			if err := im.q.DeleteProjectByID(ctx, proj.ID); err != nil {
+				return errors.Wrapf(err, "failed to delete project")
+			}
+			im.summary.Projects.Deleted++
+			continue
+		}
+		p := d.Projects[i]
+
+		groups, err := im.q.ListUserGroupsByProject(ctx, proj.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list user groups by project")
+		}
+		for _, g := range groups {
+			if slices.ContainsFunc(p.UserGroups, func(dg UserGroup) bool { return strings.EqualFold(dg.ID, g.DisplayID.String()) }) {
+				continue
+			}
+			if err := im.q.DeleteUserGroupByID(ctx, g.ID); err != nil {
+				return errors.Wrapf(err, "failed to delete user group")
+			}
+			im.summary.UserGroups.Deleted++
+		}
+		roles, err := im.q.ListRolesByProject(ctx, proj.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list roles by project")
+		}
+		for _, r := range roles {
+			if slices.ContainsFunc(p.Roles, func(dr Role) bool { return strings.EqualFold(dr.ID, r.DisplayID.String()) }) {
+				continue
+			}
+			if err := im.q.DeleteRoleByID(ctx, r.ID); err != nil {
+				return errors.Wrapf(err, "failed to delete role")
+			}
+			im.summary.Roles.Deleted++
+		}
+	}
+	return nil
+}
+
+func (im *importer) importProject(ctx context.Context, p Project) (int64, error) {
+	displayID, err := parseID("project", p.ID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := im.q.GetProjectByDisplayID(ctx, displayID); err == nil {
+		im.summary.Projects.Updated++
+	} else if errors.Is(err, pgx.ErrNoRows) {
+		im.summary.Projects.Created++
+	} else {
+		return 0, errors.Wrapf(err, "failed to get project by display id")
+	}
+	labelsJSON, annotationsJSON, err := encodeMetadata(p.Labels, p.Annotations)
+	if err != nil {
+		return 0, err
+	}
+	projectID, err := im.q.ImportProject(ctx, admindb.ImportProjectParams{
+		DisplayID:   displayID,
+		Name:        p.Name,
+		Description: p.Description,
+		Kind:        p.Kind,
+		ArchivedAt:  timestamptz(p.ArchivedAt),
+		ArchivedBy:  text(p.ArchivedBy),
+		Labels:      labelsJSON,
+		Annotations: annotationsJSON,
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to import project")
+	}
+
+	if im.mode == ModeReplace {
+		if err := im.q.DeleteProjectOwners(ctx, projectID); err != nil {
+			return 0, errors.Wrapf(err, "failed to delete project owners")
+		}
+		if err := im.q.DeleteProjectOwnerGroups(ctx, projectID); err != nil {
+			return 0, errors.Wrapf(err, "failed to delete project owner groups")
+		}
+	}
+	for _, owner := range p.Owners {
+		if err := im.q.AddProjectOwner(ctx, admindb.AddProjectOwnerParams{ProjectID: projectID, UserID: im.users[owner]}); err != nil {
+			return 0, errors.Wrapf(err, "failed to add project owner")
+		}
+	}
+
+	roles := map[string]int64{}
+	for _, r := range p.Roles {
+		if roles[r.ID], err = im.importRole(ctx, projectID, r); err != nil {
+			return 0, err
+		}
+	}
+	for _, g := range p.UserGroups {
+		if err := im.importUserGroup(ctx, projectID, g, roles); err != nil {
+			return 0, err
+		}
+	}
+	return projectID, nil
+}
+
+func (im *importer) importRole(ctx context.Context, projectID int64, r Role) (int64, error) {
+	displayID, err := parseID("role", r.ID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := im.q.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{ProjectID: projectID, DisplayID: displayID}); err == nil {
+		im.summary.Roles.Updated++
+	} else if errors.Is(err, pgx.ErrNoRows) {
+		im.summary.Roles.Created++
+	} else {
+		return 0, errors.Wrapf(err, "failed to get role by display id")
+	}
+	labelsJSON, annotationsJSON, err := encodeMetadata(r.Labels, r.Annotations)
+	if err != nil {
+		return 0, err
+	}
+	roleID, err := im.q.ImportRole(ctx, admindb.ImportRoleParams{
+		DisplayID:   displayID,
+		ProjectID:   projectID,
+		Name:        r.Name,
+		Description: r.Description,
+		Labels:      labelsJSON,
+		Annotations: annotationsJSON,
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to import role")
+	}
+
+	if im.mode == ModeReplace {
+		if err := im.q.DeleteRoleAttributeRelations(ctx, roleID); err != nil {
+			return 0, errors.Wrapf(err, "failed to delete role attributes")
+		}
+		if err := im.q.DeleteRoleUsers(ctx, roleID); err != nil {
+			return 0, errors.Wrapf(err, "failed to delete role users")
+		}
+	}
+	for _, attribute := range r.Attributes {
+		if _, err := im.q.AddRoleAttributeByName(ctx, admindb.AddRoleAttributeByNameParams{RoleID: roleID, Name: attribute}); err != nil {
+			return 0, errors.Wrapf(err, "failed to add role attribute")
+		}
+	}
+	for _, user := range r.Users {
+		if _, err := im.q.AssignRoleToUser(ctx, admindb.AssignRoleToUserParams{UserID: im.users[user], RoleID: roleID}); err != nil {
+			return 0, errors.Wrapf(err, "failed to assign role to user")
+		}
+	}
+	return roleID, nil
+}
+
+func (im *importer) importUserGroup(ctx context.Context, projectID int64, g UserGroup, roles map[string]int64) error {
+	displayID, err := parseID("user group", g.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := im.q.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{ProjectID: projectID, DisplayID: displayID}); err == nil {
+		im.summary.UserGroups.Updated++
+	} else if errors.Is(err, pgx.ErrNoRows) {
+		im.summary.UserGroups.Created++
+	} else {
+		return errors.Wrapf(err, "failed to get user group by display id")
+	}
+	labelsJSON, annotationsJSON, err := encodeMetadata(g.Labels, g.Annotations)
+	if err != nil {
+		return err
+	}
+	groupID, err := im.q.ImportUserGroup(ctx, admindb.ImportUserGroupParams{
+		DisplayID:   displayID,
+		ProjectID:   projectID,
+		Name:        g.Name,
+		Description: g.Description,
+		Labels:      labelsJSON,
+		Annotations: annotationsJSON,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to import user group")
+	}
+
+	if im.mode == ModeReplace {
+		if err := im.q.DeleteUserGroupMembers(ctx, groupID); err != nil {
+			return errors.Wrapf(err, "failed to delete user group members")
+		}
+		if err := im.q.DeleteUserGroupRoles(ctx, groupID); err != nil {
+			return errors.Wrapf(err, "failed to delete user group roles")
+		}
+	}
+	for _, role := range g.Roles {
+		if err := im.q.AssignRoleToUserGroup(ctx, admindb.AssignRoleToUserGroupParams{UsergroupID: groupID, RoleID: roles[role]}); err != nil {
+			return errors.Wrapf(err, "failed to assign role to user group")
+		}
+	}
+	for _, member := range g.Members {
+		if err := im.q.AddUserToUserGroup(ctx, admindb.AddUserToUserGroupParams{UserID: im.users[member], UsergroupID: groupID}); err != nil {
+			return errors.Wrapf(err, "failed to add user to user group")
+		}
+	}
+	if g.Owner {
+		if err := im.q.AddProjectOwnerGroup(ctx, admindb.AddProjectOwnerGroupParams{ProjectID: projectID, UsergroupID: groupID}); err != nil {
+			return errors.Wrapf(err, "failed to add project owner group")
+		}
+	}
+	return nil
+}
+
+// timestamptz converts an optional time of the document.
+func timestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
+// text converts an optional string of the document.
+func text(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *s, Valid: true}
+}
@@ -24,14 +24,63 @@ type TacokumoAdminGithubAccount struct {
 	UpdatedAt pgtype.Timestamptz
 }
 
+type TacokumoAdminInvitation struct {
+	ID             int64
+	DisplayID      pgtype.UUID
+	ProjectID      int64
+	Email          string
+	TokenHash      string
+	Status         string
+	InvitedBy      string
+	ExpiresAt      pgtype.Timestamptz
+	AcceptedAt     pgtype.Timestamptz
+	AcceptedUserID pgtype.Int8
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type TacokumoAdminInvitationRole struct {
+	InvitationID int64
+	RoleID       int64
+}
+
+type TacokumoAdminInvitationUsergroup struct {
+	InvitationID int64
+	UsergroupID  int64
+}
+
+type TacokumoAdminJob struct {
+	ID          int64
+	DisplayID   pgtype.UUID
+	Kind        string
+	Payload     []byte
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	RunAt       pgtype.Timestamptz
+	LockedBy    pgtype.Text
+	LockedUntil pgtype.Timestamptz
+	UniqueKey   pgtype.Text
+	LastError   pgtype.Text
+	StartedAt   pgtype.Timestamptz
+	FinishedAt  pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
 type TacokumoAdminProject struct {
 	ID          int64
 	DisplayID   pgtype.UUID
 	Name        string
 	Description string
 	Kind        string
+	Version     int64
 	CreatedAt   pgtype.Timestamptz
 	UpdatedAt   pgtype.Timestamptz
+	ArchivedAt  pgtype.Timestamptz
+	ArchivedBy  pgtype.Text
+	Labels      []byte
+	Annotations []byte
 }
 
 type TacokumoAdminProjectOwner struct {
@@ -42,14 +91,63 @@ type TacokumoAdminProjectOwner struct {
 	UpdatedAt pgtype.Timestamptz
 }
 
+type TacokumoAdminProjectOwnerGroup struct {
+	ID          int64
+	ProjectID   int64
+	UsergroupID int64
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type TacokumoAdminProjectTemplate struct {
+	ID              int64
+	DisplayID       pgtype.UUID
+	Name            string
+	Description     string
+	Kind            string
+	Spec            []byte
+	SourceProjectID pgtype.Int8
+	CreatedBy       string
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+}
+
+type TacokumoAdminProjectTransfer struct {
+	ID          int64
+	DisplayID   pgtype.UUID
+	ProjectID   int64
+	FromUserID  int64
+	ToUserID    int64
+	Status      string
+	InitiatedBy string
+	ExpiresAt   pgtype.Timestamptz
+	CompletedAt pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type TacokumoAdminProjectTransferAuditLog struct {
+	ID                int64
+	ProjectDisplayID  pgtype.UUID
+	TransferDisplayID pgtype.UUID
+	Action            string
+	FromUserDisplayID pgtype.UUID
+	ToUserDisplayID   pgtype.UUID
+	Actor             string
+	CreatedAt         pgtype.Timestamptz
+}
+
 type TacokumoAdminRole struct {
 	ID          int64
 	DisplayID   pgtype.UUID
 	ProjectID   int64
 	Name        string
 	Description string
+	Version     int64
 	CreatedAt   pgtype.Timestamptz
 	UpdatedAt   pgtype.Timestamptz
+	Labels      []byte
+	Annotations []byte
 }
 
 type TacokumoAdminRoleAttribute struct {
@@ -68,6 +166,64 @@ type TacokumoAdminRoleAttributesRelation struct {
 	UpdatedAt       pgtype.Timestamptz
 }
 
+type TacokumoAdminScimGroup struct {
+	UsergroupID int64
+	ExternalID  pgtype.Text
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type TacokumoAdminScimUser struct {
+	UserID      int64
+	ExternalID  pgtype.Text
+	DisplayName string
+	Active      bool
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type TacokumoAdminServiceAccount struct {
+	ID          int64
+	DisplayID   pgtype.UUID
+	ProjectID   pgtype.Int8
+	Name        string
+	Description string
+	OwnerUserID pgtype.Int8
+	Disabled    bool
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type TacokumoAdminServiceAccountRoleRelation struct {
+	ID               int64
+	ServiceAccountID int64
+	RoleID           int64
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type TacokumoAdminServiceAccountToken struct {
+	ID               int64
+	DisplayID        pgtype.UUID
+	ServiceAccountID int64
+	Description      string
+	TokenPrefix      string
+	TokenHash        string
+	ExpiresAt        pgtype.Timestamptz
+	LastUsedAt       pgtype.Timestamptz
+	RevokedAt        pgtype.Timestamptz
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type TacokumoAdminServiceAccountUsergroupsRelation struct {
+	ID               int64
+	ServiceAccountID int64
+	UsergroupID      int64
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
 type TacokumoAdminUser struct {
 	ID        int64
 	DisplayID pgtype.UUID
@@ -98,8 +254,11 @@ type TacokumoAdminUsergroup struct {
 	ProjectID   int64
 	Name        string
 	Description string
+	Version     int64
 	CreatedAt   pgtype.Timestamptz
 	UpdatedAt   pgtype.Timestamptz
+	Labels      []byte
+	Annotations []byte
 }
 
 type TacokumoAdminUsergroupRoleRelation struct {
@@ -109,3 +268,33 @@ type TacokumoAdminUsergroupRoleRelation struct {
 	CreatedAt   pgtype.Timestamptz
 	UpdatedAt   pgtype.Timestamptz
 }
+
+type TacokumoAdminWebhookDelivery struct {
+	ID             int64
+	DisplayID      pgtype.UUID
+	SubscriptionID int64
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamptz
+	LastAttemptAt  pgtype.Timestamptz
+	LastStatusCode pgtype.Int4
+	LastError      pgtype.Text
+	DeliveredAt    pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type TacokumoAdminWebhookSubscription struct {
+	ID          int64
+	DisplayID   pgtype.UUID
+	ProjectID   int64
+	Url         string
+	Description string
+	EventTypes  []string
+	Secret      string
+	Disabled    bool
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
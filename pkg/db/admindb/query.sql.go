@@ -11,259 +11,336 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const checkDBConnection = `-- name: CheckDBConnection :one
-SELECT 1
+const addInvitationRole = `-- name: AddInvitationRole :exec
+INSERT INTO tacokumo_admin.invitation_roles (invitation_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT (invitation_id, role_id) DO NOTHING
 `
 
-// CheckDBConnection
+type AddInvitationRoleParams struct {
+	InvitationID int64
+	RoleID       int64
+}
+
+// AddInvitationRole
 //
-//	SELECT 1
-func (q *Queries) CheckDBConnection(ctx context.Context) (int32, error) {
-	row := q.db.QueryRow(ctx, checkDBConnection)
-	var column_1 int32
-	err := row.Scan(&column_1)
-	return column_1, err
+//	INSERT INTO tacokumo_admin.invitation_roles (invitation_id, role_id)
+//	VALUES ($1, $2)
+//	ON CONFLICT (invitation_id, role_id) DO NOTHING
+func (q *Queries) AddInvitationRole(ctx context.Context, arg AddInvitationRoleParams) error {
+	_, err := q.db.Exec(ctx, addInvitationRole, arg.InvitationID, arg.RoleID)
+	return err
 }
 
-const createProject = `-- name: CreateProject :exec
-INSERT INTO tacokumo_admin.projects (name, description, kind) VALUES ($1, $2, $3)
+const addInvitationUserGroup = `-- name: AddInvitationUserGroup :exec
+INSERT INTO tacokumo_admin.invitation_usergroups (invitation_id, usergroup_id)
+VALUES ($1, $2)
+ON CONFLICT (invitation_id, usergroup_id) DO NOTHING
 `
 
-type CreateProjectParams struct {
-	Name        string
-	Description string
-	Kind        string
+type AddInvitationUserGroupParams struct {
+	InvitationID int64
+	UsergroupID  int64
 }
 
-// CreateProject
+// AddInvitationUserGroup
 //
-//	INSERT INTO tacokumo_admin.projects (name, description, kind) VALUES ($1, $2, $3)
-func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) error {
-	_, err := q.db.Exec(ctx, createProject, arg.Name, arg.Description, arg.Kind)
+//	INSERT INTO tacokumo_admin.invitation_usergroups (invitation_id, usergroup_id)
+//	VALUES ($1, $2)
+//	ON CONFLICT (invitation_id, usergroup_id) DO NOTHING
+func (q *Queries) AddInvitationUserGroup(ctx context.Context, arg AddInvitationUserGroupParams) error {
+	_, err := q.db.Exec(ctx, addInvitationUserGroup, arg.InvitationID, arg.UsergroupID)
 	return err
 }
 
-const createRole = `-- name: CreateRole :exec
-INSERT INTO tacokumo_admin.roles (project_id, name, description) VALUES ($1, $2, $3)
+const addProjectOwner = `-- name: AddProjectOwner :exec
+INSERT INTO tacokumo_admin.project_owners (project_id, user_id) VALUES ($1, $2)
+ON CONFLICT (project_id, user_id) DO NOTHING
 `
 
-type CreateRoleParams struct {
-	ProjectID   int64
-	Name        string
-	Description string
+type AddProjectOwnerParams struct {
+	ProjectID int64
+	UserID    int64
 }
 
-// CreateRole
+// AddProjectOwner
 //
-//	INSERT INTO tacokumo_admin.roles (project_id, name, description) VALUES ($1, $2, $3)
-func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) error {
-	_, err := q.db.Exec(ctx, createRole, arg.ProjectID, arg.Name, arg.Description)
+//	INSERT INTO tacokumo_admin.project_owners (project_id, user_id) VALUES ($1, $2)
+//	ON CONFLICT (project_id, user_id) DO NOTHING
+func (q *Queries) AddProjectOwner(ctx context.Context, arg AddProjectOwnerParams) error {
+	_, err := q.db.Exec(ctx, addProjectOwner, arg.ProjectID, arg.UserID)
 	return err
 }
 
-const createUser = `-- name: CreateUser :exec
-INSERT INTO tacokumo_admin.users (email) VALUES ($1)
+const addProjectOwnerGroup = `-- name: AddProjectOwnerGroup :exec
+INSERT INTO tacokumo_admin.project_owner_groups (project_id, usergroup_id) VALUES ($1, $2)
+ON CONFLICT (project_id, usergroup_id) DO NOTHING
 `
 
-// CreateUser
+type AddProjectOwnerGroupParams struct {
+	ProjectID   int64
+	UsergroupID int64
+}
+
+// AddProjectOwnerGroup
 //
-//	INSERT INTO tacokumo_admin.users (email) VALUES ($1)
-func (q *Queries) CreateUser(ctx context.Context, email string) error {
-	_, err := q.db.Exec(ctx, createUser, email)
+//	INSERT INTO tacokumo_admin.project_owner_groups (project_id, usergroup_id) VALUES ($1, $2)
+//	ON CONFLICT (project_id, usergroup_id) DO NOTHING
+func (q *Queries) AddProjectOwnerGroup(ctx context.Context, arg AddProjectOwnerGroupParams) error {
+	_, err := q.db.Exec(ctx, addProjectOwnerGroup, arg.ProjectID, arg.UsergroupID)
 	return err
 }
 
-const createUserGroup = `-- name: CreateUserGroup :exec
-INSERT INTO tacokumo_admin.usergroups (project_id, name, description) VALUES ($1, $2, $3)
+const addRoleAttributeByName = `-- name: AddRoleAttributeByName :execrows
+INSERT INTO tacokumo_admin.role_attributes_relations (role_id, role_attribute_id)
+SELECT $1, ra.id FROM tacokumo_admin.role_attributes ra WHERE ra.name = $2
+ON CONFLICT (role_id, role_attribute_id) DO NOTHING
 `
 
-type CreateUserGroupParams struct {
-	ProjectID   int64
-	Name        string
-	Description string
+type AddRoleAttributeByNameParams struct {
+	RoleID int64
+	Name   string
 }
 
-// CreateUserGroup
+// Affects no rows when the attribute is not defined.
 //
-//	INSERT INTO tacokumo_admin.usergroups (project_id, name, description) VALUES ($1, $2, $3)
-func (q *Queries) CreateUserGroup(ctx context.Context, arg CreateUserGroupParams) error {
-	_, err := q.db.Exec(ctx, createUserGroup, arg.ProjectID, arg.Name, arg.Description)
+//	INSERT INTO tacokumo_admin.role_attributes_relations (role_id, role_attribute_id)
+//	SELECT $1, ra.id FROM tacokumo_admin.role_attributes ra WHERE ra.name = $2
+//	ON CONFLICT (role_id, role_attribute_id) DO NOTHING
+func (q *Queries) AddRoleAttributeByName(ctx context.Context, arg AddRoleAttributeByNameParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addRoleAttributeByName, arg.RoleID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const addServiceAccountToUserGroup = `-- name: AddServiceAccountToUserGroup :execrows
+INSERT INTO tacokumo_admin.service_account_usergroups_relations (service_account_id, usergroup_id)
+VALUES ($1, $2)
+ON CONFLICT (service_account_id, usergroup_id) DO NOTHING
+`
+
+type AddServiceAccountToUserGroupParams struct {
+	ServiceAccountID int64
+	UsergroupID      int64
+}
+
+// AddServiceAccountToUserGroup
+//
+//	INSERT INTO tacokumo_admin.service_account_usergroups_relations (service_account_id, usergroup_id)
+//	VALUES ($1, $2)
+//	ON CONFLICT (service_account_id, usergroup_id) DO NOTHING
+func (q *Queries) AddServiceAccountToUserGroup(ctx context.Context, arg AddServiceAccountToUserGroupParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addServiceAccountToUserGroup, arg.ServiceAccountID, arg.UsergroupID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const addUserToUserGroup = `-- name: AddUserToUserGroup :exec
+INSERT INTO tacokumo_admin.user_usergroups_relations (user_id, usergroup_id) VALUES ($1, $2)
+ON CONFLICT (user_id, usergroup_id) DO NOTHING
+`
+
+type AddUserToUserGroupParams struct {
+	UserID      int64
+	UsergroupID int64
+}
+
+// AddUserToUserGroup
+//
+//	INSERT INTO tacokumo_admin.user_usergroups_relations (user_id, usergroup_id) VALUES ($1, $2)
+//	ON CONFLICT (user_id, usergroup_id) DO NOTHING
+func (q *Queries) AddUserToUserGroup(ctx context.Context, arg AddUserToUserGroupParams) error {
+	_, err := q.db.Exec(ctx, addUserToUserGroup, arg.UserID, arg.UsergroupID)
 	return err
 }
 
-const getProjectByDisplayID = `-- name: GetProjectByDisplayID :one
-SELECT id, display_id, name, description, kind, created_at, updated_at
-FROM tacokumo_admin.projects
+const archiveProject = `-- name: ArchiveProject :execrows
+UPDATE tacokumo_admin.projects
+SET archived_at = NOW(),
+    archived_by = $2,
+    version = version + 1,
+    updated_at = NOW()
 WHERE display_id = $1
+  AND archived_at IS NULL
+  AND ($3::BIGINT[] IS NULL OR version = ANY($3::BIGINT[]))
 `
 
-// GetProjectByDisplayID
+type ArchiveProjectParams struct {
+	DisplayID        pgtype.UUID
+	ArchivedBy       pgtype.Text
+	ExpectedVersions []int64
+}
+
+// expected_versions is the list of versions from If-Match; NULL skips the check.
 //
-//	SELECT id, display_id, name, description, kind, created_at, updated_at
-//	FROM tacokumo_admin.projects
+//	UPDATE tacokumo_admin.projects
+//	SET archived_at = NOW(),
+//	    archived_by = $2,
+//	    version = version + 1,
+//	    updated_at = NOW()
 //	WHERE display_id = $1
-func (q *Queries) GetProjectByDisplayID(ctx context.Context, displayID pgtype.UUID) (TacokumoAdminProject, error) {
-	row := q.db.QueryRow(ctx, getProjectByDisplayID, displayID)
-	var i TacokumoAdminProject
-	err := row.Scan(
-		&i.ID,
-		&i.DisplayID,
-		&i.Name,
-		&i.Description,
-		&i.Kind,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+//	  AND archived_at IS NULL
+//	  AND ($3::BIGINT[] IS NULL OR version = ANY($3::BIGINT[]))
+func (q *Queries) ArchiveProject(ctx context.Context, arg ArchiveProjectParams) (int64, error) {
+	result, err := q.db.Exec(ctx, archiveProject, arg.DisplayID, arg.ArchivedBy, arg.ExpectedVersions)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const getProjectByName = `-- name: GetProjectByName :one
-SELECT id, display_id, name, description, kind, created_at, updated_at
-FROM tacokumo_admin.projects
-WHERE name = $1
+const assignRoleToServiceAccount = `-- name: AssignRoleToServiceAccount :execrows
+INSERT INTO tacokumo_admin.service_account_role_relations (service_account_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT (service_account_id, role_id) DO NOTHING
 `
 
-// GetProjectByName
+type AssignRoleToServiceAccountParams struct {
+	ServiceAccountID int64
+	RoleID           int64
+}
+
+// AssignRoleToServiceAccount
 //
-//	SELECT id, display_id, name, description, kind, created_at, updated_at
-//	FROM tacokumo_admin.projects
-//	WHERE name = $1
-func (q *Queries) GetProjectByName(ctx context.Context, name string) (TacokumoAdminProject, error) {
-	row := q.db.QueryRow(ctx, getProjectByName, name)
-	var i TacokumoAdminProject
-	err := row.Scan(
-		&i.ID,
-		&i.DisplayID,
-		&i.Name,
-		&i.Description,
-		&i.Kind,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+//	INSERT INTO tacokumo_admin.service_account_role_relations (service_account_id, role_id)
+//	VALUES ($1, $2)
+//	ON CONFLICT (service_account_id, role_id) DO NOTHING
+func (q *Queries) AssignRoleToServiceAccount(ctx context.Context, arg AssignRoleToServiceAccountParams) (int64, error) {
+	result, err := q.db.Exec(ctx, assignRoleToServiceAccount, arg.ServiceAccountID, arg.RoleID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const getRoleByDisplayID = `-- name: GetRoleByDisplayID :one
-SELECT id, display_id, project_id, name, description, created_at, updated_at
-FROM tacokumo_admin.roles
-WHERE project_id = $1 AND display_id = $2
+const assignRoleToUser = `-- name: AssignRoleToUser :execrows
+INSERT INTO tacokumo_admin.user_role_relations (user_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, role_id) DO NOTHING
 `
 
-type GetRoleByDisplayIDParams struct {
-	ProjectID int64
-	DisplayID pgtype.UUID
+type AssignRoleToUserParams struct {
+	UserID int64
+	RoleID int64
 }
 
-// GetRoleByDisplayID
+// AssignRoleToUser
 //
-//	SELECT id, display_id, project_id, name, description, created_at, updated_at
-//	FROM tacokumo_admin.roles
-//	WHERE project_id = $1 AND display_id = $2
-func (q *Queries) GetRoleByDisplayID(ctx context.Context, arg GetRoleByDisplayIDParams) (TacokumoAdminRole, error) {
-	row := q.db.QueryRow(ctx, getRoleByDisplayID, arg.ProjectID, arg.DisplayID)
-	var i TacokumoAdminRole
-	err := row.Scan(
-		&i.ID,
-		&i.DisplayID,
-		&i.ProjectID,
-		&i.Name,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+//	INSERT INTO tacokumo_admin.user_role_relations (user_id, role_id)
+//	VALUES ($1, $2)
+//	ON CONFLICT (user_id, role_id) DO NOTHING
+func (q *Queries) AssignRoleToUser(ctx context.Context, arg AssignRoleToUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, assignRoleToUser, arg.UserID, arg.RoleID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, display_id, email, created_at, updated_at
-FROM tacokumo_admin.users
-WHERE email = $1
+const assignRoleToUserGroup = `-- name: AssignRoleToUserGroup :exec
+INSERT INTO tacokumo_admin.usergroup_role_relations (usergroup_id, role_id) VALUES ($1, $2)
+ON CONFLICT (usergroup_id, role_id) DO NOTHING
 `
 
-// GetUserByEmail
+type AssignRoleToUserGroupParams struct {
+	UsergroupID int64
+	RoleID      int64
+}
+
+// AssignRoleToUserGroup
 //
-//	SELECT id, display_id, email, created_at, updated_at
-//	FROM tacokumo_admin.users
-//	WHERE email = $1
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (TacokumoAdminUser, error) {
-	row := q.db.QueryRow(ctx, getUserByEmail, email)
-	var i TacokumoAdminUser
-	err := row.Scan(
-		&i.ID,
-		&i.DisplayID,
-		&i.Email,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+//	INSERT INTO tacokumo_admin.usergroup_role_relations (usergroup_id, role_id) VALUES ($1, $2)
+//	ON CONFLICT (usergroup_id, role_id) DO NOTHING
+func (q *Queries) AssignRoleToUserGroup(ctx context.Context, arg AssignRoleToUserGroupParams) error {
+	_, err := q.db.Exec(ctx, assignRoleToUserGroup, arg.UsergroupID, arg.RoleID)
+	return err
 }
 
-const getUserGroupByDisplayID = `-- name: GetUserGroupByDisplayID :one
-SELECT id, display_id, project_id, name, description, created_at, updated_at
-FROM tacokumo_admin.usergroups
-WHERE project_id = $1 AND display_id = $2
+const checkDBConnection = `-- name: CheckDBConnection :one
+SELECT 1
 `
 
-type GetUserGroupByDisplayIDParams struct {
-	ProjectID int64
-	DisplayID pgtype.UUID
-}
-
-// GetUserGroupByDisplayID
+// CheckDBConnection
 //
-//	SELECT id, display_id, project_id, name, description, created_at, updated_at
-//	FROM tacokumo_admin.usergroups
-//	WHERE project_id = $1 AND display_id = $2
-func (q *Queries) GetUserGroupByDisplayID(ctx context.Context, arg GetUserGroupByDisplayIDParams) (TacokumoAdminUsergroup, error) {
-	row := q.db.QueryRow(ctx, getUserGroupByDisplayID, arg.ProjectID, arg.DisplayID)
-	var i TacokumoAdminUsergroup
-	err := row.Scan(
-		&i.ID,
-		&i.DisplayID,
-		&i.ProjectID,
-		&i.Name,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+//	SELECT 1
+func (q *Queries) CheckDBConnection(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, checkDBConnection)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
 }
 
-const listProjectsWithPagination = `-- name: ListProjectsWithPagination :many
-SELECT id, display_id, name, description, kind, created_at, updated_at
-FROM tacokumo_admin.projects
-ORDER BY created_at DESC
-LIMIT $1 OFFSET $2
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE tacokumo_admin.webhook_deliveries d
+SET next_attempt_at = NOW() + $1::INTERVAL, updated_at = NOW()
+FROM tacokumo_admin.webhook_subscriptions ws
+WHERE d.subscription_id = ws.id
+  AND NOT ws.disabled
+  AND d.id IN (
+    SELECT due.id FROM tacokumo_admin.webhook_deliveries due
+    INNER JOIN tacokumo_admin.webhook_subscriptions dws ON dws.id = due.subscription_id
+    WHERE due.status = 'pending' AND due.next_attempt_at <= NOW() AND NOT dws.disabled
+    ORDER BY due.next_attempt_at
+    LIMIT $2::INT
+    FOR UPDATE OF due SKIP LOCKED
+  )
+RETURNING d.id, d.display_id, d.event_type, d.payload, d.attempts, ws.url, ws.secret
 `
 
-type ListProjectsWithPaginationParams struct {
-	Limit  int32
-	Offset int32
+type ClaimDueWebhookDeliveriesParams struct {
+	Lease     pgtype.Interval
+	BatchSize int32
 }
 
-// ListProjectsWithPagination
+type ClaimDueWebhookDeliveriesRow struct {
+	ID        int64
+	DisplayID pgtype.UUID
+	EventType string
+	Payload   []byte
+	Attempts  int32
+	Url       string
+	Secret    string
+}
+
+// Leases up to batch_size due deliveries by pushing next_attempt_at forward, so that other
+// replicas skip them while they are being delivered. Deliveries of disabled webhooks stay
+// pending until the webhook is enabled again.
 //
-//	SELECT id, display_id, name, description, kind, created_at, updated_at
-//	FROM tacokumo_admin.projects
-//	ORDER BY created_at DESC
-//	LIMIT $1 OFFSET $2
-func (q *Queries) ListProjectsWithPagination(ctx context.Context, arg ListProjectsWithPaginationParams) ([]TacokumoAdminProject, error) {
-	rows, err := q.db.Query(ctx, listProjectsWithPagination, arg.Limit, arg.Offset)
+//	UPDATE tacokumo_admin.webhook_deliveries d
+//	SET next_attempt_at = NOW() + $1::INTERVAL, updated_at = NOW()
+//	FROM tacokumo_admin.webhook_subscriptions ws
+//	WHERE d.subscription_id = ws.id
+//	  AND NOT ws.disabled
+//	  AND d.id IN (
+//	    SELECT due.id FROM tacokumo_admin.webhook_deliveries due
+//	    INNER JOIN tacokumo_admin.webhook_subscriptions dws ON dws.id = due.subscription_id
+//	    WHERE due.status = 'pending' AND due.next_attempt_at <= NOW() AND NOT dws.disabled
+//	    ORDER BY due.next_attempt_at
+//	    LIMIT $2::INT
+//	    FOR UPDATE OF due SKIP LOCKED
+//	  )
+//	RETURNING d.id, d.display_id, d.event_type, d.payload, d.attempts, ws.url, ws.secret
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimDueWebhookDeliveries, arg.Lease, arg.BatchSize)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TacokumoAdminProject
+	var items []ClaimDueWebhookDeliveriesRow
 	for rows.Next() {
-		var i TacokumoAdminProject
+		var i ClaimDueWebhookDeliveriesRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.DisplayID,
-			&i.Name,
-			&i.Description,
-			&i.Kind,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
 		); err != nil {
 			return nil, err
 		}
@@ -275,42 +352,80 @@ func (q *Queries) ListProjectsWithPagination(ctx context.Context, arg ListProjec
 	return items, nil
 }
 
-const listRolesWithPagination = `-- name: ListRolesWithPagination :many
-SELECT id, display_id, project_id, name, description, created_at, updated_at
-FROM tacokumo_admin.roles
-WHERE project_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+const claimJobs = `-- name: ClaimJobs :many
+UPDATE tacokumo_admin.jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_by = $1,
+    locked_until = NOW() + $2::INTERVAL,
+    started_at = NOW(),
+    updated_at = NOW()
+WHERE id IN (
+  SELECT id FROM tacokumo_admin.jobs
+  WHERE kind = ANY($3::TEXT[])
+    AND ((status = 'pending' AND run_at <= NOW()) OR (status = 'running' AND locked_until < NOW()))
+  ORDER BY run_at
+  LIMIT $4::INT
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
 `
 
-type ListRolesWithPaginationParams struct {
-	ProjectID int64
-	Limit     int32
-	Offset    int32
+type ClaimJobsParams struct {
+	Worker    pgtype.Text
+	Lease     pgtype.Interval
+	Kinds     []string
+	BatchSize int32
 }
 
-// ListRolesWithPagination
+// Leases runnable jobs to a worker. Running jobs whose lease expired are picked up again,
+// which covers workers that died while running a job.
 //
-//	SELECT id, display_id, project_id, name, description, created_at, updated_at
-//	FROM tacokumo_admin.roles
-//	WHERE project_id = $1
-//	ORDER BY created_at DESC
-//	LIMIT $2 OFFSET $3
-func (q *Queries) ListRolesWithPagination(ctx context.Context, arg ListRolesWithPaginationParams) ([]TacokumoAdminRole, error) {
-	rows, err := q.db.Query(ctx, listRolesWithPagination, arg.ProjectID, arg.Limit, arg.Offset)
+//	UPDATE tacokumo_admin.jobs
+//	SET status = 'running',
+//	    attempts = attempts + 1,
+//	    locked_by = $1,
+//	    locked_until = NOW() + $2::INTERVAL,
+//	    started_at = NOW(),
+//	    updated_at = NOW()
+//	WHERE id IN (
+//	  SELECT id FROM tacokumo_admin.jobs
+//	  WHERE kind = ANY($3::TEXT[])
+//	    AND ((status = 'pending' AND run_at <= NOW()) OR (status = 'running' AND locked_until < NOW()))
+//	  ORDER BY run_at
+//	  LIMIT $4::INT
+//	  FOR UPDATE SKIP LOCKED
+//	)
+//	RETURNING id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
+func (q *Queries) ClaimJobs(ctx context.Context, arg ClaimJobsParams) ([]TacokumoAdminJob, error) {
+	rows, err := q.db.Query(ctx, claimJobs,
+		arg.Worker,
+		arg.Lease,
+		arg.Kinds,
+		arg.BatchSize,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TacokumoAdminRole
+	var items []TacokumoAdminJob
 	for rows.Next() {
-		var i TacokumoAdminRole
+		var i TacokumoAdminJob
 		if err := rows.Scan(
 			&i.ID,
 			&i.DisplayID,
-			&i.ProjectID,
-			&i.Name,
-			&i.Description,
+			&i.Kind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.LockedBy,
+			&i.LockedUntil,
+			&i.UniqueKey,
+			&i.LastError,
+			&i.StartedAt,
+			&i.FinishedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -324,31 +439,3686 @@ func (q *Queries) ListRolesWithPagination(ctx context.Context, arg ListRolesWith
 	return items, nil
 }
 
-const listUserGroupMembers = `-- name: ListUserGroupMembers :many
-SELECT u.id,
-      u.display_id,
-      u.email,
-      u.created_at,
-      u.updated_at
-  FROM tacokumo_admin.users u
-  INNER JOIN tacokumo_admin.user_usergroups_relations uur ON u.id = uur.user_id
-  WHERE uur.usergroup_id = $1
-  ORDER BY u.created_at DESC
+const completeJob = `-- name: CompleteJob :exec
+UPDATE tacokumo_admin.jobs
+SET status = 'succeeded', locked_by = NULL, locked_until = NULL, last_error = NULL, finished_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND locked_by = $2
 `
 
-// ListUserGroupMembers
+type CompleteJobParams struct {
+	ID       int64
+	LockedBy pgtype.Text
+}
+
+// CompleteJob
 //
-//	SELECT u.id,
-//	      u.display_id,
-//	      u.email,
-//	      u.created_at,
-//	      u.updated_at
-//	  FROM tacokumo_admin.users u
-//	  INNER JOIN tacokumo_admin.user_usergroups_relations uur ON u.id = uur.user_id
-//	  WHERE uur.usergroup_id = $1
-//	  ORDER BY u.created_at DESC
-func (q *Queries) ListUserGroupMembers(ctx context.Context, usergroupID int64) ([]TacokumoAdminUser, error) {
-	rows, err := q.db.Query(ctx, listUserGroupMembers, usergroupID)
+//	UPDATE tacokumo_admin.jobs
+//	SET status = 'succeeded', locked_by = NULL, locked_until = NULL, last_error = NULL, finished_at = NOW(), updated_at = NOW()
+//	WHERE id = $1 AND locked_by = $2
+func (q *Queries) CompleteJob(ctx context.Context, arg CompleteJobParams) error {
+	_, err := q.db.Exec(ctx, completeJob, arg.ID, arg.LockedBy)
+	return err
+}
+
+const completeProjectTransfer = `-- name: CompleteProjectTransfer :execrows
+UPDATE tacokumo_admin.project_transfers
+SET status = $2,
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'pending'
+`
+
+type CompleteProjectTransferParams struct {
+	ID     int64
+	Status string
+}
+
+// CompleteProjectTransfer
+//
+//	UPDATE tacokumo_admin.project_transfers
+//	SET status = $2,
+//	    completed_at = NOW(),
+//	    updated_at = NOW()
+//	WHERE id = $1 AND status = 'pending'
+func (q *Queries) CompleteProjectTransfer(ctx context.Context, arg CompleteProjectTransferParams) (int64, error) {
+	result, err := q.db.Exec(ctx, completeProjectTransfer, arg.ID, arg.Status)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const copyUserGroupRolesToUser = `-- name: CopyUserGroupRolesToUser :many
+WITH inserted AS (
+  INSERT INTO tacokumo_admin.user_role_relations (user_id, role_id)
+  SELECT DISTINCT uur.user_id, ugr.role_id
+    FROM tacokumo_admin.usergroup_role_relations ugr
+    INNER JOIN tacokumo_admin.usergroups ug ON ug.id = ugr.usergroup_id
+    INNER JOIN tacokumo_admin.user_usergroups_relations uur ON uur.usergroup_id = ug.id
+    WHERE ug.project_id = $1 AND uur.user_id = $2
+  ON CONFLICT (user_id, role_id) DO NOTHING
+  RETURNING role_id
+)
+SELECT ro.display_id
+  FROM tacokumo_admin.roles ro
+  INNER JOIN inserted i ON ro.id = i.role_id
+`
+
+type CopyUserGroupRolesToUserParams struct {
+	ProjectID int64
+	UserID    int64
+}
+
+// Assigns the roles a user holds through the user groups of a project to the user directly,
+// returning the display IDs of the newly assigned roles.
+//
+//	WITH inserted AS (
+//	  INSERT INTO tacokumo_admin.user_role_relations (user_id, role_id)
+//	  SELECT DISTINCT uur.user_id, ugr.role_id
+//	    FROM tacokumo_admin.usergroup_role_relations ugr
+//	    INNER JOIN tacokumo_admin.usergroups ug ON ug.id = ugr.usergroup_id
+//	    INNER JOIN tacokumo_admin.user_usergroups_relations uur ON uur.usergroup_id = ug.id
+//	    WHERE ug.project_id = $1 AND uur.user_id = $2
+//	  ON CONFLICT (user_id, role_id) DO NOTHING
+//	  RETURNING role_id
+//	)
+//	SELECT ro.display_id
+//	  FROM tacokumo_admin.roles ro
+//	  INNER JOIN inserted i ON ro.id = i.role_id
+func (q *Queries) CopyUserGroupRolesToUser(ctx context.Context, arg CopyUserGroupRolesToUserParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, copyUserGroupRolesToUser, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var display_id pgtype.UUID
+		if err := rows.Scan(&display_id); err != nil {
+			return nil, err
+		}
+		items = append(items, display_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countJobsByStatus = `-- name: CountJobsByStatus :many
+SELECT kind, status, COUNT(*) AS count
+FROM tacokumo_admin.jobs
+GROUP BY kind, status
+ORDER BY kind, status
+`
+
+type CountJobsByStatusRow struct {
+	Kind   string
+	Status string
+	Count  int64
+}
+
+// CountJobsByStatus
+//
+//	SELECT kind, status, COUNT(*) AS count
+//	FROM tacokumo_admin.jobs
+//	GROUP BY kind, status
+//	ORDER BY kind, status
+func (q *Queries) CountJobsByStatus(ctx context.Context) ([]CountJobsByStatusRow, error) {
+	rows, err := q.db.Query(ctx, countJobsByStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountJobsByStatusRow
+	for rows.Next() {
+		var i CountJobsByStatusRow
+		if err := rows.Scan(&i.Kind, &i.Status, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countProjectUserGroupServiceAccounts = `-- name: CountProjectUserGroupServiceAccounts :one
+SELECT COUNT(DISTINCT r.service_account_id)
+  FROM tacokumo_admin.service_account_usergroups_relations r
+  INNER JOIN tacokumo_admin.usergroups ug ON ug.id = r.usergroup_id
+  WHERE ug.project_id = $1
+`
+
+// CountProjectUserGroupServiceAccounts
+//
+//	SELECT COUNT(DISTINCT r.service_account_id)
+//	  FROM tacokumo_admin.service_account_usergroups_relations r
+//	  INNER JOIN tacokumo_admin.usergroups ug ON ug.id = r.usergroup_id
+//	  WHERE ug.project_id = $1
+func (q *Queries) CountProjectUserGroupServiceAccounts(ctx context.Context, projectID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countProjectUserGroupServiceAccounts, projectID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSCIMGroups = `-- name: CountSCIMGroups :one
+SELECT COUNT(*)
+FROM tacokumo_admin.usergroups g
+LEFT JOIN tacokumo_admin.scim_groups sg ON sg.usergroup_id = g.id
+WHERE g.project_id = $1
+  AND ($2::UUID IS NULL OR g.display_id = $2::UUID)
+  AND ($3::VARCHAR IS NULL OR g.name ILIKE $3::VARCHAR)
+  AND ($4::VARCHAR IS NULL OR sg.external_id LIKE $4::VARCHAR)
+`
+
+type CountSCIMGroupsParams struct {
+	ProjectID         int64
+	DisplayID         pgtype.UUID
+	NamePattern       pgtype.Text
+	ExternalIDPattern pgtype.Text
+}
+
+// CountSCIMGroups
+//
+//	SELECT COUNT(*)
+//	FROM tacokumo_admin.usergroups g
+//	LEFT JOIN tacokumo_admin.scim_groups sg ON sg.usergroup_id = g.id
+//	WHERE g.project_id = $1
+//	  AND ($2::UUID IS NULL OR g.display_id = $2::UUID)
+//	  AND ($3::VARCHAR IS NULL OR g.name ILIKE $3::VARCHAR)
+//	  AND ($4::VARCHAR IS NULL OR sg.external_id LIKE $4::VARCHAR)
+func (q *Queries) CountSCIMGroups(ctx context.Context, arg CountSCIMGroupsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSCIMGroups,
+		arg.ProjectID,
+		arg.DisplayID,
+		arg.NamePattern,
+		arg.ExternalIDPattern,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSCIMUsers = `-- name: CountSCIMUsers :one
+SELECT COUNT(*)
+FROM tacokumo_admin.users u
+LEFT JOIN tacokumo_admin.scim_users su ON su.user_id = u.id
+WHERE ($1::UUID IS NULL OR u.display_id = $1::UUID)
+  AND ($2::VARCHAR IS NULL OR u.email ILIKE $2::VARCHAR)
+  AND ($3::VARCHAR IS NULL OR su.external_id LIKE $3::VARCHAR)
+  AND ($4::VARCHAR IS NULL OR su.display_name ILIKE $4::VARCHAR)
+`
+
+type CountSCIMUsersParams struct {
+	DisplayID          pgtype.UUID
+	EmailPattern       pgtype.Text
+	ExternalIDPattern  pgtype.Text
+	DisplayNamePattern pgtype.Text
+}
+
+// CountSCIMUsers
+//
+//	SELECT COUNT(*)
+//	FROM tacokumo_admin.users u
+//	LEFT JOIN tacokumo_admin.scim_users su ON su.user_id = u.id
+//	WHERE ($1::UUID IS NULL OR u.display_id = $1::UUID)
+//	  AND ($2::VARCHAR IS NULL OR u.email ILIKE $2::VARCHAR)
+//	  AND ($3::VARCHAR IS NULL OR su.external_id LIKE $3::VARCHAR)
+//	  AND ($4::VARCHAR IS NULL OR su.display_name ILIKE $4::VARCHAR)
+func (q *Queries) CountSCIMUsers(ctx context.Context, arg CountSCIMUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSCIMUsers,
+		arg.DisplayID,
+		arg.EmailPattern,
+		arg.ExternalIDPattern,
+		arg.DisplayNamePattern,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createInvitation = `-- name: CreateInvitation :one
+INSERT INTO tacokumo_admin.invitations (project_id, email, token_hash, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+`
+
+type CreateInvitationParams struct {
+	ProjectID int64
+	Email     string
+	TokenHash string
+	InvitedBy string
+	ExpiresAt pgtype.Timestamptz
+}
+
+// CreateInvitation
+//
+//	INSERT INTO tacokumo_admin.invitations (project_id, email, token_hash, invited_by, expires_at)
+//	VALUES ($1, $2, $3, $4, $5)
+//	RETURNING id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationParams) (TacokumoAdminInvitation, error) {
+	row := q.db.QueryRow(ctx, createInvitation,
+		arg.ProjectID,
+		arg.Email,
+		arg.TokenHash,
+		arg.InvitedBy,
+		arg.ExpiresAt,
+	)
+	var i TacokumoAdminInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Email,
+		&i.TokenHash,
+		&i.Status,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedUserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createProject = `-- name: CreateProject :exec
+INSERT INTO tacokumo_admin.projects (name, description, kind) VALUES ($1, $2, $3)
+`
+
+type CreateProjectParams struct {
+	Name        string
+	Description string
+	Kind        string
+}
+
+// CreateProject
+//
+//	INSERT INTO tacokumo_admin.projects (name, description, kind) VALUES ($1, $2, $3)
+func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) error {
+	_, err := q.db.Exec(ctx, createProject, arg.Name, arg.Description, arg.Kind)
+	return err
+}
+
+const createProjectTemplate = `-- name: CreateProjectTemplate :one
+INSERT INTO tacokumo_admin.project_templates (name, description, kind, spec, source_project_id, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING display_id
+`
+
+type CreateProjectTemplateParams struct {
+	Name            string
+	Description     string
+	Kind            string
+	Spec            []byte
+	SourceProjectID pgtype.Int8
+	CreatedBy       string
+}
+
+// CreateProjectTemplate
+//
+//	INSERT INTO tacokumo_admin.project_templates (name, description, kind, spec, source_project_id, created_by)
+//	VALUES ($1, $2, $3, $4, $5, $6)
+//	RETURNING display_id
+func (q *Queries) CreateProjectTemplate(ctx context.Context, arg CreateProjectTemplateParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createProjectTemplate,
+		arg.Name,
+		arg.Description,
+		arg.Kind,
+		arg.Spec,
+		arg.SourceProjectID,
+		arg.CreatedBy,
+	)
+	var display_id pgtype.UUID
+	err := row.Scan(&display_id)
+	return display_id, err
+}
+
+const createProjectTransfer = `-- name: CreateProjectTransfer :one
+INSERT INTO tacokumo_admin.project_transfers (project_id, from_user_id, to_user_id, initiated_by, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+`
+
+type CreateProjectTransferParams struct {
+	ProjectID   int64
+	FromUserID  int64
+	ToUserID    int64
+	InitiatedBy string
+	ExpiresAt   pgtype.Timestamptz
+}
+
+// CreateProjectTransfer
+//
+//	INSERT INTO tacokumo_admin.project_transfers (project_id, from_user_id, to_user_id, initiated_by, expires_at)
+//	VALUES ($1, $2, $3, $4, $5)
+//	RETURNING id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+func (q *Queries) CreateProjectTransfer(ctx context.Context, arg CreateProjectTransferParams) (TacokumoAdminProjectTransfer, error) {
+	row := q.db.QueryRow(ctx, createProjectTransfer,
+		arg.ProjectID,
+		arg.FromUserID,
+		arg.ToUserID,
+		arg.InitiatedBy,
+		arg.ExpiresAt,
+	)
+	var i TacokumoAdminProjectTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.FromUserID,
+		&i.ToUserID,
+		&i.Status,
+		&i.InitiatedBy,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createProjectTransferAuditLog = `-- name: CreateProjectTransferAuditLog :exec
+INSERT INTO tacokumo_admin.project_transfer_audit_logs (project_display_id, transfer_display_id, action, from_user_display_id, to_user_display_id, actor)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateProjectTransferAuditLogParams struct {
+	ProjectDisplayID  pgtype.UUID
+	TransferDisplayID pgtype.UUID
+	Action            string
+	FromUserDisplayID pgtype.UUID
+	ToUserDisplayID   pgtype.UUID
+	Actor             string
+}
+
+// CreateProjectTransferAuditLog
+//
+//	INSERT INTO tacokumo_admin.project_transfer_audit_logs (project_display_id, transfer_display_id, action, from_user_display_id, to_user_display_id, actor)
+//	VALUES ($1, $2, $3, $4, $5, $6)
+func (q *Queries) CreateProjectTransferAuditLog(ctx context.Context, arg CreateProjectTransferAuditLogParams) error {
+	_, err := q.db.Exec(ctx, createProjectTransferAuditLog,
+		arg.ProjectDisplayID,
+		arg.TransferDisplayID,
+		arg.Action,
+		arg.FromUserDisplayID,
+		arg.ToUserDisplayID,
+		arg.Actor,
+	)
+	return err
+}
+
+const createRole = `-- name: CreateRole :one
+INSERT INTO tacokumo_admin.roles (project_id, name, description) VALUES ($1, $2, $3)
+RETURNING display_id
+`
+
+type CreateRoleParams struct {
+	ProjectID   int64
+	Name        string
+	Description string
+}
+
+// CreateRole
+//
+//	INSERT INTO tacokumo_admin.roles (project_id, name, description) VALUES ($1, $2, $3)
+//	RETURNING display_id
+func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createRole, arg.ProjectID, arg.Name, arg.Description)
+	var display_id pgtype.UUID
+	err := row.Scan(&display_id)
+	return display_id, err
+}
+
+const createServiceAccount = `-- name: CreateServiceAccount :one
+INSERT INTO tacokumo_admin.service_accounts (project_id, name, description, owner_user_id)
+VALUES ($1, $2, $3, $4)
+RETURNING display_id
+`
+
+type CreateServiceAccountParams struct {
+	ProjectID   pgtype.Int8
+	Name        string
+	Description string
+	OwnerUserID pgtype.Int8
+}
+
+// CreateServiceAccount
+//
+//	INSERT INTO tacokumo_admin.service_accounts (project_id, name, description, owner_user_id)
+//	VALUES ($1, $2, $3, $4)
+//	RETURNING display_id
+func (q *Queries) CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createServiceAccount,
+		arg.ProjectID,
+		arg.Name,
+		arg.Description,
+		arg.OwnerUserID,
+	)
+	var display_id pgtype.UUID
+	err := row.Scan(&display_id)
+	return display_id, err
+}
+
+const createServiceAccountToken = `-- name: CreateServiceAccountToken :one
+INSERT INTO tacokumo_admin.service_account_tokens (service_account_id, description, token_prefix, token_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, display_id, service_account_id, description, token_prefix, token_hash, expires_at, last_used_at, revoked_at, created_at, updated_at
+`
+
+type CreateServiceAccountTokenParams struct {
+	ServiceAccountID int64
+	Description      string
+	TokenPrefix      string
+	TokenHash        string
+	ExpiresAt        pgtype.Timestamptz
+}
+
+// CreateServiceAccountToken
+//
+//	INSERT INTO tacokumo_admin.service_account_tokens (service_account_id, description, token_prefix, token_hash, expires_at)
+//	VALUES ($1, $2, $3, $4, $5)
+//	RETURNING id, display_id, service_account_id, description, token_prefix, token_hash, expires_at, last_used_at, revoked_at, created_at, updated_at
+func (q *Queries) CreateServiceAccountToken(ctx context.Context, arg CreateServiceAccountTokenParams) (TacokumoAdminServiceAccountToken, error) {
+	row := q.db.QueryRow(ctx, createServiceAccountToken,
+		arg.ServiceAccountID,
+		arg.Description,
+		arg.TokenPrefix,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i TacokumoAdminServiceAccountToken
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ServiceAccountID,
+		&i.Description,
+		&i.TokenPrefix,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createUser = `-- name: CreateUser :exec
+INSERT INTO tacokumo_admin.users (email) VALUES ($1)
+`
+
+// CreateUser
+//
+//	INSERT INTO tacokumo_admin.users (email) VALUES ($1)
+func (q *Queries) CreateUser(ctx context.Context, email string) error {
+	_, err := q.db.Exec(ctx, createUser, email)
+	return err
+}
+
+const createUserGroup = `-- name: CreateUserGroup :one
+INSERT INTO tacokumo_admin.usergroups (project_id, name, description) VALUES ($1, $2, $3)
+RETURNING display_id
+`
+
+type CreateUserGroupParams struct {
+	ProjectID   int64
+	Name        string
+	Description string
+}
+
+// CreateUserGroup
+//
+//	INSERT INTO tacokumo_admin.usergroups (project_id, name, description) VALUES ($1, $2, $3)
+//	RETURNING display_id
+func (q *Queries) CreateUserGroup(ctx context.Context, arg CreateUserGroupParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createUserGroup, arg.ProjectID, arg.Name, arg.Description)
+	var display_id pgtype.UUID
+	err := row.Scan(&display_id)
+	return display_id, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO tacokumo_admin.webhook_deliveries (subscription_id, event_type, payload)
+VALUES ($1, $2, $3)
+RETURNING id, display_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	SubscriptionID int64
+	EventType      string
+	Payload        []byte
+}
+
+// CreateWebhookDelivery
+//
+//	INSERT INTO tacokumo_admin.webhook_deliveries (subscription_id, event_type, payload)
+//	VALUES ($1, $2, $3)
+//	RETURNING id, display_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (TacokumoAdminWebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, createWebhookDelivery, arg.SubscriptionID, arg.EventType, arg.Payload)
+	var i TacokumoAdminWebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastAttemptAt,
+		&i.LastStatusCode,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO tacokumo_admin.webhook_subscriptions (project_id, url, description, event_types, secret)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	ProjectID   int64
+	Url         string
+	Description string
+	EventTypes  []string
+	Secret      string
+}
+
+// CreateWebhookSubscription
+//
+//	INSERT INTO tacokumo_admin.webhook_subscriptions (project_id, url, description, event_types, secret)
+//	VALUES ($1, $2, $3, $4, $5)
+//	RETURNING id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (TacokumoAdminWebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, createWebhookSubscription,
+		arg.ProjectID,
+		arg.Url,
+		arg.Description,
+		arg.EventTypes,
+		arg.Secret,
+	)
+	var i TacokumoAdminWebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Disabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteArchivedProjectsBefore = `-- name: DeleteArchivedProjectsBefore :many
+DELETE FROM tacokumo_admin.projects
+WHERE archived_at < $1
+RETURNING display_id
+`
+
+// Deleting a project cascades to its roles, user groups, memberships and webhooks.
+//
+//	DELETE FROM tacokumo_admin.projects
+//	WHERE archived_at < $1
+//	RETURNING display_id
+func (q *Queries) DeleteArchivedProjectsBefore(ctx context.Context, archivedAt pgtype.Timestamptz) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, deleteArchivedProjectsBefore, archivedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var display_id pgtype.UUID
+		if err := rows.Scan(&display_id); err != nil {
+			return nil, err
+		}
+		items = append(items, display_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteFinishedJobsBefore = `-- name: DeleteFinishedJobsBefore :execrows
+DELETE FROM tacokumo_admin.jobs
+WHERE status IN ('succeeded', 'failed') AND finished_at < $1
+`
+
+// DeleteFinishedJobsBefore
+//
+//	DELETE FROM tacokumo_admin.jobs
+//	WHERE status IN ('succeeded', 'failed') AND finished_at < $1
+func (q *Queries) DeleteFinishedJobsBefore(ctx context.Context, finishedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFinishedJobsBefore, finishedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteProjectByID = `-- name: DeleteProjectByID :exec
+DELETE FROM tacokumo_admin.projects
+WHERE id = $1
+`
+
+// Deleting a project cascades to its roles, user groups, memberships and webhooks.
+//
+//	DELETE FROM tacokumo_admin.projects
+//	WHERE id = $1
+func (q *Queries) DeleteProjectByID(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteProjectByID, id)
+	return err
+}
+
+const deleteProjectOwner = `-- name: DeleteProjectOwner :execrows
+DELETE FROM tacokumo_admin.project_owners
+WHERE project_id = $1 AND user_id = $2
+`
+
+type DeleteProjectOwnerParams struct {
+	ProjectID int64
+	UserID    int64
+}
+
+// DeleteProjectOwner
+//
+//	DELETE FROM tacokumo_admin.project_owners
+//	WHERE project_id = $1 AND user_id = $2
+func (q *Queries) DeleteProjectOwner(ctx context.Context, arg DeleteProjectOwnerParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteProjectOwner, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteProjectOwnerGroups = `-- name: DeleteProjectOwnerGroups :exec
+DELETE FROM tacokumo_admin.project_owner_groups
+WHERE project_id = $1
+`
+
+// DeleteProjectOwnerGroups
+//
+//	DELETE FROM tacokumo_admin.project_owner_groups
+//	WHERE project_id = $1
+func (q *Queries) DeleteProjectOwnerGroups(ctx context.Context, projectID int64) error {
+	_, err := q.db.Exec(ctx, deleteProjectOwnerGroups, projectID)
+	return err
+}
+
+const deleteProjectOwners = `-- name: DeleteProjectOwners :exec
+DELETE FROM tacokumo_admin.project_owners
+WHERE project_id = $1
+`
+
+// DeleteProjectOwners
+//
+//	DELETE FROM tacokumo_admin.project_owners
+//	WHERE project_id = $1
+func (q *Queries) DeleteProjectOwners(ctx context.Context, projectID int64) error {
+	_, err := q.db.Exec(ctx, deleteProjectOwners, projectID)
+	return err
+}
+
+const deleteProjectTemplate = `-- name: DeleteProjectTemplate :execrows
+DELETE FROM tacokumo_admin.project_templates
+WHERE display_id = $1
+`
+
+// DeleteProjectTemplate
+//
+//	DELETE FROM tacokumo_admin.project_templates
+//	WHERE display_id = $1
+func (q *Queries) DeleteProjectTemplate(ctx context.Context, displayID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteProjectTemplate, displayID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRoleAttributeRelations = `-- name: DeleteRoleAttributeRelations :exec
+DELETE FROM tacokumo_admin.role_attributes_relations
+WHERE role_id = $1
+`
+
+// DeleteRoleAttributeRelations
+//
+//	DELETE FROM tacokumo_admin.role_attributes_relations
+//	WHERE role_id = $1
+func (q *Queries) DeleteRoleAttributeRelations(ctx context.Context, roleID int64) error {
+	_, err := q.db.Exec(ctx, deleteRoleAttributeRelations, roleID)
+	return err
+}
+
+const deleteRoleByID = `-- name: DeleteRoleByID :exec
+DELETE FROM tacokumo_admin.roles
+WHERE id = $1
+`
+
+// DeleteRoleByID
+//
+//	DELETE FROM tacokumo_admin.roles
+//	WHERE id = $1
+func (q *Queries) DeleteRoleByID(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteRoleByID, id)
+	return err
+}
+
+const deleteRoleUsers = `-- name: DeleteRoleUsers :exec
+DELETE FROM tacokumo_admin.user_role_relations
+WHERE role_id = $1
+`
+
+// DeleteRoleUsers
+//
+//	DELETE FROM tacokumo_admin.user_role_relations
+//	WHERE role_id = $1
+func (q *Queries) DeleteRoleUsers(ctx context.Context, roleID int64) error {
+	_, err := q.db.Exec(ctx, deleteRoleUsers, roleID)
+	return err
+}
+
+const deleteServiceAccount = `-- name: DeleteServiceAccount :execrows
+DELETE FROM tacokumo_admin.service_accounts
+WHERE display_id = $1
+`
+
+// DeleteServiceAccount
+//
+//	DELETE FROM tacokumo_admin.service_accounts
+//	WHERE display_id = $1
+func (q *Queries) DeleteServiceAccount(ctx context.Context, displayID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteServiceAccount, displayID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserByDisplayID = `-- name: DeleteUserByDisplayID :execrows
+DELETE FROM tacokumo_admin.users
+WHERE display_id = $1
+`
+
+// DeleteUserByDisplayID
+//
+//	DELETE FROM tacokumo_admin.users
+//	WHERE display_id = $1
+func (q *Queries) DeleteUserByDisplayID(ctx context.Context, displayID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserByDisplayID, displayID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserGroupByDisplayID = `-- name: DeleteUserGroupByDisplayID :execrows
+DELETE FROM tacokumo_admin.usergroups
+WHERE project_id = $1 AND display_id = $2
+`
+
+type DeleteUserGroupByDisplayIDParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+}
+
+// DeleteUserGroupByDisplayID
+//
+//	DELETE FROM tacokumo_admin.usergroups
+//	WHERE project_id = $1 AND display_id = $2
+func (q *Queries) DeleteUserGroupByDisplayID(ctx context.Context, arg DeleteUserGroupByDisplayIDParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserGroupByDisplayID, arg.ProjectID, arg.DisplayID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserGroupByID = `-- name: DeleteUserGroupByID :exec
+DELETE FROM tacokumo_admin.usergroups
+WHERE id = $1
+`
+
+// DeleteUserGroupByID
+//
+//	DELETE FROM tacokumo_admin.usergroups
+//	WHERE id = $1
+func (q *Queries) DeleteUserGroupByID(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteUserGroupByID, id)
+	return err
+}
+
+const deleteUserGroupMembers = `-- name: DeleteUserGroupMembers :exec
+DELETE FROM tacokumo_admin.user_usergroups_relations
+WHERE usergroup_id = $1
+`
+
+// DeleteUserGroupMembers
+//
+//	DELETE FROM tacokumo_admin.user_usergroups_relations
+//	WHERE usergroup_id = $1
+func (q *Queries) DeleteUserGroupMembers(ctx context.Context, usergroupID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserGroupMembers, usergroupID)
+	return err
+}
+
+const deleteUserGroupRoles = `-- name: DeleteUserGroupRoles :exec
+DELETE FROM tacokumo_admin.usergroup_role_relations
+WHERE usergroup_id = $1
+`
+
+// DeleteUserGroupRoles
+//
+//	DELETE FROM tacokumo_admin.usergroup_role_relations
+//	WHERE usergroup_id = $1
+func (q *Queries) DeleteUserGroupRoles(ctx context.Context, usergroupID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserGroupRoles, usergroupID)
+	return err
+}
+
+const deleteUserGroupsByProject = `-- name: DeleteUserGroupsByProject :many
+DELETE FROM tacokumo_admin.usergroups
+WHERE project_id = $1
+RETURNING display_id
+`
+
+// DeleteUserGroupsByProject
+//
+//	DELETE FROM tacokumo_admin.usergroups
+//	WHERE project_id = $1
+//	RETURNING display_id
+func (q *Queries) DeleteUserGroupsByProject(ctx context.Context, projectID int64) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, deleteUserGroupsByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var display_id pgtype.UUID
+		if err := rows.Scan(&display_id); err != nil {
+			return nil, err
+		}
+		items = append(items, display_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE FROM tacokumo_admin.webhook_subscriptions
+WHERE project_id = $1 AND display_id = $2
+`
+
+type DeleteWebhookSubscriptionParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+}
+
+// DeleteWebhookSubscription
+//
+//	DELETE FROM tacokumo_admin.webhook_subscriptions
+//	WHERE project_id = $1 AND display_id = $2
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhookSubscription, arg.ProjectID, arg.DisplayID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enqueueJob = `-- name: EnqueueJob :one
+INSERT INTO tacokumo_admin.jobs (kind, payload, max_attempts, run_at, unique_key)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (unique_key) DO NOTHING
+RETURNING id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
+`
+
+type EnqueueJobParams struct {
+	Kind        string
+	Payload     []byte
+	MaxAttempts int32
+	RunAt       pgtype.Timestamptz
+	UniqueKey   pgtype.Text
+}
+
+// Returns no rows when a job with the same unique_key already exists.
+//
+//	INSERT INTO tacokumo_admin.jobs (kind, payload, max_attempts, run_at, unique_key)
+//	VALUES ($1, $2, $3, $4, $5)
+//	ON CONFLICT (unique_key) DO NOTHING
+//	RETURNING id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
+func (q *Queries) EnqueueJob(ctx context.Context, arg EnqueueJobParams) (TacokumoAdminJob, error) {
+	row := q.db.QueryRow(ctx, enqueueJob,
+		arg.Kind,
+		arg.Payload,
+		arg.MaxAttempts,
+		arg.RunAt,
+		arg.UniqueKey,
+	)
+	var i TacokumoAdminJob
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedBy,
+		&i.LockedUntil,
+		&i.UniqueKey,
+		&i.LastError,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const expireProjectTransfers = `-- name: ExpireProjectTransfers :execrows
+UPDATE tacokumo_admin.project_transfers
+SET (status, updated_at) = ('expired', NOW())
+WHERE project_id = $1 AND status = 'pending' AND expires_at <= NOW()
+`
+
+// Marks pending transfers past their expiry as expired so that a new transfer can be started.
+//
+//	UPDATE tacokumo_admin.project_transfers
+//	SET (status, updated_at) = ('expired', NOW())
+//	WHERE project_id = $1 AND status = 'pending' AND expires_at <= NOW()
+func (q *Queries) ExpireProjectTransfers(ctx context.Context, projectID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, expireProjectTransfers, projectID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const failJob = `-- name: FailJob :exec
+UPDATE tacokumo_admin.jobs
+SET status = 'failed', locked_by = NULL, locked_until = NULL, last_error = $3, finished_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND locked_by = $2
+`
+
+type FailJobParams struct {
+	ID        int64
+	LockedBy  pgtype.Text
+	LastError pgtype.Text
+}
+
+// FailJob
+//
+//	UPDATE tacokumo_admin.jobs
+//	SET status = 'failed', locked_by = NULL, locked_until = NULL, last_error = $3, finished_at = NOW(), updated_at = NOW()
+//	WHERE id = $1 AND locked_by = $2
+func (q *Queries) FailJob(ctx context.Context, arg FailJobParams) error {
+	_, err := q.db.Exec(ctx, failJob, arg.ID, arg.LockedBy, arg.LastError)
+	return err
+}
+
+const getInvitationByDisplayID = `-- name: GetInvitationByDisplayID :one
+SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+FROM tacokumo_admin.invitations
+WHERE project_id = $1 AND display_id = $2
+`
+
+type GetInvitationByDisplayIDParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+}
+
+// GetInvitationByDisplayID
+//
+//	SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+//	FROM tacokumo_admin.invitations
+//	WHERE project_id = $1 AND display_id = $2
+func (q *Queries) GetInvitationByDisplayID(ctx context.Context, arg GetInvitationByDisplayIDParams) (TacokumoAdminInvitation, error) {
+	row := q.db.QueryRow(ctx, getInvitationByDisplayID, arg.ProjectID, arg.DisplayID)
+	var i TacokumoAdminInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Email,
+		&i.TokenHash,
+		&i.Status,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedUserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getJobByDisplayID = `-- name: GetJobByDisplayID :one
+SELECT id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
+FROM tacokumo_admin.jobs
+WHERE display_id = $1
+`
+
+// GetJobByDisplayID
+//
+//	SELECT id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
+//	FROM tacokumo_admin.jobs
+//	WHERE display_id = $1
+func (q *Queries) GetJobByDisplayID(ctx context.Context, displayID pgtype.UUID) (TacokumoAdminJob, error) {
+	row := q.db.QueryRow(ctx, getJobByDisplayID, displayID)
+	var i TacokumoAdminJob
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedBy,
+		&i.LockedUntil,
+		&i.UniqueKey,
+		&i.LastError,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPendingInvitationByTokenHash = `-- name: GetPendingInvitationByTokenHash :one
+SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+FROM tacokumo_admin.invitations
+WHERE token_hash = $1 AND status = 'pending'
+FOR UPDATE
+`
+
+// GetPendingInvitationByTokenHash
+//
+//	SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+//	FROM tacokumo_admin.invitations
+//	WHERE token_hash = $1 AND status = 'pending'
+//	FOR UPDATE
+func (q *Queries) GetPendingInvitationByTokenHash(ctx context.Context, tokenHash string) (TacokumoAdminInvitation, error) {
+	row := q.db.QueryRow(ctx, getPendingInvitationByTokenHash, tokenHash)
+	var i TacokumoAdminInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Email,
+		&i.TokenHash,
+		&i.Status,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedUserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectByDisplayID = `-- name: GetProjectByDisplayID :one
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+FROM tacokumo_admin.projects
+WHERE display_id = $1
+`
+
+// GetProjectByDisplayID
+//
+//	SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+//	FROM tacokumo_admin.projects
+//	WHERE display_id = $1
+func (q *Queries) GetProjectByDisplayID(ctx context.Context, displayID pgtype.UUID) (TacokumoAdminProject, error) {
+	row := q.db.QueryRow(ctx, getProjectByDisplayID, displayID)
+	var i TacokumoAdminProject
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.ArchivedBy,
+		&i.Labels,
+		&i.Annotations,
+	)
+	return i, err
+}
+
+const getProjectByID = `-- name: GetProjectByID :one
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+FROM tacokumo_admin.projects
+WHERE id = $1
+`
+
+// GetProjectByID
+//
+//	SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+//	FROM tacokumo_admin.projects
+//	WHERE id = $1
+func (q *Queries) GetProjectByID(ctx context.Context, id int64) (TacokumoAdminProject, error) {
+	row := q.db.QueryRow(ctx, getProjectByID, id)
+	var i TacokumoAdminProject
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.ArchivedBy,
+		&i.Labels,
+		&i.Annotations,
+	)
+	return i, err
+}
+
+const getProjectByName = `-- name: GetProjectByName :one
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+FROM tacokumo_admin.projects
+WHERE name = $1
+`
+
+// GetProjectByName
+//
+//	SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+//	FROM tacokumo_admin.projects
+//	WHERE name = $1
+func (q *Queries) GetProjectByName(ctx context.Context, name string) (TacokumoAdminProject, error) {
+	row := q.db.QueryRow(ctx, getProjectByName, name)
+	var i TacokumoAdminProject
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.ArchivedBy,
+		&i.Labels,
+		&i.Annotations,
+	)
+	return i, err
+}
+
+const getProjectComposition = `-- name: GetProjectComposition :one
+SELECT p.kind,
+      (SELECT COUNT(*) FROM tacokumo_admin.project_owners po WHERE po.project_id = p.id) AS owner_count,
+      (SELECT COUNT(*) FROM tacokumo_admin.project_owner_groups pog WHERE pog.project_id = p.id) AS owner_group_count,
+      (SELECT COUNT(*) FROM tacokumo_admin.usergroups ug WHERE ug.project_id = p.id) AS usergroup_count
+  FROM tacokumo_admin.projects p
+  WHERE p.id = $1
+`
+
+type GetProjectCompositionRow struct {
+	Kind            string
+	OwnerCount      int64
+	OwnerGroupCount int64
+	UsergroupCount  int64
+}
+
+// Counts what the kind rules of a project are checked against.
+//
+//	SELECT p.kind,
+//	      (SELECT COUNT(*) FROM tacokumo_admin.project_owners po WHERE po.project_id = p.id) AS owner_count,
+//	      (SELECT COUNT(*) FROM tacokumo_admin.project_owner_groups pog WHERE pog.project_id = p.id) AS owner_group_count,
+//	      (SELECT COUNT(*) FROM tacokumo_admin.usergroups ug WHERE ug.project_id = p.id) AS usergroup_count
+//	  FROM tacokumo_admin.projects p
+//	  WHERE p.id = $1
+func (q *Queries) GetProjectComposition(ctx context.Context, id int64) (GetProjectCompositionRow, error) {
+	row := q.db.QueryRow(ctx, getProjectComposition, id)
+	var i GetProjectCompositionRow
+	err := row.Scan(
+		&i.Kind,
+		&i.OwnerCount,
+		&i.OwnerGroupCount,
+		&i.UsergroupCount,
+	)
+	return i, err
+}
+
+const getProjectTemplateByDisplayID = `-- name: GetProjectTemplateByDisplayID :one
+SELECT id, display_id, name, description, kind, spec, source_project_id, created_by, created_at, updated_at
+FROM tacokumo_admin.project_templates
+WHERE display_id = $1
+`
+
+// GetProjectTemplateByDisplayID
+//
+//	SELECT id, display_id, name, description, kind, spec, source_project_id, created_by, created_at, updated_at
+//	FROM tacokumo_admin.project_templates
+//	WHERE display_id = $1
+func (q *Queries) GetProjectTemplateByDisplayID(ctx context.Context, displayID pgtype.UUID) (TacokumoAdminProjectTemplate, error) {
+	row := q.db.QueryRow(ctx, getProjectTemplateByDisplayID, displayID)
+	var i TacokumoAdminProjectTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.Spec,
+		&i.SourceProjectID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectTransferByDisplayID = `-- name: GetProjectTransferByDisplayID :one
+SELECT id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+FROM tacokumo_admin.project_transfers
+WHERE project_id = $1 AND display_id = $2
+`
+
+type GetProjectTransferByDisplayIDParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+}
+
+// GetProjectTransferByDisplayID
+//
+//	SELECT id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+//	FROM tacokumo_admin.project_transfers
+//	WHERE project_id = $1 AND display_id = $2
+func (q *Queries) GetProjectTransferByDisplayID(ctx context.Context, arg GetProjectTransferByDisplayIDParams) (TacokumoAdminProjectTransfer, error) {
+	row := q.db.QueryRow(ctx, getProjectTransferByDisplayID, arg.ProjectID, arg.DisplayID)
+	var i TacokumoAdminProjectTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.FromUserID,
+		&i.ToUserID,
+		&i.Status,
+		&i.InitiatedBy,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectTransferByDisplayIDForUpdate = `-- name: GetProjectTransferByDisplayIDForUpdate :one
+SELECT id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+FROM tacokumo_admin.project_transfers
+WHERE project_id = $1 AND display_id = $2
+FOR UPDATE
+`
+
+type GetProjectTransferByDisplayIDForUpdateParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+}
+
+// GetProjectTransferByDisplayIDForUpdate
+//
+//	SELECT id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+//	FROM tacokumo_admin.project_transfers
+//	WHERE project_id = $1 AND display_id = $2
+//	FOR UPDATE
+func (q *Queries) GetProjectTransferByDisplayIDForUpdate(ctx context.Context, arg GetProjectTransferByDisplayIDForUpdateParams) (TacokumoAdminProjectTransfer, error) {
+	row := q.db.QueryRow(ctx, getProjectTransferByDisplayIDForUpdate, arg.ProjectID, arg.DisplayID)
+	var i TacokumoAdminProjectTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.FromUserID,
+		&i.ToUserID,
+		&i.Status,
+		&i.InitiatedBy,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRoleByDisplayID = `-- name: GetRoleByDisplayID :one
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+FROM tacokumo_admin.roles
+WHERE project_id = $1 AND display_id = $2
+`
+
+type GetRoleByDisplayIDParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+}
+
+// GetRoleByDisplayID
+//
+//	SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+//	FROM tacokumo_admin.roles
+//	WHERE project_id = $1 AND display_id = $2
+func (q *Queries) GetRoleByDisplayID(ctx context.Context, arg GetRoleByDisplayIDParams) (TacokumoAdminRole, error) {
+	row := q.db.QueryRow(ctx, getRoleByDisplayID, arg.ProjectID, arg.DisplayID)
+	var i TacokumoAdminRole
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Description,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Labels,
+		&i.Annotations,
+	)
+	return i, err
+}
+
+const getServiceAccountByDisplayID = `-- name: GetServiceAccountByDisplayID :one
+SELECT sa.id,
+      sa.display_id,
+      sa.project_id,
+      p.display_id AS project_display_id,
+      sa.name,
+      sa.description,
+      sa.owner_user_id,
+      u.display_id AS owner_display_id,
+      sa.disabled,
+      sa.created_at,
+      sa.updated_at
+  FROM tacokumo_admin.service_accounts sa
+  LEFT JOIN tacokumo_admin.projects p ON sa.project_id = p.id
+  LEFT JOIN tacokumo_admin.users u ON sa.owner_user_id = u.id
+  WHERE sa.display_id = $1
+`
+
+type GetServiceAccountByDisplayIDRow struct {
+	ID               int64
+	DisplayID        pgtype.UUID
+	ProjectID        pgtype.Int8
+	ProjectDisplayID pgtype.UUID
+	Name             string
+	Description      string
+	OwnerUserID      pgtype.Int8
+	OwnerDisplayID   pgtype.UUID
+	Disabled         bool
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
+// GetServiceAccountByDisplayID
+//
+//	SELECT sa.id,
+//	      sa.display_id,
+//	      sa.project_id,
+//	      p.display_id AS project_display_id,
+//	      sa.name,
+//	      sa.description,
+//	      sa.owner_user_id,
+//	      u.display_id AS owner_display_id,
+//	      sa.disabled,
+//	      sa.created_at,
+//	      sa.updated_at
+//	  FROM tacokumo_admin.service_accounts sa
+//	  LEFT JOIN tacokumo_admin.projects p ON sa.project_id = p.id
+//	  LEFT JOIN tacokumo_admin.users u ON sa.owner_user_id = u.id
+//	  WHERE sa.display_id = $1
+func (q *Queries) GetServiceAccountByDisplayID(ctx context.Context, displayID pgtype.UUID) (GetServiceAccountByDisplayIDRow, error) {
+	row := q.db.QueryRow(ctx, getServiceAccountByDisplayID, displayID)
+	var i GetServiceAccountByDisplayIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.ProjectDisplayID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerUserID,
+		&i.OwnerDisplayID,
+		&i.Disabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getServiceAccountByTokenHash = `-- name: GetServiceAccountByTokenHash :one
+SELECT sa.id,
+      sa.display_id,
+      sa.project_id,
+      sa.name,
+      sa.disabled,
+      t.id AS token_id,
+      t.expires_at,
+      t.revoked_at
+  FROM tacokumo_admin.service_account_tokens t
+  INNER JOIN tacokumo_admin.service_accounts sa ON t.service_account_id = sa.id
+  WHERE t.token_hash = $1
+`
+
+type GetServiceAccountByTokenHashRow struct {
+	ID        int64
+	DisplayID pgtype.UUID
+	ProjectID pgtype.Int8
+	Name      string
+	Disabled  bool
+	TokenID   int64
+	ExpiresAt pgtype.Timestamptz
+	RevokedAt pgtype.Timestamptz
+}
+
+// GetServiceAccountByTokenHash
+//
+//	SELECT sa.id,
+//	      sa.display_id,
+//	      sa.project_id,
+//	      sa.name,
+//	      sa.disabled,
+//	      t.id AS token_id,
+//	      t.expires_at,
+//	      t.revoked_at
+//	  FROM tacokumo_admin.service_account_tokens t
+//	  INNER JOIN tacokumo_admin.service_accounts sa ON t.service_account_id = sa.id
+//	  WHERE t.token_hash = $1
+func (q *Queries) GetServiceAccountByTokenHash(ctx context.Context, tokenHash string) (GetServiceAccountByTokenHashRow, error) {
+	row := q.db.QueryRow(ctx, getServiceAccountByTokenHash, tokenHash)
+	var i GetServiceAccountByTokenHashRow
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Disabled,
+		&i.TokenID,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getUserByDisplayID = `-- name: GetUserByDisplayID :one
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+WHERE display_id = $1
+`
+
+// GetUserByDisplayID
+//
+//	SELECT id, display_id, email, created_at, updated_at
+//	FROM tacokumo_admin.users
+//	WHERE display_id = $1
+func (q *Queries) GetUserByDisplayID(ctx context.Context, displayID pgtype.UUID) (TacokumoAdminUser, error) {
+	row := q.db.QueryRow(ctx, getUserByDisplayID, displayID)
+	var i TacokumoAdminUser
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+WHERE email = $1
+`
+
+// GetUserByEmail
+//
+//	SELECT id, display_id, email, created_at, updated_at
+//	FROM tacokumo_admin.users
+//	WHERE email = $1
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (TacokumoAdminUser, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i TacokumoAdminUser
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserByEmailFold = `-- name: GetUserByEmailFold :one
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+WHERE lower(email) = lower($1::VARCHAR)
+`
+
+// GetUserByEmailFold
+//
+//	SELECT id, display_id, email, created_at, updated_at
+//	FROM tacokumo_admin.users
+//	WHERE lower(email) = lower($1::VARCHAR)
+func (q *Queries) GetUserByEmailFold(ctx context.Context, email string) (TacokumoAdminUser, error) {
+	row := q.db.QueryRow(ctx, getUserByEmailFold, email)
+	var i TacokumoAdminUser
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+WHERE id = $1
+`
+
+// GetUserByID
+//
+//	SELECT id, display_id, email, created_at, updated_at
+//	FROM tacokumo_admin.users
+//	WHERE id = $1
+func (q *Queries) GetUserByID(ctx context.Context, id int64) (TacokumoAdminUser, error) {
+	row := q.db.QueryRow(ctx, getUserByID, id)
+	var i TacokumoAdminUser
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserGroupByDisplayID = `-- name: GetUserGroupByDisplayID :one
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+FROM tacokumo_admin.usergroups
+WHERE project_id = $1 AND display_id = $2
+`
+
+type GetUserGroupByDisplayIDParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+}
+
+// GetUserGroupByDisplayID
+//
+//	SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+//	FROM tacokumo_admin.usergroups
+//	WHERE project_id = $1 AND display_id = $2
+func (q *Queries) GetUserGroupByDisplayID(ctx context.Context, arg GetUserGroupByDisplayIDParams) (TacokumoAdminUsergroup, error) {
+	row := q.db.QueryRow(ctx, getUserGroupByDisplayID, arg.ProjectID, arg.DisplayID)
+	var i TacokumoAdminUsergroup
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Description,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Labels,
+		&i.Annotations,
+	)
+	return i, err
+}
+
+const getWebhookDeliveryByDisplayID = `-- name: GetWebhookDeliveryByDisplayID :one
+SELECT id, display_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+FROM tacokumo_admin.webhook_deliveries
+WHERE subscription_id = $1 AND display_id = $2
+`
+
+type GetWebhookDeliveryByDisplayIDParams struct {
+	SubscriptionID int64
+	DisplayID      pgtype.UUID
+}
+
+// GetWebhookDeliveryByDisplayID
+//
+//	SELECT id, display_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+//	FROM tacokumo_admin.webhook_deliveries
+//	WHERE subscription_id = $1 AND display_id = $2
+func (q *Queries) GetWebhookDeliveryByDisplayID(ctx context.Context, arg GetWebhookDeliveryByDisplayIDParams) (TacokumoAdminWebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, getWebhookDeliveryByDisplayID, arg.SubscriptionID, arg.DisplayID)
+	var i TacokumoAdminWebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastAttemptAt,
+		&i.LastStatusCode,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWebhookSubscriptionByDisplayID = `-- name: GetWebhookSubscriptionByDisplayID :one
+SELECT id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+FROM tacokumo_admin.webhook_subscriptions
+WHERE project_id = $1 AND display_id = $2
+`
+
+type GetWebhookSubscriptionByDisplayIDParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+}
+
+// GetWebhookSubscriptionByDisplayID
+//
+//	SELECT id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+//	FROM tacokumo_admin.webhook_subscriptions
+//	WHERE project_id = $1 AND display_id = $2
+func (q *Queries) GetWebhookSubscriptionByDisplayID(ctx context.Context, arg GetWebhookSubscriptionByDisplayIDParams) (TacokumoAdminWebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, getWebhookSubscriptionByDisplayID, arg.ProjectID, arg.DisplayID)
+	var i TacokumoAdminWebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Disabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const importProject = `-- name: ImportProject :one
+INSERT INTO tacokumo_admin.projects (display_id, name, description, kind, archived_at, archived_by, labels, annotations)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (display_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  description = EXCLUDED.description,
+  kind = EXCLUDED.kind,
+  archived_at = EXCLUDED.archived_at,
+  archived_by = EXCLUDED.archived_by,
+  labels = EXCLUDED.labels,
+  annotations = EXCLUDED.annotations,
+  version = tacokumo_admin.projects.version + 1,
+  updated_at = NOW()
+RETURNING id
+`
+
+type ImportProjectParams struct {
+	DisplayID   pgtype.UUID
+	Name        string
+	Description string
+	Kind        string
+	ArchivedAt  pgtype.Timestamptz
+	ArchivedBy  pgtype.Text
+	Labels      []byte
+	Annotations []byte
+}
+
+// Creates the project with the given display ID, or overwrites it.
+//
+//	INSERT INTO tacokumo_admin.projects (display_id, name, description, kind, archived_at, archived_by, labels, annotations)
+//	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+//	ON CONFLICT (display_id) DO UPDATE SET
+//	  name = EXCLUDED.name,
+//	  description = EXCLUDED.description,
+//	  kind = EXCLUDED.kind,
+//	  archived_at = EXCLUDED.archived_at,
+//	  archived_by = EXCLUDED.archived_by,
+//	  labels = EXCLUDED.labels,
+//	  annotations = EXCLUDED.annotations,
+//	  version = tacokumo_admin.projects.version + 1,
+//	  updated_at = NOW()
+//	RETURNING id
+func (q *Queries) ImportProject(ctx context.Context, arg ImportProjectParams) (int64, error) {
+	row := q.db.QueryRow(ctx, importProject,
+		arg.DisplayID,
+		arg.Name,
+		arg.Description,
+		arg.Kind,
+		arg.ArchivedAt,
+		arg.ArchivedBy,
+		arg.Labels,
+		arg.Annotations,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const importRole = `-- name: ImportRole :one
+INSERT INTO tacokumo_admin.roles (display_id, project_id, name, description, labels, annotations)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (project_id, display_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  description = EXCLUDED.description,
+  labels = EXCLUDED.labels,
+  annotations = EXCLUDED.annotations,
+  version = tacokumo_admin.roles.version + 1,
+  updated_at = NOW()
+RETURNING id
+`
+
+type ImportRoleParams struct {
+	DisplayID   pgtype.UUID
+	ProjectID   int64
+	Name        string
+	Description string
+	Labels      []byte
+	Annotations []byte
+}
+
+// Creates the role with the given display ID in the project, or overwrites it.
+//
+//	INSERT INTO tacokumo_admin.roles (display_id, project_id, name, description, labels, annotations)
+//	VALUES ($1, $2, $3, $4, $5, $6)
+//	ON CONFLICT (project_id, display_id) DO UPDATE SET
+//	  name = EXCLUDED.name,
+//	  description = EXCLUDED.description,
+//	  labels = EXCLUDED.labels,
+//	  annotations = EXCLUDED.annotations,
+//	  version = tacokumo_admin.roles.version + 1,
+//	  updated_at = NOW()
+//	RETURNING id
+func (q *Queries) ImportRole(ctx context.Context, arg ImportRoleParams) (int64, error) {
+	row := q.db.QueryRow(ctx, importRole,
+		arg.DisplayID,
+		arg.ProjectID,
+		arg.Name,
+		arg.Description,
+		arg.Labels,
+		arg.Annotations,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const importRoleAttribute = `-- name: ImportRoleAttribute :exec
+INSERT INTO tacokumo_admin.role_attributes (name, description) VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description, updated_at = NOW()
+`
+
+type ImportRoleAttributeParams struct {
+	Name        string
+	Description string
+}
+
+// ImportRoleAttribute
+//
+//	INSERT INTO tacokumo_admin.role_attributes (name, description) VALUES ($1, $2)
+//	ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description, updated_at = NOW()
+func (q *Queries) ImportRoleAttribute(ctx context.Context, arg ImportRoleAttributeParams) error {
+	_, err := q.db.Exec(ctx, importRoleAttribute, arg.Name, arg.Description)
+	return err
+}
+
+const importUser = `-- name: ImportUser :one
+INSERT INTO tacokumo_admin.users (display_id, email) VALUES ($1, $2)
+ON CONFLICT (display_id) DO UPDATE SET email = EXCLUDED.email, updated_at = NOW()
+RETURNING id
+`
+
+type ImportUserParams struct {
+	DisplayID pgtype.UUID
+	Email     string
+}
+
+// Creates the user with the given display ID, or updates its email.
+//
+//	INSERT INTO tacokumo_admin.users (display_id, email) VALUES ($1, $2)
+//	ON CONFLICT (display_id) DO UPDATE SET email = EXCLUDED.email, updated_at = NOW()
+//	RETURNING id
+func (q *Queries) ImportUser(ctx context.Context, arg ImportUserParams) (int64, error) {
+	row := q.db.QueryRow(ctx, importUser, arg.DisplayID, arg.Email)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const importUserGroup = `-- name: ImportUserGroup :one
+INSERT INTO tacokumo_admin.usergroups (display_id, project_id, name, description, labels, annotations)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (project_id, display_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  description = EXCLUDED.description,
+  labels = EXCLUDED.labels,
+  annotations = EXCLUDED.annotations,
+  version = tacokumo_admin.usergroups.version + 1,
+  updated_at = NOW()
+RETURNING id
+`
+
+type ImportUserGroupParams struct {
+	DisplayID   pgtype.UUID
+	ProjectID   int64
+	Name        string
+	Description string
+	Labels      []byte
+	Annotations []byte
+}
+
+// Creates the user group with the given display ID in the project, or overwrites it.
+//
+//	INSERT INTO tacokumo_admin.usergroups (display_id, project_id, name, description, labels, annotations)
+//	VALUES ($1, $2, $3, $4, $5, $6)
+//	ON CONFLICT (project_id, display_id) DO UPDATE SET
+//	  name = EXCLUDED.name,
+//	  description = EXCLUDED.description,
+//	  labels = EXCLUDED.labels,
+//	  annotations = EXCLUDED.annotations,
+//	  version = tacokumo_admin.usergroups.version + 1,
+//	  updated_at = NOW()
+//	RETURNING id
+func (q *Queries) ImportUserGroup(ctx context.Context, arg ImportUserGroupParams) (int64, error) {
+	row := q.db.QueryRow(ctx, importUserGroup,
+		arg.DisplayID,
+		arg.ProjectID,
+		arg.Name,
+		arg.Description,
+		arg.Labels,
+		arg.Annotations,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const isProjectOwner = `-- name: IsProjectOwner :one
+SELECT EXISTS (
+  SELECT 1 FROM tacokumo_admin.project_owners
+  WHERE project_id = $1 AND user_id = $2
+)
+`
+
+type IsProjectOwnerParams struct {
+	ProjectID int64
+	UserID    int64
+}
+
+// IsProjectOwner
+//
+//	SELECT EXISTS (
+//	  SELECT 1 FROM tacokumo_admin.project_owners
+//	  WHERE project_id = $1 AND user_id = $2
+//	)
+func (q *Queries) IsProjectOwner(ctx context.Context, arg IsProjectOwnerParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isProjectOwner, arg.ProjectID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listActiveWebhookSubscriptionsByProject = `-- name: ListActiveWebhookSubscriptionsByProject :many
+SELECT ws.id, ws.display_id, ws.project_id, ws.url, ws.description, ws.event_types, ws.secret, ws.disabled, ws.created_at, ws.updated_at
+FROM tacokumo_admin.webhook_subscriptions ws
+INNER JOIN tacokumo_admin.projects p ON p.id = ws.project_id
+WHERE p.display_id = $1 AND NOT ws.disabled
+`
+
+// ListActiveWebhookSubscriptionsByProject
+//
+//	SELECT ws.id, ws.display_id, ws.project_id, ws.url, ws.description, ws.event_types, ws.secret, ws.disabled, ws.created_at, ws.updated_at
+//	FROM tacokumo_admin.webhook_subscriptions ws
+//	INNER JOIN tacokumo_admin.projects p ON p.id = ws.project_id
+//	WHERE p.display_id = $1 AND NOT ws.disabled
+func (q *Queries) ListActiveWebhookSubscriptionsByProject(ctx context.Context, displayID pgtype.UUID) ([]TacokumoAdminWebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listActiveWebhookSubscriptionsByProject, displayID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminWebhookSubscription
+	for rows.Next() {
+		var i TacokumoAdminWebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Url,
+			&i.Description,
+			&i.EventTypes,
+			&i.Secret,
+			&i.Disabled,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllProjects = `-- name: ListAllProjects :many
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+FROM tacokumo_admin.projects
+ORDER BY id
+`
+
+// Lists every project including archived ones.
+//
+//	SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+//	FROM tacokumo_admin.projects
+//	ORDER BY id
+func (q *Queries) ListAllProjects(ctx context.Context) ([]TacokumoAdminProject, error) {
+	rows, err := q.db.Query(ctx, listAllProjects)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminProject
+	for rows.Next() {
+		var i TacokumoAdminProject
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Name,
+			&i.Description,
+			&i.Kind,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.ArchivedBy,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllUsers = `-- name: ListAllUsers :many
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+ORDER BY id
+`
+
+// ListAllUsers
+//
+//	SELECT id, display_id, email, created_at, updated_at
+//	FROM tacokumo_admin.users
+//	ORDER BY id
+func (q *Queries) ListAllUsers(ctx context.Context) ([]TacokumoAdminUser, error) {
+	rows, err := q.db.Query(ctx, listAllUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUser
+	for rows.Next() {
+		var i TacokumoAdminUser
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInvitationRoles = `-- name: ListInvitationRoles :many
+SELECT ro.id, ro.display_id, ro.project_id, ro.name, ro.description, ro.version, ro.created_at, ro.updated_at, ro.labels, ro.annotations
+  FROM tacokumo_admin.roles ro
+  INNER JOIN tacokumo_admin.invitation_roles r ON ro.id = r.role_id
+  WHERE r.invitation_id = $1
+  ORDER BY ro.created_at DESC
+`
+
+// ListInvitationRoles
+//
+//	SELECT ro.id, ro.display_id, ro.project_id, ro.name, ro.description, ro.version, ro.created_at, ro.updated_at, ro.labels, ro.annotations
+//	  FROM tacokumo_admin.roles ro
+//	  INNER JOIN tacokumo_admin.invitation_roles r ON ro.id = r.role_id
+//	  WHERE r.invitation_id = $1
+//	  ORDER BY ro.created_at DESC
+func (q *Queries) ListInvitationRoles(ctx context.Context, invitationID int64) ([]TacokumoAdminRole, error) {
+	rows, err := q.db.Query(ctx, listInvitationRoles, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminRole
+	for rows.Next() {
+		var i TacokumoAdminRole
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInvitationUserGroups = `-- name: ListInvitationUserGroups :many
+SELECT ug.id, ug.display_id, ug.project_id, ug.name, ug.description, ug.version, ug.created_at, ug.updated_at, ug.labels, ug.annotations
+  FROM tacokumo_admin.usergroups ug
+  INNER JOIN tacokumo_admin.invitation_usergroups r ON ug.id = r.usergroup_id
+  WHERE r.invitation_id = $1
+  ORDER BY ug.created_at DESC
+`
+
+// ListInvitationUserGroups
+//
+//	SELECT ug.id, ug.display_id, ug.project_id, ug.name, ug.description, ug.version, ug.created_at, ug.updated_at, ug.labels, ug.annotations
+//	  FROM tacokumo_admin.usergroups ug
+//	  INNER JOIN tacokumo_admin.invitation_usergroups r ON ug.id = r.usergroup_id
+//	  WHERE r.invitation_id = $1
+//	  ORDER BY ug.created_at DESC
+func (q *Queries) ListInvitationUserGroups(ctx context.Context, invitationID int64) ([]TacokumoAdminUsergroup, error) {
+	rows, err := q.db.Query(ctx, listInvitationUserGroups, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUsergroup
+	for rows.Next() {
+		var i TacokumoAdminUsergroup
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInvitationsWithPagination = `-- name: ListInvitationsWithPagination :many
+SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+FROM tacokumo_admin.invitations
+WHERE project_id = $1
+  AND ($4::VARCHAR IS NULL
+    OR ($4::VARCHAR = 'expired' AND status = 'pending' AND expires_at <= NOW())
+    OR ($4::VARCHAR = 'pending' AND status = 'pending' AND expires_at > NOW())
+    OR ($4::VARCHAR NOT IN ('pending', 'expired') AND status = $4::VARCHAR))
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListInvitationsWithPaginationParams struct {
+	ProjectID int64
+	Limit     int32
+	Offset    int32
+	Status    pgtype.Text
+}
+
+// status filters by invitation status; 'expired' matches pending invitations past their expiry.
+//
+//	SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+//	FROM tacokumo_admin.invitations
+//	WHERE project_id = $1
+//	  AND ($4::VARCHAR IS NULL
+//	    OR ($4::VARCHAR = 'expired' AND status = 'pending' AND expires_at <= NOW())
+//	    OR ($4::VARCHAR = 'pending' AND status = 'pending' AND expires_at > NOW())
+//	    OR ($4::VARCHAR NOT IN ('pending', 'expired') AND status = $4::VARCHAR))
+//	ORDER BY created_at DESC
+//	LIMIT $2 OFFSET $3
+func (q *Queries) ListInvitationsWithPagination(ctx context.Context, arg ListInvitationsWithPaginationParams) ([]TacokumoAdminInvitation, error) {
+	rows, err := q.db.Query(ctx, listInvitationsWithPagination,
+		arg.ProjectID,
+		arg.Limit,
+		arg.Offset,
+		arg.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminInvitation
+	for rows.Next() {
+		var i TacokumoAdminInvitation
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Email,
+			&i.TokenHash,
+			&i.Status,
+			&i.InvitedBy,
+			&i.ExpiresAt,
+			&i.AcceptedAt,
+			&i.AcceptedUserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsWithPagination = `-- name: ListJobsWithPagination :many
+SELECT id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
+FROM tacokumo_admin.jobs
+WHERE ($3::VARCHAR IS NULL OR status = $3::VARCHAR)
+  AND ($4::VARCHAR IS NULL OR kind = $4::VARCHAR)
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListJobsWithPaginationParams struct {
+	Limit  int32
+	Offset int32
+	Status pgtype.Text
+	Kind   pgtype.Text
+}
+
+// status and kind filter the jobs; NULL matches all.
+//
+//	SELECT id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
+//	FROM tacokumo_admin.jobs
+//	WHERE ($3::VARCHAR IS NULL OR status = $3::VARCHAR)
+//	  AND ($4::VARCHAR IS NULL OR kind = $4::VARCHAR)
+//	ORDER BY created_at DESC
+//	LIMIT $1 OFFSET $2
+func (q *Queries) ListJobsWithPagination(ctx context.Context, arg ListJobsWithPaginationParams) ([]TacokumoAdminJob, error) {
+	rows, err := q.db.Query(ctx, listJobsWithPagination,
+		arg.Limit,
+		arg.Offset,
+		arg.Status,
+		arg.Kind,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminJob
+	for rows.Next() {
+		var i TacokumoAdminJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Kind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.LockedBy,
+			&i.LockedUntil,
+			&i.UniqueKey,
+			&i.LastError,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingInvitationsByEmails = `-- name: ListPendingInvitationsByEmails :many
+SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+FROM tacokumo_admin.invitations
+WHERE email = ANY($1::VARCHAR[]) AND status = 'pending' AND expires_at > NOW()
+  -- Invitations to archived projects stay pending until the project is unarchived.
+  AND project_id NOT IN (SELECT id FROM tacokumo_admin.projects WHERE archived_at IS NOT NULL)
+ORDER BY created_at
+FOR UPDATE
+`
+
+// ListPendingInvitationsByEmails
+//
+//	SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+//	FROM tacokumo_admin.invitations
+//	WHERE email = ANY($1::VARCHAR[]) AND status = 'pending' AND expires_at > NOW()
+//	  -- Invitations to archived projects stay pending until the project is unarchived.
+//	  AND project_id NOT IN (SELECT id FROM tacokumo_admin.projects WHERE archived_at IS NOT NULL)
+//	ORDER BY created_at
+//	FOR UPDATE
+func (q *Queries) ListPendingInvitationsByEmails(ctx context.Context, emails []string) ([]TacokumoAdminInvitation, error) {
+	rows, err := q.db.Query(ctx, listPendingInvitationsByEmails, emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminInvitation
+	for rows.Next() {
+		var i TacokumoAdminInvitation
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Email,
+			&i.TokenHash,
+			&i.Status,
+			&i.InvitedBy,
+			&i.ExpiresAt,
+			&i.AcceptedAt,
+			&i.AcceptedUserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectIDsOwnedByUser = `-- name: ListProjectIDsOwnedByUser :many
+SELECT project_id
+  FROM tacokumo_admin.project_owners
+  WHERE user_id = $1
+`
+
+// ListProjectIDsOwnedByUser
+//
+//	SELECT project_id
+//	  FROM tacokumo_admin.project_owners
+//	  WHERE user_id = $1
+func (q *Queries) ListProjectIDsOwnedByUser(ctx context.Context, userID int64) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listProjectIDsOwnedByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var project_id int64
+		if err := rows.Scan(&project_id); err != nil {
+			return nil, err
+		}
+		items = append(items, project_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectOwnerGroups = `-- name: ListProjectOwnerGroups :many
+SELECT ug.id, ug.display_id, ug.project_id, ug.name, ug.description, ug.version, ug.created_at, ug.updated_at, ug.labels, ug.annotations
+  FROM tacokumo_admin.usergroups ug
+  INNER JOIN tacokumo_admin.project_owner_groups pog ON ug.id = pog.usergroup_id
+  WHERE pog.project_id = $1
+  ORDER BY pog.created_at
+`
+
+// ListProjectOwnerGroups
+//
+//	SELECT ug.id, ug.display_id, ug.project_id, ug.name, ug.description, ug.version, ug.created_at, ug.updated_at, ug.labels, ug.annotations
+//	  FROM tacokumo_admin.usergroups ug
+//	  INNER JOIN tacokumo_admin.project_owner_groups pog ON ug.id = pog.usergroup_id
+//	  WHERE pog.project_id = $1
+//	  ORDER BY pog.created_at
+func (q *Queries) ListProjectOwnerGroups(ctx context.Context, projectID int64) ([]TacokumoAdminUsergroup, error) {
+	rows, err := q.db.Query(ctx, listProjectOwnerGroups, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUsergroup
+	for rows.Next() {
+		var i TacokumoAdminUsergroup
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectOwners = `-- name: ListProjectOwners :many
+SELECT u.id, u.display_id, u.email, u.created_at, u.updated_at
+  FROM tacokumo_admin.users u
+  INNER JOIN tacokumo_admin.project_owners po ON u.id = po.user_id
+  WHERE po.project_id = $1
+  ORDER BY po.created_at
+`
+
+// ListProjectOwners
+//
+//	SELECT u.id, u.display_id, u.email, u.created_at, u.updated_at
+//	  FROM tacokumo_admin.users u
+//	  INNER JOIN tacokumo_admin.project_owners po ON u.id = po.user_id
+//	  WHERE po.project_id = $1
+//	  ORDER BY po.created_at
+func (q *Queries) ListProjectOwners(ctx context.Context, projectID int64) ([]TacokumoAdminUser, error) {
+	rows, err := q.db.Query(ctx, listProjectOwners, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUser
+	for rows.Next() {
+		var i TacokumoAdminUser
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectTemplatesWithPagination = `-- name: ListProjectTemplatesWithPagination :many
+SELECT id, display_id, name, description, kind, spec, source_project_id, created_by, created_at, updated_at
+FROM tacokumo_admin.project_templates
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListProjectTemplatesWithPaginationParams struct {
+	Limit  int32
+	Offset int32
+}
+
+// ListProjectTemplatesWithPagination
+//
+//	SELECT id, display_id, name, description, kind, spec, source_project_id, created_by, created_at, updated_at
+//	FROM tacokumo_admin.project_templates
+//	ORDER BY created_at DESC
+//	LIMIT $1 OFFSET $2
+func (q *Queries) ListProjectTemplatesWithPagination(ctx context.Context, arg ListProjectTemplatesWithPaginationParams) ([]TacokumoAdminProjectTemplate, error) {
+	rows, err := q.db.Query(ctx, listProjectTemplatesWithPagination, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminProjectTemplate
+	for rows.Next() {
+		var i TacokumoAdminProjectTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Name,
+			&i.Description,
+			&i.Kind,
+			&i.Spec,
+			&i.SourceProjectID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectTransferAuditLogs = `-- name: ListProjectTransferAuditLogs :many
+SELECT id, project_display_id, transfer_display_id, action, from_user_display_id, to_user_display_id, actor, created_at
+FROM tacokumo_admin.project_transfer_audit_logs
+WHERE project_display_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListProjectTransferAuditLogsParams struct {
+	ProjectDisplayID pgtype.UUID
+	Limit            int32
+	Offset           int32
+}
+
+// ListProjectTransferAuditLogs
+//
+//	SELECT id, project_display_id, transfer_display_id, action, from_user_display_id, to_user_display_id, actor, created_at
+//	FROM tacokumo_admin.project_transfer_audit_logs
+//	WHERE project_display_id = $1
+//	ORDER BY created_at DESC, id DESC
+//	LIMIT $2 OFFSET $3
+func (q *Queries) ListProjectTransferAuditLogs(ctx context.Context, arg ListProjectTransferAuditLogsParams) ([]TacokumoAdminProjectTransferAuditLog, error) {
+	rows, err := q.db.Query(ctx, listProjectTransferAuditLogs, arg.ProjectDisplayID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminProjectTransferAuditLog
+	for rows.Next() {
+		var i TacokumoAdminProjectTransferAuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectDisplayID,
+			&i.TransferDisplayID,
+			&i.Action,
+			&i.FromUserDisplayID,
+			&i.ToUserDisplayID,
+			&i.Actor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectTransfersWithPagination = `-- name: ListProjectTransfersWithPagination :many
+SELECT id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+FROM tacokumo_admin.project_transfers
+WHERE project_id = $1
+  AND ($4::VARCHAR IS NULL
+    OR ($4::VARCHAR = 'expired' AND (status = 'expired' OR (status = 'pending' AND expires_at <= NOW())))
+    OR ($4::VARCHAR = 'pending' AND status = 'pending' AND expires_at > NOW())
+    OR ($4::VARCHAR NOT IN ('pending', 'expired') AND status = $4::VARCHAR))
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListProjectTransfersWithPaginationParams struct {
+	ProjectID int64
+	Limit     int32
+	Offset    int32
+	Status    pgtype.Text
+}
+
+// status filters by transfer status; 'expired' also matches pending transfers past their expiry.
+//
+//	SELECT id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+//	FROM tacokumo_admin.project_transfers
+//	WHERE project_id = $1
+//	  AND ($4::VARCHAR IS NULL
+//	    OR ($4::VARCHAR = 'expired' AND (status = 'expired' OR (status = 'pending' AND expires_at <= NOW())))
+//	    OR ($4::VARCHAR = 'pending' AND status = 'pending' AND expires_at > NOW())
+//	    OR ($4::VARCHAR NOT IN ('pending', 'expired') AND status = $4::VARCHAR))
+//	ORDER BY created_at DESC
+//	LIMIT $2 OFFSET $3
+func (q *Queries) ListProjectTransfersWithPagination(ctx context.Context, arg ListProjectTransfersWithPaginationParams) ([]TacokumoAdminProjectTransfer, error) {
+	rows, err := q.db.Query(ctx, listProjectTransfersWithPagination,
+		arg.ProjectID,
+		arg.Limit,
+		arg.Offset,
+		arg.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminProjectTransfer
+	for rows.Next() {
+		var i TacokumoAdminProjectTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.FromUserID,
+			&i.ToUserID,
+			&i.Status,
+			&i.InitiatedBy,
+			&i.ExpiresAt,
+			&i.CompletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectUserGroupMembers = `-- name: ListProjectUserGroupMembers :many
+SELECT DISTINCT u.id, u.display_id, u.email, u.created_at, u.updated_at
+  FROM tacokumo_admin.users u
+  INNER JOIN tacokumo_admin.user_usergroups_relations uur ON u.id = uur.user_id
+  INNER JOIN tacokumo_admin.usergroups ug ON ug.id = uur.usergroup_id
+  WHERE ug.project_id = $1
+`
+
+// Lists the distinct users that are members of any user group of a project.
+//
+//	SELECT DISTINCT u.id, u.display_id, u.email, u.created_at, u.updated_at
+//	  FROM tacokumo_admin.users u
+//	  INNER JOIN tacokumo_admin.user_usergroups_relations uur ON u.id = uur.user_id
+//	  INNER JOIN tacokumo_admin.usergroups ug ON ug.id = uur.usergroup_id
+//	  WHERE ug.project_id = $1
+func (q *Queries) ListProjectUserGroupMembers(ctx context.Context, projectID int64) ([]TacokumoAdminUser, error) {
+	rows, err := q.db.Query(ctx, listProjectUserGroupMembers, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUser
+	for rows.Next() {
+		var i TacokumoAdminUser
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsByAnnotation = `-- name: ListProjectsByAnnotation :many
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+FROM tacokumo_admin.projects
+WHERE annotations ? $1::TEXT
+ORDER BY name
+`
+
+// Lists the projects that have the annotation key regardless of its value.
+//
+//	SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+//	FROM tacokumo_admin.projects
+//	WHERE annotations ? $1::TEXT
+//	ORDER BY name
+func (q *Queries) ListProjectsByAnnotation(ctx context.Context, key string) ([]TacokumoAdminProject, error) {
+	rows, err := q.db.Query(ctx, listProjectsByAnnotation, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminProject
+	for rows.Next() {
+		var i TacokumoAdminProject
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Name,
+			&i.Description,
+			&i.Kind,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.ArchivedBy,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsWithPagination = `-- name: ListProjectsWithPagination :many
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+FROM tacokumo_admin.projects
+WHERE ($3::BOOLEAN OR archived_at IS NULL)
+  AND ($4::JSONB IS NULL OR labels @> $4::JSONB)
+  AND ($5::TEXT[] IS NULL OR labels ?& $5::TEXT[])
+  AND ($6::TEXT[] IS NULL OR NOT (labels ?| $6::TEXT[]))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE($7::JSONB, '[]')) r
+    WHERE NOT COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE($8::JSONB, '[]')) r
+    WHERE COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListProjectsWithPaginationParams struct {
+	Limit           int32
+	Offset          int32
+	IncludeArchived bool
+	LabelEquals     []byte
+	LabelExists     []string
+	LabelNotExists  []string
+	LabelIn         []byte
+	LabelNotIn      []byte
+}
+
+// The label_* arguments come from a label selector (see pkg/labels); NULL skips the requirement.
+//
+//	SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+//	FROM tacokumo_admin.projects
+//	WHERE ($3::BOOLEAN OR archived_at IS NULL)
+//	  AND ($4::JSONB IS NULL OR labels @> $4::JSONB)
+//	  AND ($5::TEXT[] IS NULL OR labels ?& $5::TEXT[])
+//	  AND ($6::TEXT[] IS NULL OR NOT (labels ?| $6::TEXT[]))
+//	  AND NOT EXISTS (
+//	    SELECT 1 FROM jsonb_array_elements(COALESCE($7::JSONB, '[]')) r
+//	    WHERE NOT COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+//	  AND NOT EXISTS (
+//	    SELECT 1 FROM jsonb_array_elements(COALESCE($8::JSONB, '[]')) r
+//	    WHERE COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+//	ORDER BY created_at DESC
+//	LIMIT $1 OFFSET $2
+func (q *Queries) ListProjectsWithPagination(ctx context.Context, arg ListProjectsWithPaginationParams) ([]TacokumoAdminProject, error) {
+	rows, err := q.db.Query(ctx, listProjectsWithPagination,
+		arg.Limit,
+		arg.Offset,
+		arg.IncludeArchived,
+		arg.LabelEquals,
+		arg.LabelExists,
+		arg.LabelNotExists,
+		arg.LabelIn,
+		arg.LabelNotIn,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminProject
+	for rows.Next() {
+		var i TacokumoAdminProject
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Name,
+			&i.Description,
+			&i.Kind,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.ArchivedBy,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoleAttributeNames = `-- name: ListRoleAttributeNames :many
+SELECT ra.name
+  FROM tacokumo_admin.role_attributes ra
+  INNER JOIN tacokumo_admin.role_attributes_relations rar ON ra.id = rar.role_attribute_id
+  WHERE rar.role_id = $1
+  ORDER BY ra.name
+`
+
+// ListRoleAttributeNames
+//
+//	SELECT ra.name
+//	  FROM tacokumo_admin.role_attributes ra
+//	  INNER JOIN tacokumo_admin.role_attributes_relations rar ON ra.id = rar.role_attribute_id
+//	  WHERE rar.role_id = $1
+//	  ORDER BY ra.name
+func (q *Queries) ListRoleAttributeNames(ctx context.Context, roleID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, listRoleAttributeNames, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoleAttributes = `-- name: ListRoleAttributes :many
+SELECT id, name, description, created_at, updated_at
+FROM tacokumo_admin.role_attributes
+ORDER BY name
+`
+
+// ListRoleAttributes
+//
+//	SELECT id, name, description, created_at, updated_at
+//	FROM tacokumo_admin.role_attributes
+//	ORDER BY name
+func (q *Queries) ListRoleAttributes(ctx context.Context) ([]TacokumoAdminRoleAttribute, error) {
+	rows, err := q.db.Query(ctx, listRoleAttributes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminRoleAttribute
+	for rows.Next() {
+		var i TacokumoAdminRoleAttribute
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoleUsers = `-- name: ListRoleUsers :many
+SELECT u.id, u.display_id, u.email, u.created_at, u.updated_at
+  FROM tacokumo_admin.users u
+  INNER JOIN tacokumo_admin.user_role_relations urr ON u.id = urr.user_id
+  WHERE urr.role_id = $1
+  ORDER BY u.id
+`
+
+// Lists the users a role is assigned to directly.
+//
+//	SELECT u.id, u.display_id, u.email, u.created_at, u.updated_at
+//	  FROM tacokumo_admin.users u
+//	  INNER JOIN tacokumo_admin.user_role_relations urr ON u.id = urr.user_id
+//	  WHERE urr.role_id = $1
+//	  ORDER BY u.id
+func (q *Queries) ListRoleUsers(ctx context.Context, roleID int64) ([]TacokumoAdminUser, error) {
+	rows, err := q.db.Query(ctx, listRoleUsers, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUser
+	for rows.Next() {
+		var i TacokumoAdminUser
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRolesByProject = `-- name: ListRolesByProject :many
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+FROM tacokumo_admin.roles
+WHERE project_id = $1
+ORDER BY created_at, id
+`
+
+// ListRolesByProject
+//
+//	SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+//	FROM tacokumo_admin.roles
+//	WHERE project_id = $1
+//	ORDER BY created_at, id
+func (q *Queries) ListRolesByProject(ctx context.Context, projectID int64) ([]TacokumoAdminRole, error) {
+	rows, err := q.db.Query(ctx, listRolesByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminRole
+	for rows.Next() {
+		var i TacokumoAdminRole
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRolesWithPagination = `-- name: ListRolesWithPagination :many
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+FROM tacokumo_admin.roles
+WHERE project_id = $1
+  AND ($4::JSONB IS NULL OR labels @> $4::JSONB)
+  AND ($5::TEXT[] IS NULL OR labels ?& $5::TEXT[])
+  AND ($6::TEXT[] IS NULL OR NOT (labels ?| $6::TEXT[]))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE($7::JSONB, '[]')) r
+    WHERE NOT COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE($8::JSONB, '[]')) r
+    WHERE COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListRolesWithPaginationParams struct {
+	ProjectID      int64
+	Limit          int32
+	Offset         int32
+	LabelEquals    []byte
+	LabelExists    []string
+	LabelNotExists []string
+	LabelIn        []byte
+	LabelNotIn     []byte
+}
+
+// The label_* arguments come from a label selector (see pkg/labels); NULL skips the requirement.
+//
+//	SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+//	FROM tacokumo_admin.roles
+//	WHERE project_id = $1
+//	  AND ($4::JSONB IS NULL OR labels @> $4::JSONB)
+//	  AND ($5::TEXT[] IS NULL OR labels ?& $5::TEXT[])
+//	  AND ($6::TEXT[] IS NULL OR NOT (labels ?| $6::TEXT[]))
+//	  AND NOT EXISTS (
+//	    SELECT 1 FROM jsonb_array_elements(COALESCE($7::JSONB, '[]')) r
+//	    WHERE NOT COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+//	  AND NOT EXISTS (
+//	    SELECT 1 FROM jsonb_array_elements(COALESCE($8::JSONB, '[]')) r
+//	    WHERE COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+//	ORDER BY created_at DESC
+//	LIMIT $2 OFFSET $3
+func (q *Queries) ListRolesWithPagination(ctx context.Context, arg ListRolesWithPaginationParams) ([]TacokumoAdminRole, error) {
+	rows, err := q.db.Query(ctx, listRolesWithPagination,
+		arg.ProjectID,
+		arg.Limit,
+		arg.Offset,
+		arg.LabelEquals,
+		arg.LabelExists,
+		arg.LabelNotExists,
+		arg.LabelIn,
+		arg.LabelNotIn,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminRole
+	for rows.Next() {
+		var i TacokumoAdminRole
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSCIMGroups = `-- name: ListSCIMGroups :many
+SELECT g.id, g.display_id, g.project_id, g.name, g.description, g.version, g.created_at, g.updated_at,
+       sg.external_id
+FROM tacokumo_admin.usergroups g
+LEFT JOIN tacokumo_admin.scim_groups sg ON sg.usergroup_id = g.id
+WHERE g.project_id = $1
+  AND ($4::UUID IS NULL OR g.display_id = $4::UUID)
+  AND ($5::VARCHAR IS NULL OR g.name ILIKE $5::VARCHAR)
+  AND ($6::VARCHAR IS NULL OR sg.external_id LIKE $6::VARCHAR)
+ORDER BY g.id
+LIMIT $2 OFFSET $3
+`
+
+type ListSCIMGroupsParams struct {
+	ProjectID         int64
+	Limit             int32
+	Offset            int32
+	DisplayID         pgtype.UUID
+	NamePattern       pgtype.Text
+	ExternalIDPattern pgtype.Text
+}
+
+type ListSCIMGroupsRow struct {
+	ID          int64
+	DisplayID   pgtype.UUID
+	ProjectID   int64
+	Name        string
+	Description string
+	Version     int64
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	ExternalID  pgtype.Text
+}
+
+// ListSCIMGroups
+//
+//	SELECT g.id, g.display_id, g.project_id, g.name, g.description, g.version, g.created_at, g.updated_at,
+//	       sg.external_id
+//	FROM tacokumo_admin.usergroups g
+//	LEFT JOIN tacokumo_admin.scim_groups sg ON sg.usergroup_id = g.id
+//	WHERE g.project_id = $1
+//	  AND ($4::UUID IS NULL OR g.display_id = $4::UUID)
+//	  AND ($5::VARCHAR IS NULL OR g.name ILIKE $5::VARCHAR)
+//	  AND ($6::VARCHAR IS NULL OR sg.external_id LIKE $6::VARCHAR)
+//	ORDER BY g.id
+//	LIMIT $2 OFFSET $3
+func (q *Queries) ListSCIMGroups(ctx context.Context, arg ListSCIMGroupsParams) ([]ListSCIMGroupsRow, error) {
+	rows, err := q.db.Query(ctx, listSCIMGroups,
+		arg.ProjectID,
+		arg.Limit,
+		arg.Offset,
+		arg.DisplayID,
+		arg.NamePattern,
+		arg.ExternalIDPattern,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSCIMGroupsRow
+	for rows.Next() {
+		var i ListSCIMGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSCIMUsers = `-- name: ListSCIMUsers :many
+SELECT u.id, u.display_id, u.email, u.created_at, u.updated_at,
+       su.external_id, COALESCE(su.display_name, '')::VARCHAR AS display_name, COALESCE(su.active, TRUE)::BOOLEAN AS active,
+       GREATEST(u.updated_at, su.updated_at)::TIMESTAMPTZ AS last_modified
+FROM tacokumo_admin.users u
+LEFT JOIN tacokumo_admin.scim_users su ON su.user_id = u.id
+WHERE ($3::UUID IS NULL OR u.display_id = $3::UUID)
+  AND ($4::VARCHAR IS NULL OR u.email ILIKE $4::VARCHAR)
+  AND ($5::VARCHAR IS NULL OR su.external_id LIKE $5::VARCHAR)
+  AND ($6::VARCHAR IS NULL OR su.display_name ILIKE $6::VARCHAR)
+ORDER BY u.id
+LIMIT $1 OFFSET $2
+`
+
+type ListSCIMUsersParams struct {
+	Limit              int32
+	Offset             int32
+	DisplayID          pgtype.UUID
+	EmailPattern       pgtype.Text
+	ExternalIDPattern  pgtype.Text
+	DisplayNamePattern pgtype.Text
+}
+
+type ListSCIMUsersRow struct {
+	ID           int64
+	DisplayID    pgtype.UUID
+	Email        string
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+	ExternalID   pgtype.Text
+	DisplayName  string
+	Active       bool
+	LastModified pgtype.Timestamptz
+}
+
+// Patterns are ILIKE patterns (LIKE for the case exact external_id); NULL matches all.
+//
+//	SELECT u.id, u.display_id, u.email, u.created_at, u.updated_at,
+//	       su.external_id, COALESCE(su.display_name, '')::VARCHAR AS display_name, COALESCE(su.active, TRUE)::BOOLEAN AS active,
+//	       GREATEST(u.updated_at, su.updated_at)::TIMESTAMPTZ AS last_modified
+//	FROM tacokumo_admin.users u
+//	LEFT JOIN tacokumo_admin.scim_users su ON su.user_id = u.id
+//	WHERE ($3::UUID IS NULL OR u.display_id = $3::UUID)
+//	  AND ($4::VARCHAR IS NULL OR u.email ILIKE $4::VARCHAR)
+//	  AND ($5::VARCHAR IS NULL OR su.external_id LIKE $5::VARCHAR)
+//	  AND ($6::VARCHAR IS NULL OR su.display_name ILIKE $6::VARCHAR)
+//	ORDER BY u.id
+//	LIMIT $1 OFFSET $2
+func (q *Queries) ListSCIMUsers(ctx context.Context, arg ListSCIMUsersParams) ([]ListSCIMUsersRow, error) {
+	rows, err := q.db.Query(ctx, listSCIMUsers,
+		arg.Limit,
+		arg.Offset,
+		arg.DisplayID,
+		arg.EmailPattern,
+		arg.ExternalIDPattern,
+		arg.DisplayNamePattern,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSCIMUsersRow
+	for rows.Next() {
+		var i ListSCIMUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalID,
+			&i.DisplayName,
+			&i.Active,
+			&i.LastModified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceAccountRoles = `-- name: ListServiceAccountRoles :many
+SELECT ro.id, ro.display_id, ro.project_id, ro.name, ro.description, ro.version, ro.created_at, ro.updated_at, ro.labels, ro.annotations
+  FROM tacokumo_admin.roles ro
+  INNER JOIN tacokumo_admin.service_account_role_relations r ON ro.id = r.role_id
+  WHERE r.service_account_id = $1
+  ORDER BY ro.created_at DESC
+`
+
+// ListServiceAccountRoles
+//
+//	SELECT ro.id, ro.display_id, ro.project_id, ro.name, ro.description, ro.version, ro.created_at, ro.updated_at, ro.labels, ro.annotations
+//	  FROM tacokumo_admin.roles ro
+//	  INNER JOIN tacokumo_admin.service_account_role_relations r ON ro.id = r.role_id
+//	  WHERE r.service_account_id = $1
+//	  ORDER BY ro.created_at DESC
+func (q *Queries) ListServiceAccountRoles(ctx context.Context, serviceAccountID int64) ([]TacokumoAdminRole, error) {
+	rows, err := q.db.Query(ctx, listServiceAccountRoles, serviceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminRole
+	for rows.Next() {
+		var i TacokumoAdminRole
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceAccountTokens = `-- name: ListServiceAccountTokens :many
+SELECT id, display_id, service_account_id, description, token_prefix, token_hash, expires_at, last_used_at, revoked_at, created_at, updated_at
+FROM tacokumo_admin.service_account_tokens
+WHERE service_account_id = $1
+ORDER BY created_at DESC
+`
+
+// ListServiceAccountTokens
+//
+//	SELECT id, display_id, service_account_id, description, token_prefix, token_hash, expires_at, last_used_at, revoked_at, created_at, updated_at
+//	FROM tacokumo_admin.service_account_tokens
+//	WHERE service_account_id = $1
+//	ORDER BY created_at DESC
+func (q *Queries) ListServiceAccountTokens(ctx context.Context, serviceAccountID int64) ([]TacokumoAdminServiceAccountToken, error) {
+	rows, err := q.db.Query(ctx, listServiceAccountTokens, serviceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminServiceAccountToken
+	for rows.Next() {
+		var i TacokumoAdminServiceAccountToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ServiceAccountID,
+			&i.Description,
+			&i.TokenPrefix,
+			&i.TokenHash,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceAccountUserGroups = `-- name: ListServiceAccountUserGroups :many
+SELECT ug.id, ug.display_id, ug.project_id, ug.name, ug.description, ug.version, ug.created_at, ug.updated_at, ug.labels, ug.annotations
+  FROM tacokumo_admin.usergroups ug
+  INNER JOIN tacokumo_admin.service_account_usergroups_relations r ON ug.id = r.usergroup_id
+  WHERE r.service_account_id = $1
+  ORDER BY ug.created_at DESC
+`
+
+// ListServiceAccountUserGroups
+//
+//	SELECT ug.id, ug.display_id, ug.project_id, ug.name, ug.description, ug.version, ug.created_at, ug.updated_at, ug.labels, ug.annotations
+//	  FROM tacokumo_admin.usergroups ug
+//	  INNER JOIN tacokumo_admin.service_account_usergroups_relations r ON ug.id = r.usergroup_id
+//	  WHERE r.service_account_id = $1
+//	  ORDER BY ug.created_at DESC
+func (q *Queries) ListServiceAccountUserGroups(ctx context.Context, serviceAccountID int64) ([]TacokumoAdminUsergroup, error) {
+	rows, err := q.db.Query(ctx, listServiceAccountUserGroups, serviceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUsergroup
+	for rows.Next() {
+		var i TacokumoAdminUsergroup
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceAccountsWithPagination = `-- name: ListServiceAccountsWithPagination :many
+SELECT sa.id,
+      sa.display_id,
+      sa.project_id,
+      p.display_id AS project_display_id,
+      sa.name,
+      sa.description,
+      sa.owner_user_id,
+      u.display_id AS owner_display_id,
+      sa.disabled,
+      sa.created_at,
+      sa.updated_at
+  FROM tacokumo_admin.service_accounts sa
+  LEFT JOIN tacokumo_admin.projects p ON sa.project_id = p.id
+  LEFT JOIN tacokumo_admin.users u ON sa.owner_user_id = u.id
+  WHERE $3::BIGINT IS NULL OR sa.project_id = $3::BIGINT
+  ORDER BY sa.created_at DESC
+  LIMIT $1 OFFSET $2
+`
+
+type ListServiceAccountsWithPaginationParams struct {
+	Limit     int32
+	Offset    int32
+	ProjectID pgtype.Int8
+}
+
+type ListServiceAccountsWithPaginationRow struct {
+	ID               int64
+	DisplayID        pgtype.UUID
+	ProjectID        pgtype.Int8
+	ProjectDisplayID pgtype.UUID
+	Name             string
+	Description      string
+	OwnerUserID      pgtype.Int8
+	OwnerDisplayID   pgtype.UUID
+	Disabled         bool
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
+// ListServiceAccountsWithPagination
+//
+//	SELECT sa.id,
+//	      sa.display_id,
+//	      sa.project_id,
+//	      p.display_id AS project_display_id,
+//	      sa.name,
+//	      sa.description,
+//	      sa.owner_user_id,
+//	      u.display_id AS owner_display_id,
+//	      sa.disabled,
+//	      sa.created_at,
+//	      sa.updated_at
+//	  FROM tacokumo_admin.service_accounts sa
+//	  LEFT JOIN tacokumo_admin.projects p ON sa.project_id = p.id
+//	  LEFT JOIN tacokumo_admin.users u ON sa.owner_user_id = u.id
+//	  WHERE $3::BIGINT IS NULL OR sa.project_id = $3::BIGINT
+//	  ORDER BY sa.created_at DESC
+//	  LIMIT $1 OFFSET $2
+func (q *Queries) ListServiceAccountsWithPagination(ctx context.Context, arg ListServiceAccountsWithPaginationParams) ([]ListServiceAccountsWithPaginationRow, error) {
+	rows, err := q.db.Query(ctx, listServiceAccountsWithPagination, arg.Limit, arg.Offset, arg.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListServiceAccountsWithPaginationRow
+	for rows.Next() {
+		var i ListServiceAccountsWithPaginationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.ProjectDisplayID,
+			&i.Name,
+			&i.Description,
+			&i.OwnerUserID,
+			&i.OwnerDisplayID,
+			&i.Disabled,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserGroupMembers = `-- name: ListUserGroupMembers :many
+SELECT u.id,
+      u.display_id,
+      u.email,
+      u.created_at,
+      u.updated_at
+  FROM tacokumo_admin.users u
+  INNER JOIN tacokumo_admin.user_usergroups_relations uur ON u.id = uur.user_id
+  WHERE uur.usergroup_id = $1
+  ORDER BY u.created_at DESC
+`
+
+// ListUserGroupMembers
+//
+//	SELECT u.id,
+//	      u.display_id,
+//	      u.email,
+//	      u.created_at,
+//	      u.updated_at
+//	  FROM tacokumo_admin.users u
+//	  INNER JOIN tacokumo_admin.user_usergroups_relations uur ON u.id = uur.user_id
+//	  WHERE uur.usergroup_id = $1
+//	  ORDER BY u.created_at DESC
+func (q *Queries) ListUserGroupMembers(ctx context.Context, usergroupID int64) ([]TacokumoAdminUser, error) {
+	rows, err := q.db.Query(ctx, listUserGroupMembers, usergroupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUser
+	for rows.Next() {
+		var i TacokumoAdminUser
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserGroupRoleNames = `-- name: ListUserGroupRoleNames :many
+SELECT ro.name
+  FROM tacokumo_admin.roles ro
+  INNER JOIN tacokumo_admin.usergroup_role_relations ugr ON ro.id = ugr.role_id
+  WHERE ugr.usergroup_id = $1
+  ORDER BY ro.name
+`
+
+// ListUserGroupRoleNames
+//
+//	SELECT ro.name
+//	  FROM tacokumo_admin.roles ro
+//	  INNER JOIN tacokumo_admin.usergroup_role_relations ugr ON ro.id = ugr.role_id
+//	  WHERE ugr.usergroup_id = $1
+//	  ORDER BY ro.name
+func (q *Queries) ListUserGroupRoleNames(ctx context.Context, usergroupID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, listUserGroupRoleNames, usergroupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserGroupsByProject = `-- name: ListUserGroupsByProject :many
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+FROM tacokumo_admin.usergroups
+WHERE project_id = $1
+ORDER BY created_at, id
+`
+
+// ListUserGroupsByProject
+//
+//	SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+//	FROM tacokumo_admin.usergroups
+//	WHERE project_id = $1
+//	ORDER BY created_at, id
+func (q *Queries) ListUserGroupsByProject(ctx context.Context, projectID int64) ([]TacokumoAdminUsergroup, error) {
+	rows, err := q.db.Query(ctx, listUserGroupsByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUsergroup
+	for rows.Next() {
+		var i TacokumoAdminUsergroup
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserGroupsWithPagination = `-- name: ListUserGroupsWithPagination :many
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+FROM tacokumo_admin.usergroups
+WHERE project_id = $1
+  AND ($4::JSONB IS NULL OR labels @> $4::JSONB)
+  AND ($5::TEXT[] IS NULL OR labels ?& $5::TEXT[])
+  AND ($6::TEXT[] IS NULL OR NOT (labels ?| $6::TEXT[]))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE($7::JSONB, '[]')) r
+    WHERE NOT COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE($8::JSONB, '[]')) r
+    WHERE COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListUserGroupsWithPaginationParams struct {
+	ProjectID      int64
+	Limit          int32
+	Offset         int32
+	LabelEquals    []byte
+	LabelExists    []string
+	LabelNotExists []string
+	LabelIn        []byte
+	LabelNotIn     []byte
+}
+
+// The label_* arguments come from a label selector (see pkg/labels); NULL skips the requirement.
+//
+//	SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+//	FROM tacokumo_admin.usergroups
+//	WHERE project_id = $1
+//	  AND ($4::JSONB IS NULL OR labels @> $4::JSONB)
+//	  AND ($5::TEXT[] IS NULL OR labels ?& $5::TEXT[])
+//	  AND ($6::TEXT[] IS NULL OR NOT (labels ?| $6::TEXT[]))
+//	  AND NOT EXISTS (
+//	    SELECT 1 FROM jsonb_array_elements(COALESCE($7::JSONB, '[]')) r
+//	    WHERE NOT COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+//	  AND NOT EXISTS (
+//	    SELECT 1 FROM jsonb_array_elements(COALESCE($8::JSONB, '[]')) r
+//	    WHERE COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+//	ORDER BY created_at DESC
+//	LIMIT $2 OFFSET $3
+func (q *Queries) ListUserGroupsWithPagination(ctx context.Context, arg ListUserGroupsWithPaginationParams) ([]TacokumoAdminUsergroup, error) {
+	rows, err := q.db.Query(ctx, listUserGroupsWithPagination,
+		arg.ProjectID,
+		arg.Limit,
+		arg.Offset,
+		arg.LabelEquals,
+		arg.LabelExists,
+		arg.LabelNotExists,
+		arg.LabelIn,
+		arg.LabelNotIn,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminUsergroup
+	for rows.Next() {
+		var i TacokumoAdminUsergroup
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Description,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Labels,
+			&i.Annotations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersWithPagination = `-- name: ListUsersWithPagination :many
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListUsersWithPaginationParams struct {
+	Limit  int32
+	Offset int32
+}
+
+// ListUsersWithPagination
+//
+//	SELECT id, display_id, email, created_at, updated_at
+//	FROM tacokumo_admin.users
+//	ORDER BY created_at DESC
+//	LIMIT $1 OFFSET $2
+func (q *Queries) ListUsersWithPagination(ctx context.Context, arg ListUsersWithPaginationParams) ([]TacokumoAdminUser, error) {
+	rows, err := q.db.Query(ctx, listUsersWithPagination, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -367,153 +4137,776 @@ func (q *Queries) ListUserGroupMembers(ctx context.Context, usergroupID int64) (
 		}
 		items = append(items, i)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveriesWithPagination = `-- name: ListWebhookDeliveriesWithPagination :many
+SELECT id, display_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+FROM tacokumo_admin.webhook_deliveries
+WHERE subscription_id = $1
+  AND ($4::VARCHAR IS NULL OR status = $4::VARCHAR)
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListWebhookDeliveriesWithPaginationParams struct {
+	SubscriptionID int64
+	Limit          int32
+	Offset         int32
+	Status         pgtype.Text
+}
+
+// status filters by delivery status; NULL returns all deliveries.
+//
+//	SELECT id, display_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+//	FROM tacokumo_admin.webhook_deliveries
+//	WHERE subscription_id = $1
+//	  AND ($4::VARCHAR IS NULL OR status = $4::VARCHAR)
+//	ORDER BY created_at DESC
+//	LIMIT $2 OFFSET $3
+func (q *Queries) ListWebhookDeliveriesWithPagination(ctx context.Context, arg ListWebhookDeliveriesWithPaginationParams) ([]TacokumoAdminWebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveriesWithPagination,
+		arg.SubscriptionID,
+		arg.Limit,
+		arg.Offset,
+		arg.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminWebhookDelivery
+	for rows.Next() {
+		var i TacokumoAdminWebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastAttemptAt,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptionsWithPagination = `-- name: ListWebhookSubscriptionsWithPagination :many
+SELECT id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+FROM tacokumo_admin.webhook_subscriptions
+WHERE project_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListWebhookSubscriptionsWithPaginationParams struct {
+	ProjectID int64
+	Limit     int32
+	Offset    int32
+}
+
+// ListWebhookSubscriptionsWithPagination
+//
+//	SELECT id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+//	FROM tacokumo_admin.webhook_subscriptions
+//	WHERE project_id = $1
+//	ORDER BY created_at DESC
+//	LIMIT $2 OFFSET $3
+func (q *Queries) ListWebhookSubscriptionsWithPagination(ctx context.Context, arg ListWebhookSubscriptionsWithPaginationParams) ([]TacokumoAdminWebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listWebhookSubscriptionsWithPagination, arg.ProjectID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TacokumoAdminWebhookSubscription
+	for rows.Next() {
+		var i TacokumoAdminWebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayID,
+			&i.ProjectID,
+			&i.Url,
+			&i.Description,
+			&i.EventTypes,
+			&i.Secret,
+			&i.Disabled,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockProjectForArchival = `-- name: LockProjectForArchival :exec
+SELECT id
+FROM tacokumo_admin.projects
+WHERE display_id = $1
+FOR UPDATE
+`
+
+// Waits for the changes that hold LockProjectForChange and keeps new ones waiting until the
+// transaction ends. ArchiveProject alone takes a weaker lock that does not conflict with them.
+//
+//	SELECT id
+//	FROM tacokumo_admin.projects
+//	WHERE display_id = $1
+//	FOR UPDATE
+func (q *Queries) LockProjectForArchival(ctx context.Context, displayID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, lockProjectForArchival, displayID)
+	return err
+}
+
+const lockProjectForChange = `-- name: LockProjectForChange :one
+SELECT archived_at
+FROM tacokumo_admin.projects
+WHERE id = $1
+FOR KEY SHARE
+`
+
+// Takes a key share lock on the project row for the rest of the transaction. It conflicts with
+// LockProjectForArchival but not with updates of the row, so changes of a project run
+// concurrently with each other but not with its archival.
+//
+//	SELECT archived_at
+//	FROM tacokumo_admin.projects
+//	WHERE id = $1
+//	FOR KEY SHARE
+func (q *Queries) LockProjectForChange(ctx context.Context, id int64) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, lockProjectForChange, id)
+	var archived_at pgtype.Timestamptz
+	err := row.Scan(&archived_at)
+	return archived_at, err
+}
+
+const markInvitationAccepted = `-- name: MarkInvitationAccepted :execrows
+UPDATE tacokumo_admin.invitations
+SET (status, accepted_at, accepted_user_id, updated_at) = ('accepted', NOW(), $2, NOW())
+WHERE id = $1 AND status = 'pending'
+`
+
+type MarkInvitationAcceptedParams struct {
+	ID             int64
+	AcceptedUserID pgtype.Int8
+}
+
+// MarkInvitationAccepted
+//
+//	UPDATE tacokumo_admin.invitations
+//	SET (status, accepted_at, accepted_user_id, updated_at) = ('accepted', NOW(), $2, NOW())
+//	WHERE id = $1 AND status = 'pending'
+func (q *Queries) MarkInvitationAccepted(ctx context.Context, arg MarkInvitationAcceptedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markInvitationAccepted, arg.ID, arg.AcceptedUserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markWebhookDeliveryFailed = `-- name: MarkWebhookDeliveryFailed :exec
+UPDATE tacokumo_admin.webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    last_attempt_at = NOW(),
+    last_status_code = $3,
+    last_error = $4,
+    next_attempt_at = $5,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type MarkWebhookDeliveryFailedParams struct {
+	ID             int64
+	Status         string
+	LastStatusCode pgtype.Int4
+	LastError      pgtype.Text
+	NextAttemptAt  pgtype.Timestamptz
+}
+
+// status is 'pending' to retry at next_attempt_at, or 'dead' once retries are exhausted.
+//
+//	UPDATE tacokumo_admin.webhook_deliveries
+//	SET status = $2,
+//	    attempts = attempts + 1,
+//	    last_attempt_at = NOW(),
+//	    last_status_code = $3,
+//	    last_error = $4,
+//	    next_attempt_at = $5,
+//	    updated_at = NOW()
+//	WHERE id = $1
+func (q *Queries) MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error {
+	_, err := q.db.Exec(ctx, markWebhookDeliveryFailed,
+		arg.ID,
+		arg.Status,
+		arg.LastStatusCode,
+		arg.LastError,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const markWebhookDeliverySucceeded = `-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE tacokumo_admin.webhook_deliveries
+SET status = 'succeeded',
+    attempts = attempts + 1,
+    last_attempt_at = NOW(),
+    last_status_code = $2,
+    last_error = NULL,
+    delivered_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type MarkWebhookDeliverySucceededParams struct {
+	ID             int64
+	LastStatusCode pgtype.Int4
+}
+
+// MarkWebhookDeliverySucceeded
+//
+//	UPDATE tacokumo_admin.webhook_deliveries
+//	SET status = 'succeeded',
+//	    attempts = attempts + 1,
+//	    last_attempt_at = NOW(),
+//	    last_status_code = $2,
+//	    last_error = NULL,
+//	    delivered_at = NOW(),
+//	    updated_at = NOW()
+//	WHERE id = $1
+func (q *Queries) MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) error {
+	_, err := q.db.Exec(ctx, markWebhookDeliverySucceeded, arg.ID, arg.LastStatusCode)
+	return err
+}
+
+const removeProjectOwnerGroup = `-- name: RemoveProjectOwnerGroup :exec
+DELETE FROM tacokumo_admin.project_owner_groups
+WHERE project_id = $1 AND usergroup_id = $2
+`
+
+type RemoveProjectOwnerGroupParams struct {
+	ProjectID   int64
+	UsergroupID int64
+}
+
+// RemoveProjectOwnerGroup
+//
+//	DELETE FROM tacokumo_admin.project_owner_groups
+//	WHERE project_id = $1 AND usergroup_id = $2
+func (q *Queries) RemoveProjectOwnerGroup(ctx context.Context, arg RemoveProjectOwnerGroupParams) error {
+	_, err := q.db.Exec(ctx, removeProjectOwnerGroup, arg.ProjectID, arg.UsergroupID)
+	return err
+}
+
+const removeRoleAttributeByName = `-- name: RemoveRoleAttributeByName :exec
+DELETE FROM tacokumo_admin.role_attributes_relations rar
+USING tacokumo_admin.role_attributes ra
+WHERE rar.role_attribute_id = ra.id AND rar.role_id = $1 AND ra.name = $2
+`
+
+type RemoveRoleAttributeByNameParams struct {
+	RoleID int64
+	Name   string
+}
+
+// RemoveRoleAttributeByName
+//
+//	DELETE FROM tacokumo_admin.role_attributes_relations rar
+//	USING tacokumo_admin.role_attributes ra
+//	WHERE rar.role_attribute_id = ra.id AND rar.role_id = $1 AND ra.name = $2
+func (q *Queries) RemoveRoleAttributeByName(ctx context.Context, arg RemoveRoleAttributeByNameParams) error {
+	_, err := q.db.Exec(ctx, removeRoleAttributeByName, arg.RoleID, arg.Name)
+	return err
+}
+
+const removeServiceAccountFromUserGroup = `-- name: RemoveServiceAccountFromUserGroup :execrows
+DELETE FROM tacokumo_admin.service_account_usergroups_relations
+WHERE service_account_id = $1 AND usergroup_id = $2
+`
+
+type RemoveServiceAccountFromUserGroupParams struct {
+	ServiceAccountID int64
+	UsergroupID      int64
+}
+
+// RemoveServiceAccountFromUserGroup
+//
+//	DELETE FROM tacokumo_admin.service_account_usergroups_relations
+//	WHERE service_account_id = $1 AND usergroup_id = $2
+func (q *Queries) RemoveServiceAccountFromUserGroup(ctx context.Context, arg RemoveServiceAccountFromUserGroupParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeServiceAccountFromUserGroup, arg.ServiceAccountID, arg.UsergroupID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const removeUserFromUserGroup = `-- name: RemoveUserFromUserGroup :execrows
+DELETE FROM tacokumo_admin.user_usergroups_relations
+WHERE user_id = $1 AND usergroup_id = $2
+`
+
+type RemoveUserFromUserGroupParams struct {
+	UserID      int64
+	UsergroupID int64
+}
+
+// RemoveUserFromUserGroup
+//
+//	DELETE FROM tacokumo_admin.user_usergroups_relations
+//	WHERE user_id = $1 AND usergroup_id = $2
+func (q *Queries) RemoveUserFromUserGroup(ctx context.Context, arg RemoveUserFromUserGroupParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeUserFromUserGroup, arg.UserID, arg.UsergroupID)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const listUserGroupsWithPagination = `-- name: ListUserGroupsWithPagination :many
-SELECT id, display_id, project_id, name, description, created_at, updated_at
-FROM tacokumo_admin.usergroups
-WHERE project_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+const requeueFailedJob = `-- name: RequeueFailedJob :execrows
+UPDATE tacokumo_admin.jobs
+SET status = 'pending', attempts = 0, run_at = NOW(), last_error = NULL, finished_at = NULL, updated_at = NOW()
+WHERE display_id = $1 AND status = 'failed'
 `
 
-type ListUserGroupsWithPaginationParams struct {
+// RequeueFailedJob
+//
+//	UPDATE tacokumo_admin.jobs
+//	SET status = 'pending', attempts = 0, run_at = NOW(), last_error = NULL, finished_at = NULL, updated_at = NOW()
+//	WHERE display_id = $1 AND status = 'failed'
+func (q *Queries) RequeueFailedJob(ctx context.Context, displayID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, requeueFailedJob, displayID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const retryJob = `-- name: RetryJob :exec
+UPDATE tacokumo_admin.jobs
+SET status = 'pending', locked_by = NULL, locked_until = NULL, last_error = $3, run_at = $4, updated_at = NOW()
+WHERE id = $1 AND locked_by = $2
+`
+
+type RetryJobParams struct {
+	ID        int64
+	LockedBy  pgtype.Text
+	LastError pgtype.Text
+	RunAt     pgtype.Timestamptz
+}
+
+// RetryJob
+//
+//	UPDATE tacokumo_admin.jobs
+//	SET status = 'pending', locked_by = NULL, locked_until = NULL, last_error = $3, run_at = $4, updated_at = NOW()
+//	WHERE id = $1 AND locked_by = $2
+func (q *Queries) RetryJob(ctx context.Context, arg RetryJobParams) error {
+	_, err := q.db.Exec(ctx, retryJob,
+		arg.ID,
+		arg.LockedBy,
+		arg.LastError,
+		arg.RunAt,
+	)
+	return err
+}
+
+const revokeInvitation = `-- name: RevokeInvitation :execrows
+UPDATE tacokumo_admin.invitations
+SET (status, updated_at) = ('revoked', NOW())
+WHERE project_id = $1 AND display_id = $2 AND status = 'pending'
+`
+
+type RevokeInvitationParams struct {
 	ProjectID int64
-	Limit     int32
-	Offset    int32
+	DisplayID pgtype.UUID
 }
 
-// ListUserGroupsWithPagination
+// RevokeInvitation
 //
-//	SELECT id, display_id, project_id, name, description, created_at, updated_at
-//	FROM tacokumo_admin.usergroups
-//	WHERE project_id = $1
-//	ORDER BY created_at DESC
-//	LIMIT $2 OFFSET $3
-func (q *Queries) ListUserGroupsWithPagination(ctx context.Context, arg ListUserGroupsWithPaginationParams) ([]TacokumoAdminUsergroup, error) {
-	rows, err := q.db.Query(ctx, listUserGroupsWithPagination, arg.ProjectID, arg.Limit, arg.Offset)
+//	UPDATE tacokumo_admin.invitations
+//	SET (status, updated_at) = ('revoked', NOW())
+//	WHERE project_id = $1 AND display_id = $2 AND status = 'pending'
+func (q *Queries) RevokeInvitation(ctx context.Context, arg RevokeInvitationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeInvitation, arg.ProjectID, arg.DisplayID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []TacokumoAdminUsergroup
-	for rows.Next() {
-		var i TacokumoAdminUsergroup
-		if err := rows.Scan(
-			&i.ID,
-			&i.DisplayID,
-			&i.ProjectID,
-			&i.Name,
-			&i.Description,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected(), nil
+}
+
+const revokePendingInvitationsForEmail = `-- name: RevokePendingInvitationsForEmail :execrows
+UPDATE tacokumo_admin.invitations
+SET (status, updated_at) = ('revoked', NOW())
+WHERE project_id = $1 AND email = $2 AND status = 'pending'
+`
+
+type RevokePendingInvitationsForEmailParams struct {
+	ProjectID int64
+	Email     string
+}
+
+// RevokePendingInvitationsForEmail
+//
+//	UPDATE tacokumo_admin.invitations
+//	SET (status, updated_at) = ('revoked', NOW())
+//	WHERE project_id = $1 AND email = $2 AND status = 'pending'
+func (q *Queries) RevokePendingInvitationsForEmail(ctx context.Context, arg RevokePendingInvitationsForEmailParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokePendingInvitationsForEmail, arg.ProjectID, arg.Email)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const listUsersWithPagination = `-- name: ListUsersWithPagination :many
-SELECT id, display_id, email, created_at, updated_at
-FROM tacokumo_admin.users
-ORDER BY created_at DESC
-LIMIT $1 OFFSET $2
+const revokeServiceAccountToken = `-- name: RevokeServiceAccountToken :execrows
+UPDATE tacokumo_admin.service_account_tokens
+SET (revoked_at, updated_at) = (NOW(), NOW())
+WHERE service_account_id = $1 AND display_id = $2 AND revoked_at IS NULL
 `
 
-type ListUsersWithPaginationParams struct {
-	Limit  int32
-	Offset int32
+type RevokeServiceAccountTokenParams struct {
+	ServiceAccountID int64
+	DisplayID        pgtype.UUID
 }
 
-// ListUsersWithPagination
+// RevokeServiceAccountToken
 //
-//	SELECT id, display_id, email, created_at, updated_at
-//	FROM tacokumo_admin.users
-//	ORDER BY created_at DESC
-//	LIMIT $1 OFFSET $2
-func (q *Queries) ListUsersWithPagination(ctx context.Context, arg ListUsersWithPaginationParams) ([]TacokumoAdminUser, error) {
-	rows, err := q.db.Query(ctx, listUsersWithPagination, arg.Limit, arg.Offset)
+//	UPDATE tacokumo_admin.service_account_tokens
+//	SET (revoked_at, updated_at) = (NOW(), NOW())
+//	WHERE service_account_id = $1 AND display_id = $2 AND revoked_at IS NULL
+func (q *Queries) RevokeServiceAccountToken(ctx context.Context, arg RevokeServiceAccountTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeServiceAccountToken, arg.ServiceAccountID, arg.DisplayID)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
-	var items []TacokumoAdminUser
-	for rows.Next() {
-		var i TacokumoAdminUser
-		if err := rows.Scan(
-			&i.ID,
-			&i.DisplayID,
-			&i.Email,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+	return result.RowsAffected(), nil
+}
+
+const rotateInvitationToken = `-- name: RotateInvitationToken :one
+UPDATE tacokumo_admin.invitations
+SET (token_hash, expires_at, updated_at) = ($3, $4, NOW())
+WHERE project_id = $1 AND display_id = $2 AND status = 'pending'
+RETURNING id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+`
+
+type RotateInvitationTokenParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+	TokenHash string
+	ExpiresAt pgtype.Timestamptz
+}
+
+// RotateInvitationToken
+//
+//	UPDATE tacokumo_admin.invitations
+//	SET (token_hash, expires_at, updated_at) = ($3, $4, NOW())
+//	WHERE project_id = $1 AND display_id = $2 AND status = 'pending'
+//	RETURNING id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+func (q *Queries) RotateInvitationToken(ctx context.Context, arg RotateInvitationTokenParams) (TacokumoAdminInvitation, error) {
+	row := q.db.QueryRow(ctx, rotateInvitationToken,
+		arg.ProjectID,
+		arg.DisplayID,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i TacokumoAdminInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Email,
+		&i.TokenHash,
+		&i.Status,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedUserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const rotateWebhookSecret = `-- name: RotateWebhookSecret :one
+UPDATE tacokumo_admin.webhook_subscriptions
+SET secret = $3, updated_at = NOW()
+WHERE project_id = $1 AND display_id = $2
+RETURNING id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+`
+
+type RotateWebhookSecretParams struct {
+	ProjectID int64
+	DisplayID pgtype.UUID
+	Secret    string
+}
+
+// RotateWebhookSecret
+//
+//	UPDATE tacokumo_admin.webhook_subscriptions
+//	SET secret = $3, updated_at = NOW()
+//	WHERE project_id = $1 AND display_id = $2
+//	RETURNING id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+func (q *Queries) RotateWebhookSecret(ctx context.Context, arg RotateWebhookSecretParams) (TacokumoAdminWebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, rotateWebhookSecret, arg.ProjectID, arg.DisplayID, arg.Secret)
+	var i TacokumoAdminWebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayID,
+		&i.ProjectID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Disabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const touchServiceAccountToken = `-- name: TouchServiceAccountToken :exec
+UPDATE tacokumo_admin.service_account_tokens
+SET last_used_at = NOW()
+WHERE id = $1
+`
+
+// TouchServiceAccountToken
+//
+//	UPDATE tacokumo_admin.service_account_tokens
+//	SET last_used_at = NOW()
+//	WHERE id = $1
+func (q *Queries) TouchServiceAccountToken(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, touchServiceAccountToken, id)
+	return err
+}
+
+const unarchiveProject = `-- name: UnarchiveProject :execrows
+UPDATE tacokumo_admin.projects
+SET archived_at = NULL,
+    archived_by = NULL,
+    version = version + 1,
+    updated_at = NOW()
+WHERE display_id = $1
+  AND archived_at IS NOT NULL
+  AND ($2::BIGINT[] IS NULL OR version = ANY($2::BIGINT[]))
+`
+
+type UnarchiveProjectParams struct {
+	DisplayID        pgtype.UUID
+	ExpectedVersions []int64
+}
+
+// expected_versions is the list of versions from If-Match; NULL skips the check.
+//
+//	UPDATE tacokumo_admin.projects
+//	SET archived_at = NULL,
+//	    archived_by = NULL,
+//	    version = version + 1,
+//	    updated_at = NOW()
+//	WHERE display_id = $1
+//	  AND archived_at IS NOT NULL
+//	  AND ($2::BIGINT[] IS NULL OR version = ANY($2::BIGINT[]))
+func (q *Queries) UnarchiveProject(ctx context.Context, arg UnarchiveProjectParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unarchiveProject, arg.DisplayID, arg.ExpectedVersions)
+	if err != nil {
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected(), nil
+}
+
+const unassignRoleFromServiceAccount = `-- name: UnassignRoleFromServiceAccount :execrows
+DELETE FROM tacokumo_admin.service_account_role_relations
+WHERE service_account_id = $1 AND role_id = $2
+`
+
+type UnassignRoleFromServiceAccountParams struct {
+	ServiceAccountID int64
+	RoleID           int64
+}
+
+// UnassignRoleFromServiceAccount
+//
+//	DELETE FROM tacokumo_admin.service_account_role_relations
+//	WHERE service_account_id = $1 AND role_id = $2
+func (q *Queries) UnassignRoleFromServiceAccount(ctx context.Context, arg UnassignRoleFromServiceAccountParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unassignRoleFromServiceAccount, arg.ServiceAccountID, arg.RoleID)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
+}
+
+const unassignRoleFromUserGroup = `-- name: UnassignRoleFromUserGroup :exec
+DELETE FROM tacokumo_admin.usergroup_role_relations
+WHERE usergroup_id = $1 AND role_id = $2
+`
+
+type UnassignRoleFromUserGroupParams struct {
+	UsergroupID int64
+	RoleID      int64
+}
+
+// UnassignRoleFromUserGroup
+//
+//	DELETE FROM tacokumo_admin.usergroup_role_relations
+//	WHERE usergroup_id = $1 AND role_id = $2
+func (q *Queries) UnassignRoleFromUserGroup(ctx context.Context, arg UnassignRoleFromUserGroupParams) error {
+	_, err := q.db.Exec(ctx, unassignRoleFromUserGroup, arg.UsergroupID, arg.RoleID)
+	return err
 }
 
-const updateProject = `-- name: UpdateProject :exec
+const updateProject = `-- name: UpdateProject :execrows
 UPDATE tacokumo_admin.projects
-SET (name, description, updated_at) = ($2, $3, NOW())
+SET name = $2,
+    description = $3,
+    kind = COALESCE($4, kind),
+    labels = COALESCE($5::JSONB, labels),
+    annotations = COALESCE($6::JSONB, annotations),
+    version = version + 1,
+    updated_at = NOW()
 WHERE display_id = $1
+  AND ($7::BIGINT[] IS NULL OR version = ANY($7::BIGINT[]))
 `
 
 type UpdateProjectParams struct {
-	DisplayID   pgtype.UUID
-	Name        string
-	Description string
+	DisplayID        pgtype.UUID
+	Name             string
+	Description      string
+	Kind             pgtype.Text
+	Labels           []byte
+	Annotations      []byte
+	ExpectedVersions []int64
 }
 
-// UpdateProject
+// expected_versions is the list of versions from If-Match; NULL skips the check.
+// kind, labels and annotations are left unchanged when NULL.
 //
 //	UPDATE tacokumo_admin.projects
-//	SET (name, description, updated_at) = ($2, $3, NOW())
+//	SET name = $2,
+//	    description = $3,
+//	    kind = COALESCE($4, kind),
+//	    labels = COALESCE($5::JSONB, labels),
+//	    annotations = COALESCE($6::JSONB, annotations),
+//	    version = version + 1,
+//	    updated_at = NOW()
 //	WHERE display_id = $1
-func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) error {
-	_, err := q.db.Exec(ctx, updateProject, arg.DisplayID, arg.Name, arg.Description)
-	return err
+//	  AND ($7::BIGINT[] IS NULL OR version = ANY($7::BIGINT[]))
+func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateProject,
+		arg.DisplayID,
+		arg.Name,
+		arg.Description,
+		arg.Kind,
+		arg.Labels,
+		arg.Annotations,
+		arg.ExpectedVersions,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const updateRole = `-- name: UpdateRole :exec
+const updateRole = `-- name: UpdateRole :execrows
 UPDATE tacokumo_admin.roles
-SET (name, description, updated_at) = ($3, $4, NOW())
+SET name = $3,
+    description = $4,
+    labels = COALESCE($5::JSONB, labels),
+    annotations = COALESCE($6::JSONB, annotations),
+    version = version + 1,
+    updated_at = NOW()
 WHERE project_id = $1 AND display_id = $2
+  AND ($7::BIGINT[] IS NULL OR version = ANY($7::BIGINT[]))
 `
 
 type UpdateRoleParams struct {
-	ProjectID   int64
-	DisplayID   pgtype.UUID
-	Name        string
-	Description string
+	ProjectID        int64
+	DisplayID        pgtype.UUID
+	Name             string
+	Description      string
+	Labels           []byte
+	Annotations      []byte
+	ExpectedVersions []int64
 }
 
-// UpdateRole
+// labels and annotations are left unchanged when NULL.
 //
 //	UPDATE tacokumo_admin.roles
-//	SET (name, description, updated_at) = ($3, $4, NOW())
+//	SET name = $3,
+//	    description = $4,
+//	    labels = COALESCE($5::JSONB, labels),
+//	    annotations = COALESCE($6::JSONB, annotations),
+//	    version = version + 1,
+//	    updated_at = NOW()
 //	WHERE project_id = $1 AND display_id = $2
-func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) error {
-	_, err := q.db.Exec(ctx, updateRole,
+//	  AND ($7::BIGINT[] IS NULL OR version = ANY($7::BIGINT[]))
+func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateRole,
 		arg.ProjectID,
 		arg.DisplayID,
 		arg.Name,
 		arg.Description,
+		arg.Labels,
+		arg.Annotations,
+		arg.ExpectedVersions,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateServiceAccount = `-- name: UpdateServiceAccount :execrows
+UPDATE tacokumo_admin.service_accounts
+SET (name, description, owner_user_id, disabled, updated_at) = ($2, $3, $4, $5, NOW())
+WHERE display_id = $1
+`
+
+type UpdateServiceAccountParams struct {
+	DisplayID   pgtype.UUID
+	Name        string
+	Description string
+	OwnerUserID pgtype.Int8
+	Disabled    bool
+}
+
+// UpdateServiceAccount
+//
+//	UPDATE tacokumo_admin.service_accounts
+//	SET (name, description, owner_user_id, disabled, updated_at) = ($2, $3, $4, $5, NOW())
+//	WHERE display_id = $1
+func (q *Queries) UpdateServiceAccount(ctx context.Context, arg UpdateServiceAccountParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateServiceAccount,
+		arg.DisplayID,
+		arg.Name,
+		arg.Description,
+		arg.OwnerUserID,
+		arg.Disabled,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const updateUser = `-- name: UpdateUser :exec
@@ -537,30 +4930,140 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	return err
 }
 
-const updateUserGroup = `-- name: UpdateUserGroup :exec
+const updateUserGroup = `-- name: UpdateUserGroup :execrows
 UPDATE tacokumo_admin.usergroups
-SET (name, description, updated_at) = ($3, $4, NOW())
+SET name = $3,
+    description = $4,
+    labels = COALESCE($5::JSONB, labels),
+    annotations = COALESCE($6::JSONB, annotations),
+    version = version + 1,
+    updated_at = NOW()
 WHERE project_id = $1 AND display_id = $2
+  AND ($7::BIGINT[] IS NULL OR version = ANY($7::BIGINT[]))
 `
 
 type UpdateUserGroupParams struct {
+	ProjectID        int64
+	DisplayID        pgtype.UUID
+	Name             string
+	Description      string
+	Labels           []byte
+	Annotations      []byte
+	ExpectedVersions []int64
+}
+
+// labels and annotations are left unchanged when NULL.
+//
+//	UPDATE tacokumo_admin.usergroups
+//	SET name = $3,
+//	    description = $4,
+//	    labels = COALESCE($5::JSONB, labels),
+//	    annotations = COALESCE($6::JSONB, annotations),
+//	    version = version + 1,
+//	    updated_at = NOW()
+//	WHERE project_id = $1 AND display_id = $2
+//	  AND ($7::BIGINT[] IS NULL OR version = ANY($7::BIGINT[]))
+func (q *Queries) UpdateUserGroup(ctx context.Context, arg UpdateUserGroupParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserGroup,
+		arg.ProjectID,
+		arg.DisplayID,
+		arg.Name,
+		arg.Description,
+		arg.Labels,
+		arg.Annotations,
+		arg.ExpectedVersions,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateWebhookSubscription = `-- name: UpdateWebhookSubscription :execrows
+UPDATE tacokumo_admin.webhook_subscriptions
+SET (url, description, event_types, disabled, updated_at) = ($3, $4, $5, $6, NOW())
+WHERE project_id = $1 AND display_id = $2
+`
+
+type UpdateWebhookSubscriptionParams struct {
 	ProjectID   int64
 	DisplayID   pgtype.UUID
-	Name        string
+	Url         string
 	Description string
+	EventTypes  []string
+	Disabled    bool
 }
 
-// UpdateUserGroup
+// The secret is only changed by RotateWebhookSecret, so that an update cannot restore a secret
+// that was rotated after it was read.
 //
-//	UPDATE tacokumo_admin.usergroups
-//	SET (name, description, updated_at) = ($3, $4, NOW())
+//	UPDATE tacokumo_admin.webhook_subscriptions
+//	SET (url, description, event_types, disabled, updated_at) = ($3, $4, $5, $6, NOW())
 //	WHERE project_id = $1 AND display_id = $2
-func (q *Queries) UpdateUserGroup(ctx context.Context, arg UpdateUserGroupParams) error {
-	_, err := q.db.Exec(ctx, updateUserGroup,
+func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateWebhookSubscription,
 		arg.ProjectID,
 		arg.DisplayID,
-		arg.Name,
+		arg.Url,
 		arg.Description,
+		arg.EventTypes,
+		arg.Disabled,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertSCIMGroup = `-- name: UpsertSCIMGroup :exec
+INSERT INTO tacokumo_admin.scim_groups (usergroup_id, external_id)
+VALUES ($1, $2)
+ON CONFLICT (usergroup_id) DO UPDATE
+SET external_id = EXCLUDED.external_id, updated_at = NOW()
+`
+
+type UpsertSCIMGroupParams struct {
+	UsergroupID int64
+	ExternalID  pgtype.Text
+}
+
+// UpsertSCIMGroup
+//
+//	INSERT INTO tacokumo_admin.scim_groups (usergroup_id, external_id)
+//	VALUES ($1, $2)
+//	ON CONFLICT (usergroup_id) DO UPDATE
+//	SET external_id = EXCLUDED.external_id, updated_at = NOW()
+func (q *Queries) UpsertSCIMGroup(ctx context.Context, arg UpsertSCIMGroupParams) error {
+	_, err := q.db.Exec(ctx, upsertSCIMGroup, arg.UsergroupID, arg.ExternalID)
+	return err
+}
+
+const upsertSCIMUser = `-- name: UpsertSCIMUser :exec
+INSERT INTO tacokumo_admin.scim_users (user_id, external_id, display_name, active)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET external_id = EXCLUDED.external_id, display_name = EXCLUDED.display_name, active = EXCLUDED.active, updated_at = NOW()
+`
+
+type UpsertSCIMUserParams struct {
+	UserID      int64
+	ExternalID  pgtype.Text
+	DisplayName string
+	Active      bool
+}
+
+// UpsertSCIMUser
+//
+//	INSERT INTO tacokumo_admin.scim_users (user_id, external_id, display_name, active)
+//	VALUES ($1, $2, $3, $4)
+//	ON CONFLICT (user_id) DO UPDATE
+//	SET external_id = EXCLUDED.external_id, display_name = EXCLUDED.display_name, active = EXCLUDED.active, updated_at = NOW()
+func (q *Queries) UpsertSCIMUser(ctx context.Context, arg UpsertSCIMUserParams) error {
+	_, err := q.db.Exec(ctx, upsertSCIMUser,
+		arg.UserID,
+		arg.ExternalID,
+		arg.DisplayName,
+		arg.Active,
 	)
 	return err
 }
@@ -0,0 +1,589 @@
+package declarative
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+// Load reads the current state of the projects in the manifest, and with prune also of the
+// managed projects that it does not list.
+func Load(ctx context.Context, q *admindb.Queries, m Manifest, prune bool) (State, error) {
+	var state State
+	for _, p := range m.Projects {
+		proj, err := q.GetProjectByName(ctx, p.Name)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return state, errors.Wrapf(err, "failed to get project by name")
+		}
+		ps, err := loadProject(ctx, q, proj)
+		if err != nil {
+			return state, err
+		}
+		state.Projects = append(state.Projects, ps)
+	}
+
+	if prune {
+		managed, err := q.ListProjectsByAnnotation(ctx, ManagedAnnotation)
+		if err != nil {
+			return state, errors.Wrapf(err, "failed to list managed projects")
+		}
+		for _, proj := range managed {
+			if slices.ContainsFunc(state.Projects, func(ps ProjectState) bool { return ps.ID == proj.ID }) {
+				continue
+			}
+			ps, err := loadProject(ctx, q, proj)
+			if err != nil {
+				return state, err
+			}
+			state.Projects = append(state.Projects, ps)
+		}
+	}
+	return state, nil
+}
+
+func loadProject(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject) (ProjectState, error) {
+	ps := ProjectState{
+		Project: Project{
+			Name:        proj.Name,
+			Description: proj.Description,
+			Kind:        proj.Kind,
+		},
+		ID:        proj.ID,
+		DisplayID: proj.DisplayID,
+		Archived:  project.IsArchived(proj),
+	}
+	var err error
+	if ps.Labels, ps.Annotations, err = decodeMetadata(proj.Labels, proj.Annotations); err != nil {
+		return ps, err
+	}
+	_, ps.Managed = ps.Annotations[ManagedAnnotation]
+
+	owners, err := q.ListProjectOwners(ctx, proj.ID)
+	if err != nil {
+		return ps, errors.Wrapf(err, "failed to list project owners")
+	}
+	for _, owner := range owners {
+		ps.Owners = append(ps.Owners, owner.Email)
+	}
+
+	roles, err := q.ListRolesByProject(ctx, proj.ID)
+	if err != nil {
+		return ps, errors.Wrapf(err, "failed to list roles by project")
+	}
+	for _, r := range roles {
+		rs := RoleState{Role: Role{Name: r.Name, Description: r.Description}, ID: r.ID, DisplayID: r.DisplayID}
+		if rs.Attributes, err = q.ListRoleAttributeNames(ctx, r.ID); err != nil {
+			return ps, errors.Wrapf(err, "failed to list role attribute names")
+		}
+		if rs.Labels, rs.Annotations, err = decodeMetadata(r.Labels, r.Annotations); err != nil {
+			return ps, err
+		}
+		ps.RoleStates = append(ps.RoleStates, rs)
+	}
+
+	ownerGroups, err := q.ListProjectOwnerGroups(ctx, proj.ID)
+	if err != nil {
+		return ps, errors.Wrapf(err, "failed to list project owner groups")
+	}
+	groups, err := q.ListUserGroupsByProject(ctx, proj.ID)
+	if err != nil {
+		return ps, errors.Wrapf(err, "failed to list user groups by project")
+	}
+	for _, g := range groups {
+		gs := UserGroupState{
+			UserGroup: UserGroup{
+				Name:        g.Name,
+				Description: g.Description,
+				Owner:       slices.ContainsFunc(ownerGroups, func(og admindb.TacokumoAdminUsergroup) bool { return og.ID == g.ID }),
+			},
+			ID:        g.ID,
+			DisplayID: g.DisplayID,
+		}
+		if gs.Roles, err = q.ListUserGroupRoleNames(ctx, g.ID); err != nil {
+			return ps, errors.Wrapf(err, "failed to list user group role names")
+		}
+		members, err := q.ListUserGroupMembers(ctx, g.ID)
+		if err != nil {
+			return ps, errors.Wrapf(err, "failed to list user group members")
+		}
+		for _, m := range members {
+			gs.Members = append(gs.Members, m.Email)
+		}
+		if gs.Labels, gs.Annotations, err = decodeMetadata(g.Labels, g.Annotations); err != nil {
+			return ps, err
+		}
+		ps.GroupStates = append(ps.GroupStates, gs)
+	}
+	return ps, nil
+}
+
+// applier carries out a plan and remembers the projects, roles and user groups it created, which
+// later changes of the same plan refer to.
+type applier struct {
+	q        *admindb.Queries
+	projects map[string]admindb.TacokumoAdminProject
+	roles    map[string]map[string]int64
+	users    map[string]admindb.TacokumoAdminUser
+	events   []events.Event
+}
+
+// Apply carries out a plan made from the state loaded with the same queries. It is meant to
+// run in a transaction, so that the plan is applied entirely or not at all. The kind rules of
+// every project that remains are checked at the end. It returns the events to publish once the
+// transaction is committed.
+func Apply(ctx context.Context, q *admindb.Queries, plan Plan) ([]events.Event, error) {
+	a := &applier{
+		q:        q,
+		projects: map[string]admindb.TacokumoAdminProject{},
+		roles:    map[string]map[string]int64{},
+		users:    map[string]admindb.TacokumoAdminUser{},
+	}
+	deleted := map[string]bool{}
+	for _, c := range plan.Changes {
+		var err error
+		switch c.Kind {
+		case KindProject:
+			switch c.Action {
+			case ActionCreate:
+				err = a.createProject(ctx, c.project)
+			case ActionUpdate:
+				err = a.updateProject(ctx, c.project, c.projectState)
+			case ActionDelete:
+				err = a.deleteProject(ctx, c.projectState)
+				deleted[c.Project] = true
+			}
+		case KindRole:
+			switch c.Action {
+			case ActionCreate:
+				err = a.createRole(ctx, c)
+			case ActionUpdate:
+				err = a.updateRole(ctx, c)
+			case ActionDelete:
+				err = a.deleteRole(ctx, c)
+			}
+		case KindUserGroup:
+			switch c.Action {
+			case ActionCreate:
+				err = a.createUserGroup(ctx, c)
+			case ActionUpdate:
+				err = a.updateUserGroup(ctx, c)
+			case ActionDelete:
+				err = a.deleteUserGroup(ctx, c)
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to apply %s", c)
+		}
+	}
+
+	checked := map[string]bool{}
+	for _, c := range plan.Changes {
+		if deleted[c.Project] || checked[c.Project] {
+			continue
+		}
+		checked[c.Project] = true
+		proj, err := a.project(ctx, c.Project)
+		if err != nil {
+			return nil, err
+		}
+		if err := project.Check(ctx, q, proj.ID); err != nil {
+			return nil, err
+		}
+	}
+	return a.events, nil
+}
+
+func (a *applier) project(ctx context.Context, name string) (admindb.TacokumoAdminProject, error) {
+	if proj, ok := a.projects[name]; ok {
+		return proj, nil
+	}
+	proj, err := a.q.GetProjectByName(ctx, name)
+	if err != nil {
+		return proj, errors.Wrapf(err, "failed to get project by name")
+	}
+	a.projects[name] = proj
+	return proj, nil
+}
+
+// role returns the ID of a role of a project, which was either loaded or created by this plan.
+func (a *applier) role(ctx context.Context, proj admindb.TacokumoAdminProject, name string) (int64, error) {
+	if id, ok := a.roles[proj.Name][name]; ok {
+		return id, nil
+	}
+	roles, err := a.q.ListRolesByProject(ctx, proj.ID)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to list roles by project")
+	}
+	a.roles[proj.Name] = map[string]int64{}
+	for _, r := range roles {
+		a.roles[proj.Name][r.Name] = r.ID
+	}
+	id, ok := a.roles[proj.Name][name]
+	if !ok {
+		return 0, validationErrorf("role %q of project %q does not exist", name, proj.Name)
+	}
+	return id, nil
+}
+
+func (a *applier) user(ctx context.Context, email string) (admindb.TacokumoAdminUser, error) {
+	if u, ok := a.users[email]; ok {
+		return u, nil
+	}
+	u, err := a.q.GetUserByEmailFold(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return u, validationErrorf("user %q does not exist", email)
+		}
+		return u, errors.Wrapf(err, "failed to get user by email")
+	}
+	a.users[email] = u
+	return u, nil
+}
+
+func (a *applier) publish(kind events.Kind, action events.Action, resourceID string, proj admindb.TacokumoAdminProject) {
+	a.events = append(a.events, events.Event{
+		Kind:       kind,
+		Action:     action,
+		ResourceID: resourceID,
+		ProjectID:  proj.DisplayID.String(),
+	})
+}
+
+func (a *applier) createProject(ctx context.Context, p *Project) error {
+	if err := a.q.CreateProject(ctx, admindb.CreateProjectParams{Name: p.Name, Description: p.Description, Kind: p.Kind}); err != nil {
+		return errors.Wrapf(err, "failed to create project")
+	}
+	proj, err := a.q.GetProjectByName(ctx, p.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get project by name")
+	}
+	if proj, err = a.writeProject(ctx, p, proj.ID, proj.DisplayID); err != nil {
+		return err
+	}
+	a.publish(events.KindProject, events.ActionCreated, proj.DisplayID.String(), proj)
+	return nil
+}
+
+func (a *applier) updateProject(ctx context.Context, p *Project, state *ProjectState) error {
+	proj, err := a.writeProject(ctx, p, state.ID, state.DisplayID)
+	if err != nil {
+		return err
+	}
+	a.publish(events.KindProject, events.ActionUpdated, proj.DisplayID.String(), proj)
+	return nil
+}
+
+// writeProject sets the description, metadata and, for personal projects, the owners of a
+// project and returns the updated project.
+func (a *applier) writeProject(ctx context.Context, p *Project, id int64, displayID pgtype.UUID) (admindb.TacokumoAdminProject, error) {
+	labelsJSON, annotationsJSON, err := encodeMetadata(p.Labels, p.managedAnnotations())
+	if err != nil {
+		return admindb.TacokumoAdminProject{}, err
+	}
+	if _, err := a.q.UpdateProject(ctx, admindb.UpdateProjectParams{
+		DisplayID:   displayID,
+		Name:        p.Name,
+		Description: p.Description,
+		Labels:      labelsJSON,
+		Annotations: annotationsJSON,
+	}); err != nil {
+		return admindb.TacokumoAdminProject{}, errors.Wrapf(err, "failed to update project")
+	}
+
+	if p.Kind == project.KindPersonal {
+		if err := a.q.DeleteProjectOwners(ctx, id); err != nil {
+			return admindb.TacokumoAdminProject{}, errors.Wrapf(err, "failed to delete project owners")
+		}
+		for _, email := range p.Owners {
+			owner, err := a.user(ctx, email)
+			if err != nil {
+				return admindb.TacokumoAdminProject{}, err
+			}
+			if err := a.q.AddProjectOwner(ctx, admindb.AddProjectOwnerParams{ProjectID: id, UserID: owner.ID}); err != nil {
+				return admindb.TacokumoAdminProject{}, errors.Wrapf(err, "failed to add project owner")
+			}
+		}
+	}
+
+	proj, err := a.q.GetProjectByName(ctx, p.Name)
+	if err != nil {
+		return proj, errors.Wrapf(err, "failed to get project by name")
+	}
+	a.projects[p.Name] = proj
+	return proj, nil
+}
+
+func (a *applier) deleteProject(ctx context.Context, state *ProjectState) error {
+	if err := a.q.DeleteProjectByID(ctx, state.ID); err != nil {
+		return errors.Wrapf(err, "failed to delete project")
+	}
+	a.events = append(a.events, events.Event{
+		Kind:       events.KindProject,
+		Action:     events.ActionDeleted,
+		ResourceID: state.DisplayID.String(),
+		ProjectID:  state.DisplayID.String(),
+	})
+	return nil
+}
+
+func (a *applier) createRole(ctx context.Context, c Change) error {
+	proj, err := a.project(ctx, c.Project)
+	if err != nil {
+		return err
+	}
+	displayID, err := a.q.CreateRole(ctx, admindb.CreateRoleParams{ProjectID: proj.ID, Name: c.role.Name, Description: c.role.Description})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create role")
+	}
+	role, err := a.q.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{ProjectID: proj.ID, DisplayID: displayID})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get role by display id")
+	}
+	if a.roles[proj.Name] == nil {
+		a.roles[proj.Name] = map[string]int64{}
+	}
+	a.roles[proj.Name][role.Name] = role.ID
+	if err := a.writeRole(ctx, proj, displayID, role.ID, c.role, nil); err != nil {
+		return err
+	}
+	a.publish(events.KindRole, events.ActionCreated, displayID.String(), proj)
+	return nil
+}
+
+func (a *applier) updateRole(ctx context.Context, c Change) error {
+	proj, err := a.project(ctx, c.Project)
+	if err != nil {
+		return err
+	}
+	if err := a.writeRole(ctx, proj, c.roleState.DisplayID, c.roleState.ID, c.role, c.roleState.Attributes); err != nil {
+		return err
+	}
+	a.publish(events.KindRole, events.ActionUpdated, c.roleState.DisplayID.String(), proj)
+	return nil
+}
+
+// writeRole sets the description, metadata and attributes of a role, whose current attributes
+// are currentAttributes.
+func (a *applier) writeRole(ctx context.Context, proj admindb.TacokumoAdminProject, displayID pgtype.UUID, id int64, r *Role, currentAttributes []string) error {
+	labelsJSON, annotationsJSON, err := encodeMetadata(r.Labels, r.Annotations)
+	if err != nil {
+		return err
+	}
+	if _, err := a.q.UpdateRole(ctx, admindb.UpdateRoleParams{
+		ProjectID:   proj.ID,
+		DisplayID:   displayID,
+		Name:        r.Name,
+		Description: r.Description,
+		Labels:      labelsJSON,
+		Annotations: annotationsJSON,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to update role")
+	}
+	for _, attribute := range r.Attributes {
+		affected, err := a.q.AddRoleAttributeByName(ctx, admindb.AddRoleAttributeByNameParams{RoleID: id, Name: attribute})
+		if err != nil {
+			return errors.Wrapf(err, "failed to add role attribute")
+		}
+		if affected == 0 {
+			return validationErrorf("role %q refers to undefined attribute %q", r.Name, attribute)
+		}
+	}
+	for _, attribute := range currentAttributes {
+		if slices.Contains(r.Attributes, attribute) {
+			continue
+		}
+		if err := a.q.RemoveRoleAttributeByName(ctx, admindb.RemoveRoleAttributeByNameParams{RoleID: id, Name: attribute}); err != nil {
+			return errors.Wrapf(err, "failed to remove role attribute")
+		}
+	}
+	return nil
+}
+
+func (a *applier) deleteRole(ctx context.Context, c Change) error {
+	proj, err := a.project(ctx, c.Project)
+	if err != nil {
+		return err
+	}
+	if err := a.q.DeleteRoleByID(ctx, c.roleState.ID); err != nil {
+		return errors.Wrapf(err, "failed to delete role")
+	}
+	a.publish(events.KindRole, events.ActionDeleted, c.roleState.DisplayID.String(), proj)
+	return nil
+}
+
+func (a *applier) createUserGroup(ctx context.Context, c Change) error {
+	proj, err := a.project(ctx, c.Project)
+	if err != nil {
+		return err
+	}
+	displayID, err := a.q.CreateUserGroup(ctx, admindb.CreateUserGroupParams{ProjectID: proj.ID, Name: c.group.Name, Description: c.group.Description})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create user group")
+	}
+	group, err := a.q.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{ProjectID: proj.ID, DisplayID: displayID})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get user group by display id")
+	}
+	state := &UserGroupState{ID: group.ID, DisplayID: displayID}
+	if err := a.writeUserGroup(ctx, proj, c.group, state); err != nil {
+		return err
+	}
+	a.publish(events.KindUserGroup, events.ActionCreated, displayID.String(), proj)
+	return nil
+}
+
+func (a *applier) updateUserGroup(ctx context.Context, c Change) error {
+	proj, err := a.project(ctx, c.Project)
+	if err != nil {
+		return err
+	}
+	if err := a.writeUserGroup(ctx, proj, c.group, c.groupState); err != nil {
+		return err
+	}
+	a.publish(events.KindUserGroup, events.ActionUpdated, c.groupState.DisplayID.String(), proj)
+	return nil
+}
+
+// writeUserGroup brings a user group from its current state to g.
+func (a *applier) writeUserGroup(ctx context.Context, proj admindb.TacokumoAdminProject, g *UserGroup, current *UserGroupState) error {
+	labelsJSON, annotationsJSON, err := encodeMetadata(g.Labels, g.Annotations)
+	if err != nil {
+		return err
+	}
+	if _, err := a.q.UpdateUserGroup(ctx, admindb.UpdateUserGroupParams{
+		ProjectID:   proj.ID,
+		DisplayID:   current.DisplayID,
+		Name:        g.Name,
+		Description: g.Description,
+		Labels:      labelsJSON,
+		Annotations: annotationsJSON,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to update user group")
+	}
+
+	for _, name := range g.Roles {
+		roleID, err := a.role(ctx, proj, name)
+		if err != nil {
+			return err
+		}
+		if err := a.q.AssignRoleToUserGroup(ctx, admindb.AssignRoleToUserGroupParams{UsergroupID: current.ID, RoleID: roleID}); err != nil {
+			return errors.Wrapf(err, "failed to assign role to user group")
+		}
+	}
+	for _, name := range current.Roles {
+		if slices.Contains(g.Roles, name) {
+			continue
+		}
+		roleID, err := a.role(ctx, proj, name)
+		if err != nil {
+			return err
+		}
+		if err := a.q.UnassignRoleFromUserGroup(ctx, admindb.UnassignRoleFromUserGroupParams{UsergroupID: current.ID, RoleID: roleID}); err != nil {
+			return errors.Wrapf(err, "failed to unassign role from user group")
+		}
+	}
+
+	desiredMembers := normalizeEmails(g.Members)
+	currentMembers := normalizeEmails(current.Members)
+	for _, email := range desiredMembers {
+		if slices.Contains(currentMembers, email) {
+			continue
+		}
+		user, err := a.user(ctx, email)
+		if err != nil {
+			return err
+		}
+		if err := a.q.AddUserToUserGroup(ctx, admindb.AddUserToUserGroupParams{UserID: user.ID, UsergroupID: current.ID}); err != nil {
+			return errors.Wrapf(err, "failed to add user to user group")
+		}
+		a.addMemberEvent(events.ActionCreated, current.DisplayID, proj, user)
+	}
+	for _, email := range currentMembers {
+		if slices.Contains(desiredMembers, email) {
+			continue
+		}
+		user, err := a.user(ctx, email)
+		if err != nil {
+			return err
+		}
+		if _, err := a.q.RemoveUserFromUserGroup(ctx, admindb.RemoveUserFromUserGroupParams{UserID: user.ID, UsergroupID: current.ID}); err != nil {
+			return errors.Wrapf(err, "failed to remove user from user group")
+		}
+		a.addMemberEvent(events.ActionDeleted, current.DisplayID, proj, user)
+	}
+
+	switch {
+	case g.Owner && !current.Owner:
+		if err := a.q.AddProjectOwnerGroup(ctx, admindb.AddProjectOwnerGroupParams{ProjectID: proj.ID, UsergroupID: current.ID}); err != nil {
+			return errors.Wrapf(err, "failed to add project owner group")
+		}
+	case !g.Owner && current.Owner:
+		if err := a.q.RemoveProjectOwnerGroup(ctx, admindb.RemoveProjectOwnerGroupParams{ProjectID: proj.ID, UsergroupID: current.ID}); err != nil {
+			return errors.Wrapf(err, "failed to remove project owner group")
+		}
+	}
+	return nil
+}
+
+func (a *applier) addMemberEvent(action events.Action, groupID pgtype.UUID, proj admindb.TacokumoAdminProject, user admindb.TacokumoAdminUser) {
+	a.events = append(a.events, events.Event{
+		Kind:       events.KindUserGroupMember,
+		Action:     action,
+		ResourceID: groupID.String(),
+		ProjectID:  proj.DisplayID.String(),
+		MemberID:   user.DisplayID.String(),
+		MemberKind: events.MemberKindUser,
+	})
+}
+
+func (a *applier) deleteUserGroup(ctx context.Context, c Change) error {
+	proj, err := a.project(ctx, c.Project)
+	if err != nil {
+		return err
+	}
+	if _, err := a.q.DeleteUserGroupByDisplayID(ctx, admindb.DeleteUserGroupByDisplayIDParams{ProjectID: proj.ID, DisplayID: c.groupState.DisplayID}); err != nil {
+		return errors.Wrapf(err, "failed to delete user group")
+	}
+	a.publish(events.KindUserGroup, events.ActionDeleted, c.groupState.DisplayID.String(), proj)
+	return nil
+}
+
+func decodeMetadata(labelsJSON, annotationsJSON []byte) (l map[string]string, a map[string]string, err error) {
+	if len(labelsJSON) > 0 {
+		if err := json.Unmarshal(labelsJSON, &l); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to unmarshal labels")
+		}
+	}
+	if len(annotationsJSON) > 0 {
+		if err := json.Unmarshal(annotationsJSON, &a); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to unmarshal annotations")
+		}
+	}
+	return l, a, nil
+}
+
+func encodeMetadata(l, a map[string]string) (labelsJSON []byte, annotationsJSON []byte, err error) {
+	if l == nil {
+		l = map[string]string{}
+	}
+	if a == nil {
+		a = map[string]string{}
+	}
+	if labelsJSON, err = json.Marshal(l); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to marshal labels")
+	}
+	if annotationsJSON, err = json.Marshal(a); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to marshal annotations")
+	}
+	return labelsJSON, annotationsJSON, nil
+}
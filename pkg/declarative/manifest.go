@@ -0,0 +1,200 @@
+// Package declarative reconciles projects, roles and user groups with a manifest.
+//
+// A manifest lists the desired projects together with their roles, user groups and group
+// memberships. Plan compares it with the current state and Apply carries out the resulting
+// changes. Objects are identified by name and users by email, so that a manifest can be kept
+// in a repository and applied to any environment.
+package declarative
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/tacokumo/admin-api/pkg/labels"
+	"github.com/tacokumo/admin-api/pkg/project"
+	"gopkg.in/yaml.v3"
+)
+
+// APIVersion is the apiVersion of manifests. It may be omitted.
+const APIVersion = "admin.tacokumo.io/v1alpha1"
+
+// ManagedAnnotation marks the projects created or updated by Apply. Only projects that carry it
+// are deleted when they are missing from a manifest that is applied with pruning.
+const ManagedAnnotation = "admin.tacokumo.io/managed-by"
+
+const managedBy = "apply"
+
+// Manifest is the desired state of a set of projects.
+type Manifest struct {
+	APIVersion string    `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Projects   []Project `json:"projects" yaml:"projects"`
+}
+
+// Project is a desired project.
+type Project struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	Kind        string `json:"kind" yaml:"kind"`
+	// Owners are the emails of the owner of a personal project. Shared projects are owned by
+	// their owner groups instead.
+	Owners      []string          `json:"owners,omitempty" yaml:"owners,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Roles       []Role            `json:"roles,omitempty" yaml:"roles,omitempty"`
+	UserGroups  []UserGroup       `json:"userGroups,omitempty" yaml:"userGroups,omitempty"`
+}
+
+// Role is a desired role of a project.
+type Role struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	// Attributes are the names of the predefined role attributes.
+	Attributes  []string          `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// UserGroup is a desired user group of a project.
+type UserGroup struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	// Roles are the names of the roles of the project assigned to the group.
+	Roles []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+	// Members are the emails of the users in the group.
+	Members []string `json:"members,omitempty" yaml:"members,omitempty"`
+	// Owner makes the group an owner group of the project.
+	Owner       bool              `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// ValidationError reports a manifest that cannot be applied.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid manifest: " + e.Message
+}
+
+func validationErrorf(format string, args ...any) *ValidationError {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Parse decodes a YAML (or JSON) manifest. Unknown fields are rejected so that typos are not
+// silently ignored.
+func Parse(b []byte) (Manifest, error) {
+	var m Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Manifest{}, validationErrorf("manifest is empty")
+		}
+		return Manifest{}, validationErrorf("%s", err.Error())
+	}
+	return m, nil
+}
+
+// Validate checks the manifest without looking at the current state.
+func (m Manifest) Validate() error {
+	if m.APIVersion != "" && m.APIVersion != APIVersion {
+		return validationErrorf("apiVersion must be %s", APIVersion)
+	}
+	names := map[string]bool{}
+	for _, p := range m.Projects {
+		if p.Name == "" {
+			return validationErrorf("project name is required")
+		}
+		if names[p.Name] {
+			return validationErrorf("project %q is defined more than once", p.Name)
+		}
+		names[p.Name] = true
+		if err := p.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Project) validate() error {
+	switch p.Kind {
+	case project.KindPersonal:
+		if len(p.Owners) != 1 {
+			return validationErrorf("personal project %q must have exactly one owner", p.Name)
+		}
+	case project.KindShared:
+		if len(p.Owners) > 0 {
+			return validationErrorf("shared project %q is owned by its owner groups; set owner on a user group instead of owners", p.Name)
+		}
+		if !slices.ContainsFunc(p.UserGroups, func(g UserGroup) bool { return g.Owner }) {
+			return validationErrorf("shared project %q must have at least one owner group", p.Name)
+		}
+	default:
+		return validationErrorf("kind of project %q must be %s or %s", p.Name, project.KindPersonal, project.KindShared)
+	}
+	if err := labels.Validate(p.Labels); err != nil {
+		return validationErrorf("project %q: %s", p.Name, err)
+	}
+	if err := labels.ValidateAnnotations(p.Annotations); err != nil {
+		return validationErrorf("project %q: %s", p.Name, err)
+	}
+	if err := p.template().Validate(p.Kind); err != nil {
+		var te *project.TemplateError
+		if errors.As(err, &te) {
+			return validationErrorf("project %q: %s", p.Name, te.Message)
+		}
+		return err
+	}
+	return nil
+}
+
+// template returns the role and user group structure of the project, which is validated
+// with the same rules as project templates.
+func (p Project) template() project.Template {
+	t := project.Template{}
+	for _, r := range p.Roles {
+		t.Roles = append(t.Roles, project.TemplateRole{
+			Name:        r.Name,
+			Description: r.Description,
+			Attributes:  r.Attributes,
+			Labels:      r.Labels,
+			Annotations: r.Annotations,
+		})
+	}
+	for _, g := range p.UserGroups {
+		t.UserGroups = append(t.UserGroups, project.TemplateUserGroup{
+			Name:        g.Name,
+			Description: g.Description,
+			Roles:       g.Roles,
+			Owner:       g.Owner,
+			Labels:      g.Labels,
+			Annotations: g.Annotations,
+		})
+	}
+	return t
+}
+
+// managedAnnotations returns the annotations of a project with ManagedAnnotation added.
+func (p Project) managedAnnotations() map[string]string {
+	a := make(map[string]string, len(p.Annotations)+1)
+	for k, v := range p.Annotations {
+		a[k] = v
+	}
+	a[ManagedAnnotation] = managedBy
+	return a
+}
+
+// normalizeEmails lowercases and sorts emails, which are compared case-insensitively.
+func normalizeEmails(emails []string) []string {
+	out := make([]string, 0, len(emails))
+	for _, e := range emails {
+		out = append(out, strings.ToLower(strings.TrimSpace(e)))
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
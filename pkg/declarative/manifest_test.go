@@ -0,0 +1,147 @@
+package declarative
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/errors"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "yaml",
+			input: `apiVersion: admin.tacokumo.io/v1alpha1
+projects:
+  - name: web
+    description: Web
+    kind: shared
+    roles:
+      - name: admin
+        attributes: [write]
+    userGroups:
+      - name: owners
+        roles: [admin]
+        members: [alice@example.com]
+        owner: true
+`,
+			want: 1,
+		},
+		{
+			name:  "json",
+			input: `{"projects": [{"name": "alice", "kind": "personal", "owners": ["alice@example.com"]}]}`,
+			want:  1,
+		},
+		{
+			name:    "unknown field",
+			input:   "projects:\n  - name: web\n    kind: shared\n    members: [alice@example.com]\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ve *ValidationError
+			if err != nil && !errors.As(err, &ve) {
+				t.Errorf("Parse() error = %T, want *ValidationError", err)
+			}
+			if len(m.Projects) != tt.want {
+				t.Errorf("Parse() projects = %d, want %d", len(m.Projects), tt.want)
+			}
+		})
+	}
+}
+
+func TestManifestValidate(t *testing.T) {
+	t.Parallel()
+
+	shared := Project{
+		Name: "web",
+		Kind: "shared",
+		Roles: []Role{
+			{Name: "admin", Attributes: []string{"write"}},
+		},
+		UserGroups: []UserGroup{
+			{Name: "owners", Roles: []string{"admin"}, Owner: true},
+		},
+	}
+	personal := Project{Name: "alice", Kind: "personal", Owners: []string{"alice@example.com"}}
+
+	tests := []struct {
+		name     string
+		manifest Manifest
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			manifest: Manifest{APIVersion: APIVersion, Projects: []Project{shared, personal}},
+		},
+		{
+			name:     "unknown apiVersion",
+			manifest: Manifest{APIVersion: "v2", Projects: []Project{shared}},
+			wantErr:  true,
+		},
+		{
+			name:     "duplicate project",
+			manifest: Manifest{Projects: []Project{shared, shared}},
+			wantErr:  true,
+		},
+		{
+			name:     "personal without owner",
+			manifest: Manifest{Projects: []Project{{Name: "alice", Kind: "personal"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "shared with owners",
+			manifest: Manifest{Projects: []Project{{Name: "web", Kind: "shared", Owners: []string{"alice@example.com"}, UserGroups: shared.UserGroups, Roles: shared.Roles}}},
+			wantErr:  true,
+		},
+		{
+			name:     "shared without owner group",
+			manifest: Manifest{Projects: []Project{{Name: "web", Kind: "shared", Roles: shared.Roles}}},
+			wantErr:  true,
+		},
+		{
+			name:     "undefined role in group",
+			manifest: Manifest{Projects: []Project{{Name: "web", Kind: "shared", UserGroups: []UserGroup{{Name: "owners", Roles: []string{"admin"}, Owner: true}}}}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid label",
+			manifest: Manifest{Projects: []Project{{Name: "alice", Kind: "personal", Owners: []string{"alice@example.com"}, Labels: map[string]string{"env": "not valid"}}}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.manifest.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ve *ValidationError
+			if err != nil && !errors.As(err, &ve) {
+				t.Errorf("Validate() error = %T, want *ValidationError", err)
+			}
+		})
+	}
+}
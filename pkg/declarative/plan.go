@@ -0,0 +1,268 @@
+package declarative
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Actions of a change.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Kinds of objects a change applies to.
+const (
+	KindProject   = "project"
+	KindRole      = "role"
+	KindUserGroup = "usergroup"
+)
+
+// State is the current state of the projects a manifest refers to.
+type State struct {
+	Projects []ProjectState
+}
+
+// ProjectState is an existing project in the form of a manifest.
+type ProjectState struct {
+	Project
+	ID        int64
+	DisplayID pgtype.UUID
+	Archived  bool
+	// Managed reports whether the project carries ManagedAnnotation.
+	Managed    bool
+	RoleStates []RoleState
+	// GroupStates are the user groups. Project.Roles and Project.UserGroups are not used.
+	GroupStates []UserGroupState
+}
+
+// RoleState is an existing role.
+type RoleState struct {
+	Role
+	ID        int64
+	DisplayID pgtype.UUID
+}
+
+// UserGroupState is an existing user group.
+type UserGroupState struct {
+	UserGroup
+	ID        int64
+	DisplayID pgtype.UUID
+}
+
+// Change is a single step of a plan.
+type Change struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	// Project is the name of the project the change applies to, or of the project itself.
+	Project string `json:"project"`
+	// Name is the name of the role or user group. It is empty for projects.
+	Name string `json:"name,omitempty"`
+	// Fields lists what an update changes.
+	Fields []string `json:"fields,omitempty"`
+
+	project      *Project
+	projectState *ProjectState
+	role         *Role
+	roleState    *RoleState
+	group        *UserGroup
+	groupState   *UserGroupState
+}
+
+// String returns the change in the form "+ role web/admin".
+func (c Change) String() string {
+	symbol := map[string]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[c.Action]
+	name := c.Project
+	if c.Name != "" {
+		name += "/" + c.Name
+	}
+	s := fmt.Sprintf("%s %s %s", symbol, c.Kind, name)
+	if len(c.Fields) > 0 {
+		s += fmt.Sprintf(" (%v)", c.Fields)
+	}
+	return s
+}
+
+// Plan is the ordered list of changes that brings the current state to the manifest.
+// Creates and updates come first, then deletions, so that references are always valid.
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// Empty reports whether the state already matches the manifest.
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// MakePlan compares a validated manifest with the current state. With prune, roles and user
+// groups of the projects in the manifest that it does not list are deleted, and so are managed
+// projects that it does not list.
+func MakePlan(m Manifest, current State, prune bool) (Plan, error) {
+	states := map[string]*ProjectState{}
+	for i := range current.Projects {
+		states[current.Projects[i].Name] = &current.Projects[i]
+	}
+
+	plan := Plan{Changes: []Change{}}
+	var deletions []Change
+	for i := range m.Projects {
+		desired := &m.Projects[i]
+		state, exists := states[desired.Name]
+		if !exists {
+			plan.Changes = append(plan.Changes, Change{Action: ActionCreate, Kind: KindProject, Project: desired.Name, project: desired})
+			for j := range desired.Roles {
+				plan.Changes = append(plan.Changes, Change{Action: ActionCreate, Kind: KindRole, Project: desired.Name, Name: desired.Roles[j].Name, project: desired, role: &desired.Roles[j]})
+			}
+			for j := range desired.UserGroups {
+				plan.Changes = append(plan.Changes, Change{Action: ActionCreate, Kind: KindUserGroup, Project: desired.Name, Name: desired.UserGroups[j].Name, project: desired, group: &desired.UserGroups[j]})
+			}
+			continue
+		}
+
+		changes, dels, err := planProject(desired, state, prune)
+		if err != nil {
+			return Plan{}, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+		deletions = append(deletions, dels...)
+	}
+
+	if prune {
+		for i := range current.Projects {
+			state := &current.Projects[i]
+			if !state.Managed || slices.ContainsFunc(m.Projects, func(p Project) bool { return p.Name == state.Name }) {
+				continue
+			}
+			deletions = append(deletions, Change{Action: ActionDelete, Kind: KindProject, Project: state.Name, projectState: state})
+		}
+	}
+	plan.Changes = append(plan.Changes, deletions...)
+	return plan, nil
+}
+
+func planProject(desired *Project, state *ProjectState, prune bool) (changes []Change, deletions []Change, err error) {
+	if desired.Kind != state.Kind {
+		return nil, nil, validationErrorf("kind of project %q cannot be changed from %s to %s by apply; convert the project first", desired.Name, state.Kind, desired.Kind)
+	}
+
+	var fields []string
+	if desired.Description != state.Description {
+		fields = append(fields, "description")
+	}
+	if !maps.Equal(desired.Labels, state.Labels) {
+		fields = append(fields, "labels")
+	}
+	if !maps.Equal(desired.managedAnnotations(), state.Annotations) {
+		fields = append(fields, "annotations")
+	}
+	if !slices.Equal(normalizeEmails(desired.Owners), normalizeEmails(state.Owners)) {
+		fields = append(fields, "owners")
+	}
+	if len(fields) > 0 {
+		changes = append(changes, Change{Action: ActionUpdate, Kind: KindProject, Project: desired.Name, Fields: fields, project: desired, projectState: state})
+	}
+
+	roles := map[string]*RoleState{}
+	for i := range state.RoleStates {
+		roles[state.RoleStates[i].Name] = &state.RoleStates[i]
+	}
+	for i := range desired.Roles {
+		role := &desired.Roles[i]
+		current, ok := roles[role.Name]
+		if !ok {
+			changes = append(changes, Change{Action: ActionCreate, Kind: KindRole, Project: desired.Name, Name: role.Name, project: desired, projectState: state, role: role})
+			continue
+		}
+		if fields := diffRole(role, current); len(fields) > 0 {
+			changes = append(changes, Change{Action: ActionUpdate, Kind: KindRole, Project: desired.Name, Name: role.Name, Fields: fields, project: desired, projectState: state, role: role, roleState: current})
+		}
+	}
+
+	groups := map[string]*UserGroupState{}
+	for i := range state.GroupStates {
+		groups[state.GroupStates[i].Name] = &state.GroupStates[i]
+	}
+	for i := range desired.UserGroups {
+		group := &desired.UserGroups[i]
+		current, ok := groups[group.Name]
+		if !ok {
+			changes = append(changes, Change{Action: ActionCreate, Kind: KindUserGroup, Project: desired.Name, Name: group.Name, project: desired, projectState: state, group: group})
+			continue
+		}
+		if fields := diffUserGroup(group, current); len(fields) > 0 {
+			changes = append(changes, Change{Action: ActionUpdate, Kind: KindUserGroup, Project: desired.Name, Name: group.Name, Fields: fields, project: desired, projectState: state, group: group, groupState: current})
+		}
+	}
+
+	if prune {
+		for i := range state.GroupStates {
+			current := &state.GroupStates[i]
+			if !slices.ContainsFunc(desired.UserGroups, func(g UserGroup) bool { return g.Name == current.Name }) {
+				deletions = append(deletions, Change{Action: ActionDelete, Kind: KindUserGroup, Project: desired.Name, Name: current.Name, projectState: state, groupState: current})
+			}
+		}
+		for i := range state.RoleStates {
+			current := &state.RoleStates[i]
+			if !slices.ContainsFunc(desired.Roles, func(r Role) bool { return r.Name == current.Name }) {
+				deletions = append(deletions, Change{Action: ActionDelete, Kind: KindRole, Project: desired.Name, Name: current.Name, projectState: state, roleState: current})
+			}
+		}
+	}
+
+	if state.Archived && len(changes)+len(deletions) > 0 {
+		return nil, nil, validationErrorf("project %q is archived; unarchive it before applying changes", desired.Name)
+	}
+	return changes, deletions, nil
+}
+
+func diffRole(desired *Role, current *RoleState) []string {
+	var fields []string
+	if desired.Description != current.Description {
+		fields = append(fields, "description")
+	}
+	if !sameSet(desired.Attributes, current.Attributes) {
+		fields = append(fields, "attributes")
+	}
+	if !maps.Equal(desired.Labels, current.Labels) {
+		fields = append(fields, "labels")
+	}
+	if !maps.Equal(desired.Annotations, current.Annotations) {
+		fields = append(fields, "annotations")
+	}
+	return fields
+}
+
+func diffUserGroup(desired *UserGroup, current *UserGroupState) []string {
+	var fields []string
+	if desired.Description != current.Description {
+		fields = append(fields, "description")
+	}
+	if !sameSet(desired.Roles, current.Roles) {
+		fields = append(fields, "roles")
+	}
+	if !slices.Equal(normalizeEmails(desired.Members), normalizeEmails(current.Members)) {
+		fields = append(fields, "members")
+	}
+	if desired.Owner != current.Owner {
+		fields = append(fields, "owner")
+	}
+	if !maps.Equal(desired.Labels, current.Labels) {
+		fields = append(fields, "labels")
+	}
+	if !maps.Equal(desired.Annotations, current.Annotations) {
+		fields = append(fields, "annotations")
+	}
+	return fields
+}
+
+// sameSet reports whether a and b have the same elements, ignoring order and duplicates.
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
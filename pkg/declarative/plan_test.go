@@ -0,0 +1,139 @@
+package declarative
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMakePlan(t *testing.T) {
+	t.Parallel()
+
+	desired := Project{
+		Name:        "web",
+		Description: "Web",
+		Kind:        "shared",
+		Roles: []Role{
+			{Name: "admin", Attributes: []string{"write", "read"}},
+			{Name: "viewer", Attributes: []string{"read"}},
+		},
+		UserGroups: []UserGroup{
+			{Name: "owners", Roles: []string{"admin"}, Members: []string{"Alice@example.com"}, Owner: true},
+		},
+	}
+	current := ProjectState{
+		Project: Project{
+			Name:        "web",
+			Description: "Web",
+			Kind:        "shared",
+			Annotations: map[string]string{ManagedAnnotation: managedBy},
+		},
+		Managed: true,
+		RoleStates: []RoleState{
+			{Role: Role{Name: "admin", Attributes: []string{"read", "write"}}},
+			{Role: Role{Name: "editor", Attributes: []string{"write"}}},
+		},
+		GroupStates: []UserGroupState{
+			{UserGroup: UserGroup{Name: "owners", Roles: []string{"admin"}, Members: []string{"alice@example.com"}, Owner: true}},
+			{UserGroup: UserGroup{Name: "editors", Roles: []string{"editor"}}},
+		},
+	}
+	orphan := ProjectState{Project: Project{Name: "old", Kind: "shared"}, Managed: true}
+	unmanaged := ProjectState{Project: Project{Name: "manual", Kind: "shared"}}
+
+	tests := []struct {
+		name     string
+		manifest Manifest
+		current  State
+		prune    bool
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "create",
+			manifest: Manifest{Projects: []Project{desired}},
+			want: []string{
+				"+ project web",
+				"+ role web/admin",
+				"+ role web/viewer",
+				"+ usergroup web/owners",
+			},
+		},
+		{
+			name:     "in sync apart from missing role",
+			manifest: Manifest{Projects: []Project{desired}},
+			current:  State{Projects: []ProjectState{current}},
+			want:     []string{"+ role web/viewer"},
+		},
+		{
+			name:     "prune",
+			manifest: Manifest{Projects: []Project{desired}},
+			current:  State{Projects: []ProjectState{current, orphan, unmanaged}},
+			prune:    true,
+			want: []string{
+				"+ role web/viewer",
+				"- usergroup web/editors",
+				"- role web/editor",
+				"- project old",
+			},
+		},
+		{
+			name: "update",
+			manifest: Manifest{Projects: []Project{func() Project {
+				p := desired
+				p.Description = "Web frontend"
+				p.Labels = map[string]string{"env": "prod"}
+				p.Roles = []Role{{Name: "admin", Attributes: []string{"write"}}, desired.Roles[1]}
+				p.UserGroups = []UserGroup{{Name: "owners", Roles: []string{"admin"}, Members: []string{"bob@example.com"}, Owner: true}}
+				return p
+			}()}},
+			current: State{Projects: []ProjectState{current}},
+			want: []string{
+				"~ project web ([description labels])",
+				"~ role web/admin ([attributes])",
+				"+ role web/viewer",
+				"~ usergroup web/owners ([members])",
+			},
+		},
+		{
+			name: "kind change",
+			manifest: Manifest{Projects: []Project{func() Project {
+				p := desired
+				p.Kind = "personal"
+				return p
+			}()}},
+			current: State{Projects: []ProjectState{current}},
+			wantErr: true,
+		},
+		{
+			name:     "archived",
+			manifest: Manifest{Projects: []Project{desired}},
+			current: State{Projects: []ProjectState{func() ProjectState {
+				p := current
+				p.Archived = true
+				return p
+			}()}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			plan, err := MakePlan(tt.manifest, tt.current, tt.prune)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MakePlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, c := range plan.Changes {
+				got = append(got, c.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("MakePlan() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
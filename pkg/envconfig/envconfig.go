@@ -1,16 +1,27 @@
 package envconfig
 
 import (
+	"fmt"
 	"os"
 	"reflect"
-	"strconv"
-	"time"
+	"slices"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 )
 
 // LoadFromEnv populates a struct from environment variables based on `env` struct tags.
+// A variable that is not set is read from the file named by the variable with a _FILE suffix,
+// such as ADMIN_DB_PASSWORD_FILE, if that is set.
 // Accepts any struct type and returns an error if the type is not supported.
+//
+// Fields can also be tagged with `default:"..."`, which is used when the variable is not set and
+// the field is empty, and `required:"true"`, which fails when neither sets the field. See
+// parseValue for the supported types. All variables are checked before an *Error is returned.
+//
+// Variables that look meant for the struct but match no tag are reported as well: a _FILE
+// variable under the prefix of a tag, such as ADMIN_DB_PASWORD_FILE, and a variable that is a
+// misspelling of a tag, such as ADMIN_DB_PASWORD.
 func LoadFromEnv[T any]() (T, error) {
 	var cfg T
 	var zero T
@@ -20,7 +31,7 @@ func LoadFromEnv[T any]() (T, error) {
 		return zero, errors.New("envconfig: LoadFromEnv requires struct type")
 	}
 
-	if err := loadFromEnvRecursive(value); err != nil {
+	if err := load(value); err != nil {
 		return zero, errors.Wrapf(err, "failed to load from env")
 	}
 
@@ -36,59 +47,237 @@ func OverrideFromEnv[T any](cfg *T) error {
 		return errors.New("envconfig: OverrideFromEnv requires pointer to struct type")
 	}
 
-	if err := loadFromEnvRecursive(value); err != nil {
+	if err := load(value); err != nil {
 		return errors.Wrapf(err, "failed to override from env")
 	}
 
 	return nil
 }
 
-func loadFromEnvRecursive(v reflect.Value) error {
+// ApplyDefaults sets the empty fields of a struct to their `default` tags without reading the
+// environment, for configs loaded from elsewhere. As with LoadFromEnv, a field set to its zero
+// value, such as a duration of 0, is empty and gets the default.
+func ApplyDefaults[T any](cfg *T) error {
+	value := reflect.ValueOf(cfg).Elem()
+	if value.Kind() != reflect.Struct {
+		return errors.New("envconfig: ApplyDefaults requires pointer to struct type")
+	}
+
+	l := &loader{known: map[string]string{}, defaultsOnly: true}
+	l.loadStruct(value, "")
+	if len(l.errs) > 0 {
+		return errors.Wrapf(&Error{Errors: l.errs}, "failed to apply defaults")
+	}
+	return nil
+}
+
+// VarError is a problem with a single environment variable.
+type VarError struct {
+	// Var is the environment variable.
+	Var string
+	// Field is the path of the struct field, such as "Auth.AllowedOrgs".
+	Field string
+	Err   error
+}
+
+func (e VarError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", e.Var, e.Err)
+	}
+	return fmt.Sprintf("%s (%s): %s", e.Var, e.Field, e.Err)
+}
+
+// Error lists the environment variables that could not be loaded.
+type Error struct {
+	Errors []VarError
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, ve := range e.Errors {
+		msgs = append(msgs, ve.Error())
+	}
+	return "envconfig: " + strings.Join(msgs, "; ")
+}
+
+func load(v reflect.Value) error {
+	l := &loader{known: map[string]string{}}
+	l.loadStruct(v, "")
+	l.checkUnknown(os.Environ())
+	if len(l.errs) > 0 {
+		return &Error{Errors: l.errs}
+	}
+	return nil
+}
+
+type loader struct {
+	errs []VarError
+	// known maps the variables of the struct to the paths of their fields.
+	known map[string]string
+	// defaultsOnly only applies the defaults, leaving the environment alone.
+	defaultsOnly bool
+}
+
+func (l *loader) add(name, path string, err error) {
+	l.errs = append(l.errs, VarError{Var: name, Field: path, Err: err})
+}
+
+func (l *loader) loadStruct(v reflect.Value, prefix string) {
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)
 		fieldType := v.Type().Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+		path := fieldType.Name
+		if prefix != "" {
+			path = prefix + "." + fieldType.Name
+		}
+
+		if name := fieldType.Tag.Get("env"); name != "" {
+			l.loadField(field, fieldType, name, path)
+			continue
+		}
 
-		if tag := fieldType.Tag.Get("env"); tag != "" {
-			if envValue := os.Getenv(tag); envValue != "" {
-				if !field.CanSet() {
-					continue
-				}
-
-				// Handle time.Duration specially
-				if field.Type() == reflect.TypeOf(time.Duration(0)) {
-					duration, err := time.ParseDuration(envValue)
-					if err != nil {
-						return errors.Wrapf(err, "envconfig: parse duration for field %s", fieldType.Name)
-					}
-					field.Set(reflect.ValueOf(duration))
-					continue
-				}
-
-				switch field.Kind() {
-				case reflect.String:
-					field.SetString(envValue)
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-					intVal, err := strconv.ParseInt(envValue, 10, field.Type().Bits())
-					if err != nil {
-						return errors.Wrapf(err, "envconfig: parse int for field %s", fieldType.Name)
-					}
-					field.SetInt(intVal)
-				case reflect.Bool:
-					boolVal, err := strconv.ParseBool(envValue)
-					if err != nil {
-						return errors.Wrapf(err, "envconfig: parse bool for field %s", fieldType.Name)
-					}
-					field.SetBool(boolVal)
-				}
+		switch {
+		case field.Kind() == reflect.Struct:
+			l.loadStruct(field, path)
+		case field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.Struct:
+			// A nil struct is only allocated if any of its fields is set.
+			elem := reflect.New(field.Type().Elem())
+			if !field.IsNil() {
+				elem.Elem().Set(field.Elem())
+			}
+			l.loadStruct(elem.Elem(), path)
+			if !field.IsNil() || !elem.Elem().IsZero() {
+				field.Set(elem)
 			}
 		}
+	}
+}
 
-		if field.Kind() == reflect.Struct {
-			if err := loadFromEnvRecursive(field); err != nil {
-				return err
+func (l *loader) loadField(field reflect.Value, fieldType reflect.StructField, name, path string) {
+	l.known[name] = path
+	if !l.defaultsOnly {
+		envValue, err := lookupEnv(name)
+		if err != nil {
+			l.add(name, path, err)
+			return
+		}
+		if envValue != "" {
+			if err := parseValue(field, envValue); err != nil {
+				l.add(name, path, err)
 			}
+			return
+		}
+	}
+	if def, ok := fieldType.Tag.Lookup("default"); ok && field.IsZero() {
+		if err := parseValue(field, def); err != nil {
+			l.add(name, path, errors.Wrapf(err, "invalid default"))
 		}
+		return
 	}
+	if !l.defaultsOnly && fieldType.Tag.Get("required") == "true" && field.IsZero() {
+		l.add(name, path, errors.New("required variable is not set"))
+	}
+}
 
-	return nil
+// lookupEnv returns the value of the environment variable name. If it is not set, the value is
+// read from the file named by name followed by _FILE, as with Docker and Kubernetes secrets.
+// A trailing newline in the file is ignored.
+func lookupEnv(name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "read %s_FILE", name)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// maxMisspelling is the largest edit distance at which a variable is taken for a misspelling of
+// a known variable.
+const maxMisspelling = 2
+
+// checkUnknown reports the variables of environ that look meant for the struct but match none of
+// its variables. Only variables under the prefix of a known variable, such as ADMIN_ for
+// ADMIN_DB_HOST, are considered, so that unrelated variables are left alone.
+func (l *loader) checkUnknown(environ []string) {
+	prefixes := map[string]bool{}
+	for name := range l.known {
+		if prefix, _, ok := strings.Cut(name, "_"); ok {
+			prefixes[prefix] = true
+		}
+	}
+
+	var names []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range slices.Compact(names) {
+		if _, ok := l.known[name]; ok {
+			continue
+		}
+		base, isFile := strings.CutSuffix(name, "_FILE")
+		if _, ok := l.known[base]; ok {
+			continue
+		}
+		if prefix, _, ok := strings.Cut(base, "_"); !ok || !prefixes[prefix] {
+			continue
+		}
+		suggestion, ok := l.closest(base)
+		switch {
+		case ok && isFile:
+			l.add(name, l.known[suggestion], errors.Newf("unknown variable, did you mean %s_FILE?", suggestion))
+		case ok:
+			l.add(name, l.known[suggestion], errors.Newf("unknown variable, did you mean %s?", suggestion))
+		case isFile:
+			l.add(name, "", errors.Newf("unknown variable, %s is not a variable that can be read from a file", base))
+		}
+	}
+}
+
+// closest returns the known variable that name is most likely a misspelling of. Only variables
+// with the same prefix are considered.
+func (l *loader) closest(name string) (string, bool) {
+	prefix, _, _ := strings.Cut(name, "_")
+	best, bestDistance := "", maxMisspelling+1
+	for known := range l.known {
+		if !strings.HasPrefix(known, prefix+"_") {
+			continue
+		}
+		if d := editDistance(name, known); d < bestDistance || (d == bestDistance && known < best) {
+			best, bestDistance = known, d
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
 }
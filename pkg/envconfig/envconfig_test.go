@@ -0,0 +1,183 @@
+package envconfig
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name     string            `env:"TEST_NAME" default:"admin-api"`
+	Port     uint16            `env:"TEST_PORT"`
+	Ratio    float64           `env:"TEST_RATIO"`
+	Timeout  time.Duration     `env:"TEST_TIMEOUT" default:"5s"`
+	Orgs     []string          `env:"TEST_ORGS"`
+	Windows  []time.Duration   `env:"TEST_WINDOWS"`
+	Limits   map[string]int    `env:"TEST_LIMITS"`
+	Labels   map[string]string `env:"TEST_LABELS"`
+	Addr     netip.Addr        `env:"TEST_ADDR"`
+	Replicas *int              `env:"TEST_REPLICAS"`
+	Token    string            `env:"TEST_TOKEN" required:"true"`
+	Nested   *nestedConfig
+	Optional *optionalConfig
+}
+
+type fileConfig struct {
+	CertFile string `env:"TEST_CERT_FILE"`
+}
+
+type nestedConfig struct {
+	Enabled bool `env:"TEST_NESTED_ENABLED"`
+}
+
+type optionalConfig struct {
+	Level string `env:"TEST_OPTIONAL_LEVEL"`
+}
+
+// Tests in this file set environment variables, so they do not run in parallel.
+
+func TestLoadFromEnv(t *testing.T) {
+	t.Setenv("TEST_PORT", "8080")
+	t.Setenv("TEST_RATIO", "0.5")
+	t.Setenv("TEST_ORGS", "tacokumo, other,")
+	t.Setenv("TEST_WINDOWS", `["1s", "1m"]`)
+	t.Setenv("TEST_LIMITS", "auth=60,default=600")
+	t.Setenv("TEST_LABELS", `{"team":"platform","tier":1}`)
+	t.Setenv("TEST_ADDR", "192.0.2.1")
+	t.Setenv("TEST_REPLICAS", "3")
+	t.Setenv("TEST_NESTED_ENABLED", "true")
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_TOKEN_FILE", tokenFile)
+
+	got, err := LoadFromEnv[testConfig]()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+
+	replicas := 3
+	want := testConfig{
+		Name:     "admin-api",
+		Port:     8080,
+		Ratio:    0.5,
+		Timeout:  5 * time.Second,
+		Orgs:     []string{"tacokumo", "other"},
+		Windows:  []time.Duration{time.Second, time.Minute},
+		Limits:   map[string]int{"auth": 60, "default": 600},
+		Labels:   map[string]string{"team": "platform", "tier": "1"},
+		Addr:     netip.MustParseAddr("192.0.2.1"),
+		Replicas: &replicas,
+		Token:    "secret",
+		Nested:   &nestedConfig{Enabled: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadFromEnv() = %+v, want %+v", got, want)
+	}
+}
+
+func TestOverrideFromEnv(t *testing.T) {
+	t.Setenv("TEST_ORGS", "from-env")
+
+	cfg := testConfig{Name: "from-yaml", Orgs: []string{"from-yaml"}, Token: "from-yaml"}
+	if err := OverrideFromEnv(&cfg); err != nil {
+		t.Fatalf("OverrideFromEnv() error = %v", err)
+	}
+	if cfg.Name != "from-yaml" {
+		t.Errorf("Name = %q, want the default not to replace a set value", cfg.Name)
+	}
+	if !reflect.DeepEqual(cfg.Orgs, []string{"from-env"}) {
+		t.Errorf("Orgs = %v, want [from-env]", cfg.Orgs)
+	}
+	if cfg.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want the default 5s", cfg.Timeout)
+	}
+	if cfg.Nested != nil || cfg.Optional != nil {
+		t.Error("nested structs without set fields should stay nil")
+	}
+}
+
+func TestLoadFromEnvErrors(t *testing.T) {
+	t.Setenv("TEST_PORT", "70000")
+	t.Setenv("TEST_RATIO", "half")
+	t.Setenv("TEST_LIMITS", "auth")
+	t.Setenv("TEST_WINDOWS", "1s,forever")
+
+	_, err := LoadFromEnv[testConfig]()
+	var envErr *Error
+	if !errors.As(err, &envErr) {
+		t.Fatalf("LoadFromEnv() error = %v, want *Error", err)
+	}
+
+	var vars []string
+	for _, ve := range envErr.Errors {
+		vars = append(vars, ve.Var)
+	}
+	want := []string{"TEST_PORT", "TEST_RATIO", "TEST_WINDOWS", "TEST_LIMITS", "TEST_TOKEN"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("errors for %v, want %v: %v", vars, want, err)
+	}
+}
+
+func TestLoadFromEnvUnknownVars(t *testing.T) {
+	t.Setenv("TEST_TOKEN", "secret")
+	t.Setenv("TEST_TIMOUT", "10s")
+	t.Setenv("TEST_TOKN_FILE", "/run/secrets/token")
+	t.Setenv("TEST_SECRET_FILE", "/run/secrets/secret")
+	t.Setenv("TEST_UNRELATED_SETTING", "on")
+	t.Setenv("OTHER_NAME", "unrelated")
+
+	_, err := LoadFromEnv[testConfig]()
+	var envErr *Error
+	if !errors.As(err, &envErr) {
+		t.Fatalf("LoadFromEnv() error = %v, want *Error", err)
+	}
+	want := []VarError{
+		{Var: "TEST_SECRET_FILE"},
+		{Var: "TEST_TIMOUT", Field: "Timeout"},
+		{Var: "TEST_TOKN_FILE", Field: "Token"},
+	}
+	if len(envErr.Errors) != len(want) {
+		t.Fatalf("errors = %v, want %d errors", err, len(want))
+	}
+	for i, ve := range envErr.Errors {
+		if ve.Var != want[i].Var || ve.Field != want[i].Field {
+			t.Errorf("error %d = %s (%s), want %s (%s)", i, ve.Var, ve.Field, want[i].Var, want[i].Field)
+		}
+	}
+}
+
+func TestLoadFromEnvFileSuffixedVar(t *testing.T) {
+	t.Setenv("TEST_CERT_FILE", "/etc/tls/cert.pem")
+
+	got, err := LoadFromEnv[fileConfig]()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+	if got.CertFile != "/etc/tls/cert.pem" {
+		t.Errorf("CertFile = %q, want /etc/tls/cert.pem", got.CertFile)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	t.Setenv("TEST_NAME", "from-env")
+
+	cfg := testConfig{Orgs: []string{"from-yaml"}}
+	if err := ApplyDefaults(&cfg); err != nil {
+		t.Fatalf("ApplyDefaults() error = %v", err)
+	}
+	if cfg.Name != "admin-api" {
+		t.Errorf("Name = %q, want the default and not the environment", cfg.Name)
+	}
+	if cfg.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want the default 5s", cfg.Timeout)
+	}
+	if !reflect.DeepEqual(cfg.Orgs, []string{"from-yaml"}) {
+		t.Errorf("Orgs = %v, want [from-yaml]", cfg.Orgs)
+	}
+}
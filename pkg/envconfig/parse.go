@@ -0,0 +1,161 @@
+package envconfig
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// parseValue sets v from the value of an environment variable. Supported are:
+//   - types implementing encoding.TextUnmarshaler
+//   - string, bool, signed and unsigned integers, floats and time.Duration
+//   - slices, as a comma separated list ("a,b") or a JSON array (["a","b"])
+//   - maps with string keys, as comma separated pairs ("a=1,b=2") or a JSON object ({"a":1})
+//   - pointers to any of these
+func parseValue(v reflect.Value, s string) error {
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.Wrapf(err, "parse duration")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.Wrapf(err, "parse bool")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "parse int")
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "parse uint")
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "parse float")
+		}
+		v.SetFloat(f)
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := parseValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		return parseSlice(v, s)
+	case reflect.Map:
+		return parseMap(v, s)
+	default:
+		return errors.Newf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func parseSlice(v reflect.Value, s string) error {
+	var items []string
+	if strings.HasPrefix(strings.TrimSpace(s), "[") {
+		var raw []json.RawMessage
+		if err := json.Unmarshal([]byte(s), &raw); err != nil {
+			return errors.Wrapf(err, "parse JSON array")
+		}
+		for _, r := range raw {
+			items = append(items, jsonText(r))
+		}
+	} else {
+		items = splitList(s)
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := parseValue(slice.Index(i), item); err != nil {
+			return errors.Wrapf(err, "item %d", i)
+		}
+	}
+	v.Set(slice)
+	return nil
+}
+
+func parseMap(v reflect.Value, s string) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return errors.Newf("unsupported type %s", v.Type())
+	}
+	pairs := map[string]string{}
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(s), &raw); err != nil {
+			return errors.Wrapf(err, "parse JSON object")
+		}
+		for k, r := range raw {
+			pairs[k] = jsonText(r)
+		}
+	} else {
+		for _, item := range splitList(s) {
+			key, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return errors.Newf("%q is not a key=value pair", item)
+			}
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	m := reflect.MakeMapWithSize(v.Type(), len(pairs))
+	for key, value := range pairs {
+		k := reflect.New(v.Type().Key()).Elem()
+		k.SetString(key)
+		e := reflect.New(v.Type().Elem()).Elem()
+		if err := parseValue(e, value); err != nil {
+			return errors.Wrapf(err, "key %q", key)
+		}
+		m.SetMapIndex(k, e)
+	}
+	v.Set(m)
+	return nil
+}
+
+// splitList splits a comma separated list, ignoring spaces around items and empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// jsonText returns the value of a JSON string, or the text of any other JSON value, so that
+// ["1s", "2s"] and [1, 2] are parsed like the items of a comma separated list.
+func jsonText(r json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(r, &s); err == nil {
+		return s
+	}
+	return string(r)
+}
@@ -0,0 +1,182 @@
+package envconfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// durationPattern matches the durations accepted by time.ParseDuration, such as "1m30s".
+const durationPattern = `^-?(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+// JSONSchema is the subset of JSON Schema (draft 2020-12) that describes a config file.
+type JSONSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Pattern     string `json:"pattern,omitempty"`
+	Minimum     *int   `json:"minimum,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	WriteOnly   bool   `json:"writeOnly,omitempty"`
+
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	// AdditionalProperties is false for structs, which have no other keys, and the schema of
+	// the values for maps.
+	AdditionalProperties any         `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema `json:"items,omitempty"`
+}
+
+// Schema returns the JSON Schema of the YAML file that T is decoded from, based on the `yaml`
+// struct tags. Defaults come from `default` tags, and secret fields are marked write-only.
+func Schema[T any]() *JSONSchema {
+	s := schemaOf(reflect.TypeOf((*T)(nil)).Elem())
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	return s
+}
+
+func schemaOf(t reflect.Type) *JSONSchema {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return &JSONSchema{Type: "string"}
+	}
+	if t == durationType {
+		return &JSONSchema{Type: "string", Pattern: durationPattern}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0
+		return &JSONSchema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Slice:
+		return &JSONSchema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.Struct:
+		s := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := yamlName(field)
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			p := schemaOf(field.Type)
+			if def, ok := field.Tag.Lookup("default"); ok {
+				p.Default = defaultValue(field.Type, def)
+			}
+			if env := field.Tag.Get("env"); env != "" {
+				p.Description = "Environment variable: " + env
+			}
+			p.WriteOnly = isSecret(field)
+			s.Properties[name] = p
+		}
+		return s
+	default:
+		return &JSONSchema{}
+	}
+}
+
+// defaultValue converts a `default` tag to the JSON value of the field, so that numbers and
+// booleans are not quoted.
+func defaultValue(t reflect.Type, def string) any {
+	v := reflect.New(t).Elem()
+	if t == durationType || parseValue(v, def) != nil {
+		return def
+	}
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v.Interface()
+	default:
+		return def
+	}
+}
+
+// Var describes an environment variable of a config.
+type Var struct {
+	// Name is the environment variable.
+	Name string
+	// Path is the YAML path of the field it sets, such as "auth.allowed_orgs".
+	Path     string
+	Type     string
+	Default  string
+	Required bool
+	Secret   bool
+}
+
+// Vars returns the environment variables of T in field order.
+func Vars[T any]() []Var {
+	var vars []Var
+	collectVars(reflect.TypeOf((*T)(nil)).Elem(), "", &vars)
+	return vars
+}
+
+func collectVars(t reflect.Type, prefix string, vars *[]Var) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		path := yamlName(field)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if name := field.Tag.Get("env"); name != "" {
+			*vars = append(*vars, Var{
+				Name:     name,
+				Path:     path,
+				Type:     typeName(field.Type),
+				Default:  field.Tag.Get("default"),
+				Required: field.Tag.Get("required") == "true",
+				Secret:   isSecret(field),
+			})
+			continue
+		}
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			collectVars(ft, path, vars)
+		}
+	}
+}
+
+// typeName returns how the value of a variable is written.
+func typeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return "string"
+	case t.Kind() == reflect.Pointer:
+		return typeName(t.Elem())
+	case t.Kind() == reflect.Slice:
+		return "list of " + typeName(t.Elem())
+	case t.Kind() == reflect.Map:
+		return "map of " + typeName(t.Elem())
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "float"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// yamlName returns the key of a field in a YAML file, which is its lowercased name without a
+// `yaml` tag.
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
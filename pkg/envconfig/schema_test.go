@@ -0,0 +1,60 @@
+package envconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+
+	type secretConfig struct {
+		Password string `yaml:"password" secret:"true"`
+	}
+	type config struct {
+		Port    int               `env:"TEST_PORT" yaml:"port" default:"8080"`
+		Timeout string            `yaml:"timeout"`
+		Labels  map[string]string `yaml:"labels"`
+		Orgs    []string          `yaml:"orgs"`
+		DB      *secretConfig     `yaml:"db"`
+	}
+
+	s := Schema[config]()
+	if s.Type != "object" || s.AdditionalProperties != false {
+		t.Errorf("root = %+v, want an object without additional properties", s)
+	}
+	port := s.Properties["port"]
+	if port.Type != "integer" || port.Default != 8080 || port.Description != "Environment variable: TEST_PORT" {
+		t.Errorf("port = %+v", port)
+	}
+	if labels := s.Properties["labels"]; labels.Type != "object" || !reflect.DeepEqual(labels.AdditionalProperties, &JSONSchema{Type: "string"}) {
+		t.Errorf("labels = %+v", labels)
+	}
+	if orgs := s.Properties["orgs"]; orgs.Type != "array" || orgs.Items.Type != "string" {
+		t.Errorf("orgs = %+v", orgs)
+	}
+	if password := s.Properties["db"].Properties["password"]; !password.WriteOnly {
+		t.Errorf("db.password = %+v, want a write-only secret", password)
+	}
+}
+
+func TestVars(t *testing.T) {
+	t.Parallel()
+
+	got := Vars[testConfig]()
+	if len(got) != 13 {
+		t.Fatalf("Vars() returned %d variables, want 13", len(got))
+	}
+	tests := map[string]Var{
+		"TEST_TIMEOUT":        {Name: "TEST_TIMEOUT", Path: "timeout", Type: "duration", Default: "5s"},
+		"TEST_WINDOWS":        {Name: "TEST_WINDOWS", Path: "windows", Type: "list of duration"},
+		"TEST_LIMITS":         {Name: "TEST_LIMITS", Path: "limits", Type: "map of int"},
+		"TEST_TOKEN":          {Name: "TEST_TOKEN", Path: "token", Type: "string", Required: true},
+		"TEST_NESTED_ENABLED": {Name: "TEST_NESTED_ENABLED", Path: "nested.enabled", Type: "bool"},
+	}
+	for _, v := range got {
+		if want, ok := tests[v.Name]; ok && v != want {
+			t.Errorf("Vars() %s = %+v, want %+v", v.Name, v, want)
+		}
+	}
+}
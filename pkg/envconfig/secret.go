@@ -0,0 +1,116 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the values of secret fields.
+const Redacted = "[REDACTED]"
+
+// isSecret reports whether a field is tagged `secret:"true"`.
+func isSecret(field reflect.StructField) bool {
+	return field.Tag.Get("secret") == "true"
+}
+
+// Redact returns a copy of v with its secret string fields replaced by Redacted. Secrets that are
+// not set stay empty, so that it still shows which are missing. Nested structs and slices of
+// structs are copied, so v is not modified.
+func Redact[T any](v T) T {
+	value := reflect.ValueOf(&v).Elem()
+	redactValue(value)
+	return v
+}
+
+func redactValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			if isSecret(v.Type().Field(i)) && field.Kind() == reflect.String {
+				if field.String() != "" {
+					field.SetString(Redacted)
+				}
+				continue
+			}
+			redactValue(field)
+		}
+	case reflect.Slice:
+		if v.IsNil() || !hasSecrets(v.Type().Elem()) {
+			return
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		for i := 0; i < copied.Len(); i++ {
+			redactValue(copied.Index(i))
+		}
+		v.Set(copied)
+	}
+}
+
+// hasSecrets reports whether values of t contain secret fields.
+func hasSecrets(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() && (isSecret(f) || hasSecrets(f.Type)) {
+				return true
+			}
+		}
+	case reflect.Slice:
+		return hasSecrets(t.Elem())
+	}
+	return false
+}
+
+// Format formats a struct like the %+v verb, with secret fields redacted and unexported fields
+// left out. Types with secrets use it in their String method, so that printing them with %v or
+// logging them does not leak the secrets.
+func Format[T any](v T) string {
+	var b strings.Builder
+	formatValue(&b, reflect.ValueOf(Redact(v)))
+	return b.String()
+}
+
+// formatValue formats structs and slices that contain secrets field by field, and other values
+// with fmt, so that their String methods are used.
+func formatValue(b *strings.Builder, v reflect.Value) {
+	switch {
+	case v.Kind() == reflect.Struct:
+		b.WriteString("{")
+		first := true
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if !first {
+				b.WriteString(" ")
+			}
+			first = false
+			b.WriteString(field.Name)
+			b.WriteString(":")
+			if hasSecrets(field.Type) {
+				formatValue(b, v.Field(i))
+			} else {
+				fmt.Fprint(b, v.Field(i).Interface())
+			}
+		}
+		b.WriteString("}")
+	case v.Kind() == reflect.Slice:
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			formatValue(b, v.Index(i))
+		}
+		b.WriteString("]")
+	default:
+		fmt.Fprint(b, v.Interface())
+	}
+}
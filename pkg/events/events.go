@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Kind is the kind of resource an event is about.
+type Kind string
+
+const (
+	KindProject        Kind = "project"
+	KindRole           Kind = "role"
+	KindUserGroup      Kind = "usergroup"
+	KindUser           Kind = "user"
+	KindServiceAccount Kind = "serviceaccount"
+	// KindUserGroupMember is a user or service account joining or leaving a user group.
+	KindUserGroupMember Kind = "usergroup_member"
+	// KindRoleAssignment is a role being assigned to or unassigned from a principal.
+	KindRoleAssignment Kind = "role_assignment"
+	// KindInvitation is an invitation to a project being created, accepted or revoked.
+	KindInvitation Kind = "invitation"
+	// KindProjectTransfer is an ownership transfer of a project being started, accepted or cancelled.
+	KindProjectTransfer Kind = "project_transfer"
+)
+
+// Kinds lists every event kind.
+var Kinds = []Kind{
+	KindProject,
+	KindRole,
+	KindUserGroup,
+	KindUser,
+	KindServiceAccount,
+	KindUserGroupMember,
+	KindRoleAssignment,
+	KindInvitation,
+	KindProjectTransfer,
+}
+
+// Action is what happened to the resource.
+type Action string
+
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionDeleted Action = "deleted"
+)
+
+// Actions lists every event action.
+var Actions = []Action{ActionCreated, ActionUpdated, ActionDeleted}
+
+// MemberKind is the kind of principal in membership events.
+type MemberKind string
+
+const (
+	MemberKindUser           MemberKind = "user"
+	MemberKindServiceAccount MemberKind = "serviceaccount"
+)
+
+// Event describes a change to an admin resource.
+type Event struct {
+	// ID is assigned by the stream when the event is published and is used for Last-Event-ID.
+	ID     string `json:"id"`
+	Kind   Kind   `json:"kind"`
+	Action Action `json:"action"`
+	// ResourceID is the display ID of the changed resource. For membership events it is the
+	// user group or role the member was added to or removed from.
+	ResourceID string `json:"resourceId"`
+	// ProjectID is the display ID of the project the resource belongs to, if any.
+	ProjectID  string     `json:"projectId,omitempty"`
+	MemberID   string     `json:"memberId,omitempty"`
+	MemberKind MemberKind `json:"memberKind,omitempty"`
+	// Actor is the session user that made the change.
+	Actor      string    `json:"actor,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// Type returns the event type in the "<kind>.<action>" form, e.g. "project.created".
+func (e Event) Type() string {
+	return string(e.Kind) + "." + string(e.Action)
+}
+
+// Publisher publishes events to subscribers on every replica.
+type Publisher interface {
+	// Publish stores the event and returns the ID it was assigned.
+	Publish(ctx context.Context, event Event) (string, error)
+}
+
+// Subscriber reads published events in order.
+type Subscriber interface {
+	// Latest returns the ID of the most recently published event so that a reader can start
+	// from the current position without replaying history.
+	Latest(ctx context.Context) (string, error)
+	// Read returns events published after afterID, waiting up to block for new ones.
+	// It returns an empty slice if nothing was published in that time.
+	Read(ctx context.Context, afterID string, block time.Duration) ([]Event, error)
+}
+
+// Stream is both ends of the event stream.
+type Stream interface {
+	Publisher
+	Subscriber
+}
+
+// Filter selects the events a subscriber receives.
+type Filter struct {
+	// ProjectIDs restricts events to these projects. Events that do not belong to a project
+	// are dropped when it is set.
+	ProjectIDs []string
+	// Kinds restricts events to these kinds.
+	Kinds []Kind
+}
+
+// Match reports whether the event passes the filter.
+func (f Filter) Match(e Event) bool {
+	if len(f.ProjectIDs) > 0 && !slices.Contains(f.ProjectIDs, e.ProjectID) {
+		return false
+	}
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, e.Kind) {
+		return false
+	}
+	return true
+}
+
+var idPattern = regexp.MustCompile(`^[0-9]+-[0-9]+$`)
+
+// ValidID reports whether id has the form of an event ID, as sent back in Last-Event-ID.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
@@ -0,0 +1,96 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		filter Filter
+		event  Event
+		want   bool
+	}{
+		{
+			name:   "empty filter matches everything",
+			filter: Filter{},
+			event:  Event{Kind: KindUser, Action: ActionCreated},
+			want:   true,
+		},
+		{
+			name:   "matching project",
+			filter: Filter{ProjectIDs: []string{"p1", "p2"}},
+			event:  Event{Kind: KindRole, ProjectID: "p2"},
+			want:   true,
+		},
+		{
+			name:   "other project",
+			filter: Filter{ProjectIDs: []string{"p1"}},
+			event:  Event{Kind: KindRole, ProjectID: "p2"},
+			want:   false,
+		},
+		{
+			name:   "project filter drops events without a project",
+			filter: Filter{ProjectIDs: []string{"p1"}},
+			event:  Event{Kind: KindUser},
+			want:   false,
+		},
+		{
+			name:   "matching kind",
+			filter: Filter{Kinds: []Kind{KindProject, KindUserGroupMember}},
+			event:  Event{Kind: KindUserGroupMember, ProjectID: "p1"},
+			want:   true,
+		},
+		{
+			name:   "other kind",
+			filter: Filter{ProjectIDs: []string{"p1"}, Kinds: []Kind{KindProject}},
+			event:  Event{Kind: KindRole, ProjectID: "p1"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.filter.Match(tt.event); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "1700000000000-0", want: true},
+		{id: "0-0", want: true},
+		{id: "", want: false},
+		{id: "$", want: false},
+		{id: "1700000000000", want: false},
+		{id: "abc-1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			t.Parallel()
+			if got := ValidID(tt.id); got != tt.want {
+				t.Errorf("ValidID(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventType(t *testing.T) {
+	t.Parallel()
+
+	e := Event{Kind: KindUserGroupMember, Action: ActionDeleted}
+	if got, want := e.Type(), "usergroup_member.deleted"; got != want {
+		t.Errorf("Type() = %q, want %q", got, want)
+	}
+}
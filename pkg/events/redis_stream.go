@@ -0,0 +1,125 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamKey = "events:admin"
+	// eventField is the stream entry field holding the JSON encoded event.
+	eventField = "event"
+	// readBatchSize bounds the number of events returned by a single Read.
+	readBatchSize = 100
+	// maxReadBlock bounds a single blocking XREAD. go-redis does not abort a command when its
+	// context is cancelled, so Read waits in reads of at most this long and checks the context
+	// between them. This keeps a shutdown from waiting for the full block of every subscriber.
+	maxReadBlock = time.Second
+	// DefaultMaxLen is the approximate number of events kept in the stream for resuming.
+	DefaultMaxLen = 10000
+)
+
+// RedisStream stores events in a Redis stream so that every replica sees every event and
+// clients can resume from the ID of the last event they received.
+type RedisStream struct {
+	client *redis.Client
+	// reader serves the blocking reads, which hold a connection for as long as they wait.
+	reader *redis.Client
+	maxLen int64
+}
+
+// NewRedisStream returns a stream that publishes with client and reads with reader. Every
+// subscriber keeps a connection of reader busy, so it should not be shared with anything that
+// must not wait for a free connection, such as sessions or rate limiting.
+func NewRedisStream(client, reader *redis.Client, maxLen int) *RedisStream {
+	if maxLen <= 0 {
+		maxLen = DefaultMaxLen
+	}
+	return &RedisStream{client: client, reader: reader, maxLen: int64(maxLen)}
+}
+
+// Publish implements Publisher.
+func (s *RedisStream) Publish(ctx context.Context, event Event) (string, error) {
+	event.ID = ""
+	data, err := json.Marshal(&event)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal event")
+	}
+
+	id, err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamKey,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]any{eventField: data},
+	}).Result()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to add event to redis stream")
+	}
+	return id, nil
+}
+
+// Latest implements Subscriber.
+func (s *RedisStream) Latest(ctx context.Context) (string, error) {
+	msgs, err := s.client.XRevRangeN(ctx, streamKey, "+", "-", 1).Result()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read latest event from redis stream")
+	}
+	if len(msgs) == 0 {
+		return "0-0", nil
+	}
+	return msgs[0].ID, nil
+}
+
+// Read implements Subscriber.
+func (s *RedisStream) Read(ctx context.Context, afterID string, block time.Duration) ([]Event, error) {
+	deadline := time.Now().Add(block)
+	for {
+		// XREAD rounds the block down to milliseconds, and a block of 0 waits forever.
+		wait := max(min(time.Until(deadline), maxReadBlock), time.Millisecond)
+		events, found, err := s.read(ctx, afterID, wait)
+		if err != nil || found || !time.Now().Before(deadline) {
+			return events, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// read runs a single XREAD. found reports whether there were entries after afterID, which
+// may all have been skipped.
+func (s *RedisStream) read(ctx context.Context, afterID string, block time.Duration) (events []Event, found bool, err error) {
+	streams, err := s.reader.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{streamKey, afterID},
+		Count:   readBatchSize,
+		Block:   block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return []Event{}, false, nil
+		}
+		return nil, false, errors.Wrap(err, "failed to read events from redis stream")
+	}
+
+	events = []Event{}
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			found = true
+			raw, ok := msg.Values[eventField].(string)
+			if !ok {
+				continue
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(raw), &event); err != nil {
+				return nil, false, errors.Wrapf(err, "failed to unmarshal event %s", msg.ID)
+			}
+			event.ID = msg.ID
+			events = append(events, event)
+		}
+	}
+	return events, found, nil
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tacokumo/admin-api/internal/redistest"
+)
+
+// blockingXRead serves XREAD like Redis does when the stream has nothing new: it waits for the
+// whole BLOCK regardless of the client going away, and replies with nil. It records the blocks
+// it was called with.
+type blockingXRead struct {
+	mu     sync.Mutex
+	blocks []time.Duration
+}
+
+func (x *blockingXRead) handle(args []string) any {
+	for i := 0; i+1 < len(args); i++ {
+		if strings.EqualFold(args[i], "BLOCK") {
+			ms, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return err
+			}
+			block := time.Duration(ms) * time.Millisecond
+			x.mu.Lock()
+			x.blocks = append(x.blocks, block)
+			x.mu.Unlock()
+			time.Sleep(block)
+		}
+	}
+	return nil
+}
+
+func (x *blockingXRead) calls() []time.Duration {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return append([]time.Duration(nil), x.blocks...)
+}
+
+func TestRedisStreamReadWaitsInBoundedReads(t *testing.T) {
+	t.Parallel()
+
+	server := redistest.NewServer(t)
+	xread := &blockingXRead{}
+	server.Handle("XREAD", xread.handle)
+	stream := NewRedisStream(server.Client(t), server.Client(t), 0)
+
+	block := maxReadBlock + maxReadBlock/2
+	start := time.Now()
+	evs, err := stream.Read(context.Background(), "0-0", block)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(evs) != 0 {
+		t.Errorf("Read() = %v, want no events", evs)
+	}
+	if elapsed := time.Since(start); elapsed < block {
+		t.Errorf("Read() returned after %v, want at least the block %v", elapsed, block)
+	}
+	calls := xread.calls()
+	if len(calls) < 2 {
+		t.Errorf("XREAD was called %d times, want the block split into several reads", len(calls))
+	}
+	for _, b := range calls {
+		if b <= 0 || b > maxReadBlock {
+			t.Errorf("XREAD BLOCK = %v, want between 0 and %v", b, maxReadBlock)
+		}
+	}
+}
+
+func TestRedisStreamReadStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	server := redistest.NewServer(t)
+	xread := &blockingXRead{}
+	server.Handle("XREAD", xread.handle)
+	stream := NewRedisStream(server.Client(t), server.Client(t), 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := stream.Read(ctx, "0-0", time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Read() error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed, limit := time.Since(start), 2*maxReadBlock; elapsed > limit {
+		t.Errorf("Read() returned %v after being cancelled, want within %v", elapsed, limit)
+	}
+}
+
+func TestRedisStreamReadReturnsEvents(t *testing.T) {
+	t.Parallel()
+
+	server := redistest.NewServer(t)
+	server.Handle("XREAD", func(args []string) any {
+		return []any{
+			[]any{streamKey, []any{
+				[]any{"1-0", []any{eventField, `{"kind":"user","action":"created","resourceId":"u1","occurredAt":"2026-01-01T00:00:00Z"}`}},
+				[]any{"2-0", []any{"other", "ignored"}},
+			}},
+		}
+	})
+	stream := NewRedisStream(server.Client(t), server.Client(t), 0)
+
+	evs, err := stream.Read(context.Background(), "0-0", time.Minute)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(evs) != 1 || evs[0].ID != "1-0" || evs[0].Kind != KindUser || evs[0].ResourceID != "u1" {
+		t.Errorf("Read() = %+v, want the event 1-0 of user u1", evs)
+	}
+}
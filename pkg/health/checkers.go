@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// DialChecker checks that addr accepts TCP connections. It suits dependencies such as an OTLP
+// collector that have no cheap request to probe them with.
+func DialChecker(addr string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to %s", addr)
+		}
+		return conn.Close()
+	})
+}
+
+// HTTPChecker checks that a HEAD request to rawURL gets a response that is not a server error.
+func HTTPChecker(client *http.Client, rawURL string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create request")
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "failed to send request to %s", rawURL)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return errors.Newf("%s returned %s", rawURL, resp.Status)
+		}
+		return nil
+	})
+}
+
+// EndpointAddr returns the host:port of an endpoint given either as host:port or as a URL.
+// defaultPort is used when the endpoint has none.
+func EndpointAddr(endpoint, defaultPort string) (string, error) {
+	host := endpoint
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse endpoint %q", endpoint)
+		}
+		host = u.Host
+	}
+	if host == "" {
+		return "", errors.Newf("endpoint %q has no host", endpoint)
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		return net.JoinHostPort(host, defaultPort), nil
+	}
+	return host, nil
+}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes serves the probes under g as /health/startup, /health/liveness and
+// /health/readiness. A failing probe responds with 503 and the report of its components.
+func (r *Registry) RegisterRoutes(g *echo.Group) {
+	g.GET("/health/startup", probeHandler(r.Startup))
+	g.GET("/health/liveness", probeHandler(r.Liveness))
+	g.GET("/health/readiness", probeHandler(r.Readiness))
+}
+
+func probeHandler(probe func(ctx context.Context) Report) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report := probe(c.Request().Context())
+		status := http.StatusOK
+		if !report.OK() {
+			status = http.StatusServiceUnavailable
+		}
+		return c.JSON(status, report)
+	}
+}
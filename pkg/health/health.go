@@ -0,0 +1,243 @@
+// Package health runs the health checks of the server's dependencies.
+//
+// Checks are registered on a Registry and evaluated by three probes:
+//
+//   - Liveness reports whether the process is able to serve requests at all. It runs no checks,
+//     so that a failing dependency never gets the server restarted.
+//   - Readiness runs every check and fails if a required one fails. Optional checks are
+//     reported but do not make the server unready.
+//   - Startup fails until the server has been marked started and readiness has succeeded once.
+//
+// Once the server is marked draining, readiness fails without running the checks, so that load
+// balancers stop sending requests before the server shuts down.
+//
+// Results are cached for a short time, so that frequent probes do not put load on the dependencies.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Status is the status of a probe or a component. The values match the status of the
+// generated HealthResponse.
+type Status string
+
+const (
+	StatusOK Status = "ok"
+	StatusNG Status = "ng"
+)
+
+// Default settings of a Registry and its checks.
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTTL = 5 * time.Second
+)
+
+// Checker checks a single dependency. It returns nil if the dependency is healthy.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to a Checker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check implements Checker.
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Check is a checker registered under a component name.
+type Check struct {
+	Name    string
+	Checker Checker
+	// Timeout bounds a single run of the checker. Defaults to the timeout of the registry.
+	Timeout time.Duration
+	// CacheTTL is how long a result is reused. Defaults to the cache TTL of the registry.
+	CacheTTL time.Duration
+	// Optional checks are reported but do not fail readiness.
+	Optional bool
+}
+
+// Config configures a Registry.
+type Config struct {
+	// Timeout is the default timeout of a check. Defaults to 2s.
+	Timeout time.Duration
+	// CacheTTL is the default time a result is reused. Defaults to 5s.
+	CacheTTL time.Duration
+}
+
+// ComponentReport is the result of the check of a component.
+type ComponentReport struct {
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Optional bool   `json:"optional,omitempty"`
+	// LatencyMS is the time the check took, in milliseconds.
+	LatencyMS float64   `json:"latencyMs"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Report is the result of a probe.
+type Report struct {
+	Status Status `json:"status"`
+	// Reason explains a failure that is not caused by a component, such as a server shutting down.
+	Reason     string            `json:"reason,omitempty"`
+	Components []ComponentReport `json:"components,omitempty"`
+}
+
+// OK reports whether the probe succeeded.
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Registry holds the checks of the server.
+type Registry struct {
+	cfg Config
+	now func() time.Time
+
+	mu     sync.RWMutex
+	checks []*entry
+
+	started  atomic.Bool
+	draining atomic.Bool
+	// startedUp latches once startup has succeeded, so later failures only affect readiness.
+	startedUp atomic.Bool
+}
+
+// entry is a registered check and its last result. mu is held while the check runs, so that
+// concurrent probes wait for the running check and share its result.
+type entry struct {
+	check Check
+
+	mu     sync.Mutex
+	result ComponentReport
+	valid  bool
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry(cfg Config) *Registry {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = DefaultCacheTTL
+	}
+	return &Registry{cfg: cfg, now: time.Now}
+}
+
+// Register adds a check. Components are reported in the order they are registered.
+func (r *Registry) Register(c Check) {
+	if c.Timeout <= 0 {
+		c.Timeout = r.cfg.Timeout
+	}
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = r.cfg.CacheTTL
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, &entry{check: c})
+}
+
+// MarkStarted records that the server has finished initializing and accepts requests.
+func (r *Registry) MarkStarted() {
+	r.started.Store(true)
+}
+
+// MarkDraining makes readiness fail from now on. The server calls it when it starts shutting down.
+func (r *Registry) MarkDraining() {
+	r.draining.Store(true)
+}
+
+// Liveness reports whether the process is alive. It does not run any checks.
+func (r *Registry) Liveness(_ context.Context) Report {
+	return Report{Status: StatusOK}
+}
+
+// Readiness runs the checks, reusing cached results, and fails if the server has not started,
+// is draining or a required check fails.
+func (r *Registry) Readiness(ctx context.Context) Report {
+	if r.draining.Load() {
+		return Report{Status: StatusNG, Reason: "shutting down"}
+	}
+
+	r.mu.RLock()
+	checks := append([]*entry(nil), r.checks...)
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Components: make([]ComponentReport, len(checks))}
+	var wg sync.WaitGroup
+	for i, e := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Components[i] = r.run(ctx, e)
+		}()
+	}
+	wg.Wait()
+
+	if !r.started.Load() {
+		report.Status = StatusNG
+		report.Reason = "starting"
+	}
+	for _, c := range report.Components {
+		if c.Status != StatusOK && !c.Optional {
+			report.Status = StatusNG
+		}
+	}
+	return report
+}
+
+// Startup fails until the server has been marked started and readiness has succeeded once.
+// After that it always succeeds.
+func (r *Registry) Startup(ctx context.Context) Report {
+	if r.startedUp.Load() {
+		return Report{Status: StatusOK}
+	}
+	report := r.Readiness(ctx)
+	if report.OK() {
+		r.startedUp.Store(true)
+	}
+	return report
+}
+
+// run returns the cached result of a check, running the check if the result has expired.
+func (r *Registry) run(ctx context.Context, e *entry) ComponentReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.valid && r.now().Sub(e.result.CheckedAt) < e.check.CacheTTL {
+		return e.result
+	}
+
+	// The result is shared with other probes, so it must not depend on the caller's cancellation.
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.check.Timeout)
+	defer cancel()
+
+	start := r.now()
+	err := e.check.Checker.Check(checkCtx)
+	if err == nil && checkCtx.Err() != nil {
+		err = checkCtx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = errors.Newf("timed out after %s", e.check.Timeout)
+	}
+
+	e.result = ComponentReport{
+		Name:      e.check.Name,
+		Status:    StatusOK,
+		Optional:  e.check.Optional,
+		LatencyMS: float64(r.now().Sub(start).Microseconds()) / 1000,
+		CheckedAt: start,
+	}
+	if err != nil {
+		e.result.Status = StatusNG
+		e.result.Error = err.Error()
+	}
+	e.valid = true
+	return e.result
+}
@@ -0,0 +1,258 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock for cache expiry.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func okChecker() Checker {
+	return CheckerFunc(func(context.Context) error { return nil })
+}
+
+func failingChecker() Checker {
+	return CheckerFunc(func(context.Context) error { return errors.New("connection refused") })
+}
+
+func TestReadiness(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		checks     []Check
+		started    bool
+		wantStatus Status
+	}{
+		{
+			name:       "all checks pass",
+			checks:     []Check{{Name: "postgres", Checker: okChecker()}, {Name: "redis", Checker: okChecker()}},
+			started:    true,
+			wantStatus: StatusOK,
+		},
+		{
+			name:       "required check fails",
+			checks:     []Check{{Name: "postgres", Checker: okChecker()}, {Name: "redis", Checker: failingChecker()}},
+			started:    true,
+			wantStatus: StatusNG,
+		},
+		{
+			name:       "optional check fails",
+			checks:     []Check{{Name: "postgres", Checker: okChecker()}, {Name: "github", Checker: failingChecker(), Optional: true}},
+			started:    true,
+			wantStatus: StatusOK,
+		},
+		{
+			name:       "not started",
+			checks:     []Check{{Name: "postgres", Checker: okChecker()}},
+			started:    false,
+			wantStatus: StatusNG,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewRegistry(Config{})
+			for _, c := range tt.checks {
+				r.Register(c)
+			}
+			if tt.started {
+				r.MarkStarted()
+			}
+
+			report := r.Readiness(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("Readiness() status = %q, want %q", report.Status, tt.wantStatus)
+			}
+			if len(report.Components) != len(tt.checks) {
+				t.Fatalf("Readiness() components = %d, want %d", len(report.Components), len(tt.checks))
+			}
+			for i, c := range tt.checks {
+				if report.Components[i].Name != c.Name {
+					t.Errorf("component %d name = %q, want %q", i, report.Components[i].Name, c.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestReadinessReportsErrors(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry(Config{})
+	r.Register(Check{Name: "redis", Checker: failingChecker()})
+	r.Register(Check{
+		Name:    "slow",
+		Timeout: 10 * time.Millisecond,
+		Checker: CheckerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+	})
+	r.MarkStarted()
+
+	report := r.Readiness(context.Background())
+	if got := report.Components[0]; got.Status != StatusNG || got.Error != "connection refused" {
+		t.Errorf("failing component = %+v", got)
+	}
+	if got := report.Components[1]; got.Status != StatusNG || got.Error != "timed out after 10ms" {
+		t.Errorf("slow component = %+v", got)
+	}
+}
+
+func TestReadinessCachesResults(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	r := NewRegistry(Config{CacheTTL: 5 * time.Second})
+	r.now = clock.Now
+
+	var calls atomic.Int32
+	r.Register(Check{Name: "postgres", Checker: CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})})
+	r.Register(Check{Name: "github", CacheTTL: time.Minute, Checker: CheckerFunc(func(context.Context) error {
+		calls.Add(10)
+		return nil
+	})})
+	r.MarkStarted()
+
+	r.Readiness(context.Background())
+	r.Readiness(context.Background())
+	if got := calls.Load(); got != 11 {
+		t.Fatalf("calls after two probes = %d, want 11", got)
+	}
+
+	clock.Advance(5 * time.Second)
+	r.Readiness(context.Background())
+	if got := calls.Load(); got != 12 {
+		t.Fatalf("calls after the default TTL = %d, want 12", got)
+	}
+
+	clock.Advance(time.Minute)
+	r.Readiness(context.Background())
+	if got := calls.Load(); got != 23 {
+		t.Fatalf("calls after the check's TTL = %d, want 23", got)
+	}
+}
+
+func TestStartup(t *testing.T) {
+	t.Parallel()
+
+	var healthy atomic.Bool
+	r := NewRegistry(Config{CacheTTL: time.Nanosecond})
+	r.Register(Check{Name: "postgres", Checker: CheckerFunc(func(context.Context) error {
+		if !healthy.Load() {
+			return errors.New("down")
+		}
+		return nil
+	})})
+
+	healthy.Store(true)
+	if r.Startup(context.Background()).OK() {
+		t.Error("Startup() should fail before MarkStarted")
+	}
+
+	r.MarkStarted()
+	healthy.Store(false)
+	if r.Startup(context.Background()).OK() {
+		t.Error("Startup() should fail while a required check fails")
+	}
+
+	healthy.Store(true)
+	if !r.Startup(context.Background()).OK() {
+		t.Error("Startup() should succeed once started and ready")
+	}
+
+	healthy.Store(false)
+	if !r.Startup(context.Background()).OK() {
+		t.Error("Startup() should keep succeeding after it succeeded once")
+	}
+	if r.Readiness(context.Background()).OK() {
+		t.Error("Readiness() should fail while a required check fails")
+	}
+	if !r.Liveness(context.Background()).OK() {
+		t.Error("Liveness() should not depend on checks")
+	}
+}
+
+func TestReadinessDraining(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	r := NewRegistry(Config{CacheTTL: time.Nanosecond})
+	r.Register(Check{Name: "postgres", Checker: CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})})
+	r.MarkStarted()
+
+	if !r.Readiness(context.Background()).OK() {
+		t.Fatal("Readiness() should succeed before draining")
+	}
+
+	r.MarkDraining()
+	report := r.Readiness(context.Background())
+	if report.OK() || report.Reason != "shutting down" {
+		t.Errorf("Readiness() while draining = %+v, want a failure with a reason", report)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("checks run = %d, want 1; draining should not run checks", got)
+	}
+	if !r.Liveness(context.Background()).OK() {
+		t.Error("Liveness() should succeed while draining")
+	}
+}
+
+func TestEndpointAddr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{"otel-collector:4317", "otel-collector:4317", false},
+		{"otel-collector", "otel-collector:4317", false},
+		{"http://otel-collector:4318", "otel-collector:4318", false},
+		{"https://otel.example.com", "otel.example.com:4317", false},
+		{"http://", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := EndpointAddr(tt.endpoint, "4317")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EndpointAddr(%q) error = %v, wantErr %v", tt.endpoint, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EndpointAddr(%q) = %q, want %q", tt.endpoint, got, tt.want)
+			}
+		})
+	}
+}
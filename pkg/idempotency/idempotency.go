@@ -0,0 +1,61 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// HeaderKey is the request header clients use to make a request idempotent.
+const HeaderKey = "Idempotency-Key"
+
+// HeaderReplayed is set on responses that were replayed from a stored record.
+const HeaderReplayed = "Idempotent-Replayed"
+
+// MaxKeyLength bounds the size of client supplied keys.
+const MaxKeyLength = 255
+
+var ErrRecordNotFound = errors.New("idempotency record not found")
+
+type State string
+
+const (
+	StateInProgress State = "in_progress"
+	StateCompleted  State = "completed"
+)
+
+// Record is what is remembered about a request made with an idempotency key.
+type Record struct {
+	Fingerprint string      `json:"fingerprint"`
+	State       State       `json:"state"`
+	StatusCode  int         `json:"status_code,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Store persists idempotency records.
+type Store interface {
+	// Reserve atomically stores an in-progress record for key unless one exists.
+	// It returns the existing record and false when the key was already used.
+	Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (*Record, bool, error)
+	// Complete stores the final response of the request made with key.
+	Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	// Release forgets key so that the request can be retried, e.g. after a server error.
+	Release(ctx context.Context, key string) error
+}
+
+// Fingerprint identifies a request by its method, path and body so that reusing a key
+// for a different request can be detected.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
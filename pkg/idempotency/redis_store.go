@@ -0,0 +1,82 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "idempotency:"
+
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Reserve implements Store.
+func (s *RedisStore) Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (*Record, bool, error) {
+	data, err := json.Marshal(&Record{
+		Fingerprint: fingerprint,
+		State:       StateInProgress,
+	})
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to marshal idempotency record")
+	}
+
+	ok, err := s.client.SetNX(ctx, keyPrefix+key, data, ttl).Result()
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to reserve idempotency key in redis")
+	}
+	if ok {
+		return nil, true, nil
+	}
+
+	existing, err := s.get(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// Complete implements Store.
+func (s *RedisStore) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal idempotency record")
+	}
+
+	if err := s.client.Set(ctx, keyPrefix+key, data, ttl).Err(); err != nil {
+		return errors.Wrap(err, "failed to store idempotency record in redis")
+	}
+	return nil
+}
+
+// Release implements Store.
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, keyPrefix+key).Err(); err != nil {
+		return errors.Wrap(err, "failed to delete idempotency record from redis")
+	}
+	return nil
+}
+
+func (s *RedisStore) get(ctx context.Context, key string) (*Record, error) {
+	data, err := s.client.Get(ctx, keyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get idempotency record from redis")
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal idempotency record")
+	}
+	return &record, nil
+}
@@ -0,0 +1,64 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tacokumo/admin-api/internal/redistest"
+)
+
+func TestRedisStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	server := redistest.NewServer(t)
+	store := NewRedisStore(server.Client(t))
+	fingerprint := Fingerprint(http.MethodPost, "/v1alpha1/projects", []byte(`{"name":"p"}`))
+
+	existing, reserved, err := store.Reserve(ctx, "user:1:key", fingerprint, time.Hour)
+	if err != nil || !reserved || existing != nil {
+		t.Fatalf("Reserve() = %v, %t, %v, want a new reservation", existing, reserved, err)
+	}
+	if ttl := server.TTL(keyPrefix + "user:1:key"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("TTL = %v, want up to 1h", ttl)
+	}
+
+	existing, reserved, err = store.Reserve(ctx, "user:1:key", fingerprint, time.Hour)
+	if err != nil || reserved {
+		t.Fatalf("Reserve() = %v, %t, %v, want the existing reservation", existing, reserved, err)
+	}
+	if existing.State != StateInProgress || existing.Fingerprint != fingerprint {
+		t.Errorf("existing = %+v, want the in-progress record", existing)
+	}
+
+	record := &Record{
+		Fingerprint: fingerprint,
+		State:       StateCompleted,
+		StatusCode:  http.StatusCreated,
+		Header:      http.Header{"Location": {"/v1alpha1/projects/p"}},
+		Body:        []byte(`{"id":"p"}`),
+	}
+	if err := store.Complete(ctx, "user:1:key", record, time.Hour); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	existing, reserved, err = store.Reserve(ctx, "user:1:key", fingerprint, time.Hour)
+	if err != nil || reserved {
+		t.Fatalf("Reserve() = %v, %t, %v, want the completed record", existing, reserved, err)
+	}
+	if !reflect.DeepEqual(existing, record) {
+		t.Errorf("existing = %+v, want %+v for replay", existing, record)
+	}
+
+	if err := store.Release(ctx, "user:1:key"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, ok := server.Get(keyPrefix + "user:1:key"); ok {
+		t.Error("the key should be gone after Release")
+	}
+	if _, reserved, err := store.Reserve(ctx, "user:1:key", fingerprint, time.Hour); err != nil || !reserved {
+		t.Errorf("Reserve() = %t, %v, want a new reservation after Release", reserved, err)
+	}
+}
@@ -0,0 +1,113 @@
+// Package invitation implements the tokens and emails of project invitations.
+//
+// An invitation grants roles and user group memberships of a project to an email address.
+// It is accepted either when the invitee logs in with a matching verified GitHub email or
+// when they present the token that was mailed to them.
+package invitation
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	tkmail "github.com/tacokumo/admin-api/pkg/mail"
+)
+
+// Invitation statuses stored in tacokumo_admin.invitations.
+// StatusExpired is never stored; it is derived from a pending invitation's expiry.
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+	StatusRevoked  = "revoked"
+	StatusExpired  = "expired"
+)
+
+// TokenPrefix marks invitation tokens so that they are not mistaken for other credentials.
+const TokenPrefix = "tkinv_"
+
+// DefaultTTL is how long an invitation can be accepted when no TTL is configured.
+const DefaultTTL = 7 * 24 * time.Hour
+
+// Token is a freshly generated invitation token.
+// Plaintext is only available at creation time; only Hash is persisted.
+type Token struct {
+	Plaintext string
+	Hash      string
+}
+
+// GenerateToken creates a new random invitation token.
+func GenerateToken() (Token, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return Token{}, errors.Wrap(err, "failed to generate random bytes")
+	}
+	plaintext := TokenPrefix + hex.EncodeToString(bytes)
+	return Token{
+		Plaintext: plaintext,
+		Hash:      HashToken(plaintext),
+	}, nil
+}
+
+// HashToken returns the hex encoded SHA-256 hash of a token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeEmail validates an email address and returns it in the lowercased form
+// invitations are stored and matched in.
+func NormalizeEmail(email string) (string, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || addr.Name != "" || !strings.Contains(addr.Address, "@") {
+		return "", errors.Newf("invalid email address %q", email)
+	}
+	return strings.ToLower(addr.Address), nil
+}
+
+// AcceptURL returns the link of an invitation, which carries the token in the query string.
+func AcceptURL(base, token string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid invitation accept url %q", base)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// MessageParams are the values shown in an invitation email.
+type MessageParams struct {
+	Email       string
+	ProjectName string
+	InvitedBy   string
+	AcceptURL   string
+	ExpiresAt   time.Time
+}
+
+// NewMessage builds the email sent to an invitee.
+func NewMessage(p MessageParams) tkmail.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You have been invited to the tacokumo project %q", p.ProjectName)
+	if p.InvitedBy != "" {
+		fmt.Fprintf(&b, " by %s", p.InvitedBy)
+	}
+	b.WriteString(".\n\n")
+	b.WriteString("Sign in with a GitHub account that has this email address verified to join the project,\n")
+	b.WriteString("or open the link below while signed in:\n\n")
+	fmt.Fprintf(&b, "%s\n\n", p.AcceptURL)
+	fmt.Fprintf(&b, "This invitation expires at %s.\n", p.ExpiresAt.UTC().Format(time.RFC1123))
+	b.WriteString("If you were not expecting it, you can ignore this email.\n")
+
+	return tkmail.Message{
+		To:      []string{p.Email},
+		Subject: fmt.Sprintf("Invitation to the tacokumo project %s", p.ProjectName),
+		Text:    b.String(),
+	}
+}
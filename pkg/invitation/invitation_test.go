@@ -0,0 +1,96 @@
+package invitation
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateToken(t *testing.T) {
+	t.Parallel()
+
+	token, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("GenerateToken() failed: %v", err)
+	}
+	if !strings.HasPrefix(token.Plaintext, TokenPrefix) {
+		t.Errorf("Plaintext %q does not start with %q", token.Plaintext, TokenPrefix)
+	}
+	if token.Hash != HashToken(token.Plaintext) {
+		t.Error("Hash does not match HashToken(Plaintext)")
+	}
+
+	other, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("GenerateToken() failed: %v", err)
+	}
+	if other.Plaintext == token.Plaintext {
+		t.Error("GenerateToken() returned the same token twice")
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "Alice@Example.com", want: "alice@example.com"},
+		{input: "  bob@example.com ", want: "bob@example.com"},
+		{input: "Alice <alice@example.com>", wantErr: true},
+		{input: "not-an-email", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeEmail(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeEmail(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAcceptURL(t *testing.T) {
+	t.Parallel()
+
+	got, err := AcceptURL("https://admin.example.com/invitations/accept?source=mail", "tkinv_abc")
+	if err != nil {
+		t.Fatalf("AcceptURL() failed: %v", err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("AcceptURL() returned an invalid url: %v", err)
+	}
+	if u.Query().Get("token") != "tkinv_abc" || u.Query().Get("source") != "mail" {
+		t.Errorf("AcceptURL() = %q", got)
+	}
+}
+
+func TestNewMessage(t *testing.T) {
+	t.Parallel()
+
+	msg := NewMessage(MessageParams{
+		Email:       "alice@example.com",
+		ProjectName: "payments",
+		InvitedBy:   "octocat",
+		AcceptURL:   "https://admin.example.com/invitations/accept?token=tkinv_abc",
+		ExpiresAt:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if err := msg.Validate(); err != nil {
+		t.Fatalf("message is invalid: %v", err)
+	}
+	if len(msg.To) != 1 || msg.To[0] != "alice@example.com" {
+		t.Errorf("To = %v", msg.To)
+	}
+	for _, want := range []string{`"payments"`, "by octocat", "token=tkinv_abc", "Thu, 02 Jan 2025 03:04:05 UTC"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("Text does not contain %q:\n%s", want, msg.Text)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+const (
+	// CleanupJobKind is the kind of the job that deletes finished jobs.
+	CleanupJobKind = "jobs.cleanup"
+
+	DefaultRetention = 72 * time.Hour
+)
+
+// NewCleanupHandler returns the handler of CleanupJobKind, which deletes jobs that
+// finished longer than retention ago.
+func NewCleanupHandler(logger *slog.Logger, queries *admindb.Queries, retention time.Duration) Handler {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return func(ctx context.Context, job Job) error {
+		deleted, err := queries.DeleteFinishedJobsBefore(ctx, pgtype.Timestamptz{Time: time.Now().Add(-retention), Valid: true})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete finished jobs")
+		}
+		if deleted > 0 {
+			logger.InfoContext(ctx, "deleted finished jobs", slog.Int64("count", deleted))
+		}
+		return nil
+	}
+}
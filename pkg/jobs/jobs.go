@@ -0,0 +1,153 @@
+// Package jobs runs background work queued in the admin database.
+//
+// Jobs are rows of tacokumo_admin.jobs. Any replica running a Runner leases due jobs with
+// FOR UPDATE SKIP LOCKED, so a job runs on one replica at a time, and a job whose worker
+// died is picked up again once its lease expires. Because jobs are enqueued with the same
+// queries as the rest of the admin data, a job enqueued inside a transaction is only
+// visible to workers once the transaction commits.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+
+	DefaultMaxAttempts = 5
+)
+
+// ErrDuplicate is returned by Enqueue when a job with the same unique key already exists.
+var ErrDuplicate = errors.New("job with the same unique key already exists")
+
+// Job is a leased job passed to its handler.
+type Job struct {
+	ID      string
+	Kind    string
+	Payload json.RawMessage
+	// Attempt is the 1-based number of the current attempt.
+	Attempt     int
+	MaxAttempts int
+}
+
+// Decode unmarshals the payload of the job into v.
+func (j Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return errors.Wrapf(err, "failed to decode payload of job %s", j.ID)
+	}
+	return nil
+}
+
+// Handler runs a job. Returning an error retries the job with backoff until its
+// attempts are exhausted, unless the error is wrapped with Permanent.
+type Handler func(ctx context.Context, job Job) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying. The job fails immediately.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+type enqueueOptions struct {
+	runAt       time.Time
+	maxAttempts int
+	uniqueKey   string
+}
+
+type EnqueueOption func(*enqueueOptions)
+
+// RunAt delays the job until t.
+func RunAt(t time.Time) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.runAt = t
+	}
+}
+
+// MaxAttempts sets the number of attempts after which the job fails.
+func MaxAttempts(n int) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.maxAttempts = n
+	}
+}
+
+// UniqueKey makes Enqueue return ErrDuplicate if a job with the same key already exists.
+func UniqueKey(key string) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.uniqueKey = key
+	}
+}
+
+// Enqueue queues a job of the given kind. Pass queries bound to a transaction to enqueue
+// the job atomically with other changes.
+func Enqueue(ctx context.Context, q *admindb.Queries, kind string, payload any, opts ...EnqueueOption) (admindb.TacokumoAdminJob, error) {
+	o := enqueueOptions{
+		runAt:       time.Now(),
+		maxAttempts: DefaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if payload == nil {
+		payload = struct{}{}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return admindb.TacokumoAdminJob{}, errors.Wrapf(err, "failed to marshal payload of %s job", kind)
+	}
+
+	job, err := q.EnqueueJob(ctx, admindb.EnqueueJobParams{
+		Kind:        kind,
+		Payload:     data,
+		MaxAttempts: int32(max(o.maxAttempts, 1)),
+		RunAt:       pgtype.Timestamptz{Time: o.runAt, Valid: true},
+		UniqueKey:   pgtype.Text{String: o.uniqueKey, Valid: o.uniqueKey != ""},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return job, ErrDuplicate
+		}
+		return job, errors.Wrapf(err, "failed to enqueue %s job", kind)
+	}
+	return job, nil
+}
+
+// Backoff returns the delay before the next attempt after attempts failed attempts.
+// The delay doubles from base and is capped at max.
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	if attempts < 1 {
+		return base
+	}
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	return min(d, max)
+}
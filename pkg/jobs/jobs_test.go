@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	base := 30 * time.Second
+	max := 10 * time.Minute
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 30 * time.Second},
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 3, want: 2 * time.Minute},
+		{attempts: 5, want: 8 * time.Minute},
+		{attempts: 6, want: 10 * time.Minute},
+		{attempts: 100, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts, base, max); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("bad payload")
+	if IsPermanent(cause) {
+		t.Errorf("IsPermanent() = true for a plain error")
+	}
+	err := errors.Wrapf(Permanent(cause), "failed to run job")
+	if !IsPermanent(err) {
+		t.Errorf("IsPermanent() = false for a wrapped permanent error")
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("permanent error does not wrap its cause")
+	}
+	if Permanent(nil) != nil {
+		t.Errorf("Permanent(nil) != nil")
+	}
+}
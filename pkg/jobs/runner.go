@@ -0,0 +1,342 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/samber/lo"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+const (
+	defaultConcurrency   = 4
+	defaultPollInterval  = time.Second
+	defaultLeaseDuration = 5 * time.Minute
+	defaultBackoffBase   = 10 * time.Second
+	defaultBackoffMax    = time.Hour
+
+	// bookkeepingTimeout bounds recording the outcome of a job, which also happens during shutdown.
+	bookkeepingTimeout = 10 * time.Second
+	// maxErrorLength bounds the error message stored with a failed attempt.
+	maxErrorLength = 1024
+)
+
+// Config controls a Runner. Zero values fall back to defaults.
+type Config struct {
+	// WorkerID identifies the runner in the locked_by column. Defaults to the hostname and pid.
+	WorkerID string
+	// Concurrency is the maximum number of jobs run at the same time.
+	Concurrency int
+	// PollInterval is how often due jobs are claimed.
+	PollInterval time.Duration
+	// LeaseDuration is how long a claimed job is reserved. It is also the timeout of a single attempt,
+	// so that a job is never run twice at the same time.
+	LeaseDuration time.Duration
+	BackoffBase   time.Duration
+	BackoffMax    time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.WorkerID == "" {
+		hostname, _ := os.Hostname()
+		c.WorkerID = fmt.Sprintf("%s/%d", hostname, os.Getpid())
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = defaultConcurrency
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultPollInterval
+	}
+	if c.LeaseDuration <= 0 {
+		c.LeaseDuration = defaultLeaseDuration
+	}
+	if c.BackoffBase <= 0 {
+		c.BackoffBase = defaultBackoffBase
+	}
+	if c.BackoffMax <= 0 {
+		c.BackoffMax = defaultBackoffMax
+	}
+	return c
+}
+
+type scheduledJob struct {
+	name     string
+	schedule Schedule
+	kind     string
+	payload  any
+}
+
+// Runner claims due jobs and runs them with the registered handlers. It also enqueues
+// scheduled jobs. Handlers and schedules must be registered before Start.
+type Runner struct {
+	logger    *slog.Logger
+	queries   *admindb.Queries
+	cfg       Config
+	handlers  map[string]Handler
+	schedules []scheduledJob
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRunner(logger *slog.Logger, queries *admindb.Queries, cfg Config) *Runner {
+	return &Runner{
+		logger:   logger.With(slog.String("component", "job-runner")),
+		queries:  queries,
+		cfg:      cfg.withDefaults(),
+		handlers: map[string]Handler{},
+	}
+}
+
+// Register sets the handler of a job kind. Only jobs of registered kinds are claimed by this runner.
+func (r *Runner) Register(kind string, handler Handler) {
+	r.handlers[kind] = handler
+}
+
+// Schedule enqueues a job of the given kind at every occurrence of spec (see ParseSchedule).
+// Each occurrence is enqueued once across all replicas. Scheduled jobs are not retried;
+// the next occurrence runs them again.
+func (r *Runner) Schedule(name, spec, kind string, payload any) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(r.schedules, func(s scheduledJob) bool { return s.name == name }) {
+		return errors.Newf("schedule %s is already registered", name)
+	}
+	r.schedules = append(r.schedules, scheduledJob{
+		name:     name,
+		schedule: schedule,
+		kind:     kind,
+		payload:  payload,
+	})
+	return nil
+}
+
+// Start runs the runner in the background until ctx is cancelled or Stop is called.
+func (r *Runner) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	r.wg.Add(2)
+	go func() {
+		defer r.wg.Done()
+		r.poll(ctx)
+	}()
+	go func() {
+		defer r.wg.Done()
+		r.schedule(ctx)
+	}()
+	r.logger.InfoContext(ctx, "job runner started",
+		slog.String("worker_id", r.cfg.WorkerID),
+		slog.Any("kinds", lo.Keys(r.handlers)),
+	)
+}
+
+// Stop cancels running jobs and waits for them to record their outcome until ctx is done.
+// Jobs that are still running afterwards are picked up by another runner once their lease expires.
+func (r *Runner) Stop(ctx context.Context) {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		r.logger.InfoContext(ctx, "job runner stopped")
+	case <-ctx.Done():
+		r.logger.WarnContext(ctx, "job runner did not stop in time", slog.String("error", ctx.Err().Error()))
+	}
+}
+
+func (r *Runner) poll(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	slots := make(chan struct{}, r.cfg.Concurrency)
+	for {
+		if err := r.claimAndRun(ctx, slots); err != nil && ctx.Err() == nil {
+			r.logger.ErrorContext(ctx, "failed to claim jobs", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimAndRun claims as many due jobs as there are free slots and runs each in its own goroutine.
+func (r *Runner) claimAndRun(ctx context.Context, slots chan struct{}) error {
+	free := cap(slots) - len(slots)
+	if free == 0 || len(r.handlers) == 0 {
+		return nil
+	}
+
+	rows, err := r.queries.ClaimJobs(ctx, admindb.ClaimJobsParams{
+		Worker:    pgtype.Text{String: r.cfg.WorkerID, Valid: true},
+		Lease:     pgtype.Interval{Microseconds: r.cfg.LeaseDuration.Microseconds(), Valid: true},
+		Kinds:     lo.Keys(r.handlers),
+		BatchSize: int32(free),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to claim jobs")
+	}
+
+	for _, row := range rows {
+		// Only this goroutine fills the slots, so claiming at most the free slots never blocks here.
+		slots <- struct{}{}
+		r.wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				r.wg.Done()
+			}()
+			r.run(ctx, row)
+		}()
+	}
+	return nil
+}
+
+func (r *Runner) run(ctx context.Context, row admindb.TacokumoAdminJob) {
+	job := Job{
+		ID:          row.DisplayID.String(),
+		Kind:        row.Kind,
+		Payload:     row.Payload,
+		Attempt:     int(row.Attempts),
+		MaxAttempts: int(row.MaxAttempts),
+	}
+	logger := r.logger.With(
+		slog.String("job_id", job.ID),
+		slog.String("kind", job.Kind),
+		slog.Int("attempt", job.Attempt),
+	)
+
+	jobCtx, cancel := context.WithTimeout(ctx, r.cfg.LeaseDuration)
+	err := r.invoke(jobCtx, r.handlers[row.Kind], job)
+	cancel()
+
+	// The outcome is recorded even if the runner is stopping.
+	bookCtx, bookCancel := context.WithTimeout(context.WithoutCancel(ctx), bookkeepingTimeout)
+	defer bookCancel()
+	worker := pgtype.Text{String: r.cfg.WorkerID, Valid: true}
+
+	if err == nil {
+		if err := r.queries.CompleteJob(bookCtx, admindb.CompleteJobParams{ID: row.ID, LockedBy: worker}); err != nil {
+			logger.ErrorContext(bookCtx, "failed to mark job as succeeded", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	message := err.Error()
+	if len(message) > maxErrorLength {
+		message = message[:maxErrorLength]
+	}
+	lastError := pgtype.Text{String: message, Valid: true}
+
+	switch {
+	case ctx.Err() != nil:
+		// Interrupted by shutdown: hand the job back so that another runner picks it up right away.
+		logger.WarnContext(bookCtx, "job interrupted by shutdown", slog.String("error", message))
+		err = r.queries.RetryJob(bookCtx, admindb.RetryJobParams{
+			ID:        row.ID,
+			LockedBy:  worker,
+			LastError: lastError,
+			RunAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+	case IsPermanent(err) || job.Attempt >= job.MaxAttempts:
+		logger.ErrorContext(bookCtx, "job failed", slog.String("error", message))
+		err = r.queries.FailJob(bookCtx, admindb.FailJobParams{
+			ID:        row.ID,
+			LockedBy:  worker,
+			LastError: lastError,
+		})
+	default:
+		next := time.Now().Add(Backoff(job.Attempt, r.cfg.BackoffBase, r.cfg.BackoffMax))
+		logger.WarnContext(bookCtx, "job failed, retrying", slog.String("error", message), slog.Time("run_at", next))
+		err = r.queries.RetryJob(bookCtx, admindb.RetryJobParams{
+			ID:        row.ID,
+			LockedBy:  worker,
+			LastError: lastError,
+			RunAt:     pgtype.Timestamptz{Time: next, Valid: true},
+		})
+	}
+	if err != nil {
+		logger.ErrorContext(bookCtx, "failed to record job failure", slog.String("error", err.Error()))
+	}
+}
+
+// invoke runs the handler and turns a panic into an error so that one job cannot take down the server.
+func (r *Runner) invoke(ctx context.Context, handler Handler, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = errors.Newf("job panicked: %v", p)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// schedule enqueues scheduled jobs at their next occurrence until ctx is cancelled.
+func (r *Runner) schedule(ctx context.Context) {
+	if len(r.schedules) == 0 {
+		return
+	}
+
+	now := time.Now()
+	next := make([]time.Time, len(r.schedules))
+	for i, s := range r.schedules {
+		next[i] = s.schedule.Next(now)
+	}
+
+	for {
+		earliest := time.Time{}
+		for _, t := range next {
+			if !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+				earliest = t
+			}
+		}
+		if earliest.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(earliest))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for i, s := range r.schedules {
+			if next[i].IsZero() || next[i].After(earliest) {
+				continue
+			}
+			r.enqueueScheduled(ctx, s, next[i])
+			next[i] = s.schedule.Next(next[i])
+		}
+	}
+}
+
+func (r *Runner) enqueueScheduled(ctx context.Context, s scheduledJob, at time.Time) {
+	// The unique key makes every replica enqueue the same occurrence only once.
+	key := fmt.Sprintf("schedule:%s:%d", s.name, at.Unix())
+	_, err := Enqueue(ctx, r.queries, s.kind, s.payload, RunAt(at), MaxAttempts(1), UniqueKey(key))
+	if err != nil && !errors.Is(err, ErrDuplicate) && ctx.Err() == nil {
+		r.logger.ErrorContext(ctx, "failed to enqueue scheduled job",
+			slog.String("schedule", s.name),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Schedule computes the occurrences of a recurring job.
+type Schedule interface {
+	// Next returns the first occurrence strictly after t, or the zero time if there is none.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses a schedule specification:
+//
+//   - "@every <duration>", e.g. "@every 5m". Occurrences are aligned to multiples of the
+//     duration so that every replica computes the same times.
+//   - "@yearly", "@monthly", "@weekly", "@daily" and "@hourly".
+//   - A standard five field cron expression (minute, hour, day of month, month, day of week)
+//     supporting "*", lists, ranges and steps, e.g. "*/15 9-17 * * 1-5".
+//
+// Cron expressions are evaluated in UTC.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule %q", spec)
+		}
+		if d < time.Second {
+			return nil, errors.Newf("invalid schedule %q: interval must be at least 1s", spec)
+		}
+		return everySchedule{interval: d}, nil
+	}
+
+	switch spec {
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@hourly":
+		spec = "0 * * * *"
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Newf("invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+	s := &cronSchedule{}
+	var err error
+	if s.minute, _, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, errors.Wrapf(err, "invalid minute in schedule %q", spec)
+	}
+	if s.hour, _, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, errors.Wrapf(err, "invalid hour in schedule %q", spec)
+	}
+	var domAny, dowAny bool
+	if s.dom, domAny, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, errors.Wrapf(err, "invalid day of month in schedule %q", spec)
+	}
+	if s.month, _, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, errors.Wrapf(err, "invalid month in schedule %q", spec)
+	}
+	if s.dow, dowAny, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, errors.Wrapf(err, "invalid day of week in schedule %q", spec)
+	}
+	// 7 is an alias of Sunday.
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	// As in cron, a day matches either field when both are restricted.
+	s.dayOr = !domAny && !dowAny
+	return s, nil
+}
+
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(s.interval).Add(s.interval)
+}
+
+// cronSchedule holds the allowed values of each field as a bit set.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	dayOr                         bool
+}
+
+// maxCronSearch bounds the search for expressions that never match, such as "0 0 30 2 *".
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronSearch)
+
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom := has(s.dom, t.Day())
+	dow := has(s.dow, int(t.Weekday()))
+	if s.dayOr {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// parseCronField parses a comma separated list of "*", "n", "a-b" with an optional "/step".
+// It also reports whether the field is unrestricted, i.e. starts with "*".
+func parseCronField(field string, low, high int) (uint64, bool, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, false, errors.Newf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		var from, to int
+		switch {
+		case rangePart == "*":
+			from, to = low, high
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err error
+			if from, err = parseCronValue(a, low, high); err != nil {
+				return 0, false, err
+			}
+			if to, err = parseCronValue(b, low, high); err != nil {
+				return 0, false, err
+			}
+			if from > to {
+				return 0, false, errors.Newf("invalid range %q", rangePart)
+			}
+		default:
+			v, err := parseCronValue(rangePart, low, high)
+			if err != nil {
+				return 0, false, err
+			}
+			from, to = v, v
+			// "n/step" runs from n to the end of the range.
+			if hasStep {
+				to = high
+			}
+		}
+		for v := from; v <= to; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, strings.HasPrefix(field, "*"), nil
+}
+
+func parseCronValue(s string, low, high int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Newf("invalid value %q", s)
+	}
+	if v < low || v > high {
+		return 0, errors.Newf("value %d out of range %d-%d", v, low, high)
+	}
+	return v, nil
+}
@@ -0,0 +1,144 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2025, 1, 31, 10, 7, 30, 0, time.UTC) // Friday
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "every",
+			spec: "@every 5m",
+			from: base,
+			want: []time.Time{
+				time.Date(2025, 1, 31, 10, 10, 0, 0, time.UTC),
+				time.Date(2025, 1, 31, 10, 15, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "hourly",
+			spec: "@hourly",
+			from: base,
+			want: []time.Time{
+				time.Date(2025, 1, 31, 11, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "daily",
+			spec: "@daily",
+			from: base,
+			want: []time.Time{
+				time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "steps and ranges",
+			spec: "*/20 9-10 * * *",
+			from: base,
+			want: []time.Time{
+				time.Date(2025, 1, 31, 10, 20, 0, 0, time.UTC),
+				time.Date(2025, 1, 31, 10, 40, 0, 0, time.UTC),
+				time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "weekdays",
+			spec: "30 2 * * 1-5",
+			from: base,
+			want: []time.Time{
+				time.Date(2025, 2, 3, 2, 30, 0, 0, time.UTC),
+				time.Date(2025, 2, 4, 2, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "sunday as 7",
+			spec: "0 0 * * 7",
+			from: base,
+			want: []time.Time{
+				time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "day of month or day of week",
+			spec: "0 0 15 * 0",
+			from: base,
+			want: []time.Time{
+				time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 2, 9, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "lists and month",
+			spec: "0 12 1,31 3 *",
+			from: base,
+			want: []time.Time{
+				time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "never",
+			spec: "0 0 30 2 *",
+			from: base,
+			want: []time.Time{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) returned error: %v", tt.spec, err)
+			}
+			from := tt.from
+			for i, want := range tt.want {
+				got := schedule.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("occurrence %d of %q = %v, want %v", i, tt.spec, got, want)
+				}
+				from = got
+			}
+		})
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range []string{
+		"",
+		"@every",
+		"@every 10ms",
+		"@every five",
+		"@sometimes",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) returned no error", spec)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package labels implements the labels and annotations of projects, roles and user groups.
+//
+// Labels follow the Kubernetes rules: keys are an optional DNS subdomain prefix and a short
+// name, and values are short identifiers. They are indexed and can be queried with a label
+// selector. Annotations are free-form metadata that only need valid keys.
+package labels
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// MaxKeyNameLength is the maximum length of the name part of a key.
+	MaxKeyNameLength = 63
+	// MaxKeyPrefixLength is the maximum length of the optional prefix of a key.
+	MaxKeyPrefixLength = 253
+	// MaxValueLength is the maximum length of a label value.
+	MaxValueLength = 63
+	// MaxAnnotationsSize is the maximum total size of the keys and values of annotations.
+	MaxAnnotationsSize = 256 * 1024
+
+	maxDNSLabelLength = 63
+)
+
+var (
+	nameRegexp     = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	dnsLabelRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// ValidateKey validates a label or annotation key of the form "[prefix/]name".
+func ValidateKey(key string) error {
+	prefix, name, hasPrefix := strings.Cut(key, "/")
+	if !hasPrefix {
+		name, prefix = prefix, ""
+	} else if err := validatePrefix(prefix); err != nil {
+		return errors.Wrapf(err, "invalid key %q", key)
+	}
+	if name == "" {
+		return errors.Newf("invalid key %q: name must not be empty", key)
+	}
+	if len(name) > MaxKeyNameLength {
+		return errors.Newf("invalid key %q: name must be at most %d characters", key, MaxKeyNameLength)
+	}
+	if !nameRegexp.MatchString(name) {
+		return errors.Newf("invalid key %q: name must consist of alphanumerics, '-', '_' or '.' and start and end with an alphanumeric", key)
+	}
+	return nil
+}
+
+func validatePrefix(prefix string) error {
+	if prefix == "" {
+		return errors.New("prefix must not be empty")
+	}
+	if len(prefix) > MaxKeyPrefixLength {
+		return errors.Newf("prefix must be at most %d characters", MaxKeyPrefixLength)
+	}
+	for _, label := range strings.Split(prefix, ".") {
+		if len(label) > maxDNSLabelLength || !dnsLabelRegexp.MatchString(label) {
+			return errors.New("prefix must be a lowercase DNS subdomain")
+		}
+	}
+	return nil
+}
+
+// ValidateValue validates a label value. Empty values are allowed.
+func ValidateValue(value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > MaxValueLength {
+		return errors.Newf("invalid value %q: must be at most %d characters", value, MaxValueLength)
+	}
+	if !nameRegexp.MatchString(value) {
+		return errors.Newf("invalid value %q: must consist of alphanumerics, '-', '_' or '.' and start and end with an alphanumeric", value)
+	}
+	return nil
+}
+
+// Validate validates the keys and values of labels.
+func Validate(labels map[string]string) error {
+	for k, v := range labels {
+		if err := ValidateKey(k); err != nil {
+			return err
+		}
+		if err := ValidateValue(v); err != nil {
+			return errors.Wrapf(err, "label %q", k)
+		}
+	}
+	return nil
+}
+
+// ValidateAnnotations validates the keys and the total size of annotations.
+// Annotation values are not restricted otherwise.
+func ValidateAnnotations(annotations map[string]string) error {
+	size := 0
+	for k, v := range annotations {
+		if err := ValidateKey(k); err != nil {
+			return err
+		}
+		size += len(k) + len(v)
+	}
+	if size > MaxAnnotationsSize {
+		return errors.Newf("annotations must be at most %d bytes in total", MaxAnnotationsSize)
+	}
+	return nil
+}
+
+// Merge applies a JSON Merge Patch of labels or annotations to current.
+// A nil value removes the key. current is not modified.
+func Merge(current map[string]string, patch map[string]*string) map[string]string {
+	merged := make(map[string]string, len(current)+len(patch))
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = *v
+	}
+	return merged
+}
@@ -0,0 +1,92 @@
+package labels
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: "env"},
+		{key: "cost-center"},
+		{key: "app.kubernetes.io/name"},
+		{key: "example.com/team_1"},
+		{key: "", wantErr: true},
+		{key: "/env", wantErr: true},
+		{key: "example.com/", wantErr: true},
+		{key: "Example.com/env", wantErr: true},
+		{key: "a/b/c", wantErr: true},
+		{key: "-env", wantErr: true},
+		{key: "env-", wantErr: true},
+		{key: "env var", wantErr: true},
+		{key: strings.Repeat("a", MaxKeyNameLength)},
+		{key: strings.Repeat("a", MaxKeyNameLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Parallel()
+
+			if err := ValidateKey(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKey(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{name: "valid", labels: map[string]string{"env": "prod", "team": "infra", "empty": ""}},
+		{name: "invalid value", labels: map[string]string{"env": "prod env"}, wantErr: true},
+		{name: "too long value", labels: map[string]string{"env": strings.Repeat("a", MaxValueLength+1)}, wantErr: true},
+		{name: "invalid key", labels: map[string]string{"env/": "prod"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := Validate(tt.labels); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAnnotations(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateAnnotations(map[string]string{"description": "free form text, with spaces!"}); err != nil {
+		t.Errorf("ValidateAnnotations() error = %v", err)
+	}
+	if err := ValidateAnnotations(map[string]string{"note": strings.Repeat("a", MaxAnnotationsSize)}); err == nil {
+		t.Error("ValidateAnnotations() accepted annotations over the size limit")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	prod := "prod"
+	current := map[string]string{"env": "dev", "team": "infra"}
+	got := Merge(current, map[string]*string{"env": &prod, "team": nil, "missing": nil})
+
+	if want := map[string]string{"env": "prod"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+	if current["env"] != "dev" || current["team"] != "infra" {
+		t.Errorf("Merge() modified current: %v", current)
+	}
+}
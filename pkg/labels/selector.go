@@ -0,0 +1,235 @@
+package labels
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Operators of a selector requirement.
+const (
+	OperatorEquals       = "="
+	OperatorNotEquals    = "!="
+	OperatorIn           = "in"
+	OperatorNotIn        = "notin"
+	OperatorExists       = "exists"
+	OperatorDoesNotExist = "!"
+)
+
+// Requirement is a single comma separated term of a label selector.
+type Requirement struct {
+	Key      string
+	Operator string
+	// Values is empty for OperatorExists and OperatorDoesNotExist and has exactly one element
+	// for OperatorEquals and OperatorNotEquals.
+	Values []string
+}
+
+// Selector is a conjunction of requirements, e.g. "env=prod,team!=infra,tier in (web,api),!legacy".
+// As in Kubernetes, != and notin also match resources that do not have the label at all.
+type Selector []Requirement
+
+// ParseSelector parses the labelSelector query parameter of a list request.
+// An empty string yields an empty selector, which matches everything.
+func ParseSelector(s string) (Selector, error) {
+	terms, err := splitTerms(s)
+	if err != nil {
+		return nil, err
+	}
+	var selector Selector
+	for _, term := range terms {
+		req, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, req)
+	}
+	return selector, nil
+}
+
+// splitTerms splits a selector on the commas that are not inside a value set.
+func splitTerms(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var (
+		terms []string
+		depth int
+		start int
+	)
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, errors.Newf("invalid label selector %q: nested parentheses", s)
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, errors.Newf("invalid label selector %q: unbalanced parentheses", s)
+			}
+		case ',':
+			if depth == 0 {
+				terms = append(terms, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, errors.Newf("invalid label selector %q: unbalanced parentheses", s)
+	}
+	return append(terms, s[start:]), nil
+}
+
+func parseRequirement(term string) (Requirement, error) {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return Requirement{}, errors.New("invalid label selector: empty requirement")
+	}
+
+	var req Requirement
+	switch {
+	case strings.HasPrefix(term, "!") && !strings.Contains(term, "="):
+		req = Requirement{Key: strings.TrimSpace(term[1:]), Operator: OperatorDoesNotExist}
+	case strings.HasSuffix(term, ")"):
+		head, set, ok := strings.Cut(strings.TrimSuffix(term, ")"), "(")
+		fields := strings.Fields(head)
+		if !ok || len(fields) != 2 || (fields[1] != OperatorIn && fields[1] != OperatorNotIn) {
+			return Requirement{}, errors.Newf("invalid label selector requirement %q: expected \"key in (values)\" or \"key notin (values)\"", term)
+		}
+		req = Requirement{Key: fields[0], Operator: fields[1]}
+		for _, v := range strings.Split(set, ",") {
+			req.Values = append(req.Values, strings.TrimSpace(v))
+		}
+	case strings.Contains(term, "!="):
+		key, value, _ := strings.Cut(term, "!=")
+		req = Requirement{Key: strings.TrimSpace(key), Operator: OperatorNotEquals, Values: []string{strings.TrimSpace(value)}}
+	case strings.Contains(term, "="):
+		key, value, _ := strings.Cut(term, "=")
+		value = strings.TrimPrefix(value, "=")
+		req = Requirement{Key: strings.TrimSpace(key), Operator: OperatorEquals, Values: []string{strings.TrimSpace(value)}}
+	default:
+		req = Requirement{Key: term, Operator: OperatorExists}
+	}
+
+	if err := ValidateKey(req.Key); err != nil {
+		return Requirement{}, errors.Wrapf(err, "invalid label selector requirement %q", term)
+	}
+	for _, v := range req.Values {
+		if err := ValidateValue(v); err != nil {
+			return Requirement{}, errors.Wrapf(err, "invalid label selector requirement %q", term)
+		}
+	}
+	return req, nil
+}
+
+// Matches reports whether labels satisfy every requirement of the selector.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, ok := labels[req.Key]
+		switch req.Operator {
+		case OperatorExists:
+			if !ok {
+				return false
+			}
+		case OperatorDoesNotExist:
+			if ok {
+				return false
+			}
+		case OperatorEquals, OperatorIn:
+			if !ok || !slices.Contains(req.Values, value) {
+				return false
+			}
+		case OperatorNotEquals, OperatorNotIn:
+			if ok && slices.Contains(req.Values, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String returns the canonical form of the selector.
+func (s Selector) String() string {
+	terms := make([]string, 0, len(s))
+	for _, req := range s {
+		switch req.Operator {
+		case OperatorExists:
+			terms = append(terms, req.Key)
+		case OperatorDoesNotExist:
+			terms = append(terms, "!"+req.Key)
+		case OperatorEquals, OperatorNotEquals:
+			terms = append(terms, req.Key+req.Operator+req.Values[0])
+		default:
+			terms = append(terms, req.Key+" "+req.Operator+" ("+strings.Join(req.Values, ",")+")")
+		}
+	}
+	return strings.Join(terms, ",")
+}
+
+// Filter is a selector in the form of the label_* arguments of the list queries.
+// Fields are nil when the selector has no requirement of that kind, which skips the check.
+type Filter struct {
+	// Equals is a JSON object of the = requirements, matched with the @> operator so that the
+	// GIN index on labels can be used.
+	Equals []byte
+	// Exists and NotExists are the keys that must or must not be present.
+	Exists    []string
+	NotExists []string
+	// In and NotIn are JSON arrays of {"key": ..., "values": [...]} objects.
+	// = and != requirements are included as single value sets.
+	In    []byte
+	NotIn []byte
+}
+
+type setRequirement struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+// Filter converts the selector to the arguments of the list queries.
+func (s Selector) Filter() (Filter, error) {
+	var (
+		f      Filter
+		equals = map[string]string{}
+		in     []setRequirement
+		notIn  []setRequirement
+	)
+	for _, req := range s {
+		switch req.Operator {
+		case OperatorExists:
+			f.Exists = append(f.Exists, req.Key)
+		case OperatorDoesNotExist:
+			f.NotExists = append(f.NotExists, req.Key)
+		case OperatorEquals:
+			// The value set keeps "k=a,k=b" correct; equals only narrows the index scan.
+			equals[req.Key] = req.Values[0]
+			in = append(in, setRequirement{Key: req.Key, Values: req.Values})
+		case OperatorIn:
+			in = append(in, setRequirement{Key: req.Key, Values: req.Values})
+		case OperatorNotEquals, OperatorNotIn:
+			notIn = append(notIn, setRequirement{Key: req.Key, Values: req.Values})
+		}
+	}
+
+	var err error
+	if len(equals) > 0 {
+		if f.Equals, err = json.Marshal(equals); err != nil {
+			return Filter{}, errors.Wrap(err, "failed to marshal label selector")
+		}
+	}
+	if len(in) > 0 {
+		if f.In, err = json.Marshal(in); err != nil {
+			return Filter{}, errors.Wrap(err, "failed to marshal label selector")
+		}
+	}
+	if len(notIn) > 0 {
+		if f.NotIn, err = json.Marshal(notIn); err != nil {
+			return Filter{}, errors.Wrap(err, "failed to marshal label selector")
+		}
+	}
+	return f, nil
+}
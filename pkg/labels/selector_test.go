@@ -0,0 +1,131 @@
+package labels
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		selector string
+		want     Selector
+		wantErr  bool
+	}{
+		{name: "empty", selector: " "},
+		{
+			name:     "equality",
+			selector: "env=prod,team!=infra,tier==web",
+			want: Selector{
+				{Key: "env", Operator: OperatorEquals, Values: []string{"prod"}},
+				{Key: "team", Operator: OperatorNotEquals, Values: []string{"infra"}},
+				{Key: "tier", Operator: OperatorEquals, Values: []string{"web"}},
+			},
+		},
+		{
+			name:     "sets",
+			selector: "env in (prod, staging), team notin (infra)",
+			want: Selector{
+				{Key: "env", Operator: OperatorIn, Values: []string{"prod", "staging"}},
+				{Key: "team", Operator: OperatorNotIn, Values: []string{"infra"}},
+			},
+		},
+		{
+			name:     "existence",
+			selector: "example.com/owner,!legacy",
+			want: Selector{
+				{Key: "example.com/owner", Operator: OperatorExists},
+				{Key: "legacy", Operator: OperatorDoesNotExist},
+			},
+		},
+		{name: "empty requirement", selector: "env=prod,", wantErr: true},
+		{name: "invalid key", selector: "-env=prod", wantErr: true},
+		{name: "invalid value", selector: "env=prod env", wantErr: true},
+		{name: "unknown set operator", selector: "env within (prod)", wantErr: true},
+		{name: "unbalanced parentheses", selector: "env in (prod", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseSelector(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSelector(%q) error = %v, wantErr %v", tt.selector, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSelector(%q) = %#v, want %#v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	t.Parallel()
+
+	labels := map[string]string{"env": "prod", "team": "web"}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: true},
+		{selector: "env=prod", want: true},
+		{selector: "env=dev", want: false},
+		{selector: "env=prod,env=dev", want: false},
+		{selector: "team!=infra", want: true},
+		{selector: "owner!=alice", want: true},
+		{selector: "env in (dev,prod)", want: true},
+		{selector: "owner in (alice)", want: false},
+		{selector: "team notin (web)", want: false},
+		{selector: "env", want: true},
+		{selector: "!env", want: false},
+		{selector: "!owner", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			t.Parallel()
+
+			selector, err := ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseSelector(%q) error = %v", tt.selector, err)
+			}
+			if got := selector.Matches(labels); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorFilter(t *testing.T) {
+	t.Parallel()
+
+	selector, err := ParseSelector("env=prod,team!=infra,tier in (web,api),owner,!legacy")
+	if err != nil {
+		t.Fatalf("ParseSelector() error = %v", err)
+	}
+	got, err := selector.Filter()
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	want := Filter{
+		Equals:    []byte(`{"env":"prod"}`),
+		Exists:    []string{"owner"},
+		NotExists: []string{"legacy"},
+		In:        []byte(`[{"key":"env","values":["prod"]},{"key":"tier","values":["web","api"]}]`),
+		NotIn:     []byte(`[{"key":"team","values":["infra"]}]`),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter() = %+v, want %+v", got, want)
+	}
+
+	empty, err := Selector(nil).Filter()
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	if !reflect.DeepEqual(empty, Filter{}) {
+		t.Errorf("Filter() of an empty selector = %+v, want all nil", empty)
+	}
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// FileSender writes every message to an .eml file in a directory, which can be opened
+// with a mail client during local development.
+type FileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir, from string) *FileSender {
+	return &FileSender{dir: dir, from: from}
+}
+
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	data, err := Build(s.from, msg, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return errors.Wrapf(err, "failed to create mail directory %s", s.dir)
+	}
+
+	// The random suffix keeps messages sent in the same nanosecond apart.
+	suffix, err := newMessageID("")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), suffix[1:9])
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return errors.Wrapf(err, "failed to write mail to %s", path)
+	}
+	return nil
+}
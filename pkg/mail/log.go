@@ -0,0 +1,28 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// LogSender writes messages to the log instead of sending them.
+type LogSender struct {
+	logger *slog.Logger
+}
+
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "mail",
+		slog.String("to", strings.Join(msg.To, ", ")),
+		slog.String("subject", msg.Subject),
+		slog.String("text", msg.Text),
+	)
+	return nil
+}
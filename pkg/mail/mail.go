@@ -0,0 +1,108 @@
+// Package mail sends notification emails such as invitations.
+//
+// Senders are pluggable: SMTPSender delivers through an SMTP relay, while LogSender and
+// FileSender are meant for local development and write messages to the log or to .eml files.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+}
+
+// Sender delivers messages.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Validate checks the recipients and rejects header values that could inject headers.
+func (m Message) Validate() error {
+	if len(m.To) == 0 {
+		return errors.New("message has no recipients")
+	}
+	for _, to := range m.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return errors.Wrapf(err, "invalid recipient %q", to)
+		}
+	}
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return errors.New("subject must not contain line breaks")
+	}
+	return nil
+}
+
+// Build renders the message in RFC 5322 format with a quoted-printable UTF-8 body.
+func Build(from string, msg Message, now time.Time) ([]byte, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid sender %q", from)
+	}
+
+	messageID, err := newMessageID(fromAddr.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", fromAddr.String())
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID)
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(strings.ReplaceAll(msg.Text, "\n", "\r\n"))); err != nil {
+		return nil, errors.Wrapf(err, "failed to encode message body")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrapf(err, "failed to encode message body")
+	}
+	return buf.Bytes(), nil
+}
+
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate random bytes")
+	}
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok {
+		domain = d
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain), nil
+}
+
+// parseAddress returns the bare address of "Name <addr>" or "addr".
+func parseAddress(s string) (string, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid address %q", s)
+	}
+	return addr.Address, nil
+}
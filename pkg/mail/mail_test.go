@@ -0,0 +1,233 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	msg := Message{
+		To:      []string{"alice@example.com"},
+		Subject: "tacokumo への招待",
+		Text:    "Hello,\nplease accept = here.\n",
+	}
+	data, err := Build("TacoKumo <noreply@example.com>", msg, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("failed to parse built message: %v", err)
+	}
+	if got := parsed.Header.Get("To"); got != "alice@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if got := parsed.Header.Get("From"); got != `"TacoKumo" <noreply@example.com>` {
+		t.Errorf("From = %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q, %v, want %q", subject, err, msg.Subject)
+	}
+	if got := parsed.Header.Get("Message-ID"); !strings.HasSuffix(got, "@example.com>") {
+		t.Errorf("Message-ID = %q", got)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if got := string(body); got != "Hello,\r\nplease accept = here.\r\n" {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestMessageValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		msg     Message
+		wantErr bool
+	}{
+		{name: "valid", msg: Message{To: []string{"a@example.com"}, Subject: "hi"}},
+		{name: "no recipients", msg: Message{Subject: "hi"}, wantErr: true},
+		{name: "invalid recipient", msg: Message{To: []string{"not an address"}}, wantErr: true},
+		{name: "header injection in recipient", msg: Message{To: []string{"a@example.com\r\nBcc: b@example.com"}}, wantErr: true},
+		{name: "header injection in subject", msg: Message{To: []string{"a@example.com"}, Subject: "hi\r\nBcc: b@example.com"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.msg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileSender(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender := NewFileSender(dir, "noreply@example.com")
+	msg := Message{To: []string{"alice@example.com"}, Subject: "hi", Text: "hello"}
+	for range 2 {
+		if err := sender.Send(context.Background(), msg); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read mail dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d files, want 2", len(entries))
+	}
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatalf("failed to stat mail file: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() {
+		_ = ln.Close()
+	}()
+
+	received := make(chan []string, 1)
+	go serveSMTP(t, ln, received)
+
+	addr := ln.Addr().(*net.TCPAddr)
+	sender, err := NewSMTPSender(SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    addr.Port,
+		From:    "noreply@example.com",
+		TLSMode: TLSModeNone,
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSMTPSender() error = %v", err)
+	}
+	msg := Message{To: []string{"Alice <alice@example.com>"}, Subject: "hi", Text: "hello"}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	commands := <-received
+	want := []string{"MAIL FROM:<noreply@example.com>", "RCPT TO:<alice@example.com>", "DATA"}
+	for _, w := range want {
+		found := false
+		for _, c := range commands {
+			if strings.HasPrefix(c, w) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("command %q not received in %v", w, commands)
+		}
+	}
+}
+
+func TestNewSMTPSenderRejectsStartTLSWithoutSupport(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() {
+		_ = ln.Close()
+	}()
+	go serveSMTP(t, ln, make(chan []string, 1))
+
+	sender, err := NewSMTPSender(SMTPConfig{
+		Host: "127.0.0.1",
+		Port: ln.Addr().(*net.TCPAddr).Port,
+		From: "noreply@example.com",
+	})
+	if err != nil {
+		t.Fatalf("NewSMTPSender() error = %v", err)
+	}
+	err = sender.Send(context.Background(), Message{To: []string{"alice@example.com"}})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("Send() error = %v, want STARTTLS error", err)
+	}
+
+	if _, err := NewSMTPSender(SMTPConfig{Host: "localhost", Port: 25, TLSMode: "ssl"}); err == nil {
+		t.Errorf("NewSMTPSender() accepted an invalid tls mode")
+	}
+}
+
+// serveSMTP accepts a single connection and speaks just enough SMTP for net/smtp.
+func serveSMTP(t *testing.T, ln net.Listener, received chan<- []string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	var commands []string
+	defer func() {
+		received <- commands
+	}()
+	r := bufio.NewReader(conn)
+	reply := func(code int, text string) {
+		_, _ = io.WriteString(conn, strconv.Itoa(code)+" "+text+"\r\n")
+	}
+	reply(220, "localhost ESMTP test")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		commands = append(commands, line)
+		switch {
+		case strings.HasPrefix(line, "EHLO"):
+			_, _ = io.WriteString(conn, "250-localhost\r\n250 8BITMIME\r\n")
+		case line == "DATA":
+			reply(354, "go ahead")
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+			}
+			reply(250, "queued")
+		case line == "QUIT":
+			reply(221, "bye")
+			return
+		default:
+			reply(250, "ok")
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// TLS modes of SMTPConfig.
+const (
+	// TLSModeStartTLS upgrades the connection with STARTTLS and fails if the server does not support it.
+	TLSModeStartTLS = "starttls"
+	// TLSModeImplicit connects with TLS from the start, usually on port 465.
+	TLSModeImplicit = "tls"
+	// TLSModeNone sends in plain text. Only use it with a relay on a trusted network.
+	TLSModeNone = "none"
+
+	defaultSMTPTimeout = 30 * time.Second
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// TLSMode is one of TLSModeStartTLS (default), TLSModeImplicit or TLSModeNone.
+	TLSMode string
+	Timeout time.Duration
+}
+
+// SMTPSender sends messages through an SMTP server.
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	if cfg.Host == "" || cfg.Port == 0 {
+		return nil, errors.New("smtp host and port are required")
+	}
+	if cfg.TLSMode == "" {
+		cfg.TLSMode = TLSModeStartTLS
+	}
+	switch cfg.TLSMode {
+	case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+	default:
+		return nil, errors.Newf("invalid smtp tls mode %q", cfg.TLSMode)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultSMTPTimeout
+	}
+	return &SMTPSender{cfg: cfg}, nil
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	data, err := Build(s.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > s.cfg.Timeout {
+		deadline = time.Now().Add(s.cfg.Timeout)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to smtp server %s", addr)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return errors.Wrapf(err, "failed to set smtp deadline")
+	}
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}
+	if s.cfg.TLSMode == TLSModeImplicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return errors.Wrapf(err, "failed to start smtp session")
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	if s.cfg.TLSMode == TLSModeStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.Newf("smtp server %s does not support STARTTLS", addr)
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return errors.Wrapf(err, "failed to start tls")
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return errors.Wrapf(err, "failed to authenticate to smtp server")
+		}
+	}
+
+	from, err := parseAddress(s.cfg.From)
+	if err != nil {
+		return err
+	}
+	if err := c.Mail(from); err != nil {
+		return errors.Wrapf(err, "smtp MAIL FROM failed")
+	}
+	for _, to := range msg.To {
+		rcpt, err := parseAddress(to)
+		if err != nil {
+			return err
+		}
+		if err := c.Rcpt(rcpt); err != nil {
+			return errors.Wrapf(err, "smtp RCPT TO %s failed", rcpt)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrapf(err, "smtp DATA failed")
+	}
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrapf(err, "failed to write message")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrapf(err, "failed to send message")
+	}
+	return c.Quit()
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/auth/session"
+)
+
+// CertificateAuthenticator authenticates requests made with a verified TLS client certificate.
+type CertificateAuthenticator interface {
+	// Authenticate returns a session for the certificate, or session.ErrSessionNotFound if the
+	// certificate does not identify a principal.
+	Authenticate(ctx context.Context, cert *x509.Certificate) (*session.Session, error)
+}
+
+// WithCertificateAuthenticator authenticates requests with a verified client certificate before
+// bearer tokens and session cookies are looked at. Requests whose certificate identifies no
+// principal fall back to them.
+func WithCertificateAuthenticator(a CertificateAuthenticator) SessionOption {
+	return func(o *sessionOptions) {
+		o.certificateAuthenticator = a
+	}
+}
+
+// VerifiedClientCertificate returns the client certificate of r if the TLS listener verified it,
+// or nil.
+func VerifiedClientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+// RequireClientCertificate rejects requests under the path prefixes that were not made with a
+// verified client certificate, such as internal service-to-service endpoints.
+func RequireClientCertificate(logger *slog.Logger, prefixes []string) echo.MiddlewareFunc {
+	trimmed := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if prefix = strings.TrimSuffix(prefix, "/"); prefix != "" {
+			trimmed = append(trimmed, prefix)
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !hasPathPrefix(c.Request().URL.Path, trimmed) {
+				return next(c)
+			}
+			if VerifiedClientCertificate(c.Request()) == nil {
+				logger.DebugContext(c.Request().Context(), "client certificate required", slog.String("path", c.Request().URL.Path))
+				return echo.NewHTTPError(http.StatusUnauthorized, "client certificate required")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/auth/session"
+)
+
+// mockCertificateAuthenticator authenticates certificates by their common name.
+type mockCertificateAuthenticator struct {
+	sessions map[string]*session.Session
+	err      error
+}
+
+func (m *mockCertificateAuthenticator) Authenticate(_ context.Context, cert *x509.Certificate) (*session.Session, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	sess, ok := m.sessions[cert.Subject.CommonName]
+	if !ok {
+		return nil, session.ErrSessionNotFound
+	}
+	return sess, nil
+}
+
+func withClientCertificate(req *http.Request, commonName string) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+}
+
+func TestRequireClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	mw := RequireClientCertificate(slog.Default(), []string{"/v1alpha1/internal/"})
+
+	tests := []struct {
+		name     string
+		path     string
+		withCert bool
+		wantCode int
+	}{
+		{"required path with certificate", "/v1alpha1/internal/sync", true, http.StatusOK},
+		{"required path without certificate", "/v1alpha1/internal/sync", false, http.StatusUnauthorized},
+		{"required prefix itself", "/v1alpha1/internal", false, http.StatusUnauthorized},
+		{"other path without certificate", "/v1alpha1/projects", false, http.StatusOK},
+		{"similar prefix without certificate", "/v1alpha1/internals", false, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.withCert {
+				withClientCertificate(req, "deployer")
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			err := mw(func(c echo.Context) error {
+				return c.String(http.StatusOK, "OK")
+			})(c)
+
+			code := http.StatusOK
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				code = httpErr.Code
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("status = %d, want %d", code, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("unverified certificates do not count", func(t *testing.T) {
+		t.Parallel()
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/v1alpha1/internal/sync", nil)
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{}}}
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		if err := mw(func(echo.Context) error { return nil })(c); err == nil {
+			t.Error("RequireClientCertificate() should reject certificates that were not verified")
+		}
+	})
+}
+
+func TestSessionMiddlewareWithCertificateAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.Default()
+	deployer := &session.Session{
+		ID:               "clientcert:deployer",
+		UserID:           "serviceaccount:deployer",
+		ServiceAccountID: "deployer",
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name        string
+		commonName  string
+		bearer      string
+		authErr     error
+		wantCode    int
+		wantSession string
+	}{
+		{"certificate identifies a service account", "deployer", "", nil, http.StatusOK, "clientcert:deployer"},
+		{"certificate wins over bearer token", "deployer", "user-session", nil, http.StatusOK, "clientcert:deployer"},
+		{"unknown certificate falls back to bearer token", "unknown", "user-session", nil, http.StatusOK, "user-session"},
+		{"unknown certificate without token", "unknown", "", nil, http.StatusUnauthorized, ""},
+		{"authenticator error", "deployer", "user-session", errors.New("db down"), http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := NewMockSessionStore()
+			_ = store.Create(context.Background(), &session.Session{ID: "user-session", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)})
+			authenticator := &mockCertificateAuthenticator{sessions: map[string]*session.Session{"deployer": deployer}, err: tt.authErr}
+			mw := SessionMiddleware(logger, store, WithCertificateAuthenticator(authenticator))
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/v1alpha1/projects", nil)
+			withClientCertificate(req, tt.commonName)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			var got *session.Session
+			err := mw(func(c echo.Context) error {
+				got = GetCurrentSession(c.Request().Context())
+				return nil
+			})(c)
+
+			code := http.StatusOK
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				code = httpErr.Code
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantSession != "" && (got == nil || got.ID != tt.wantSession) {
+				t.Errorf("session = %+v, want %s", got, tt.wantSession)
+			}
+		})
+	}
+}
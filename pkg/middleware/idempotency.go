@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/idempotency"
+)
+
+// replayedHeaders are the response headers that are stored and replayed with the body.
+var replayedHeaders = []string{
+	echo.HeaderContentType,
+	echo.HeaderLocation,
+	"ETag",
+}
+
+// Idempotency makes POST requests that carry an Idempotency-Key header safe to retry.
+// The first response for a key is stored for ttl and replayed for retries with the same
+// request; reusing a key for a different request is rejected with 422.
+// Keys are scoped per principal, so it must run after SessionMiddleware.
+func Idempotency(
+	logger *slog.Logger,
+	store idempotency.Store,
+	ttl time.Duration,
+) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := req.Context()
+
+			key := req.Header.Get(idempotency.HeaderKey)
+			if req.Method != http.MethodPost || key == "" {
+				return next(c)
+			}
+			if len(key) > idempotency.MaxKeyLength {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "idempotency key is too long"})
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "failed to read request body"})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := principalKey(c) + ":" + key
+			fingerprint := idempotency.Fingerprint(req.Method, req.URL.Path, body)
+
+			existing, reserved, err := store.Reserve(ctx, storeKey, fingerprint, ttl)
+			if err != nil && !errors.Is(err, idempotency.ErrRecordNotFound) {
+				logger.ErrorContext(ctx, "failed to reserve idempotency key", slog.String("error", err.Error()))
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal error"})
+			}
+			if !reserved {
+				// A different request is rejected even while the first one is in progress.
+				if existing != nil && existing.Fingerprint != fingerprint {
+					return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "idempotency key was already used for a different request"})
+				}
+				if existing == nil || existing.State == idempotency.StateInProgress {
+					return c.JSON(http.StatusConflict, map[string]string{"error": "a request with this idempotency key is in progress"})
+				}
+				return replayResponse(c, existing)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			// Responses are only remembered when the handler wrote them itself and they are not
+			// server errors; otherwise the key is released so that the client can retry.
+			err = next(c)
+			status := c.Response().Status
+			if err != nil || status >= http.StatusInternalServerError || !c.Response().Committed {
+				if releaseErr := store.Release(ctx, storeKey); releaseErr != nil {
+					logger.WarnContext(ctx, "failed to release idempotency key", slog.String("error", releaseErr.Error()))
+				}
+				return err
+			}
+
+			record := &idempotency.Record{
+				Fingerprint: fingerprint,
+				State:       idempotency.StateCompleted,
+				StatusCode:  status,
+				Header:      make(http.Header),
+				Body:        recorder.body.Bytes(),
+			}
+			for _, h := range replayedHeaders {
+				if v := c.Response().Header().Values(h); len(v) > 0 {
+					record.Header[h] = v
+				}
+			}
+			if completeErr := store.Complete(ctx, storeKey, record, ttl); completeErr != nil {
+				logger.WarnContext(ctx, "failed to store idempotent response", slog.String("error", completeErr.Error()))
+			}
+			return nil
+		}
+	}
+}
+
+func replayResponse(c echo.Context, record *idempotency.Record) error {
+	for k, values := range record.Header {
+		for _, v := range values {
+			c.Response().Header().Add(k, v)
+		}
+	}
+	c.Response().Header().Set(idempotency.HeaderReplayed, "true")
+	c.Response().WriteHeader(record.StatusCode)
+	_, err := c.Response().Write(record.Body)
+	return err
+}
+
+// responseRecorder passes writes through while keeping a copy of the body.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/auth/session"
+	"github.com/tacokumo/admin-api/pkg/idempotency"
+)
+
+// mockIdempotencyStore implements idempotency.Store for testing
+type mockIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*idempotency.Record
+}
+
+func newMockIdempotencyStore() *mockIdempotencyStore {
+	return &mockIdempotencyStore{records: make(map[string]*idempotency.Record)}
+}
+
+func (m *mockIdempotencyStore) Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (*idempotency.Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.records[key]; ok {
+		return existing, false, nil
+	}
+	m.records[key] = &idempotency.Record{Fingerprint: fingerprint, State: idempotency.StateInProgress}
+	return nil, true, nil
+}
+
+func (m *mockIdempotencyStore) Complete(ctx context.Context, key string, record *idempotency.Record, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[key] = record
+	return nil
+}
+
+func (m *mockIdempotencyStore) Release(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.Default()
+	sess := &session.Session{ID: "s1", UserID: "user-1"}
+
+	doRequest := func(mw echo.MiddlewareFunc, handler echo.HandlerFunc, method, key, body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(method, "/v1alpha1/projects", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(idempotency.HeaderKey, key)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), CurrentSessionKey, sess))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := mw(handler)(c); err != nil {
+			e.HTTPErrorHandler(err, c)
+		}
+		return rec
+	}
+
+	newCountingHandler := func(status int) (echo.HandlerFunc, *int) {
+		calls := 0
+		return func(c echo.Context) error {
+			calls++
+			return c.JSON(status, map[string]int{"call": calls})
+		}, &calls
+	}
+
+	t.Run("replays the stored response for a retried request", func(t *testing.T) {
+		t.Parallel()
+
+		mw := Idempotency(logger, newMockIdempotencyStore(), time.Hour)
+		handler, calls := newCountingHandler(http.StatusCreated)
+
+		first := doRequest(mw, handler, http.MethodPost, "key-1", `{"name":"a"}`)
+		second := doRequest(mw, handler, http.MethodPost, "key-1", `{"name":"a"}`)
+
+		if *calls != 1 {
+			t.Errorf("handler called %d times, want 1", *calls)
+		}
+		if second.Code != http.StatusCreated {
+			t.Errorf("replayed status = %d, want %d", second.Code, http.StatusCreated)
+		}
+		if second.Body.String() != first.Body.String() {
+			t.Errorf("replayed body = %q, want %q", second.Body.String(), first.Body.String())
+		}
+		if second.Header().Get(idempotency.HeaderReplayed) != "true" {
+			t.Errorf("%s header should be set on replay", idempotency.HeaderReplayed)
+		}
+	})
+
+	t.Run("rejects a reused key with a different body", func(t *testing.T) {
+		t.Parallel()
+
+		mw := Idempotency(logger, newMockIdempotencyStore(), time.Hour)
+		handler, calls := newCountingHandler(http.StatusCreated)
+
+		doRequest(mw, handler, http.MethodPost, "key-1", `{"name":"a"}`)
+		rec := doRequest(mw, handler, http.MethodPost, "key-1", `{"name":"b"}`)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+		}
+		if *calls != 1 {
+			t.Errorf("handler called %d times, want 1", *calls)
+		}
+	})
+
+	t.Run("returns 409 while the original request is in progress", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMockIdempotencyStore()
+		mw := Idempotency(logger, store, time.Hour)
+
+		var inner *httptest.ResponseRecorder
+		handler := func(c echo.Context) error {
+			inner = doRequest(mw, func(c echo.Context) error {
+				t.Error("Handler should not be called for a concurrent retry")
+				return nil
+			}, http.MethodPost, "key-1", `{"name":"a"}`)
+			return c.NoContent(http.StatusCreated)
+		}
+
+		doRequest(mw, handler, http.MethodPost, "key-1", `{"name":"a"}`)
+		if inner.Code != http.StatusConflict {
+			t.Errorf("status = %d, want %d", inner.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("rejects a different body with 422 while the original request is in progress", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMockIdempotencyStore()
+		mw := Idempotency(logger, store, time.Hour)
+
+		var inner *httptest.ResponseRecorder
+		handler := func(c echo.Context) error {
+			inner = doRequest(mw, func(c echo.Context) error {
+				t.Error("Handler should not be called for a different request")
+				return nil
+			}, http.MethodPost, "key-1", `{"name":"b"}`)
+			return c.NoContent(http.StatusCreated)
+		}
+
+		doRequest(mw, handler, http.MethodPost, "key-1", `{"name":"a"}`)
+		if inner.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want %d", inner.Code, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("does not remember server errors", func(t *testing.T) {
+		t.Parallel()
+
+		mw := Idempotency(logger, newMockIdempotencyStore(), time.Hour)
+		handler, calls := newCountingHandler(http.StatusInternalServerError)
+
+		doRequest(mw, handler, http.MethodPost, "key-1", `{"name":"a"}`)
+		doRequest(mw, handler, http.MethodPost, "key-1", `{"name":"a"}`)
+
+		if *calls != 2 {
+			t.Errorf("handler called %d times, want 2", *calls)
+		}
+	})
+
+	t.Run("ignores requests without key and non-POST requests", func(t *testing.T) {
+		t.Parallel()
+
+		mw := Idempotency(logger, newMockIdempotencyStore(), time.Hour)
+		handler, calls := newCountingHandler(http.StatusOK)
+
+		doRequest(mw, handler, http.MethodPost, "", `{"name":"a"}`)
+		doRequest(mw, handler, http.MethodPost, "", `{"name":"a"}`)
+		doRequest(mw, handler, http.MethodPut, "key-1", `{"name":"a"}`)
+		doRequest(mw, handler, http.MethodPut, "key-1", `{"name":"a"}`)
+
+		if *calls != 4 {
+			t.Errorf("handler called %d times, want 4", *calls)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/ratelimit"
+)
+
+// RateLimit limits requests per principal using the rule the policy selects for the path.
+// It must run after SessionMiddleware so that authenticated requests are keyed by user.
+// When the limiter is unavailable requests are let through.
+func RateLimit(
+	logger *slog.Logger,
+	limiter ratelimit.Limiter,
+	policy ratelimit.Policy,
+) echo.MiddlewareFunc {
+	return rateLimit(logger, limiter, policy, principalKey)
+}
+
+// IPRateLimit limits requests per client IP with a single rule. It runs before
+// SessionMiddleware, so that requests failing authentication, such as guessed tokens, are
+// limited as well. Paths under bypassPrefixes are not limited.
+func IPRateLimit(
+	logger *slog.Logger,
+	limiter ratelimit.Limiter,
+	rule ratelimit.Rule,
+	bypassPrefixes []string,
+) echo.MiddlewareFunc {
+	policy := ratelimit.Policy{Default: rule, BypassPrefixes: bypassPrefixes}
+	return rateLimit(logger, limiter, policy, func(c echo.Context) string {
+		return "ip:" + c.RealIP()
+	})
+}
+
+func rateLimit(
+	logger *slog.Logger,
+	limiter ratelimit.Limiter,
+	policy ratelimit.Policy,
+	keyOf func(c echo.Context) string,
+) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rule, ok := policy.Match(c.Request().URL.Path)
+			if !ok {
+				return next(c)
+			}
+
+			key := rule.Name + ":" + keyOf(c)
+			result, err := limiter.Allow(c.Request().Context(), key, rule.Limit, rule.Window)
+			if err != nil {
+				logger.WarnContext(c.Request().Context(), "rate limit check failed", slog.String("error", err.Error()))
+				return next(c)
+			}
+
+			setRateLimitHeaders(c.Response().Header(), rule, result)
+			if !result.Allowed {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// principalKey identifies who a request is attributed to: the session user,
+// the bearer token for requests that are not (yet) authenticated, or the client IP.
+func principalKey(c echo.Context) string {
+	if sess := GetCurrentSession(c.Request().Context()); sess != nil {
+		return "user:" + sess.UserID
+	}
+
+	if token := extractSessionID(c); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(sum[:8])
+	}
+
+	return "ip:" + c.RealIP()
+}
+
+func setRateLimitHeaders(h http.Header, rule ratelimit.Rule, result ratelimit.Result) {
+	h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+	h.Set("RateLimit-Policy", strconv.Itoa(rule.Limit)+";w="+strconv.Itoa(ceilSeconds(rule.Window)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
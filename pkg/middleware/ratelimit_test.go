@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/auth/session"
+	"github.com/tacokumo/admin-api/pkg/ratelimit"
+)
+
+// mockLimiter implements ratelimit.Limiter with a fixed window counter for testing
+type mockLimiter struct {
+	mu     sync.Mutex
+	counts map[string]int
+	err    error
+}
+
+func newMockLimiter() *mockLimiter {
+	return &mockLimiter{counts: make(map[string]int)}
+}
+
+func (m *mockLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (ratelimit.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return ratelimit.Result{}, m.err
+	}
+
+	if m.counts[key] >= limit {
+		return ratelimit.Result{Allowed: false, Limit: limit, ResetAfter: window, RetryAfter: window}, nil
+	}
+	m.counts[key]++
+	return ratelimit.Result{Allowed: true, Limit: limit, Remaining: limit - m.counts[key], ResetAfter: window}, nil
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.Default()
+	policy := ratelimit.Policy{
+		Rules: []ratelimit.Rule{
+			{Name: "auth", PathPrefix: "/v1alpha1/auth/", Limit: 2, Window: 30 * time.Second},
+		},
+		BypassPrefixes: []string{"/v1alpha1/health/"},
+	}
+
+	doRequest := func(mw echo.MiddlewareFunc, path string, sess *session.Session) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		if sess != nil {
+			req = req.WithContext(context.WithValue(req.Context(), CurrentSessionKey, sess))
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		next := func(c echo.Context) error {
+			return c.String(http.StatusOK, "OK")
+		}
+		if err := mw(next)(c); err != nil {
+			t.Fatalf("RateLimit() returned error: %v", err)
+		}
+		return rec
+	}
+
+	t.Run("returns 429 with headers once the limit is exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		mw := RateLimit(logger, newMockLimiter(), policy)
+
+		for i := 0; i < 2; i++ {
+			rec := doRequest(mw, "/v1alpha1/auth/login", nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("request %d: expected status %d, got %d", i+1, http.StatusOK, rec.Code)
+			}
+			if rec.Header().Get("RateLimit-Limit") != "2" {
+				t.Errorf("RateLimit-Limit = %q, want %q", rec.Header().Get("RateLimit-Limit"), "2")
+			}
+		}
+
+		rec := doRequest(mw, "/v1alpha1/auth/login", nil)
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, rec.Code)
+		}
+		if rec.Header().Get("Retry-After") != "30" {
+			t.Errorf("Retry-After = %q, want %q", rec.Header().Get("Retry-After"), "30")
+		}
+		if rec.Header().Get("RateLimit-Remaining") != "0" {
+			t.Errorf("RateLimit-Remaining = %q, want %q", rec.Header().Get("RateLimit-Remaining"), "0")
+		}
+	})
+
+	t.Run("counts sessions separately from client IP", func(t *testing.T) {
+		t.Parallel()
+
+		mw := RateLimit(logger, newMockLimiter(), policy)
+		sess := &session.Session{ID: "s1", UserID: "user-1"}
+
+		for i := 0; i < 2; i++ {
+			doRequest(mw, "/v1alpha1/auth/me", nil)
+		}
+		rec := doRequest(mw, "/v1alpha1/auth/me", sess)
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status %d for authenticated user, got %d", http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("does not limit bypassed and unmatched paths", func(t *testing.T) {
+		t.Parallel()
+
+		mw := RateLimit(logger, newMockLimiter(), policy)
+
+		for i := 0; i < 5; i++ {
+			for _, path := range []string{"/v1alpha1/health/liveness", "/v1alpha1/projects"} {
+				rec := doRequest(mw, path, nil)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("%s: expected status %d, got %d", path, http.StatusOK, rec.Code)
+				}
+				if rec.Header().Get("RateLimit-Limit") != "" {
+					t.Errorf("%s: RateLimit-Limit should not be set", path)
+				}
+			}
+		}
+	})
+
+	t.Run("lets requests through when the limiter fails", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := newMockLimiter()
+		limiter.err = errors.New("redis unavailable")
+		mw := RateLimit(logger, limiter, policy)
+
+		rec := doRequest(mw, "/v1alpha1/auth/login", nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+	})
+}
+
+func TestIPRateLimit(t *testing.T) {
+	t.Parallel()
+
+	limiter := newMockLimiter()
+	rule := ratelimit.Rule{Name: "per_ip", Limit: 2, Window: time.Minute}
+	mw := IPRateLimit(slog.Default(), limiter, rule, []string{"/v1alpha1/health/"})
+
+	doRequest := func(path, remoteAddr, token string) int {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		next := func(c echo.Context) error {
+			return c.String(http.StatusOK, "OK")
+		}
+		if err := mw(next)(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("IPRateLimit() returned error: %v", err)
+		}
+		return rec.Code
+	}
+
+	// Guessing a different token with every request does not reset the limit.
+	for i, token := range []string{"tksa_guess1", "tksa_guess2"} {
+		if code := doRequest("/v1alpha1/projects", "192.0.2.1:1234", token); code != http.StatusOK {
+			t.Fatalf("request %d: expected status %d, got %d", i+1, http.StatusOK, code)
+		}
+	}
+	if code := doRequest("/v1alpha1/projects", "192.0.2.1:1234", "tksa_guess3"); code != http.StatusTooManyRequests {
+		t.Errorf("expected status %d, got %d", http.StatusTooManyRequests, code)
+	}
+	if code := doRequest("/v1alpha1/projects", "192.0.2.2:1234", "tksa_guess4"); code != http.StatusOK {
+		t.Errorf("other client IP: expected status %d, got %d", http.StatusOK, code)
+	}
+	if code := doRequest("/v1alpha1/health/liveness", "192.0.2.1:1234", ""); code != http.StatusOK {
+		t.Errorf("bypassed path: expected status %d, got %d", http.StatusOK, code)
+	}
+}
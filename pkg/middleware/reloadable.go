@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Reloadable is a middleware that can be replaced while the server is running, such as CORS
+// or rate limiting with settings from a reloaded config.
+type Reloadable struct {
+	current atomic.Pointer[echo.MiddlewareFunc]
+}
+
+// NewReloadable wraps mw. A nil middleware passes requests through.
+func NewReloadable(mw echo.MiddlewareFunc) *Reloadable {
+	r := &Reloadable{}
+	r.Swap(mw)
+	return r
+}
+
+// Swap replaces the middleware. Requests already past it are not affected.
+func (r *Reloadable) Swap(mw echo.MiddlewareFunc) {
+	r.current.Store(&mw)
+}
+
+// Middleware returns the middleware to register with Echo.
+func (r *Reloadable) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			mw := *r.current.Load()
+			if mw == nil {
+				return next(c)
+			}
+			return mw(next)(c)
+		}
+	}
+}
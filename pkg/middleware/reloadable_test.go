@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestReloadable(t *testing.T) {
+	t.Parallel()
+
+	setHeader := func(value string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Response().Header().Set("X-Test", value)
+				return next(c)
+			}
+		}
+	}
+
+	r := NewReloadable(setHeader("first"))
+	handler := r.Middleware()(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	serve := func() string {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		if err := handler(c); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return rec.Header().Get("X-Test")
+	}
+
+	if got := serve(); got != "first" {
+		t.Errorf("X-Test = %q, want first", got)
+	}
+
+	r.Swap(setHeader("second"))
+	if got := serve(); got != "second" {
+		t.Errorf("X-Test after swap = %q, want second", got)
+	}
+
+	r.Swap(nil)
+	if got := serve(); got != "" {
+		t.Errorf("X-Test after swapping in nil = %q, want empty", got)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type requestContextKey struct{}
+
+type requestContext struct {
+	request        *http.Request
+	responseHeader http.Header
+}
+
+// RequestContext makes the raw request and the response headers available through the
+// request context. The generated ogen handlers only receive decoded parameters, so this is
+// how they read headers such as If-Match and set headers such as ETag.
+func RequestContext() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rc := &requestContext{
+				request:        c.Request(),
+				responseHeader: c.Response().Header(),
+			}
+			ctx := context.WithValue(c.Request().Context(), requestContextKey{}, rc)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// RequestHeader returns a header of the current request, or an empty string if the
+// context does not come from RequestContext.
+func RequestHeader(ctx context.Context, name string) string {
+	rc, ok := ctx.Value(requestContextKey{}).(*requestContext)
+	if !ok {
+		return ""
+	}
+	return rc.request.Header.Get(name)
+}
+
+// QueryParam returns a query parameter of the current request, or an empty string if the
+// context does not come from RequestContext.
+func QueryParam(ctx context.Context, name string) string {
+	rc, ok := ctx.Value(requestContextKey{}).(*requestContext)
+	if !ok {
+		return ""
+	}
+	return rc.request.URL.Query().Get(name)
+}
+
+// SetResponseHeader sets a header on the response of the current request.
+// It must be called before the response is written and is a no-op outside of RequestContext.
+func SetResponseHeader(ctx context.Context, name, value string) {
+	rc, ok := ctx.Value(requestContextKey{}).(*requestContext)
+	if !ok {
+		return
+	}
+	rc.responseHeader.Set(name, value)
+}
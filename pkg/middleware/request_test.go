@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequestContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("exposes request headers, query and response headers to handlers", func(t *testing.T) {
+		t.Parallel()
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/v1alpha1/projects?includeArchived=true", nil)
+		req.Header.Set("If-Match", `"3"`)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		next := func(c echo.Context) error {
+			ctx := c.Request().Context()
+			if got := RequestHeader(ctx, "If-Match"); got != `"3"` {
+				t.Errorf("RequestHeader() = %q, want %q", got, `"3"`)
+			}
+			if got := QueryParam(ctx, "includeArchived"); got != "true" {
+				t.Errorf("QueryParam() = %q, want %q", got, "true")
+			}
+			SetResponseHeader(ctx, "ETag", `"4"`)
+			return c.NoContent(http.StatusOK)
+		}
+
+		if err := RequestContext()(next)(c); err != nil {
+			t.Fatalf("RequestContext() returned error: %v", err)
+		}
+		if got := rec.Header().Get("ETag"); got != `"4"` {
+			t.Errorf("ETag = %q, want %q", got, `"4"`)
+		}
+	})
+
+	t.Run("is a no-op outside of the middleware", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		if got := RequestHeader(ctx, "If-Match"); got != "" {
+			t.Errorf("RequestHeader() = %q, want empty string", got)
+		}
+		if got := QueryParam(ctx, "limit"); got != "" {
+			t.Errorf("QueryParam() = %q, want empty string", got)
+		}
+		SetResponseHeader(ctx, "ETag", `"1"`)
+	})
+}
@@ -17,34 +17,89 @@ type SessionContextKey string
 const CurrentSessionKey SessionContextKey = "current_session"
 
 var publicPaths = []string{
+	"/v1alpha1/health/startup",
 	"/v1alpha1/health/liveness",
 	"/v1alpha1/health/readiness",
 	"/v1alpha1/auth/login",
 	"/v1alpha1/auth/callback",
 }
 
+// TokenAuthenticator authenticates bearer tokens that are not backed by the session store,
+// such as service account tokens.
+type TokenAuthenticator interface {
+	// Supports reports whether the authenticator is responsible for the token.
+	Supports(token string) bool
+	// Authenticate returns a session for the token, or session.ErrSessionNotFound if the token is invalid.
+	Authenticate(ctx context.Context, token string) (*session.Session, error)
+}
+
+type SessionOption func(*sessionOptions)
+
+type sessionOptions struct {
+	tokenAuthenticators      []TokenAuthenticator
+	certificateAuthenticator CertificateAuthenticator
+	skipPathPrefixes         []string
+}
+
+// WithTokenAuthenticator registers an authenticator that is consulted before the session store.
+func WithTokenAuthenticator(a TokenAuthenticator) SessionOption {
+	return func(o *sessionOptions) {
+		o.tokenAuthenticators = append(o.tokenAuthenticators, a)
+	}
+}
+
+// WithSkipPathPrefix skips session authentication for requests under prefix, such as
+// endpoints that authenticate requests with their own credentials.
+func WithSkipPathPrefix(prefix string) SessionOption {
+	return func(o *sessionOptions) {
+		o.skipPathPrefixes = append(o.skipPathPrefixes, strings.TrimSuffix(prefix, "/"))
+	}
+}
+
 func SessionMiddleware(
 	logger *slog.Logger,
 	store session.Store,
+	opts ...SessionOption,
 ) echo.MiddlewareFunc {
+	options := &sessionOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			path := c.Request().URL.Path
 
-			if isPublicPath(path) {
+			if isPublicPath(path) || hasPathPrefix(path, options.skipPathPrefixes) {
 				return next(c)
 			}
 
+			if options.certificateAuthenticator != nil {
+				if cert := VerifiedClientCertificate(c.Request()); cert != nil {
+					sess, err := options.certificateAuthenticator.Authenticate(c.Request().Context(), cert)
+					if err == nil {
+						ctx := context.WithValue(c.Request().Context(), CurrentSessionKey, sess)
+						c.SetRequest(c.Request().WithContext(ctx))
+						return next(c)
+					}
+					if !errors.Is(err, session.ErrSessionNotFound) {
+						logger.ErrorContext(c.Request().Context(), "client certificate authentication error", slog.String("error", err.Error()))
+						return echo.NewHTTPError(http.StatusInternalServerError, "internal error")
+					}
+				}
+			}
+
 			sessionID := extractSessionID(c)
 			if sessionID == "" {
 				logger.DebugContext(c.Request().Context(), "no session id found in request")
 				return echo.NewHTTPError(http.StatusUnauthorized, "missing authentication")
 			}
 
-			sess, err := store.Get(c.Request().Context(), sessionID)
+			sess, err := lookupSession(c.Request().Context(), store, options.tokenAuthenticators, sessionID)
 			if err != nil {
 				if errors.Is(err, session.ErrSessionNotFound) {
-					logger.DebugContext(c.Request().Context(), "session not found", slog.String("session_id", sessionID))
+					// The session ID from the request is a credential, so it is not logged.
+					logger.DebugContext(c.Request().Context(), "session not found")
 					return echo.NewHTTPError(http.StatusUnauthorized, "invalid session")
 				}
 				logger.ErrorContext(c.Request().Context(), "session lookup error", slog.String("error", err.Error()))
@@ -52,7 +107,7 @@ func SessionMiddleware(
 			}
 
 			if time.Now().After(sess.ExpiresAt) {
-				logger.DebugContext(c.Request().Context(), "session expired", slog.String("session_id", sessionID))
+				logger.DebugContext(c.Request().Context(), "session expired", slog.String("user_id", sess.UserID))
 				return echo.NewHTTPError(http.StatusUnauthorized, "session expired")
 			}
 
@@ -64,6 +119,20 @@ func SessionMiddleware(
 	}
 }
 
+func lookupSession(
+	ctx context.Context,
+	store session.Store,
+	authenticators []TokenAuthenticator,
+	token string,
+) (*session.Session, error) {
+	for _, a := range authenticators {
+		if a.Supports(token) {
+			return a.Authenticate(ctx, token)
+		}
+	}
+	return store.Get(ctx, token)
+}
+
 func isPublicPath(path string) bool {
 	for _, p := range publicPaths {
 		if path == p {
@@ -73,6 +142,15 @@ func isPublicPath(path string) bool {
 	return false
 }
 
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 func extractSessionID(c echo.Context) string {
 	authHeader := c.Request().Header.Get("Authorization")
 	if strings.HasPrefix(authHeader, "Bearer ") {
@@ -66,6 +66,7 @@ func TestIsPublicPath(t *testing.T) {
 		path     string
 		expected bool
 	}{
+		{"/v1alpha1/health/startup", true},
 		{"/v1alpha1/health/liveness", true},
 		{"/v1alpha1/health/readiness", true},
 		{"/v1alpha1/auth/login", true},
@@ -361,6 +362,157 @@ func TestSessionMiddleware(t *testing.T) {
 	})
 }
 
+// mockTokenAuthenticator implements TokenAuthenticator for testing
+type mockTokenAuthenticator struct {
+	prefix   string
+	sessions map[string]*session.Session
+}
+
+func (m *mockTokenAuthenticator) Supports(token string) bool {
+	return len(token) > len(m.prefix) && token[:len(m.prefix)] == m.prefix
+}
+
+func (m *mockTokenAuthenticator) Authenticate(ctx context.Context, token string) (*session.Session, error) {
+	sess, exists := m.sessions[token]
+	if !exists {
+		return nil, session.ErrSessionNotFound
+	}
+	return sess, nil
+}
+
+func TestSessionMiddlewareWithTokenAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.Default()
+
+	newAuthenticator := func() *mockTokenAuthenticator {
+		return &mockTokenAuthenticator{
+			prefix: "tksa_",
+			sessions: map[string]*session.Session{
+				"tksa_valid": {
+					ID:               "tksa_valid",
+					UserID:           "serviceaccount:sa-1",
+					ServiceAccountID: "sa-1",
+					ExpiresAt:        time.Now().Add(time.Hour),
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		wantStatus     int
+		wantServiceAcc string
+	}{
+		{"authenticates supported token", "tksa_valid", http.StatusOK, "sa-1"},
+		{"rejects unknown supported token", "tksa_unknown", http.StatusUnauthorized, ""},
+		{"falls back to session store for other tokens", "valid-session", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := NewMockSessionStore()
+			if err := store.Create(context.Background(), &session.Session{
+				ID:        "valid-session",
+				ExpiresAt: time.Now().Add(time.Hour),
+			}); err != nil {
+				t.Fatalf("Failed to create valid session: %v", err)
+			}
+
+			middleware := SessionMiddleware(logger, store, WithTokenAuthenticator(newAuthenticator()))
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/v1alpha1/projects", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var sessionFromContext *session.Session
+			next := func(c echo.Context) error {
+				sessionFromContext = GetCurrentSession(c.Request().Context())
+				return c.String(http.StatusOK, "OK")
+			}
+
+			err := middleware(next)(c)
+			if tt.wantStatus != http.StatusOK {
+				httpErr, ok := err.(*echo.HTTPError)
+				if !ok {
+					t.Fatalf("Expected *echo.HTTPError, got %T", err)
+				}
+				if httpErr.Code != tt.wantStatus {
+					t.Errorf("Expected status code %d, got %d", tt.wantStatus, httpErr.Code)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SessionMiddleware() returned error: %v", err)
+			}
+			if sessionFromContext == nil {
+				t.Fatal("Session should be available in context")
+			}
+			if sessionFromContext.ServiceAccountID != tt.wantServiceAcc {
+				t.Errorf("Expected service account ID %q, got %q", tt.wantServiceAcc, sessionFromContext.ServiceAccountID)
+			}
+		})
+	}
+}
+
+func TestSessionMiddlewareWithSkipPathPrefix(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.Default()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"skips prefix", "/scim/v2", http.StatusOK},
+		{"skips paths under prefix", "/scim/v2/Users", http.StatusOK},
+		{"does not skip paths sharing the prefix", "/scim/v2x", http.StatusUnauthorized},
+		{"does not skip other paths", "/v1alpha1/projects", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			middleware := SessionMiddleware(logger, NewMockSessionStore(), WithSkipPathPrefix("/scim/v2/"))
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			next := func(c echo.Context) error {
+				if GetCurrentSession(c.Request().Context()) != nil {
+					t.Error("Session should not be set for skipped paths")
+				}
+				return c.String(http.StatusOK, "OK")
+			}
+
+			err := middleware(next)(c)
+			if tt.wantStatus == http.StatusOK {
+				if err != nil {
+					t.Fatalf("SessionMiddleware() returned error: %v", err)
+				}
+				return
+			}
+			httpErr, ok := err.(*echo.HTTPError)
+			if !ok {
+				t.Fatalf("Expected *echo.HTTPError, got %T", err)
+			}
+			if httpErr.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, httpErr.Code)
+			}
+		})
+	}
+}
+
 func TestGetCurrentSession(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,32 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: true},
+		{name: "wrapped unique violation", err: errors.Wrapf(&pgconn.PgError{Code: "23505"}, "failed to insert"), want: true},
+		{name: "foreign key violation", err: &pgconn.PgError{Code: "23503"}, want: false},
+		{name: "other error", err: errors.New("boom"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := IsUniqueViolation(tt.err); got != tt.want {
+				t.Errorf("IsUniqueViolation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
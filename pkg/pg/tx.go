@@ -0,0 +1,32 @@
+package pg
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WithTx runs fn in a transaction of pool with the given options. The transaction is committed if fn
+// returns nil and rolled back otherwise. A failure to roll back is logged to logger.
+func WithTx(ctx context.Context, pool *pgxpool.Pool, logger *slog.Logger, opts pgx.TxOptions, fn func(tx pgx.Tx) error) error {
+	tx, err := pool.BeginTx(ctx, opts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to begin transaction")
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			logger.ErrorContext(ctx, "failed to rollback transaction", slog.String("error", rbErr.Error()))
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction")
+	}
+	return nil
+}
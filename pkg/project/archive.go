@@ -0,0 +1,76 @@
+package project
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/jobs"
+)
+
+const (
+	// PurgeJobKind is the kind of the job that deletes projects archived longer than the retention.
+	PurgeJobKind = "projects.purge"
+
+	DefaultArchiveRetention = 30 * 24 * time.Hour
+)
+
+// ErrArchived is returned for changes to an archived project, which is read-only until it is unarchived.
+var ErrArchived = errors.New("project is archived")
+
+// IsArchived reports whether the project is archived.
+func IsArchived(p admindb.TacokumoAdminProject) bool {
+	return p.ArchivedAt.Valid
+}
+
+// EnsureActive returns ErrArchived if the project is archived.
+func EnsureActive(p admindb.TacokumoAdminProject) error {
+	if IsArchived(p) {
+		return ErrArchived
+	}
+	return nil
+}
+
+// Lock locks the project against archival for the rest of the transaction of q and returns
+// ErrArchived if it is archived already. Archival waits for the transactions that hold the lock,
+// so a change made under it cannot end up in an archived project.
+func Lock(ctx context.Context, q *admindb.Queries, projectID int64) error {
+	archivedAt, err := q.LockProjectForChange(ctx, projectID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to lock project")
+	}
+	if archivedAt.Valid {
+		return ErrArchived
+	}
+	return nil
+}
+
+// NewPurgeHandler returns the handler of PurgeJobKind, which deletes projects that were archived
+// longer than retention ago together with everything that belongs to them. A deleted event is
+// published for every purged project. Its webhooks are deleted with it, so nothing is queued
+// for them.
+func NewPurgeHandler(logger *slog.Logger, queries *admindb.Queries, retention time.Duration, publish func(context.Context, events.Event)) jobs.Handler {
+	if retention <= 0 {
+		retention = DefaultArchiveRetention
+	}
+	return func(ctx context.Context, job jobs.Job) error {
+		purged, err := queries.DeleteArchivedProjectsBefore(ctx, pgtype.Timestamptz{Time: time.Now().Add(-retention), Valid: true})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete archived projects")
+		}
+		for _, id := range purged {
+			logger.InfoContext(ctx, "purged archived project", slog.String("project_id", id.String()))
+			publish(ctx, events.Event{
+				Kind:       events.KindProject,
+				Action:     events.ActionDeleted,
+				ResourceID: id.String(),
+				ProjectID:  id.String(),
+			})
+		}
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+func TestEnsureActive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		archivedAt pgtype.Timestamptz
+		wantErr    error
+	}{
+		{
+			name: "active project",
+		},
+		{
+			name:       "archived project",
+			archivedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+			wantErr:    ErrArchived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := admindb.TacokumoAdminProject{ArchivedAt: tt.archivedAt}
+			err := EnsureActive(p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EnsureActive() = %v, want %v", err, tt.wantErr)
+			}
+			if got := IsArchived(p); got != (tt.wantErr != nil) {
+				t.Errorf("IsArchived() = %v, want %v", got, tt.wantErr != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package project implements the rules that depend on the kind of a project.
+//
+// A personal project belongs to exactly one user and has no user groups. A shared project is
+// owned by at least one owner group, whose members manage it together.
+package project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+// Project kinds stored in tacokumo_admin.projects.kind.
+const (
+	KindPersonal = "personal"
+	KindShared   = "shared"
+)
+
+// DefaultOwnerGroupName is the name of the owner group created for new shared projects.
+const DefaultOwnerGroupName = "owners"
+
+// ValidKind reports whether kind is a known project kind.
+func ValidKind(kind string) bool {
+	return kind == KindPersonal || kind == KindShared
+}
+
+// Composition is the part of a project the kind rules are checked against.
+type Composition struct {
+	Kind        string
+	Owners      int64
+	OwnerGroups int64
+	UserGroups  int64
+}
+
+// InvariantError reports the kind rules a project would violate.
+type InvariantError struct {
+	Kind       string
+	Violations []string
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("%s project %s", e.Kind, strings.Join(e.Violations, "; "))
+}
+
+// Validate checks the composition against the rules of its kind.
+func (c Composition) Validate() error {
+	var violations []string
+	switch c.Kind {
+	case KindPersonal:
+		if c.Owners != 1 {
+			violations = append(violations, fmt.Sprintf("must have exactly one owner, but has %d", c.Owners))
+		}
+		if c.OwnerGroups > 0 {
+			violations = append(violations, "cannot have owner groups")
+		}
+		if c.UserGroups > 0 {
+			violations = append(violations, fmt.Sprintf("cannot have user groups, but has %d", c.UserGroups))
+		}
+	case KindShared:
+		if c.OwnerGroups < 1 {
+			violations = append(violations, "must have at least one owner group")
+		}
+	default:
+		violations = append(violations, fmt.Sprintf("has unknown kind %q", c.Kind))
+	}
+	if len(violations) > 0 {
+		return &InvariantError{Kind: c.Kind, Violations: violations}
+	}
+	return nil
+}
+
+// Check loads the composition of a project and validates it. It is meant to be called at the
+// end of a transaction that changes owners or user groups, so that a violation rolls it back.
+func Check(ctx context.Context, q *admindb.Queries, projectID int64) error {
+	row, err := q.GetProjectComposition(ctx, projectID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get project composition")
+	}
+	return Composition{
+		Kind:        row.Kind,
+		Owners:      row.OwnerCount,
+		OwnerGroups: row.OwnerGroupCount,
+		UserGroups:  row.UsergroupCount,
+	}.Validate()
+}
@@ -0,0 +1,94 @@
+package project
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+)
+
+func TestCompositionValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		composition Composition
+		// wantViolations are substrings of the expected violations; empty means valid.
+		wantViolations []string
+	}{
+		{
+			name:        "personal with one owner",
+			composition: Composition{Kind: KindPersonal, Owners: 1},
+		},
+		{
+			name:           "personal without owner",
+			composition:    Composition{Kind: KindPersonal},
+			wantViolations: []string{"exactly one owner, but has 0"},
+		},
+		{
+			name:           "personal with two owners and user groups",
+			composition:    Composition{Kind: KindPersonal, Owners: 2, UserGroups: 1},
+			wantViolations: []string{"exactly one owner, but has 2", "cannot have user groups"},
+		},
+		{
+			name:           "personal with owner group",
+			composition:    Composition{Kind: KindPersonal, Owners: 1, OwnerGroups: 1, UserGroups: 1},
+			wantViolations: []string{"cannot have owner groups", "cannot have user groups"},
+		},
+		{
+			name:        "shared with owner group",
+			composition: Composition{Kind: KindShared, OwnerGroups: 1, UserGroups: 3},
+		},
+		{
+			name:        "shared with owner group and individual owners",
+			composition: Composition{Kind: KindShared, Owners: 2, OwnerGroups: 2, UserGroups: 2},
+		},
+		{
+			name:           "shared without owner group",
+			composition:    Composition{Kind: KindShared, Owners: 1, UserGroups: 1},
+			wantViolations: []string{"at least one owner group"},
+		},
+		{
+			name:           "unknown kind",
+			composition:    Composition{Kind: "team"},
+			wantViolations: []string{`unknown kind "team"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.composition.Validate()
+			if len(tt.wantViolations) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var ie *InvariantError
+			if !errors.As(err, &ie) {
+				t.Fatalf("Validate() error = %v, want *InvariantError", err)
+			}
+			if len(ie.Violations) != len(tt.wantViolations) {
+				t.Fatalf("Violations = %v, want %d violations", ie.Violations, len(tt.wantViolations))
+			}
+			for i, want := range tt.wantViolations {
+				if !strings.Contains(ie.Violations[i], want) {
+					t.Errorf("Violations[%d] = %q, want it to contain %q", i, ie.Violations[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestInvariantErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	err := Composition{Kind: KindPersonal, Owners: 0, UserGroups: 1}.Validate()
+	want := "personal project must have exactly one owner, but has 0; cannot have user groups, but has 1"
+	if err == nil || err.Error() != want {
+		t.Errorf("Error() = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,305 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/labels"
+)
+
+const (
+	maxRoleNameLength      = 32
+	maxUserGroupNameLength = 64
+	maxDescriptionLength   = 256
+)
+
+// Template is the role and user group structure of a project. It holds no members, so that
+// a new project created from it starts with only its owners.
+type Template struct {
+	Roles      []TemplateRole      `json:"roles"`
+	UserGroups []TemplateUserGroup `json:"userGroups"`
+}
+
+// TemplateRole is a role of a template.
+type TemplateRole struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Attributes are the names of the predefined role attributes.
+	Attributes  []string          `json:"attributes,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// TemplateUserGroup is a user group of a template.
+type TemplateUserGroup struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Roles are the names of the template roles assigned to the group.
+	Roles []string `json:"roles,omitempty"`
+	// Owner makes the group an owner group. The owners of a new project become its members.
+	Owner       bool              `json:"owner,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// TemplateError reports a template that cannot be applied to a project.
+type TemplateError struct {
+	Message string
+}
+
+func (e *TemplateError) Error() string {
+	return "invalid project template: " + e.Message
+}
+
+func templateErrorf(format string, args ...any) *TemplateError {
+	return &TemplateError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Validate checks that the template can be applied to a project of kind.
+func (t Template) Validate(kind string) error {
+	if !ValidKind(kind) {
+		return templateErrorf("unknown kind %q", kind)
+	}
+	if kind == KindPersonal && len(t.UserGroups) > 0 {
+		return templateErrorf("personal projects cannot have user groups")
+	}
+
+	roles := map[string]bool{}
+	for _, r := range t.Roles {
+		if r.Name == "" || len(r.Name) > maxRoleNameLength {
+			return templateErrorf("role name %q must be 1 to %d characters", r.Name, maxRoleNameLength)
+		}
+		if len(r.Description) > maxDescriptionLength {
+			return templateErrorf("description of role %q must be at most %d characters", r.Name, maxDescriptionLength)
+		}
+		if roles[r.Name] {
+			return templateErrorf("role %q is defined more than once", r.Name)
+		}
+		roles[r.Name] = true
+		if err := validateMetadata(r.Labels, r.Annotations); err != nil {
+			return templateErrorf("role %q: %s", r.Name, err)
+		}
+	}
+
+	groups := map[string]bool{}
+	for _, g := range t.UserGroups {
+		if g.Name == "" || len(g.Name) > maxUserGroupNameLength {
+			return templateErrorf("user group name %q must be 1 to %d characters", g.Name, maxUserGroupNameLength)
+		}
+		if len(g.Description) > maxDescriptionLength {
+			return templateErrorf("description of user group %q must be at most %d characters", g.Name, maxDescriptionLength)
+		}
+		if groups[g.Name] {
+			return templateErrorf("user group %q is defined more than once", g.Name)
+		}
+		groups[g.Name] = true
+		for _, role := range g.Roles {
+			if !roles[role] {
+				return templateErrorf("user group %q refers to undefined role %q", g.Name, role)
+			}
+		}
+		if err := validateMetadata(g.Labels, g.Annotations); err != nil {
+			return templateErrorf("user group %q: %s", g.Name, err)
+		}
+	}
+	return nil
+}
+
+// HasOwnerGroup reports whether the template defines an owner group.
+func (t Template) HasOwnerGroup() bool {
+	for _, g := range t.UserGroups {
+		if g.Owner {
+			return true
+		}
+	}
+	return false
+}
+
+func validateMetadata(l, a map[string]string) error {
+	if err := labels.Validate(l); err != nil {
+		return err
+	}
+	return labels.ValidateAnnotations(a)
+}
+
+// Capture reads the role and user group structure of a project into a template.
+func Capture(ctx context.Context, q *admindb.Queries, projectID int64) (Template, error) {
+	t := Template{Roles: []TemplateRole{}, UserGroups: []TemplateUserGroup{}}
+
+	roles, err := q.ListRolesByProject(ctx, projectID)
+	if err != nil {
+		return t, errors.Wrapf(err, "failed to list roles by project")
+	}
+	for _, r := range roles {
+		attributes, err := q.ListRoleAttributeNames(ctx, r.ID)
+		if err != nil {
+			return t, errors.Wrapf(err, "failed to list role attribute names")
+		}
+		role := TemplateRole{Name: r.Name, Description: r.Description, Attributes: attributes}
+		if role.Labels, role.Annotations, err = decodeMetadata(r.Labels, r.Annotations); err != nil {
+			return t, err
+		}
+		t.Roles = append(t.Roles, role)
+	}
+
+	ownerGroups, err := q.ListProjectOwnerGroups(ctx, projectID)
+	if err != nil {
+		return t, errors.Wrapf(err, "failed to list project owner groups")
+	}
+	isOwner := map[int64]bool{}
+	for _, g := range ownerGroups {
+		isOwner[g.ID] = true
+	}
+	groups, err := q.ListUserGroupsByProject(ctx, projectID)
+	if err != nil {
+		return t, errors.Wrapf(err, "failed to list user groups by project")
+	}
+	for _, g := range groups {
+		roleNames, err := q.ListUserGroupRoleNames(ctx, g.ID)
+		if err != nil {
+			return t, errors.Wrapf(err, "failed to list user group role names")
+		}
+		group := TemplateUserGroup{Name: g.Name, Description: g.Description, Roles: roleNames, Owner: isOwner[g.ID]}
+		if group.Labels, group.Annotations, err = decodeMetadata(g.Labels, g.Annotations); err != nil {
+			return t, err
+		}
+		t.UserGroups = append(t.UserGroups, group)
+	}
+	return t, nil
+}
+
+// Instance is what Instantiate created, for publishing events.
+type Instance struct {
+	Roles       []pgtype.UUID
+	UserGroups  []pgtype.UUID
+	OwnerGroups []pgtype.UUID
+}
+
+// Instantiate creates the roles and user groups of a template in a project. Owners become the
+// members of the owner groups of the template. It does not check the kind rules, so it is
+// meant to be followed by Check in the same transaction.
+func Instantiate(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, t Template, owners []admindb.TacokumoAdminUser) (Instance, error) {
+	var inst Instance
+
+	roleIDs := map[string]int64{}
+	for _, r := range t.Roles {
+		displayID, err := q.CreateRole(ctx, admindb.CreateRoleParams{ProjectID: proj.ID, Name: r.Name, Description: r.Description})
+		if err != nil {
+			return inst, errors.Wrapf(err, "failed to create role")
+		}
+		role, err := q.GetRoleByDisplayID(ctx, admindb.GetRoleByDisplayIDParams{ProjectID: proj.ID, DisplayID: displayID})
+		if err != nil {
+			return inst, errors.Wrapf(err, "failed to get role by display id")
+		}
+		if len(r.Labels) > 0 || len(r.Annotations) > 0 {
+			l, a, err := encodeMetadata(r.Labels, r.Annotations)
+			if err != nil {
+				return inst, err
+			}
+			if _, err := q.UpdateRole(ctx, admindb.UpdateRoleParams{
+				ProjectID:   proj.ID,
+				DisplayID:   displayID,
+				Name:        role.Name,
+				Description: role.Description,
+				Labels:      l,
+				Annotations: a,
+			}); err != nil {
+				return inst, errors.Wrapf(err, "failed to update role")
+			}
+		}
+		for _, attribute := range r.Attributes {
+			affected, err := q.AddRoleAttributeByName(ctx, admindb.AddRoleAttributeByNameParams{RoleID: role.ID, Name: attribute})
+			if err != nil {
+				return inst, errors.Wrapf(err, "failed to add role attribute")
+			}
+			if affected == 0 {
+				return inst, templateErrorf("role %q refers to undefined attribute %q", r.Name, attribute)
+			}
+		}
+		roleIDs[r.Name] = role.ID
+		inst.Roles = append(inst.Roles, displayID)
+	}
+
+	for _, g := range t.UserGroups {
+		displayID, err := q.CreateUserGroup(ctx, admindb.CreateUserGroupParams{ProjectID: proj.ID, Name: g.Name, Description: g.Description})
+		if err != nil {
+			return inst, errors.Wrapf(err, "failed to create user group")
+		}
+		group, err := q.GetUserGroupByDisplayID(ctx, admindb.GetUserGroupByDisplayIDParams{ProjectID: proj.ID, DisplayID: displayID})
+		if err != nil {
+			return inst, errors.Wrapf(err, "failed to get user group by display id")
+		}
+		if len(g.Labels) > 0 || len(g.Annotations) > 0 {
+			l, a, err := encodeMetadata(g.Labels, g.Annotations)
+			if err != nil {
+				return inst, err
+			}
+			if _, err := q.UpdateUserGroup(ctx, admindb.UpdateUserGroupParams{
+				ProjectID:   proj.ID,
+				DisplayID:   displayID,
+				Name:        group.Name,
+				Description: group.Description,
+				Labels:      l,
+				Annotations: a,
+			}); err != nil {
+				return inst, errors.Wrapf(err, "failed to update user group")
+			}
+		}
+		for _, role := range g.Roles {
+			roleID, ok := roleIDs[role]
+			if !ok {
+				return inst, templateErrorf("user group %q refers to undefined role %q", g.Name, role)
+			}
+			if err := q.AssignRoleToUserGroup(ctx, admindb.AssignRoleToUserGroupParams{UsergroupID: group.ID, RoleID: roleID}); err != nil {
+				return inst, errors.Wrapf(err, "failed to assign role to user group")
+			}
+		}
+		if g.Owner {
+			for _, owner := range owners {
+				if err := q.AddUserToUserGroup(ctx, admindb.AddUserToUserGroupParams{UserID: owner.ID, UsergroupID: group.ID}); err != nil {
+					return inst, errors.Wrapf(err, "failed to add user to user group")
+				}
+			}
+			if err := q.AddProjectOwnerGroup(ctx, admindb.AddProjectOwnerGroupParams{ProjectID: proj.ID, UsergroupID: group.ID}); err != nil {
+				return inst, errors.Wrapf(err, "failed to add project owner group")
+			}
+			inst.OwnerGroups = append(inst.OwnerGroups, displayID)
+		}
+		inst.UserGroups = append(inst.UserGroups, displayID)
+	}
+	return inst, nil
+}
+
+func decodeMetadata(labelsJSON, annotationsJSON []byte) (l map[string]string, a map[string]string, err error) {
+	if len(labelsJSON) > 0 {
+		if err := json.Unmarshal(labelsJSON, &l); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to unmarshal labels")
+		}
+	}
+	if len(annotationsJSON) > 0 {
+		if err := json.Unmarshal(annotationsJSON, &a); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to unmarshal annotations")
+		}
+	}
+	return l, a, nil
+}
+
+func encodeMetadata(l, a map[string]string) (labelsJSON []byte, annotationsJSON []byte, err error) {
+	if l == nil {
+		l = map[string]string{}
+	}
+	if a == nil {
+		a = map[string]string{}
+	}
+	if labelsJSON, err = json.Marshal(l); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to marshal labels")
+	}
+	if annotationsJSON, err = json.Marshal(a); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to marshal annotations")
+	}
+	return labelsJSON, annotationsJSON, nil
+}
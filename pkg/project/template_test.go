@@ -0,0 +1,90 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/errors"
+)
+
+func TestTemplateValidate(t *testing.T) {
+	t.Parallel()
+
+	roles := []TemplateRole{
+		{Name: "admin", Description: "Administrators", Attributes: []string{"write"}},
+		{Name: "viewer", Description: "Viewers", Labels: map[string]string{"tier": "read"}},
+	}
+	tests := []struct {
+		name     string
+		kind     string
+		template Template
+		wantErr  bool
+	}{
+		{
+			name: "shared with groups",
+			kind: KindShared,
+			template: Template{
+				Roles: roles,
+				UserGroups: []TemplateUserGroup{
+					{Name: "owners", Roles: []string{"admin"}, Owner: true},
+					{Name: "readers", Roles: []string{"viewer"}},
+				},
+			},
+		},
+		{
+			name:     "personal with roles only",
+			kind:     KindPersonal,
+			template: Template{Roles: roles},
+		},
+		{
+			name:     "personal with groups",
+			kind:     KindPersonal,
+			template: Template{UserGroups: []TemplateUserGroup{{Name: "owners"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown kind",
+			kind:     "team",
+			template: Template{},
+			wantErr:  true,
+		},
+		{
+			name:     "duplicate role",
+			kind:     KindShared,
+			template: Template{Roles: []TemplateRole{{Name: "admin"}, {Name: "admin"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "role name too long",
+			kind:     KindShared,
+			template: Template{Roles: []TemplateRole{{Name: "a-role-name-that-is-longer-than-32"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "undefined role in group",
+			kind:     KindShared,
+			template: Template{Roles: roles, UserGroups: []TemplateUserGroup{{Name: "editors", Roles: []string{"editor"}}}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid label",
+			kind:     KindShared,
+			template: Template{Roles: []TemplateRole{{Name: "admin", Labels: map[string]string{"env": "not valid"}}}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.template.Validate(tt.kind)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var te *TemplateError
+			if err != nil && !errors.As(err, &te) {
+				t.Errorf("Validate() error = %T, want *TemplateError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package project
+
+import (
+	"time"
+
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+// Statuses of an ownership transfer. Pending transfers past their expiry are reported as expired
+// even before they are marked so.
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusAccepted  = "accepted"
+	TransferStatusCancelled = "cancelled"
+	TransferStatusExpired   = "expired"
+)
+
+// TransferStatuses lists every transfer status.
+var TransferStatuses = []string{TransferStatusPending, TransferStatusAccepted, TransferStatusCancelled, TransferStatusExpired}
+
+// Actions recorded in the transfer audit log.
+const (
+	TransferActionInitiated = "initiated"
+	TransferActionAccepted  = "accepted"
+	TransferActionCancelled = "cancelled"
+)
+
+// DefaultTransferTTL is how long the recipient has to accept a transfer by default.
+const DefaultTransferTTL = 7 * 24 * time.Hour
+
+// TransferStatus returns the status of a transfer at now.
+func TransferStatus(t admindb.TacokumoAdminProjectTransfer, now time.Time) string {
+	if t.Status == TransferStatusPending && !t.ExpiresAt.Time.After(now) {
+		return TransferStatusExpired
+	}
+	return t.Status
+}
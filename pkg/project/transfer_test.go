@@ -0,0 +1,41 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+)
+
+func TestTransferStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tests := []struct {
+		name      string
+		status    string
+		expiresAt time.Time
+		want      string
+	}{
+		{name: "pending before expiry", status: TransferStatusPending, expiresAt: now.Add(time.Hour), want: TransferStatusPending},
+		{name: "pending at expiry", status: TransferStatusPending, expiresAt: now, want: TransferStatusExpired},
+		{name: "pending after expiry", status: TransferStatusPending, expiresAt: now.Add(-time.Hour), want: TransferStatusExpired},
+		{name: "accepted after expiry", status: TransferStatusAccepted, expiresAt: now.Add(-time.Hour), want: TransferStatusAccepted},
+		{name: "cancelled", status: TransferStatusCancelled, expiresAt: now.Add(time.Hour), want: TransferStatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			transfer := admindb.TacokumoAdminProjectTransfer{
+				Status:    tt.status,
+				ExpiresAt: pgtype.Timestamptz{Time: tt.expiresAt, Valid: true},
+			}
+			if got := TransferStatus(transfer, now); got != tt.want {
+				t.Errorf("TransferStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Result describes the outcome of a single rate limit check.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is the time until the window no longer contains any of the counted requests.
+	ResetAfter time.Duration
+	// RetryAfter is the time until the next request will be allowed. Zero if Allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter counts requests for a key within a sliding window.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+}
+
+// Rule limits the requests of a route group, identified by its path prefix.
+type Rule struct {
+	Name       string
+	PathPrefix string
+	Limit      int
+	Window     time.Duration
+}
+
+// Enabled reports whether the rule actually limits anything.
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Window > 0
+}
+
+// Policy selects the rule to apply to a request path.
+type Policy struct {
+	// Rules are matched by the longest PathPrefix.
+	Rules []Rule
+	// Default applies to paths that match no rule. Disabled when its Limit is zero.
+	Default Rule
+	// BypassPrefixes are path prefixes that are never limited, such as health checks.
+	BypassPrefixes []string
+}
+
+// Match returns the rule for a path, or false if the path is not limited.
+func (p Policy) Match(path string) (Rule, bool) {
+	for _, prefix := range p.BypassPrefixes {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return Rule{}, false
+		}
+	}
+
+	var matched Rule
+	found := false
+	for _, r := range p.Rules {
+		if !strings.HasPrefix(path, r.PathPrefix) {
+			continue
+		}
+		if !found || len(r.PathPrefix) > len(matched.PathPrefix) {
+			matched = r
+			found = true
+		}
+	}
+	if !found {
+		matched = p.Default
+		if matched.Name == "" {
+			matched.Name = "default"
+		}
+	}
+
+	if !matched.Enabled() {
+		return Rule{}, false
+	}
+	return matched, true
+}
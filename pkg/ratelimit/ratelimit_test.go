@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyMatch(t *testing.T) {
+	t.Parallel()
+
+	policy := Policy{
+		Rules: []Rule{
+			{Name: "auth", PathPrefix: "/v1alpha1/auth/", Limit: 10, Window: time.Minute},
+			{Name: "auth-callback", PathPrefix: "/v1alpha1/auth/callback", Limit: 5, Window: time.Minute},
+			{Name: "disabled", PathPrefix: "/v1alpha1/users", Limit: 0, Window: time.Minute},
+		},
+		Default:        Rule{Limit: 100, Window: time.Minute},
+		BypassPrefixes: []string{"/v1alpha1/health/"},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		wantRule string
+		wantOK   bool
+	}{
+		{"matches route group", "/v1alpha1/auth/login", "auth", true},
+		{"prefers longest prefix", "/v1alpha1/auth/callback", "auth-callback", true},
+		{"falls back to default", "/v1alpha1/projects", "default", true},
+		{"bypasses health checks", "/v1alpha1/health/readiness", "", false},
+		{"disabled rule is not limited", "/v1alpha1/users", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, ok := policy.Match(tt.path)
+			if ok != tt.wantOK {
+				t.Fatalf("Match(%q) ok = %v, want %v", tt.path, ok, tt.wantOK)
+			}
+			if rule.Name != tt.wantRule {
+				t.Errorf("Match(%q) rule = %q, want %q", tt.path, rule.Name, tt.wantRule)
+			}
+		})
+	}
+
+	t.Run("disabled default does not limit unmatched paths", func(t *testing.T) {
+		t.Parallel()
+
+		p := Policy{Rules: policy.Rules}
+		if _, ok := p.Match("/v1alpha1/projects"); ok {
+			t.Error("Match() should not limit paths when default is disabled")
+		}
+	})
+}
@@ -0,0 +1,100 @@
+// Package reload watches files and reloads what is read from them while the server is running.
+package reload
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Watcher reports whether files changed since they were last polled. The caller polls it on
+// its own schedule. Files are compared by modification time and size. Stat follows symlinks, so
+// the watcher also notices the symlink swaps Kubernetes uses to update mounted secrets and
+// config maps.
+type Watcher struct {
+	logger *slog.Logger
+	paths  []string
+	state  map[string]fileState
+}
+
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func NewWatcher(logger *slog.Logger, paths []string) *Watcher {
+	w := &Watcher{
+		logger: logger,
+		paths:  paths,
+		state:  map[string]fileState{},
+	}
+	for _, path := range paths {
+		w.state[path] = stat(path)
+	}
+	return w
+}
+
+// Poll reports whether any file changed since the last poll.
+func (w *Watcher) Poll() bool {
+	changed := false
+	for _, path := range w.paths {
+		current := stat(path)
+		if current != w.state[path] {
+			w.logger.Debug("watched file changed", slog.String("path", path), slog.Bool("exists", current.exists))
+			w.state[path] = current
+			changed = true
+		}
+	}
+	return changed
+}
+
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+// CertificateReloader serves a certificate that can be reloaded from its files through
+// tls.Config.GetCertificate, so that rotated certificates are used without a restart.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+}
+
+// NewCertificateReloader loads the key pair.
+func NewCertificateReloader(certFile, keyFile string) (*CertificateReloader, error) {
+	r := &CertificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the key pair again. The current certificate is kept if the files are invalid,
+// for example while only one of them has been replaced.
+func (r *CertificateReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load X509 key pair")
+	}
+	r.cert.Store(&cert)
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Files returns the certificate and key files, which are watched for changes.
+func (r *CertificateReloader) Files() []string {
+	return []string{r.certFile, r.keyFile}
+}
@@ -0,0 +1,133 @@
+package reload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherPoll(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.yaml")
+	writeFile(t, path, "port: 8080\n", time.Now().Add(-time.Hour))
+
+	w := NewWatcher(slog.Default(), []string{path})
+	if w.Poll() {
+		t.Error("Poll() reported a change before the file changed")
+	}
+
+	writeFile(t, path, "port: 9090\n", time.Now())
+	if !w.Poll() {
+		t.Error("Poll() did not report a modified file")
+	}
+	if w.Poll() {
+		t.Error("Poll() reported the same change twice")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if !w.Poll() {
+		t.Error("Poll() did not report a removed file")
+	}
+
+	writeFile(t, path, "port: 9090\n", time.Now())
+	if !w.Poll() {
+		t.Error("Poll() did not report a recreated file")
+	}
+}
+
+func TestCertificateReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	writeKeyPair(t, certFile, keyFile, "first")
+	r, err := NewCertificateReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertificateReloader() error = %v", err)
+	}
+	if got := commonName(t, r); got != "first" {
+		t.Fatalf("certificate = %q, want first", got)
+	}
+
+	writeKeyPair(t, certFile, keyFile, "second")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := commonName(t, r); got != "second" {
+		t.Errorf("certificate after reload = %q, want second", got)
+	}
+
+	// A half-written rotation must not replace the certificate being served.
+	writeFile(t, keyFile, "not a key", time.Now())
+	if err := r.Reload(); err == nil {
+		t.Error("Reload() should fail for an invalid key")
+	}
+	if got := commonName(t, r); got != "second" {
+		t.Errorf("certificate after failed reload = %q, want second", got)
+	}
+}
+
+func commonName(t *testing.T, r *CertificateReloader) string {
+	t.Helper()
+
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeKeyPair(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, certFile, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), time.Now())
+	writeFile(t, keyFile, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})), time.Now())
+}
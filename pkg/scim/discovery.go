@@ -0,0 +1,186 @@
+package scim
+
+// Attribute describes an attribute of a schema (RFC 7643 section 7).
+type Attribute struct {
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
+	MultiValued   bool        `json:"multiValued"`
+	Description   string      `json:"description,omitempty"`
+	Required      bool        `json:"required"`
+	CaseExact     bool        `json:"caseExact"`
+	Mutability    string      `json:"mutability"`
+	Returned      string      `json:"returned"`
+	Uniqueness    string      `json:"uniqueness"`
+	SubAttributes []Attribute `json:"subAttributes,omitempty"`
+}
+
+type Schema struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Attributes  []Attribute `json:"attributes"`
+	Meta        *Meta       `json:"meta,omitempty"`
+}
+
+type ResourceType struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Endpoint    string   `json:"endpoint"`
+	Description string   `json:"description"`
+	Schema      string   `json:"schema"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+type supported struct {
+	Supported bool `json:"supported"`
+}
+
+type filterSupported struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type bulkSupported struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+type AuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+type ServiceProviderConfig struct {
+	Schemas               []string               `json:"schemas"`
+	Patch                 supported              `json:"patch"`
+	Bulk                  bulkSupported          `json:"bulk"`
+	Filter                filterSupported        `json:"filter"`
+	ChangePassword        supported              `json:"changePassword"`
+	Sort                  supported              `json:"sort"`
+	ETag                  supported              `json:"etag"`
+	AuthenticationSchemes []AuthenticationScheme `json:"authenticationSchemes"`
+	Meta                  *Meta                  `json:"meta,omitempty"`
+}
+
+func newServiceProviderConfig() ServiceProviderConfig {
+	return ServiceProviderConfig{
+		Schemas: []string{SchemaServiceProviderConfig},
+		Patch:   supported{Supported: true},
+		Filter:  filterSupported{Supported: true, MaxResults: maxCount},
+		AuthenticationSchemes: []AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer Token",
+			Description: "Authentication with the SCIM bearer token configured on the server",
+			Primary:     true,
+		}},
+		Meta: &Meta{ResourceType: "ServiceProviderConfig"},
+	}
+}
+
+func stringAttribute(name, description string, required, caseExact bool, uniqueness string) Attribute {
+	return Attribute{
+		Name:        name,
+		Type:        "string",
+		Description: description,
+		Required:    required,
+		CaseExact:   caseExact,
+		Mutability:  "readWrite",
+		Returned:    "default",
+		Uniqueness:  uniqueness,
+	}
+}
+
+func idAttributes() []Attribute {
+	id := stringAttribute("id", "Identifier assigned by the service provider.", false, true, "server")
+	id.Mutability = "readOnly"
+	id.Returned = "always"
+	return []Attribute{
+		id,
+		stringAttribute("externalId", "Identifier assigned by the provisioning client.", false, true, "none"),
+	}
+}
+
+func newSchemas() []Schema {
+	emailValue := stringAttribute("value", "Email address.", false, false, "none")
+	emails := Attribute{
+		Name:        "emails",
+		Type:        "complex",
+		MultiValued: true,
+		Description: "Email addresses of the user. The primary email is always the userName.",
+		Mutability:  "readOnly",
+		Returned:    "default",
+		Uniqueness:  "none",
+		SubAttributes: []Attribute{
+			emailValue,
+			stringAttribute("type", "Type of the email address.", false, false, "none"),
+			{Name: "primary", Type: "boolean", Mutability: "readOnly", Returned: "default", Uniqueness: "none"},
+		},
+	}
+	user := Schema{
+		Schemas:     []string{SchemaSchema},
+		ID:          SchemaUser,
+		Name:        "User",
+		Description: "Admin user. userName is the email address of the user.",
+		Attributes: append(idAttributes(),
+			stringAttribute("userName", "Email address that identifies the user.", true, false, "server"),
+			stringAttribute("displayName", "Name of the user suitable for display.", false, false, "none"),
+			emails,
+			Attribute{Name: "active", Type: "boolean", Description: "Whether the user is active in the identity provider.", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+		),
+		Meta: &Meta{ResourceType: "Schema"},
+	}
+
+	memberValue := stringAttribute("value", "Identifier of the member user.", false, true, "none")
+	memberValue.Mutability = "immutable"
+	memberDisplay := stringAttribute("display", "Email address of the member user.", false, false, "none")
+	memberDisplay.Mutability = "readOnly"
+	group := Schema{
+		Schemas:     []string{SchemaSchema},
+		ID:          SchemaGroup,
+		Name:        "Group",
+		Description: "User group of the provisioning project.",
+		Attributes: append(idAttributes(),
+			stringAttribute("displayName", "Name of the user group.", true, false, "server"),
+			Attribute{
+				Name:          "members",
+				Type:          "complex",
+				MultiValued:   true,
+				Description:   "Users that belong to the group.",
+				Mutability:    "readWrite",
+				Returned:      "default",
+				Uniqueness:    "none",
+				SubAttributes: []Attribute{memberValue, memberDisplay},
+			},
+		),
+		Meta: &Meta{ResourceType: "Schema"},
+	}
+	return []Schema{user, group}
+}
+
+func newResourceTypes() []ResourceType {
+	return []ResourceType{
+		{
+			Schemas:     []string{SchemaResourceType},
+			ID:          "User",
+			Name:        "User",
+			Endpoint:    "/Users",
+			Description: "Admin users",
+			Schema:      SchemaUser,
+			Meta:        &Meta{ResourceType: "ResourceType"},
+		},
+		{
+			Schemas:     []string{SchemaResourceType},
+			ID:          "Group",
+			Name:        "Group",
+			Endpoint:    "/Groups",
+			Description: "User groups of the provisioning project",
+			Schema:      SchemaGroup,
+			Meta:        &Meta{ResourceType: "ResourceType"},
+		},
+	}
+}
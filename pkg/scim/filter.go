@@ -0,0 +1,162 @@
+package scim
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	OperatorEqual      = "eq"
+	OperatorContains   = "co"
+	OperatorStartsWith = "sw"
+)
+
+// Comparison is a single "attribute operator value" expression of a filter.
+type Comparison struct {
+	// Attribute is the lower-cased attribute path without schema URN, e.g. "username" or "emails.value".
+	Attribute string
+	Operator  string
+	Value     string
+}
+
+// Filter is a conjunction of comparisons. Only the eq, co and sw operators joined by "and"
+// are supported, which covers the queries that identity providers issue.
+type Filter []Comparison
+
+// ParseFilter parses the filter query parameter of a list request.
+func ParseFilter(s string) (Filter, error) {
+	tokens, err := tokenizeFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errBadRequest(ErrorTypeInvalidFilter, "filter is empty")
+	}
+
+	var filter Filter
+	for len(tokens) > 0 {
+		if len(tokens) < 3 {
+			return nil, errBadRequest(ErrorTypeInvalidFilter, "incomplete filter expression")
+		}
+		attr, op, value := tokens[0], strings.ToLower(tokens[1].text), tokens[2]
+		if attr.quoted || op == "" || tokens[1].quoted {
+			return nil, errBadRequest(ErrorTypeInvalidFilter, "expected attribute and operator")
+		}
+		switch op {
+		case OperatorEqual, OperatorContains, OperatorStartsWith:
+		default:
+			return nil, errBadRequest(ErrorTypeInvalidFilter, "operator %q is not supported", tokens[1].text)
+		}
+		name, err := normalizeAttribute(attr.text)
+		if err != nil {
+			return nil, errBadRequest(ErrorTypeInvalidFilter, "%s", err.Detail)
+		}
+		if !value.quoted && value.text != "true" && value.text != "false" {
+			return nil, errBadRequest(ErrorTypeInvalidFilter, "value of %s must be a string or boolean", attr.text)
+		}
+		filter = append(filter, Comparison{Attribute: name, Operator: op, Value: value.text})
+
+		tokens = tokens[3:]
+		if len(tokens) == 0 {
+			break
+		}
+		if tokens[0].quoted || !strings.EqualFold(tokens[0].text, "and") {
+			return nil, errBadRequest(ErrorTypeInvalidFilter, "only \"and\" is supported to combine expressions, got %q", tokens[0].text)
+		}
+		tokens = tokens[1:]
+	}
+	return filter, nil
+}
+
+// Match reports whether v satisfies the comparison.
+func (c Comparison) Match(v string, caseExact bool) bool {
+	want := c.Value
+	if !caseExact {
+		v, want = strings.ToLower(v), strings.ToLower(want)
+	}
+	switch c.Operator {
+	case OperatorEqual:
+		return v == want
+	case OperatorContains:
+		return strings.Contains(v, want)
+	case OperatorStartsWith:
+		return strings.HasPrefix(v, want)
+	}
+	return false
+}
+
+// LikePattern returns a LIKE pattern equivalent to the comparison.
+func (c Comparison) LikePattern() string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(c.Value)
+	switch c.Operator {
+	case OperatorContains:
+		return "%" + escaped + "%"
+	case OperatorStartsWith:
+		return escaped + "%"
+	}
+	return escaped
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizeFilter(s string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			return nil, errBadRequest(ErrorTypeInvalidFilter, "grouping is not supported")
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, errBadRequest(ErrorTypeInvalidFilter, "unterminated string")
+			}
+			var v string
+			if err := json.Unmarshal([]byte(s[i:end+1]), &v); err != nil {
+				return nil, errBadRequest(ErrorTypeInvalidFilter, "invalid string %s", s[i:end+1])
+			}
+			tokens = append(tokens, filterToken{text: v, quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for end < len(s) && !strings.ContainsRune(" \t()[]\"", rune(s[end])) {
+				end++
+			}
+			tokens = append(tokens, filterToken{text: s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// normalizeAttribute lower-cases an attribute path and strips the schema URN of the core
+// user or group schema, e.g. "urn:ietf:params:scim:schemas:core:2.0:User:userName" becomes "username".
+func normalizeAttribute(attr string) (string, *Error) {
+	lower := strings.ToLower(attr)
+	if strings.HasPrefix(lower, "urn:") {
+		stripped := false
+		for _, schema := range []string{SchemaUser, SchemaGroup} {
+			if rest, ok := strings.CutPrefix(lower, strings.ToLower(schema)+":"); ok {
+				lower, stripped = rest, true
+				break
+			}
+		}
+		if !stripped {
+			return "", errBadRequest(ErrorTypeInvalidPath, "attribute %q has an unsupported schema", attr)
+		}
+	}
+	if lower == "" {
+		return "", errBadRequest(ErrorTypeInvalidPath, "attribute is empty")
+	}
+	return lower, nil
+}
@@ -0,0 +1,114 @@
+package scim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		filter string
+		want   Filter
+	}{
+		{
+			name:   "eq",
+			filter: `userName eq "alice@example.com"`,
+			want:   Filter{{Attribute: "username", Operator: "eq", Value: "alice@example.com"}},
+		},
+		{
+			name:   "case insensitive operator and schema urn",
+			filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName EQ "a"`,
+			want:   Filter{{Attribute: "username", Operator: "eq", Value: "a"}},
+		},
+		{
+			name:   "sub-attribute and escaped quote",
+			filter: `emails.value co "a\"b c"`,
+			want:   Filter{{Attribute: "emails.value", Operator: "co", Value: `a"b c`}},
+		},
+		{
+			name:   "and",
+			filter: `displayName sw "dev" and externalId eq "123"`,
+			want: Filter{
+				{Attribute: "displayname", Operator: "sw", Value: "dev"},
+				{Attribute: "externalid", Operator: "eq", Value: "123"},
+			},
+		},
+		{
+			name:   "boolean",
+			filter: `active eq true`,
+			want:   Filter{{Attribute: "active", Operator: "eq", Value: "true"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) returned error: %v", tt.filter, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, filter := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName gt "a"`,
+		`userName pr`,
+		`userName eq "a" or userName eq "b"`,
+		`(userName eq "a")`,
+		`emails[type eq "work"]`,
+		`userName eq "unterminated`,
+		`userName eq unquoted`,
+		`urn:example:ext:attr eq "a"`,
+	} {
+		_, err := ParseFilter(filter)
+		if err == nil {
+			t.Errorf("ParseFilter(%q) returned no error", filter)
+			continue
+		}
+		scimErr, ok := err.(*Error)
+		if !ok || scimErr.Status != "400" || scimErr.ScimType != ErrorTypeInvalidFilter {
+			t.Errorf("ParseFilter(%q) returned %v, want an invalidFilter error", filter, err)
+		}
+	}
+}
+
+func TestComparison(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		cmp         Comparison
+		value       string
+		caseExact   bool
+		wantMatch   bool
+		wantPattern string
+	}{
+		{Comparison{Operator: "eq", Value: "Alice"}, "alice", false, true, "Alice"},
+		{Comparison{Operator: "eq", Value: "Alice"}, "alice", true, false, "Alice"},
+		{Comparison{Operator: "co", Value: "50%_"}, "a50%_b", true, true, `%50\%\_%`},
+		{Comparison{Operator: "sw", Value: `a\`}, `a\b`, true, true, `a\\%`},
+		{Comparison{Operator: "sw", Value: "b"}, "ab", true, false, "b%"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cmp.Match(tt.value, tt.caseExact); got != tt.wantMatch {
+			t.Errorf("%+v.Match(%q, %v) = %v, want %v", tt.cmp, tt.value, tt.caseExact, got, tt.wantMatch)
+		}
+		if got := tt.cmp.LikePattern(); got != tt.wantPattern {
+			t.Errorf("%+v.LikePattern() = %q, want %q", tt.cmp, got, tt.wantPattern)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+const maxGroupNameLength = 64
+
+// groupFilterColumns maps filterable group attributes to the pattern parameters of ListSCIMGroups.
+var groupFilterColumns = map[string]string{
+	"displayname": "name",
+	"externalid":  "externalId",
+}
+
+func (s *Server) listGroups(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	startIndex, count, err := parseListParams(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var patterns map[string]pgtype.Text
+	id, matchable := pgtype.UUID{}, true
+	if v := c.QueryParam("filter"); v != "" {
+		filter, err := ParseFilter(v)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		if patterns, id, matchable, err = filterParams(filter, groupFilterColumns); err != nil {
+			return s.writeError(c, err)
+		}
+	}
+	if !matchable {
+		return writeJSON(c, http.StatusOK, NewListResponse(0, startIndex, []any{}))
+	}
+
+	total, err := s.queries.CountSCIMGroups(ctx, admindb.CountSCIMGroupsParams{
+		ProjectID:         proj.ID,
+		DisplayID:         id,
+		NamePattern:       patterns["name"],
+		ExternalIDPattern: patterns["externalId"],
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to count user groups"))
+	}
+	rows, err := s.queries.ListSCIMGroups(ctx, admindb.ListSCIMGroupsParams{
+		ProjectID:         proj.ID,
+		Limit:             int32(count),
+		Offset:            int32(startIndex - 1),
+		DisplayID:         id,
+		NamePattern:       patterns["name"],
+		ExternalIDPattern: patterns["externalId"],
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list user groups"))
+	}
+
+	// Identity providers usually exclude members when listing groups, which avoids a query per group.
+	withMembers := !excludesAttribute(c.QueryParam("excludedAttributes"), "members")
+	resources := make([]any, 0, len(rows))
+	for _, row := range rows {
+		var members []admindb.TacokumoAdminUser
+		if withMembers {
+			if members, err = s.queries.ListUserGroupMembers(ctx, row.ID); err != nil {
+				return s.writeError(c, errors.Wrapf(err, "failed to list user group members"))
+			}
+		}
+		resources = append(resources, toGroup(c, row, members))
+	}
+	return writeJSON(c, http.StatusOK, NewListResponse(total, startIndex, resources))
+}
+
+func (s *Server) getGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	id, err := parseID(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	row, members, err := loadGroup(ctx, s.queries, proj, id)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	if excludesAttribute(c.QueryParam("excludedAttributes"), "members") {
+		members = nil
+	}
+	return writeJSON(c, http.StatusOK, toGroup(c, row, members))
+}
+
+func (s *Server) createGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var req Group
+	if err := decodeBody(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := validateGroup(req); err != nil {
+		return s.writeError(c, err)
+	}
+
+	var row admindb.ListSCIMGroupsRow
+	var members []admindb.TacokumoAdminUser
+	var evs []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		if err := project.Lock(ctx, q, proj.ID); err != nil {
+			return err
+		}
+		displayID, err := q.CreateUserGroup(ctx, admindb.CreateUserGroupParams{
+			ProjectID:   proj.ID,
+			Name:        req.DisplayName,
+			Description: "",
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create user group")
+		}
+		row, _, err = loadGroup(ctx, q, proj, displayID)
+		if err != nil {
+			return err
+		}
+		if evs, err = saveGroup(ctx, q, proj, row, nil, req); err != nil {
+			return err
+		}
+		// Personal projects cannot have user groups.
+		if err := project.Check(ctx, q, proj.ID); err != nil {
+			return err
+		}
+		if row, members, err = loadGroup(ctx, q, proj, displayID); err != nil {
+			return err
+		}
+		evs, err = s.recordEvents(ctx, q, append([]events.Event{{
+			Kind:       events.KindUserGroup,
+			Action:     events.ActionCreated,
+			ResourceID: row.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		}}, evs...)...)
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, evs)
+	resp := toGroup(c, row, members)
+	c.Response().Header().Set(echo.HeaderLocation, resp.Meta.Location)
+	return writeJSON(c, http.StatusCreated, resp)
+}
+
+func (s *Server) replaceGroup(c echo.Context) error {
+	var req Group
+	if err := decodeBody(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	return s.updateGroup(c, func(g *Group) error {
+		*g = req
+		return nil
+	})
+}
+
+func (s *Server) patchGroup(c echo.Context) error {
+	var req PatchRequest
+	if err := decodeBody(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := req.Validate(); err != nil {
+		return s.writeError(c, err)
+	}
+	return s.updateGroup(c, func(g *Group) error {
+		return ApplyGroupPatch(g, req.Operations)
+	})
+}
+
+// updateGroup loads the group, lets modify change it and stores the result.
+func (s *Server) updateGroup(c echo.Context, modify func(g *Group) error) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	id, err := parseID(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var row admindb.ListSCIMGroupsRow
+	var members []admindb.TacokumoAdminUser
+	var evs []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		if err := project.Lock(ctx, q, proj.ID); err != nil {
+			return err
+		}
+		current, currentMembers, err := loadGroup(ctx, q, proj, id)
+		if err != nil {
+			return err
+		}
+		group := toGroup(c, current, currentMembers)
+		if err := modify(&group); err != nil {
+			return err
+		}
+		if err := validateGroup(group); err != nil {
+			return err
+		}
+		if evs, err = saveGroup(ctx, q, proj, current, currentMembers, group); err != nil {
+			return err
+		}
+		if row, members, err = loadGroup(ctx, q, proj, id); err != nil {
+			return err
+		}
+		evs, err = s.recordEvents(ctx, q, append([]events.Event{{
+			Kind:       events.KindUserGroup,
+			Action:     events.ActionUpdated,
+			ResourceID: row.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+		}}, evs...)...)
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, evs)
+	return writeJSON(c, http.StatusOK, toGroup(c, row, members))
+}
+
+func (s *Server) deleteGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	proj, err := s.loadProject(ctx)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	id, err := parseID(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var pending []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		if err := project.Lock(ctx, q, proj.ID); err != nil {
+			return err
+		}
+		affected, err := q.DeleteUserGroupByDisplayID(ctx, admindb.DeleteUserGroupByDisplayIDParams{
+			ProjectID: proj.ID,
+			DisplayID: id,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete user group")
+		}
+		if affected == 0 {
+			return NewError(http.StatusNotFound, "", "group %s not found", c.Param("id"))
+		}
+		// The last owner group of a shared project cannot be deleted.
+		if err := project.Check(ctx, q, proj.ID); err != nil {
+			return err
+		}
+		pending, err = s.recordEvents(ctx, q, events.Event{
+			Kind:       events.KindUserGroup,
+			Action:     events.ActionDeleted,
+			ResourceID: id.String(),
+			ProjectID:  proj.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, pending)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// saveGroup stores the name, external ID and members of group and returns the membership events.
+func saveGroup(
+	ctx context.Context,
+	q *admindb.Queries,
+	proj admindb.TacokumoAdminProject,
+	current admindb.ListSCIMGroupsRow,
+	currentMembers []admindb.TacokumoAdminUser,
+	group Group,
+) ([]events.Event, error) {
+	if group.DisplayName != current.Name {
+		if _, err := q.UpdateUserGroup(ctx, admindb.UpdateUserGroupParams{
+			ProjectID:   proj.ID,
+			DisplayID:   current.DisplayID,
+			Name:        group.DisplayName,
+			Description: current.Description,
+		}); err != nil {
+			return nil, errors.Wrapf(err, "failed to update user group")
+		}
+	}
+	if err := q.UpsertSCIMGroup(ctx, admindb.UpsertSCIMGroupParams{
+		UsergroupID: current.ID,
+		ExternalID:  pgtype.Text{String: group.ExternalID, Valid: group.ExternalID != ""},
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to upsert scim group")
+	}
+
+	var evs []events.Event
+	memberEvent := func(action events.Action, user admindb.TacokumoAdminUser) events.Event {
+		return events.Event{
+			Kind:       events.KindUserGroupMember,
+			Action:     action,
+			ResourceID: current.DisplayID.String(),
+			ProjectID:  proj.DisplayID.String(),
+			MemberID:   user.DisplayID.String(),
+			MemberKind: events.MemberKindUser,
+		}
+	}
+
+	for _, m := range group.Members {
+		if slices.ContainsFunc(currentMembers, func(u admindb.TacokumoAdminUser) bool { return u.DisplayID.String() == m.Value }) {
+			continue
+		}
+		user, err := resolveMember(ctx, q, m.Value)
+		if err != nil {
+			return nil, err
+		}
+		if err := q.AddUserToUserGroup(ctx, admindb.AddUserToUserGroupParams{UserID: user.ID, UsergroupID: current.ID}); err != nil {
+			return nil, errors.Wrapf(err, "failed to add user to user group")
+		}
+		evs = append(evs, memberEvent(events.ActionCreated, user))
+	}
+	for _, user := range currentMembers {
+		if slices.ContainsFunc(group.Members, func(m Member) bool { return m.Value == user.DisplayID.String() }) {
+			continue
+		}
+		if _, err := q.RemoveUserFromUserGroup(ctx, admindb.RemoveUserFromUserGroupParams{UserID: user.ID, UsergroupID: current.ID}); err != nil {
+			return nil, errors.Wrapf(err, "failed to remove user from user group")
+		}
+		evs = append(evs, memberEvent(events.ActionDeleted, user))
+	}
+	return evs, nil
+}
+
+func resolveMember(ctx context.Context, q *admindb.Queries, value string) (admindb.TacokumoAdminUser, error) {
+	id := pgtype.UUID{}
+	if err := id.Scan(value); err != nil {
+		return admindb.TacokumoAdminUser{}, errBadRequest(ErrorTypeInvalidValue, "member %s is not a user", value)
+	}
+	user, err := q.GetUserByDisplayID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, errBadRequest(ErrorTypeInvalidValue, "member %s is not a user", value)
+		}
+		return user, errors.Wrapf(err, "failed to get user by display id")
+	}
+	return user, nil
+}
+
+func validateGroup(g Group) error {
+	if g.DisplayName == "" {
+		return errBadRequest(ErrorTypeInvalidValue, "displayName is required")
+	}
+	if len(g.DisplayName) > maxGroupNameLength {
+		return errBadRequest(ErrorTypeInvalidValue, "displayName must be at most %d characters", maxGroupNameLength)
+	}
+	if len(g.ExternalID) > maxUserNameLength {
+		return errBadRequest(ErrorTypeInvalidValue, "externalId must be at most %d characters", maxUserNameLength)
+	}
+	return nil
+}
+
+// loadProject returns the project that provisioned groups belong to.
+func (s *Server) loadProject(ctx context.Context) (admindb.TacokumoAdminProject, error) {
+	proj, err := s.queries.GetProjectByDisplayID(ctx, s.projectID)
+	if err != nil {
+		// A missing project is a configuration error, so it must not be reported as a missing resource.
+		return proj, errors.Newf("failed to get scim project %s: %s", s.projectID.String(), err.Error())
+	}
+	return proj, nil
+}
+
+func loadGroup(ctx context.Context, q *admindb.Queries, proj admindb.TacokumoAdminProject, id pgtype.UUID) (admindb.ListSCIMGroupsRow, []admindb.TacokumoAdminUser, error) {
+	rows, err := q.ListSCIMGroups(ctx, admindb.ListSCIMGroupsParams{ProjectID: proj.ID, Limit: 1, DisplayID: id})
+	if err != nil {
+		return admindb.ListSCIMGroupsRow{}, nil, errors.Wrapf(err, "failed to get user group by display id")
+	}
+	if len(rows) == 0 {
+		return admindb.ListSCIMGroupsRow{}, nil, NewError(http.StatusNotFound, "", "group %s not found", id.String())
+	}
+	members, err := q.ListUserGroupMembers(ctx, rows[0].ID)
+	if err != nil {
+		return rows[0], nil, errors.Wrapf(err, "failed to list user group members")
+	}
+	return rows[0], members, nil
+}
+
+func excludesAttribute(param, attribute string) bool {
+	for _, a := range strings.Split(param, ",") {
+		if strings.EqualFold(strings.TrimSpace(a), attribute) {
+			return true
+		}
+	}
+	return false
+}
+
+func toGroup(c echo.Context, row admindb.ListSCIMGroupsRow, members []admindb.TacokumoAdminUser) Group {
+	group := Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          row.DisplayID.String(),
+		ExternalID:  row.ExternalID.String,
+		DisplayName: row.Name,
+		Meta: &Meta{
+			ResourceType: "Group",
+			Created:      timePtr(row.CreatedAt),
+			LastModified: timePtr(row.UpdatedAt),
+			Location:     baseURL(c) + "/Groups/" + row.DisplayID.String(),
+		},
+	}
+	for _, user := range members {
+		group.Members = append(group.Members, Member{
+			Value:   user.DisplayID.String(),
+			Ref:     baseURL(c) + "/Users/" + user.DisplayID.String(),
+			Display: user.Email,
+			Type:    "User",
+		})
+	}
+	return group
+}
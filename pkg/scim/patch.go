@@ -0,0 +1,321 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	PatchOpAdd     = "add"
+	PatchOpRemove  = "remove"
+	PatchOpReplace = "replace"
+)
+
+// PatchRequest is the body of a PATCH request.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is one operation of a PATCH request. Op is matched case-insensitively
+// because some identity providers send "Replace" instead of "replace".
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Validate checks the message schema and the operation names and normalizes the latter to lower case.
+func (r *PatchRequest) Validate() error {
+	if !slices.Contains(r.Schemas, SchemaPatchOp) {
+		return errBadRequest(ErrorTypeInvalidSyntax, "schemas must contain %s", SchemaPatchOp)
+	}
+	if len(r.Operations) == 0 {
+		return errBadRequest(ErrorTypeInvalidSyntax, "Operations must not be empty")
+	}
+	for i := range r.Operations {
+		op := strings.ToLower(r.Operations[i].Op)
+		switch op {
+		case PatchOpAdd, PatchOpReplace:
+			if len(r.Operations[i].Value) == 0 {
+				return errBadRequest(ErrorTypeInvalidValue, "%s operation requires a value", op)
+			}
+		case PatchOpRemove:
+			if r.Operations[i].Path == "" {
+				return errBadRequest(ErrorTypeNoTarget, "remove operation requires a path")
+			}
+		default:
+			return errBadRequest(ErrorTypeInvalidSyntax, "unsupported operation %q", r.Operations[i].Op)
+		}
+		r.Operations[i].Op = op
+	}
+	return nil
+}
+
+// Path is a parsed PATCH path such as "members[value eq \"id\"]" or "name.givenName".
+type Path struct {
+	// Attribute is the lower-cased attribute name without schema URN.
+	Attribute string
+	// Filter selects values of a multi-valued attribute.
+	Filter Filter
+	// SubAttribute is the lower-cased sub-attribute name, if any.
+	SubAttribute string
+}
+
+func ParsePath(s string) (Path, error) {
+	var path Path
+	attr, rest := s, ""
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		j := strings.LastIndexByte(s, ']')
+		if j < i {
+			return path, errBadRequest(ErrorTypeInvalidPath, "invalid path %q", s)
+		}
+		filter, err := ParseFilter(s[i+1 : j])
+		if err != nil {
+			return path, errBadRequest(ErrorTypeInvalidPath, "invalid filter in path %q", s)
+		}
+		path.Filter = filter
+		attr, rest = s[:i], s[j+1:]
+		if rest != "" && !strings.HasPrefix(rest, ".") {
+			return path, errBadRequest(ErrorTypeInvalidPath, "invalid path %q", s)
+		}
+		rest = strings.TrimPrefix(rest, ".")
+	}
+
+	name, scimErr := normalizeAttribute(attr)
+	if scimErr != nil {
+		return path, scimErr
+	}
+	if path.Filter == nil {
+		name, rest, _ = strings.Cut(name, ".")
+	}
+	path.Attribute = name
+	path.SubAttribute = strings.ToLower(rest)
+	return path, nil
+}
+
+// ApplyUserPatch applies the operations to u. Attributes that are not part of User are ignored.
+func ApplyUserPatch(u *User, ops []PatchOperation) error {
+	for _, op := range ops {
+		if op.Path == "" {
+			values, err := decodeObject(op.Value)
+			if err != nil {
+				return err
+			}
+			for key, raw := range values {
+				path, err := ParsePath(key)
+				if err != nil {
+					return err
+				}
+				if err := applyUserAttribute(u, op.Op, path, raw); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		path, err := ParsePath(op.Path)
+		if err != nil {
+			return err
+		}
+		if err := applyUserAttribute(u, op.Op, path, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyUserAttribute(u *User, op string, path Path, raw json.RawMessage) error {
+	if path.SubAttribute != "" || path.Filter != nil {
+		// Sub-attributes such as name.givenName or emails[type eq "work"].value are not stored.
+		return nil
+	}
+	switch path.Attribute {
+	case "username":
+		if op == PatchOpRemove {
+			return NewError(http.StatusBadRequest, ErrorTypeMutability, "userName is required")
+		}
+		return decodeString(raw, "userName", &u.UserName)
+	case "displayname":
+		if op == PatchOpRemove {
+			u.DisplayName = ""
+			return nil
+		}
+		return decodeString(raw, "displayName", &u.DisplayName)
+	case "externalid":
+		if op == PatchOpRemove {
+			u.ExternalID = ""
+			return nil
+		}
+		return decodeString(raw, "externalId", &u.ExternalID)
+	case "active":
+		if op == PatchOpRemove {
+			return NewError(http.StatusBadRequest, ErrorTypeMutability, "active cannot be removed")
+		}
+		active, err := decodeBool(raw, "active")
+		if err != nil {
+			return err
+		}
+		u.Active = &active
+	}
+	return nil
+}
+
+// ApplyGroupPatch applies the operations to g. Attributes that are not part of Group are ignored.
+func ApplyGroupPatch(g *Group, ops []PatchOperation) error {
+	for _, op := range ops {
+		if op.Path == "" {
+			values, err := decodeObject(op.Value)
+			if err != nil {
+				return err
+			}
+			for key, raw := range values {
+				path, err := ParsePath(key)
+				if err != nil {
+					return err
+				}
+				if err := applyGroupAttribute(g, op.Op, path, raw); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		path, err := ParsePath(op.Path)
+		if err != nil {
+			return err
+		}
+		if err := applyGroupAttribute(g, op.Op, path, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyGroupAttribute(g *Group, op string, path Path, raw json.RawMessage) error {
+	switch path.Attribute {
+	case "displayname":
+		if op == PatchOpRemove {
+			return NewError(http.StatusBadRequest, ErrorTypeMutability, "displayName is required")
+		}
+		return decodeString(raw, "displayName", &g.DisplayName)
+	case "externalid":
+		if op == PatchOpRemove {
+			g.ExternalID = ""
+			return nil
+		}
+		return decodeString(raw, "externalId", &g.ExternalID)
+	case "members":
+		return applyMembers(g, op, path, raw)
+	}
+	return nil
+}
+
+func applyMembers(g *Group, op string, path Path, raw json.RawMessage) error {
+	if path.SubAttribute != "" {
+		return errBadRequest(ErrorTypeInvalidPath, "members sub-attributes cannot be modified")
+	}
+
+	if op == PatchOpRemove {
+		switch {
+		case path.Filter != nil:
+			for _, c := range path.Filter {
+				if c.Attribute != "value" {
+					return errBadRequest(ErrorTypeInvalidFilter, "members can only be selected by value")
+				}
+			}
+			g.Members = slices.DeleteFunc(g.Members, func(m Member) bool {
+				return matchAll(path.Filter, m.Value)
+			})
+		case len(raw) > 0:
+			members, err := decodeMembers(raw)
+			if err != nil {
+				return err
+			}
+			g.Members = slices.DeleteFunc(g.Members, func(m Member) bool {
+				return slices.ContainsFunc(members, func(r Member) bool { return r.Value == m.Value })
+			})
+		default:
+			g.Members = nil
+		}
+		return nil
+	}
+
+	if path.Filter != nil {
+		return errBadRequest(ErrorTypeInvalidPath, "%s operation cannot use a value filter on members", op)
+	}
+	members, err := decodeMembers(raw)
+	if err != nil {
+		return err
+	}
+	if op == PatchOpReplace {
+		g.Members = nil
+	}
+	for _, m := range members {
+		if !slices.ContainsFunc(g.Members, func(e Member) bool { return e.Value == m.Value }) {
+			g.Members = append(g.Members, m)
+		}
+	}
+	return nil
+}
+
+func matchAll(filter Filter, v string) bool {
+	for _, c := range filter {
+		if !c.Match(v, true) {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeObject(raw json.RawMessage) (map[string]json.RawMessage, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil || values == nil {
+		return nil, errBadRequest(ErrorTypeInvalidValue, "value must be an object when path is omitted")
+	}
+	return values, nil
+}
+
+func decodeString(raw json.RawMessage, name string, dst *string) error {
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return errBadRequest(ErrorTypeInvalidValue, "%s must be a string", name)
+	}
+	return nil
+}
+
+// decodeBool accepts JSON booleans as well as the strings "true" and "false" in any case,
+// which some identity providers send.
+func decodeBool(raw json.RawMessage, name string) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if b, err := strconv.ParseBool(strings.ToLower(s)); err == nil {
+			return b, nil
+		}
+	}
+	return false, errBadRequest(ErrorTypeInvalidValue, "%s must be a boolean", name)
+}
+
+// decodeMembers accepts a list of members or a single member.
+func decodeMembers(raw json.RawMessage) ([]Member, error) {
+	var members []Member
+	if err := json.Unmarshal(raw, &members); err != nil {
+		var m Member
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, errBadRequest(ErrorTypeInvalidValue, "members must be a list of objects with a value")
+		}
+		members = []Member{m}
+	}
+	for _, m := range members {
+		if m.Value == "" {
+			return nil, errBadRequest(ErrorTypeInvalidValue, "member value is required")
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,195 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want Path
+	}{
+		{"userName", Path{Attribute: "username"}},
+		{"name.givenName", Path{Attribute: "name", SubAttribute: "givenname"}},
+		{"urn:ietf:params:scim:schemas:core:2.0:User:displayName", Path{Attribute: "displayname"}},
+		{
+			`members[value eq "u1"]`,
+			Path{Attribute: "members", Filter: Filter{{Attribute: "value", Operator: "eq", Value: "u1"}}},
+		},
+		{
+			`emails[type eq "work"].value`,
+			Path{Attribute: "emails", Filter: Filter{{Attribute: "type", Operator: "eq", Value: "work"}}, SubAttribute: "value"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePath(tt.path)
+		if err != nil {
+			t.Errorf("ParsePath(%q) returned error: %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePath(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+
+	for _, path := range []string{"", `members[value eq "u1"`, `members[value eq "u1"]x`, `members[value]`} {
+		if _, err := ParsePath(path); err == nil {
+			t.Errorf("ParsePath(%q) returned no error", path)
+		}
+	}
+}
+
+func TestPatchRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := PatchRequest{
+		Schemas:    []string{SchemaPatchOp},
+		Operations: []PatchOperation{{Op: "Replace", Path: "active", Value: json.RawMessage(`false`)}},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() returned error: %v", err)
+	}
+	if valid.Operations[0].Op != PatchOpReplace {
+		t.Errorf("Validate() did not normalize op, got %q", valid.Operations[0].Op)
+	}
+
+	for name, req := range map[string]PatchRequest{
+		"missing schema":    {Operations: []PatchOperation{{Op: "add", Value: json.RawMessage(`{}`)}}},
+		"no operations":     {Schemas: []string{SchemaPatchOp}},
+		"unknown op":        {Schemas: []string{SchemaPatchOp}, Operations: []PatchOperation{{Op: "move", Path: "a"}}},
+		"add without value": {Schemas: []string{SchemaPatchOp}, Operations: []PatchOperation{{Op: "add", Path: "a"}}},
+		"remove no path":    {Schemas: []string{SchemaPatchOp}, Operations: []PatchOperation{{Op: "remove"}}},
+	} {
+		if err := req.Validate(); err == nil {
+			t.Errorf("%s: Validate() returned no error", name)
+		}
+	}
+}
+
+func TestApplyUserPatch(t *testing.T) {
+	t.Parallel()
+
+	active := true
+	u := User{UserName: "alice@example.com", DisplayName: "Alice", ExternalID: "ext-1", Active: &active}
+	ops := []PatchOperation{
+		{Op: "replace", Path: "active", Value: json.RawMessage(`"False"`)},
+		{Op: "replace", Value: json.RawMessage(`{"displayName":"Alice A.","name.givenName":"Alice","urn:ietf:params:scim:schemas:core:2.0:User:userName":"alice.a@example.com"}`)},
+		{Op: "remove", Path: "externalId"},
+		{Op: "replace", Path: `emails[type eq "work"].value`, Value: json.RawMessage(`"ignored@example.com"`)},
+	}
+	if err := ApplyUserPatch(&u, ops); err != nil {
+		t.Fatalf("ApplyUserPatch() returned error: %v", err)
+	}
+	if *u.Active || u.DisplayName != "Alice A." || u.UserName != "alice.a@example.com" || u.ExternalID != "" {
+		t.Errorf("unexpected user after patch: %+v", u)
+	}
+
+	for name, op := range map[string]PatchOperation{
+		"remove userName":   {Op: "remove", Path: "userName"},
+		"invalid active":    {Op: "replace", Path: "active", Value: json.RawMessage(`"maybe"`)},
+		"non string name":   {Op: "replace", Path: "displayName", Value: json.RawMessage(`1`)},
+		"non object value":  {Op: "replace", Value: json.RawMessage(`"x"`)},
+		"unsupported urn":   {Op: "add", Path: "urn:example:ext:attr", Value: json.RawMessage(`"x"`)},
+		"remove active":     {Op: "remove", Path: "active"},
+		"malformed filters": {Op: "add", Path: `emails[type]`, Value: json.RawMessage(`"x"`)},
+	} {
+		if err := ApplyUserPatch(&User{UserName: "a@example.com"}, []PatchOperation{op}); err == nil {
+			t.Errorf("%s: ApplyUserPatch() returned no error", name)
+		}
+	}
+}
+
+func TestApplyGroupPatch(t *testing.T) {
+	t.Parallel()
+
+	members := func(values ...string) []Member {
+		var ms []Member
+		for _, v := range values {
+			ms = append(ms, Member{Value: v})
+		}
+		return ms
+	}
+
+	tests := []struct {
+		name        string
+		ops         []PatchOperation
+		wantMembers []Member
+		wantName    string
+	}{
+		{
+			name:        "add members",
+			ops:         []PatchOperation{{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"u2"},{"value":"u3"}]`)}},
+			wantMembers: members("u1", "u2", "u3"),
+		},
+		{
+			name:        "add single member object",
+			ops:         []PatchOperation{{Op: "add", Path: "members", Value: json.RawMessage(`{"value":"u3"}`)}},
+			wantMembers: members("u1", "u2", "u3"),
+		},
+		{
+			name:        "remove member by filter",
+			ops:         []PatchOperation{{Op: "remove", Path: `members[value eq "u1"]`}},
+			wantMembers: members("u2"),
+		},
+		{
+			name:        "remove members by value",
+			ops:         []PatchOperation{{Op: "remove", Path: "members", Value: json.RawMessage(`[{"value":"u2"}]`)}},
+			wantMembers: members("u1"),
+		},
+		{
+			name:        "remove all members",
+			ops:         []PatchOperation{{Op: "remove", Path: "members"}},
+			wantMembers: nil,
+		},
+		{
+			name:        "replace members",
+			ops:         []PatchOperation{{Op: "replace", Path: "members", Value: json.RawMessage(`[{"value":"u3"}]`)}},
+			wantMembers: members("u3"),
+		},
+		{
+			name:        "replace without path",
+			ops:         []PatchOperation{{Op: "replace", Value: json.RawMessage(`{"id":"g1","displayName":"renamed"}`)}},
+			wantMembers: members("u1", "u2"),
+			wantName:    "renamed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			g := Group{DisplayName: "devs", Members: members("u1", "u2")}
+			if err := ApplyGroupPatch(&g, tt.ops); err != nil {
+				t.Fatalf("ApplyGroupPatch() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(g.Members, tt.wantMembers) {
+				t.Errorf("members = %+v, want %+v", g.Members, tt.wantMembers)
+			}
+			wantName := tt.wantName
+			if wantName == "" {
+				wantName = "devs"
+			}
+			if g.DisplayName != wantName {
+				t.Errorf("displayName = %q, want %q", g.DisplayName, wantName)
+			}
+		})
+	}
+
+	for name, op := range map[string]PatchOperation{
+		"remove displayName":      {Op: "remove", Path: "displayName"},
+		"member without value":    {Op: "add", Path: "members", Value: json.RawMessage(`[{"display":"x"}]`)},
+		"add with filter":         {Op: "add", Path: `members[value eq "u1"]`, Value: json.RawMessage(`{"value":"u1"}`)},
+		"filter on other attr":    {Op: "remove", Path: `members[display eq "x"]`},
+		"members sub-attributes":  {Op: "replace", Path: "members.display", Value: json.RawMessage(`"x"`)},
+		"non object without path": {Op: "add", Value: json.RawMessage(`[]`)},
+	} {
+		if err := ApplyGroupPatch(&Group{DisplayName: "devs"}, []PatchOperation{op}); err == nil {
+			t.Errorf("%s: ApplyGroupPatch() returned no error", name)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package scim
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/pg"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+const (
+	// BasePath is where the SCIM endpoints are mounted.
+	BasePath = "/scim/v2"
+
+	defaultCount = 100
+	maxCount     = 100
+
+	// actor is recorded as the actor of the events of changes made through SCIM.
+	actor = "scim"
+)
+
+type Config struct {
+	// Token is the bearer token that the identity provider authenticates with.
+	Token string
+	// ProjectID is the display ID of the project that provisioned groups belong to.
+	ProjectID string
+}
+
+// Server serves the SCIM endpoints.
+type Server struct {
+	logger    *slog.Logger
+	pool      *pgxpool.Pool
+	queries   *admindb.Queries
+	tokenHash [sha256.Size]byte
+	projectID pgtype.UUID
+	events    EventRecorder
+}
+
+// EventRecorder records the events of a change in its transaction, which queues them for
+// webhooks, and publishes them once the transaction is committed.
+type EventRecorder interface {
+	RecordEvents(ctx context.Context, q *admindb.Queries, evs ...events.Event) ([]events.Event, error)
+	Publish(ctx context.Context, event events.Event)
+}
+
+// discardEvents is the EventRecorder of a server created without one.
+type discardEvents struct{}
+
+func (discardEvents) RecordEvents(_ context.Context, _ *admindb.Queries, evs ...events.Event) ([]events.Event, error) {
+	return evs, nil
+}
+
+func (discardEvents) Publish(context.Context, events.Event) {}
+
+// NewServer creates a SCIM server. recorder receives the events of changes and may be nil.
+func NewServer(
+	logger *slog.Logger,
+	pool *pgxpool.Pool,
+	queries *admindb.Queries,
+	cfg Config,
+	recorder EventRecorder,
+) (*Server, error) {
+	if cfg.Token == "" {
+		return nil, errors.New("scim token is required")
+	}
+	projectID := pgtype.UUID{}
+	if err := projectID.Scan(cfg.ProjectID); err != nil {
+		return nil, errors.Wrapf(err, "invalid scim project id %q", cfg.ProjectID)
+	}
+	if recorder == nil {
+		recorder = discardEvents{}
+	}
+	return &Server{
+		logger:    logger.With(slog.String("component", "scim")),
+		pool:      pool,
+		queries:   queries,
+		tokenHash: sha256.Sum256([]byte(cfg.Token)),
+		projectID: projectID,
+		events:    recorder,
+	}, nil
+}
+
+// RegisterRoutes registers the SCIM endpoints on g, which is expected to be mounted at BasePath.
+func (s *Server) RegisterRoutes(g *echo.Group) {
+	g.Use(s.authenticate)
+
+	g.GET("/ServiceProviderConfig", s.getServiceProviderConfig)
+	g.GET("/Schemas", s.listSchemas)
+	g.GET("/Schemas/:id", s.getSchema)
+	g.GET("/ResourceTypes", s.listResourceTypes)
+	g.GET("/ResourceTypes/:id", s.getResourceType)
+
+	g.GET("/Users", s.listUsers)
+	g.POST("/Users", s.createUser)
+	g.GET("/Users/:id", s.getUser)
+	g.PUT("/Users/:id", s.replaceUser)
+	g.PATCH("/Users/:id", s.patchUser)
+	g.DELETE("/Users/:id", s.deleteUser)
+
+	g.GET("/Groups", s.listGroups)
+	g.POST("/Groups", s.createGroup)
+	g.GET("/Groups/:id", s.getGroup)
+	g.PUT("/Groups/:id", s.replaceGroup)
+	g.PATCH("/Groups/:id", s.patchGroup)
+	g.DELETE("/Groups/:id", s.deleteGroup)
+}
+
+// authenticate checks the bearer token. It compares hashes so that the comparison takes
+// the same time regardless of the token length.
+func (s *Server) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		hash := sha256.Sum256([]byte(token))
+		if !ok || subtle.ConstantTimeCompare(hash[:], s.tokenHash[:]) != 1 {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="scim"`)
+			return s.writeError(c, NewError(http.StatusUnauthorized, "", "invalid or missing bearer token"))
+		}
+		return next(c)
+	}
+}
+
+func (s *Server) getServiceProviderConfig(c echo.Context) error {
+	cfg := newServiceProviderConfig()
+	cfg.Meta.Location = baseURL(c) + "/ServiceProviderConfig"
+	return writeJSON(c, http.StatusOK, cfg)
+}
+
+func (s *Server) listSchemas(c echo.Context) error {
+	resources := []any{}
+	for _, schema := range newSchemas() {
+		schema.Meta.Location = baseURL(c) + "/Schemas/" + schema.ID
+		resources = append(resources, schema)
+	}
+	return writeJSON(c, http.StatusOK, NewListResponse(int64(len(resources)), 1, resources))
+}
+
+func (s *Server) getSchema(c echo.Context) error {
+	for _, schema := range newSchemas() {
+		if schema.ID == c.Param("id") {
+			schema.Meta.Location = baseURL(c) + "/Schemas/" + schema.ID
+			return writeJSON(c, http.StatusOK, schema)
+		}
+	}
+	return s.writeError(c, NewError(http.StatusNotFound, "", "schema %s not found", c.Param("id")))
+}
+
+func (s *Server) listResourceTypes(c echo.Context) error {
+	resources := []any{}
+	for _, rt := range newResourceTypes() {
+		rt.Meta.Location = baseURL(c) + "/ResourceTypes/" + rt.ID
+		resources = append(resources, rt)
+	}
+	return writeJSON(c, http.StatusOK, NewListResponse(int64(len(resources)), 1, resources))
+}
+
+func (s *Server) getResourceType(c echo.Context) error {
+	for _, rt := range newResourceTypes() {
+		if rt.ID == c.Param("id") {
+			rt.Meta.Location = baseURL(c) + "/ResourceTypes/" + rt.ID
+			return writeJSON(c, http.StatusOK, rt)
+		}
+	}
+	return s.writeError(c, NewError(http.StatusNotFound, "", "resource type %s not found", c.Param("id")))
+}
+
+// withTx runs fn in a transaction. The transaction is committed if fn returns nil and rolled back otherwise.
+func (s *Server) withTx(ctx context.Context, fn func(q *admindb.Queries) error) error {
+	return pg.WithTx(ctx, s.pool, s.logger, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		return fn(s.queries.WithTx(tx))
+	})
+}
+
+// recordEvents records the events of a change made with q, which must be its transaction.
+func (s *Server) recordEvents(ctx context.Context, q *admindb.Queries, evs ...events.Event) ([]events.Event, error) {
+	for i := range evs {
+		evs[i].Actor = actor
+	}
+	return s.events.RecordEvents(ctx, q, evs...)
+}
+
+// publishAll publishes the recorded events once their transaction is committed.
+func (s *Server) publishAll(ctx context.Context, evs []events.Event) {
+	for _, event := range evs {
+		s.events.Publish(ctx, event)
+	}
+}
+
+// writeError renders err as a SCIM error response.
+func (s *Server) writeError(c echo.Context, err error) error {
+	var scimErr *Error
+	var invariantErr *project.InvariantError
+	switch {
+	case errors.As(err, &scimErr):
+	case errors.Is(err, pgx.ErrNoRows):
+		scimErr = NewError(http.StatusNotFound, "", "resource not found")
+	case errors.Is(err, project.ErrArchived):
+		scimErr = NewError(http.StatusConflict, "", "the scim project is archived")
+	case errors.As(err, &invariantErr):
+		scimErr = NewError(http.StatusConflict, "", "%s", invariantErr.Error())
+	case pg.IsUniqueViolation(err):
+		scimErr = NewError(http.StatusConflict, ErrorTypeUniqueness, "resource already exists")
+	default:
+		s.logger.ErrorContext(c.Request().Context(), "scim request failed",
+			slog.String("method", c.Request().Method),
+			slog.String("path", c.Path()),
+			slog.String("error", err.Error()),
+		)
+		scimErr = NewError(http.StatusInternalServerError, "", "internal error")
+	}
+	return writeJSON(c, scimErr.StatusCode(), scimErr)
+}
+
+func writeJSON(c echo.Context, status int, v any) error {
+	c.Response().Header().Set(echo.HeaderContentType, ContentType)
+	c.Response().WriteHeader(status)
+	return json.NewEncoder(c.Response()).Encode(v)
+}
+
+// decodeBody decodes a JSON request body. Unknown attributes are ignored as allowed by RFC 7644.
+func decodeBody(c echo.Context, v any) error {
+	if err := json.NewDecoder(c.Request().Body).Decode(v); err != nil {
+		return errBadRequest(ErrorTypeInvalidSyntax, "invalid request body: %s", err.Error())
+	}
+	return nil
+}
+
+// baseURL returns the absolute URL of the SCIM endpoints for resource locations.
+func baseURL(c echo.Context) string {
+	return c.Scheme() + "://" + c.Request().Host + BasePath
+}
+
+// parseListParams reads the 1-based startIndex and count query parameters.
+func parseListParams(c echo.Context) (startIndex int, count int, err error) {
+	startIndex, count = 1, defaultCount
+	if v := c.QueryParam("startIndex"); v != "" {
+		if startIndex, err = strconv.Atoi(v); err != nil {
+			return 0, 0, errBadRequest(ErrorTypeInvalidValue, "invalid startIndex: %s", v)
+		}
+		startIndex = max(startIndex, 1)
+	}
+	if v := c.QueryParam("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil {
+			return 0, 0, errBadRequest(ErrorTypeInvalidValue, "invalid count: %s", v)
+		}
+		count = min(max(count, 0), maxCount)
+	}
+	return startIndex, count, nil
+}
+
+// parseID parses the id path parameter. Malformed IDs cannot exist and are reported as not found.
+func parseID(c echo.Context) (pgtype.UUID, error) {
+	id := pgtype.UUID{}
+	if err := id.Scan(c.Param("id")); err != nil {
+		return id, NewError(http.StatusNotFound, "", "resource %s not found", c.Param("id"))
+	}
+	return id, nil
+}
+
+// filterParams maps the comparisons of a filter onto the LIKE patterns of the list queries.
+// columns maps lower-cased attribute names to the name of their pattern. The id attribute is
+// returned separately since it is matched exactly. ok is false if the filter cannot match anything.
+func filterParams(filter Filter, columns map[string]string) (patterns map[string]pgtype.Text, id pgtype.UUID, ok bool, err error) {
+	patterns = map[string]pgtype.Text{}
+	for _, cmp := range filter {
+		if cmp.Attribute == "id" {
+			if cmp.Operator != OperatorEqual {
+				return nil, id, false, errBadRequest(ErrorTypeInvalidFilter, "id only supports the eq operator")
+			}
+			if id.Valid {
+				return nil, id, false, errBadRequest(ErrorTypeInvalidFilter, "id can only be filtered once")
+			}
+			if err := id.Scan(cmp.Value); err != nil {
+				return patterns, id, false, nil
+			}
+			continue
+		}
+		column, found := columns[cmp.Attribute]
+		if !found {
+			return nil, id, false, errBadRequest(ErrorTypeInvalidFilter, "filtering by %s is not supported", cmp.Attribute)
+		}
+		if _, dup := patterns[column]; dup {
+			return nil, id, false, errBadRequest(ErrorTypeInvalidFilter, "%s can only be filtered once", cmp.Attribute)
+		}
+		patterns[column] = pgtype.Text{String: cmp.LikePattern(), Valid: true}
+	}
+	return patterns, id, true, nil
+}
+
+func timePtr(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
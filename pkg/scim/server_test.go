@@ -0,0 +1,135 @@
+package scim
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newTestEcho(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	s, err := NewServer(slog.Default(), nil, nil, Config{
+		Token:     "secret-token",
+		ProjectID: "0192a6c4-5b7e-7c3a-9d1e-2f3a4b5c6d7e",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewServer() returned error: %v", err)
+	}
+	e := echo.New()
+	s.RegisterRoutes(e.Group(BasePath))
+	return e
+}
+
+func TestNewServerValidatesConfig(t *testing.T) {
+	t.Parallel()
+
+	for name, cfg := range map[string]Config{
+		"missing token":      {ProjectID: "0192a6c4-5b7e-7c3a-9d1e-2f3a4b5c6d7e"},
+		"invalid project id": {Token: "t", ProjectID: "not-a-uuid"},
+	} {
+		if _, err := NewServer(slog.Default(), nil, nil, cfg, nil); err == nil {
+			t.Errorf("%s: NewServer() returned no error", name)
+		}
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{"valid token", "Bearer secret-token", http.StatusOK},
+		{"wrong token", "Bearer other-token", http.StatusUnauthorized},
+		{"missing token", "", http.StatusUnauthorized},
+		{"not a bearer token", "Basic secret-token", http.StatusUnauthorized},
+	}
+
+	e := newTestEcho(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, BasePath+"/ServiceProviderConfig", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get(echo.HeaderContentType); got != ContentType {
+				t.Errorf("Content-Type = %q, want %q", got, ContentType)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var cfg ServiceProviderConfig
+				if err := json.Unmarshal(rec.Body.Bytes(), &cfg); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if !cfg.Patch.Supported || cfg.Bulk.Supported {
+					t.Errorf("unexpected service provider config: %+v", cfg)
+				}
+				return
+			}
+			var scimErr Error
+			if err := json.Unmarshal(rec.Body.Bytes(), &scimErr); err != nil {
+				t.Fatalf("failed to decode error: %v", err)
+			}
+			if scimErr.Status != "401" || len(scimErr.Schemas) != 1 || scimErr.Schemas[0] != SchemaError {
+				t.Errorf("unexpected error response: %+v", scimErr)
+			}
+		})
+	}
+}
+
+func TestServerSchemas(t *testing.T) {
+	t.Parallel()
+
+	e := newTestEcho(t)
+	for path, wantStatus := range map[string]int{
+		BasePath + "/Schemas":               http.StatusOK,
+		BasePath + "/Schemas/" + SchemaUser: http.StatusOK,
+		BasePath + "/Schemas/urn:unknown":   http.StatusNotFound,
+		BasePath + "/ResourceTypes":         http.StatusOK,
+		BasePath + "/ResourceTypes/Group":   http.StatusOK,
+		BasePath + "/ResourceTypes/Device":  http.StatusNotFound,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer secret-token")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != wantStatus {
+			t.Errorf("GET %s: status = %d, want %d", path, rec.Code, wantStatus)
+		}
+	}
+}
+
+func TestServerRejectsInvalidFilter(t *testing.T) {
+	t.Parallel()
+
+	e := newTestEcho(t)
+	req := httptest.NewRequest(http.MethodGet, BasePath+`/Users?filter=userName+gt+%22a%22`, nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	var scimErr Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &scimErr); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if scimErr.ScimType != ErrorTypeInvalidFilter {
+		t.Errorf("scimType = %q, want %q", scimErr.ScimType, ErrorTypeInvalidFilter)
+	}
+}
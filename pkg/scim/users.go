@@ -0,0 +1,323 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"net/mail"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/project"
+)
+
+const maxUserNameLength = 256
+
+// userFilterColumns maps filterable user attributes to the pattern parameters of ListSCIMUsers.
+var userFilterColumns = map[string]string{
+	"username":     "email",
+	"emails":       "email",
+	"emails.value": "email",
+	"externalid":   "externalId",
+	"displayname":  "displayName",
+}
+
+func (s *Server) listUsers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	startIndex, count, err := parseListParams(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var patterns map[string]pgtype.Text
+	id, matchable := pgtype.UUID{}, true
+	if v := c.QueryParam("filter"); v != "" {
+		filter, err := ParseFilter(v)
+		if err != nil {
+			return s.writeError(c, err)
+		}
+		if patterns, id, matchable, err = filterParams(filter, userFilterColumns); err != nil {
+			return s.writeError(c, err)
+		}
+	}
+	if !matchable {
+		return writeJSON(c, http.StatusOK, NewListResponse(0, startIndex, []any{}))
+	}
+
+	total, err := s.queries.CountSCIMUsers(ctx, admindb.CountSCIMUsersParams{
+		DisplayID:          id,
+		EmailPattern:       patterns["email"],
+		ExternalIDPattern:  patterns["externalId"],
+		DisplayNamePattern: patterns["displayName"],
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to count users"))
+	}
+	rows, err := s.queries.ListSCIMUsers(ctx, admindb.ListSCIMUsersParams{
+		Limit:              int32(count),
+		Offset:             int32(startIndex - 1),
+		DisplayID:          id,
+		EmailPattern:       patterns["email"],
+		ExternalIDPattern:  patterns["externalId"],
+		DisplayNamePattern: patterns["displayName"],
+	})
+	if err != nil {
+		return s.writeError(c, errors.Wrapf(err, "failed to list users"))
+	}
+
+	resources := make([]any, 0, len(rows))
+	for _, row := range rows {
+		resources = append(resources, toUser(c, row))
+	}
+	return writeJSON(c, http.StatusOK, NewListResponse(total, startIndex, resources))
+}
+
+func (s *Server) getUser(c echo.Context) error {
+	id, err := parseID(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	row, err := loadUser(c.Request().Context(), s.queries, id)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	return writeJSON(c, http.StatusOK, toUser(c, row))
+}
+
+func (s *Server) createUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req User
+	if err := decodeBody(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := validateUser(&req); err != nil {
+		return s.writeError(c, err)
+	}
+
+	var (
+		row     admindb.ListSCIMUsersRow
+		pending []events.Event
+	)
+	err := s.withTx(ctx, func(q *admindb.Queries) error {
+		if _, err := q.GetUserByEmail(ctx, req.UserName); err == nil {
+			return NewError(http.StatusConflict, ErrorTypeUniqueness, "user %s already exists", req.UserName)
+		}
+		if err := q.CreateUser(ctx, req.UserName); err != nil {
+			return errors.Wrapf(err, "failed to create user")
+		}
+		user, err := q.GetUserByEmail(ctx, req.UserName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get user by email")
+		}
+		if err := upsertUserAttributes(ctx, q, user.ID, req); err != nil {
+			return err
+		}
+		if row, err = loadUser(ctx, q, user.DisplayID); err != nil {
+			return err
+		}
+		pending, err = s.recordEvents(ctx, q, events.Event{
+			Kind:       events.KindUser,
+			Action:     events.ActionCreated,
+			ResourceID: row.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, pending)
+	resp := toUser(c, row)
+	c.Response().Header().Set(echo.HeaderLocation, resp.Meta.Location)
+	return writeJSON(c, http.StatusCreated, resp)
+}
+
+func (s *Server) replaceUser(c echo.Context) error {
+	var req User
+	if err := decodeBody(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	return s.updateUser(c, func(u *User) error {
+		// PUT replaces every attribute, so an omitted active attribute resets to its default.
+		*u = req
+		return nil
+	})
+}
+
+func (s *Server) patchUser(c echo.Context) error {
+	var req PatchRequest
+	if err := decodeBody(c, &req); err != nil {
+		return s.writeError(c, err)
+	}
+	if err := req.Validate(); err != nil {
+		return s.writeError(c, err)
+	}
+	return s.updateUser(c, func(u *User) error {
+		return ApplyUserPatch(u, req.Operations)
+	})
+}
+
+// updateUser loads the user, lets modify change it and stores the result.
+func (s *Server) updateUser(c echo.Context, modify func(u *User) error) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	var (
+		row     admindb.ListSCIMUsersRow
+		pending []events.Event
+	)
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		current, err := loadUser(ctx, q, id)
+		if err != nil {
+			return err
+		}
+		user := toUser(c, current)
+		if err := modify(&user); err != nil {
+			return err
+		}
+		if err := validateUser(&user); err != nil {
+			return err
+		}
+
+		if user.UserName != current.Email {
+			if err := q.UpdateUser(ctx, admindb.UpdateUserParams{DisplayID: id, Email: user.UserName}); err != nil {
+				return errors.Wrapf(err, "failed to update user")
+			}
+		}
+		if err := upsertUserAttributes(ctx, q, current.ID, user); err != nil {
+			return err
+		}
+		if row, err = loadUser(ctx, q, id); err != nil {
+			return err
+		}
+		pending, err = s.recordEvents(ctx, q, events.Event{
+			Kind:       events.KindUser,
+			Action:     events.ActionUpdated,
+			ResourceID: row.DisplayID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, pending)
+	return writeJSON(c, http.StatusOK, toUser(c, row))
+}
+
+func (s *Server) deleteUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c)
+	if err != nil {
+		return s.writeError(c, err)
+	}
+	var pending []events.Event
+	err = s.withTx(ctx, func(q *admindb.Queries) error {
+		user, err := q.GetUserByDisplayID(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return NewError(http.StatusNotFound, "", "user %s not found", c.Param("id"))
+			}
+			return errors.Wrapf(err, "failed to get user by display id")
+		}
+		ownedProjects, err := q.ListProjectIDsOwnedByUser(ctx, user.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list projects owned by user")
+		}
+		if _, err := q.DeleteUserByDisplayID(ctx, id); err != nil {
+			return errors.Wrapf(err, "failed to delete user")
+		}
+		// The only owner of a personal project cannot be deleted.
+		for _, projectID := range ownedProjects {
+			if err := project.Check(ctx, q, projectID); err != nil {
+				return err
+			}
+		}
+		pending, err = s.recordEvents(ctx, q, events.Event{
+			Kind:       events.KindUser,
+			Action:     events.ActionDeleted,
+			ResourceID: id.String(),
+		})
+		return err
+	})
+	if err != nil {
+		return s.writeError(c, err)
+	}
+
+	s.publishAll(ctx, pending)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// validateUser checks the attributes of a user and fills in defaults.
+func validateUser(u *User) error {
+	if u.UserName == "" {
+		return errBadRequest(ErrorTypeInvalidValue, "userName is required")
+	}
+	if len(u.UserName) > maxUserNameLength {
+		return errBadRequest(ErrorTypeInvalidValue, "userName must be at most %d characters", maxUserNameLength)
+	}
+	// userName is stored as the email address of the user.
+	addr, err := mail.ParseAddress(u.UserName)
+	if err != nil || addr.Address != u.UserName {
+		return errBadRequest(ErrorTypeInvalidValue, "userName must be an email address")
+	}
+	if len(u.DisplayName) > maxUserNameLength || len(u.ExternalID) > maxUserNameLength {
+		return errBadRequest(ErrorTypeInvalidValue, "displayName and externalId must be at most %d characters", maxUserNameLength)
+	}
+	if u.Active == nil {
+		active := true
+		u.Active = &active
+	}
+	return nil
+}
+
+func upsertUserAttributes(ctx context.Context, q *admindb.Queries, userID int64, u User) error {
+	if err := q.UpsertSCIMUser(ctx, admindb.UpsertSCIMUserParams{
+		UserID:      userID,
+		ExternalID:  pgtype.Text{String: u.ExternalID, Valid: u.ExternalID != ""},
+		DisplayName: u.DisplayName,
+		Active:      u.Active == nil || *u.Active,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to upsert scim user")
+	}
+	return nil
+}
+
+func loadUser(ctx context.Context, q *admindb.Queries, id pgtype.UUID) (admindb.ListSCIMUsersRow, error) {
+	rows, err := q.ListSCIMUsers(ctx, admindb.ListSCIMUsersParams{Limit: 1, DisplayID: id})
+	if err != nil {
+		return admindb.ListSCIMUsersRow{}, errors.Wrapf(err, "failed to get user by display id")
+	}
+	if len(rows) == 0 {
+		return admindb.ListSCIMUsersRow{}, NewError(http.StatusNotFound, "", "user %s not found", id.String())
+	}
+	return rows[0], nil
+}
+
+func toUser(c echo.Context, row admindb.ListSCIMUsersRow) User {
+	active := row.Active
+	return User{
+		Schemas:     []string{SchemaUser},
+		ID:          row.DisplayID.String(),
+		ExternalID:  row.ExternalID.String,
+		UserName:    row.Email,
+		DisplayName: row.DisplayName,
+		Emails:      []Email{{Value: row.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      timePtr(row.CreatedAt),
+			LastModified: timePtr(row.LastModified),
+			Location:     baseURL(c) + "/Users/" + row.DisplayID.String(),
+		},
+	}
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/tacokumo/admin-api/pkg/config"
+	"github.com/tacokumo/admin-api/pkg/middleware"
+	"github.com/tacokumo/admin-api/pkg/ratelimit"
+	"github.com/tacokumo/admin-api/pkg/reload"
+)
+
+// Option configures optional behavior of a Server.
+type Option func(*Server)
+
+// WithLogLevel lets the config set the level of the logger, also when it is reloaded.
+func WithLogLevel(level *slog.LevelVar) Option {
+	return func(s *Server) {
+		s.logLevel = level
+	}
+}
+
+// WithConfigSource enables config reloads. load reads the config again, and path is the config
+// file that is watched for changes. An empty path disables watching, leaving SIGHUP.
+func WithConfigSource(path string, load func() (config.Config, error)) Option {
+	return func(s *Server) {
+		s.configPath = path
+		s.loadConfig = load
+	}
+}
+
+// reloadablePaths are the config paths that are applied by a reload. Other changes need a restart.
+var reloadablePaths = []string{"cors", "auth.allowed_orgs", "log_level", "rate_limit"}
+
+func isReloadable(path string) bool {
+	for _, p := range reloadablePaths {
+		if path == p || strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadableSettings are the settings built from the reloadable sections of a config.
+type reloadableSettings struct {
+	logLevel    slog.Level
+	cors        echo.MiddlewareFunc
+	rateLimit   echo.MiddlewareFunc
+	ipRateLimit echo.MiddlewareFunc
+	allowedOrgs []string
+}
+
+// buildReloadableSettings builds what is swapped in from the reloadable sections of cfg, which
+// has been validated.
+func (s *Server) buildReloadableSettings(cfg config.Config) (reloadableSettings, error) {
+	level, err := config.ParseLogLevel(cfg.LogLevel)
+	if err != nil {
+		return reloadableSettings{}, err
+	}
+	return reloadableSettings{
+		logLevel:    level,
+		cors:        echomiddleware.CORSWithConfig(setupCORSConfig(cfg)),
+		rateLimit:   s.setupRateLimit(cfg.RateLimit),
+		ipRateLimit: s.setupIPRateLimit(cfg.RateLimit),
+		allowedOrgs: cfg.Auth.AllowedOrgs,
+	}, nil
+}
+
+// setupRateLimit returns the rate limit middleware, or nil if rate limiting is disabled.
+func (s *Server) setupRateLimit(cfg config.RateLimitConfig) echo.MiddlewareFunc {
+	if !cfg.Enabled {
+		return nil
+	}
+	return middleware.RateLimit(s.logger, s.rateLimiter, setupRateLimitPolicy(cfg))
+}
+
+// setupIPRateLimit returns the per IP rate limit middleware that runs before authentication, or
+// nil if rate limiting is disabled.
+func (s *Server) setupIPRateLimit(cfg config.RateLimitConfig) echo.MiddlewareFunc {
+	if !cfg.Enabled {
+		return nil
+	}
+	rule := ratelimit.Rule{Name: "per_ip", Limit: cfg.PerIP.Limit, Window: cfg.PerIP.Window}
+	return middleware.IPRateLimit(s.logger, s.rateLimiter, rule, cfg.BypassPaths)
+}
+
+// startReloading reloads the config on SIGHUP and when the config file changes, and the TLS
+// certificate when its files change, until ctx is cancelled. The returned channel is closed
+// once it has stopped.
+func (s *Server) startReloading(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+
+	interval := s.cfg.Reload.WatchInterval
+	var configWatcher, certWatcher *reload.Watcher
+	if interval > 0 && s.loadConfig != nil && s.configPath != "" {
+		configWatcher = reload.NewWatcher(s.logger, []string{s.configPath})
+	}
+	if interval > 0 && s.certs != nil {
+		certWatcher = reload.NewWatcher(s.logger, s.certs.Files())
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer close(done)
+		defer signal.Stop(hup)
+
+		var tick <-chan time.Time
+		if configWatcher != nil || certWatcher != nil {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				s.logger.InfoContext(ctx, "received SIGHUP, reloading")
+				s.reloadConfig(ctx)
+				s.reloadCertificates(ctx)
+			case <-tick:
+				// Watchers are polled on this goroutine, so reloads never run concurrently.
+				if configWatcher != nil && configWatcher.Poll() {
+					s.reloadConfig(ctx)
+				}
+				if certWatcher != nil && certWatcher.Poll() {
+					s.reloadCertificates(ctx)
+				}
+			}
+		}
+	}()
+	return done
+}
+
+// reloadConfig loads the config again and applies the changes to the reloadable sections.
+// Invalid configs are rejected as a whole, and other changes are only reported.
+func (s *Server) reloadConfig(ctx context.Context) {
+	if s.loadConfig == nil {
+		return
+	}
+	next, err := s.loadConfig()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to reload config, keeping the current config", slog.String("error", err.Error()))
+		return
+	}
+	if err := next.Validate(); err != nil {
+		s.logger.ErrorContext(ctx, "reloaded config is invalid, keeping the current config", slog.String("error", err.Error()))
+		return
+	}
+
+	var applied, ignored []string
+	for _, path := range config.Diff(s.cfg, next) {
+		if isReloadable(path) {
+			applied = append(applied, path)
+		} else {
+			ignored = append(ignored, path)
+		}
+	}
+	if len(ignored) > 0 {
+		s.logger.WarnContext(ctx, "config changes that need a restart were not applied", slog.Any("fields", ignored))
+	}
+	if len(applied) == 0 {
+		s.logger.InfoContext(ctx, "config reloaded without changes to apply")
+		return
+	}
+
+	settings, err := s.buildReloadableSettings(next)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "reloaded config is invalid, keeping the current config", slog.String("error", err.Error()))
+		return
+	}
+	if s.logLevel != nil {
+		s.logLevel.Set(settings.logLevel)
+	}
+	s.cors.Swap(settings.cors)
+	s.rateLimit.Swap(settings.rateLimit)
+	s.ipRateLimit.Swap(settings.ipRateLimit)
+	s.githubClient.SetAllowedOrgs(settings.allowedOrgs)
+
+	s.cfg.LogLevel = next.LogLevel
+	s.cfg.CORS = next.CORS
+	s.cfg.Auth.AllowedOrgs = next.Auth.AllowedOrgs
+	s.cfg.RateLimit = next.RateLimit
+	s.logger.InfoContext(ctx, "config reloaded", slog.Any("changed", applied))
+}
+
+// reloadCertificates loads the TLS certificate again. New connections use the new certificate.
+func (s *Server) reloadCertificates(ctx context.Context) {
+	if s.certs == nil {
+		return
+	}
+	if err := s.certs.Reload(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to reload TLS certificate, keeping the current certificate", slog.String("error", err.Error()))
+		return
+	}
+	s.logger.InfoContext(ctx, "TLS certificate reloaded", slog.String("cert_file", s.cfg.TLS.CertFile))
+}
@@ -7,8 +7,8 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"net/netip"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -20,12 +20,24 @@ import (
 	"github.com/samber/lo"
 	adminv1alpha1 "github.com/tacokumo/admin-api/pkg/apis/v1alpha1"
 	adminv1alpha1generated "github.com/tacokumo/admin-api/pkg/apis/v1alpha1/generated"
+	"github.com/tacokumo/admin-api/pkg/auth/clientcert"
 	"github.com/tacokumo/admin-api/pkg/auth/oauth"
+	"github.com/tacokumo/admin-api/pkg/auth/serviceaccount"
 	"github.com/tacokumo/admin-api/pkg/auth/session"
 	"github.com/tacokumo/admin-api/pkg/config"
 	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/health"
+	"github.com/tacokumo/admin-api/pkg/idempotency"
+	"github.com/tacokumo/admin-api/pkg/jobs"
+	"github.com/tacokumo/admin-api/pkg/mail"
 	"github.com/tacokumo/admin-api/pkg/middleware"
 	"github.com/tacokumo/admin-api/pkg/pg"
+	"github.com/tacokumo/admin-api/pkg/project"
+	"github.com/tacokumo/admin-api/pkg/ratelimit"
+	"github.com/tacokumo/admin-api/pkg/reload"
+	"github.com/tacokumo/admin-api/pkg/scim"
+	"github.com/tacokumo/admin-api/pkg/webhook"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
@@ -37,42 +49,83 @@ import (
 )
 
 type Server struct {
-	logger   *slog.Logger
-	cfg      config.Config
-	e        *echo.Echo
-	cleanups []func(context.Context)
+	logger *slog.Logger
+	cfg    config.Config
+	e      *echo.Echo
+	// cleanups release the resources of the server. They run in reverse order on shutdown.
+	cleanups []cleanup
+	// jobRunner runs background jobs while the server is running. It is nil when jobs are disabled.
+	jobRunner *jobs.Runner
+	health    *health.Registry
+	// onShutdown ends long-lived requests, such as event streams, when the HTTP server is shut
+	// down after the drain period, as they would otherwise hold up the shutdown until the timeout.
+	onShutdown []func()
+
+	// The fields below are used to reload settings while the server is running.
+	logLevel     *slog.LevelVar
+	configPath   string
+	loadConfig   func() (config.Config, error)
+	githubClient *oauth.GitHubClient
+	cors         *middleware.Reloadable
+	rateLimit    *middleware.Reloadable
+	ipRateLimit  *middleware.Reloadable
+	rateLimiter  ratelimit.Limiter
+	// certs serves the TLS certificate. It is nil when TLS is disabled.
+	certs *reload.CertificateReloader
 }
 
-func New(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Server, error) {
+// cleanup is a named step of the shutdown sequence.
+type cleanup struct {
+	name string
+	fn   func(context.Context)
+}
+
+func New(ctx context.Context, cfg config.Config, logger *slog.Logger, options ...Option) (*Server, error) {
 	s := &Server{
 		logger: logger,
 		cfg:    cfg,
 		e:      echo.New(),
 	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.logLevel != nil {
+		level, err := config.ParseLogLevel(cfg.LogLevel)
+		if err != nil {
+			logger.WarnContext(ctx, "ignoring invalid log level", slog.String("error", err.Error()))
+		} else if cfg.LogLevel != "" {
+			s.logLevel.Set(level)
+		}
+	}
+
+	ipExtractor, err := setupIPExtractor(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	s.e.IPExtractor = ipExtractor
 
-	var cleanups []func(context.Context)
+	var cleanups []cleanup
 
 	// Initialize Redis
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	})
+	redisClient := newRedisClient(cfg.Redis, 0)
 	if err := redisClient.Ping(ctx).Err(); err != nil {
 		return nil, errors.Wrap(err, "failed to connect to Redis")
 	}
-	cleanups = append(cleanups, func(ctx context.Context) {
+	cleanups = append(cleanups, cleanup{name: "redis", fn: func(ctx context.Context) {
 		if err := redisClient.Close(); err != nil {
 			logger.ErrorContext(ctx, "failed to close Redis connection", slog.String("error", err.Error()))
 		}
-	})
+	}})
+	// Event streams block on reads, so they get their own connections.
+	eventsRedisClient := newRedisClient(cfg.Redis, cfg.Events.ReadPoolSize)
+	cleanups = append(cleanups, cleanup{name: "redis events", fn: func(ctx context.Context) {
+		if err := eventsRedisClient.Close(); err != nil {
+			logger.ErrorContext(ctx, "failed to close Redis connection for events", slog.String("error", err.Error()))
+		}
+	}})
 
 	// Initialize session stores
-	sessionTTL := cfg.Auth.SessionTTL
-	if sessionTTL == 0 {
-		sessionTTL = 24 * time.Hour
-	}
-	sessionStore := session.NewRedisStore(redisClient, sessionTTL)
+	sessionStore := session.NewRedisStore(redisClient, cfg.Auth.SessionTTL)
 	stateStore := session.NewRedisStore(redisClient, 10*time.Minute) // Short TTL for OAuth state
 
 	// Initialize GitHub OAuth client
@@ -82,13 +135,13 @@ func New(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Server,
 		cfg.Auth.CallbackURL,
 		cfg.Auth.AllowedOrgs,
 	)
+	s.githubClient = githubClient
 
 	// Setup middleware
 	s.e.Use(middleware.Logger(logger))
-	corsConfig := setupCORSConfig(cfg)
-	s.e.Use(echomiddleware.CORSWithConfig(corsConfig))
-	sessionMiddleware := middleware.SessionMiddleware(logger, sessionStore)
-	s.e.Use(sessionMiddleware)
+	s.cors = middleware.NewReloadable(echomiddleware.CORSWithConfig(setupCORSConfig(cfg)))
+	s.e.Use(s.cors.Middleware())
+	s.e.Use(middleware.RequestContext())
 
 	opts, otelCleanups, err := initAdminServerConfig(ctx, logger, cfg.Telemetry)
 	if err != nil {
@@ -117,9 +170,9 @@ func New(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Server,
 	if err != nil {
 		return s, errors.Wrapf(err, "failed to create pgx pool")
 	}
-	cleanups = append(cleanups, func(ctx context.Context) {
+	cleanups = append(cleanups, cleanup{name: "admin db pool", fn: func(ctx context.Context) {
 		p.Close()
-	})
+	}})
 
 	retryCount := 1
 	if cfg.AdminDBConfig.InitialConnRetry > 1 {
@@ -148,17 +201,75 @@ func New(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Server,
 	}
 	queries := admindb.New(p)
 
+	s.health = setupHealthRegistry(cfg, p, redisClient)
+
+	// Session middleware needs the admin DB to authenticate service account tokens
+	serviceAccountAuthenticator := serviceaccount.NewAuthenticator(logger, queries, cfg.Auth.SessionTTL)
+	sessionOpts := []middleware.SessionOption{
+		middleware.WithTokenAuthenticator(serviceAccountAuthenticator),
+	}
+	if cfg.TLS.Enabled && len(cfg.TLS.ClientCertIdentities) > 0 {
+		identities, err := setupClientCertIdentities(cfg.TLS.ClientCertIdentities)
+		if err != nil {
+			return s, errors.Wrapf(err, "failed to setup client certificate identities")
+		}
+		sessionOpts = append(sessionOpts, middleware.WithCertificateAuthenticator(
+			clientcert.NewAuthenticator(logger, queries, cfg.Auth.SessionTTL, identities),
+		))
+	}
+	if cfg.SCIM.Enabled {
+		// SCIM clients authenticate with their own bearer token
+		sessionOpts = append(sessionOpts, middleware.WithSkipPathPrefix(scim.BasePath))
+	}
+	// Rate limiting is always registered so that a config reload can enable it. The per IP limit
+	// runs before authentication, and the per principal limit after it.
+	s.rateLimiter = ratelimit.NewRedisLimiter(redisClient)
+	s.ipRateLimit = middleware.NewReloadable(s.setupIPRateLimit(cfg.RateLimit))
+	s.e.Use(s.ipRateLimit.Middleware())
+	if cfg.TLS.Enabled && len(cfg.TLS.ClientCertPaths) > 0 {
+		s.e.Use(middleware.RequireClientCertificate(logger, cfg.TLS.ClientCertPaths))
+	}
+	sessionMiddleware := middleware.SessionMiddleware(logger, sessionStore, sessionOpts...)
+	s.e.Use(sessionMiddleware)
+	s.rateLimit = middleware.NewReloadable(s.setupRateLimit(cfg.RateLimit))
+	s.e.Use(s.rateLimit.Middleware())
+	if cfg.Idempotency.Enabled {
+		s.e.Use(middleware.Idempotency(logger, idempotency.NewRedisStore(redisClient), cfg.Idempotency.TTL))
+	}
+
+	mailer, err := setupMailer(logger, cfg.Mail)
+	if err != nil {
+		return s, errors.Wrapf(err, "failed to setup mailer")
+	}
+	invitationAcceptURL := cfg.Invitation.AcceptURL
+	if invitationAcceptURL == "" {
+		invitationAcceptURL = strings.TrimSuffix(cfg.Auth.FrontendURL, "/") + "/invitations/accept"
+	}
+
 	// Create service with OAuth dependencies
 	service := adminv1alpha1.NewService(
 		logger,
+		p,
 		queries,
 		githubClient,
 		sessionStore,
 		stateStore,
 		cfg.Auth.FrontendURL,
-		sessionTTL,
+		cfg.Auth.SessionTTL,
+		events.NewRedisStream(redisClient, eventsRedisClient, cfg.Events.StreamMaxLen),
+		adminv1alpha1.InvitationSettings{
+			Mailer:    mailer,
+			TTL:       cfg.Invitation.TTL,
+			AcceptURL: invitationAcceptURL,
+		},
+		adminv1alpha1.ProjectSettings{
+			TransferTTL: cfg.Project.TransferTTL,
+		},
+		s.health,
 	)
+	s.onShutdown = append(s.onShutdown, service.CloseStreams)
 
+	opts = append(opts, adminv1alpha1generated.WithErrorHandler(service.HandleError))
 	v1alpha1Server, err := adminv1alpha1generated.NewServer(
 		service,
 		service,
@@ -170,15 +281,151 @@ func New(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Server,
 
 	// Register OAuth endpoints with Echo for proper redirect support
 	s.e.GET("/v1alpha1/auth/login", createLoginHandler(logger, githubClient, stateStore))
-	s.e.GET("/v1alpha1/auth/callback", createCallbackHandler(logger, githubClient, sessionStore, stateStore, cfg.Auth.FrontendURL, sessionTTL))
+	s.e.GET("/v1alpha1/auth/callback", createCallbackHandler(logger, githubClient, sessionStore, stateStore, cfg.Auth.FrontendURL, cfg.Auth.SessionTTL, service.AcceptInvitationsForLogin))
 
 	v1alphaGroup := s.e.Group("/v1alpha1")
+	s.health.RegisterRoutes(v1alphaGroup)
+	service.RegisterRoutes(v1alphaGroup)
 	v1alphaGroup.Any("/*", echo.WrapHandler(v1alpha1Server))
 
+	if cfg.SCIM.Enabled {
+		scimServer, err := scim.NewServer(logger, p, queries, scim.Config{
+			Token:     cfg.SCIM.Token,
+			ProjectID: cfg.SCIM.ProjectID,
+		}, service)
+		if err != nil {
+			return s, errors.Wrapf(err, "failed to create scim server")
+		}
+		scimServer.RegisterRoutes(s.e.Group(scim.BasePath))
+	}
+
+	if cfg.Jobs.Enabled {
+		runner, err := setupJobRunner(logger, queries, cfg, service.Publish)
+		if err != nil {
+			return s, errors.Wrapf(err, "failed to setup job runner")
+		}
+		s.jobRunner = runner
+	} else if cfg.Webhook.Enabled {
+		logger.WarnContext(ctx, "jobs are disabled on this replica, webhook deliveries are sent by other replicas only")
+	}
+
 	s.cleanups = cleanups
 	return s, nil
 }
 
+// newRedisClient returns a client for the Redis of cfg. A poolSize of 0 uses the default of
+// go-redis.
+func newRedisClient(cfg config.RedisConfig, poolSize int) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+		PoolSize: poolSize,
+	})
+}
+
+// setupHealthRegistry registers the checks of the dependencies of this server.
+func setupHealthRegistry(cfg config.Config, p *pgxpool.Pool, redisClient *redis.Client) *health.Registry {
+	registry := health.NewRegistry(health.Config{
+		Timeout:  cfg.Health.Timeout,
+		CacheTTL: cfg.Health.CacheTTL,
+	})
+	registry.Register(health.Check{Name: "postgres", Checker: health.CheckerFunc(p.Ping)})
+	registry.Register(health.Check{Name: "redis", Checker: health.CheckerFunc(func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})})
+
+	if cfg.Telemetry.Enabled && cfg.Health.OTLP {
+		// The exporters default to localhost:4317 when no endpoint is configured.
+		endpoint := cfg.Telemetry.OTLPEndpoint
+		if endpoint == "" {
+			endpoint = "localhost:4317"
+		}
+		checker := health.CheckerFunc(func(ctx context.Context) error {
+			addr, err := health.EndpointAddr(endpoint, "4317")
+			if err != nil {
+				return err
+			}
+			return health.DialChecker(addr).Check(ctx)
+		})
+		registry.Register(health.Check{Name: "otlp", Checker: checker, Optional: true})
+	}
+
+	if cfg.Health.GitHub {
+		registry.Register(health.Check{
+			Name:     "github",
+			Checker:  health.HTTPChecker(http.DefaultClient, "https://api.github.com"),
+			CacheTTL: cfg.Health.GitHubCacheTTL,
+			Optional: true,
+		})
+	}
+	return registry
+}
+
+// setupJobRunner registers the job handlers and schedules of this server.
+func setupJobRunner(logger *slog.Logger, queries *admindb.Queries, cfg config.Config, publish func(context.Context, events.Event)) (*jobs.Runner, error) {
+	runner := jobs.NewRunner(logger, queries, jobs.Config{
+		Concurrency:   cfg.Jobs.Concurrency,
+		PollInterval:  cfg.Jobs.PollInterval,
+		LeaseDuration: cfg.Jobs.LeaseDuration,
+	})
+
+	runner.Register(jobs.CleanupJobKind, jobs.NewCleanupHandler(logger, queries, cfg.Jobs.Retention))
+	if err := runner.Schedule(jobs.CleanupJobKind, "@hourly", jobs.CleanupJobKind, nil); err != nil {
+		return nil, err
+	}
+
+	runner.Register(project.PurgeJobKind, project.NewPurgeHandler(logger, queries, cfg.Project.ArchiveRetention, publish))
+	if err := runner.Schedule(project.PurgeJobKind, "@hourly", project.PurgeJobKind, nil); err != nil {
+		return nil, err
+	}
+
+	if cfg.Webhook.Enabled {
+		allowedNetworks, err := parsePrefixes(cfg.Webhook.AllowedNetworks)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse webhook allowed networks")
+		}
+		dispatcher := webhook.NewDispatcher(logger, queries, webhook.Config{
+			BatchSize:       cfg.Webhook.BatchSize,
+			MaxAttempts:     cfg.Webhook.MaxAttempts,
+			Timeout:         cfg.Webhook.Timeout,
+			AllowedNetworks: allowedNetworks,
+		})
+		runner.Register(webhook.DispatchJobKind, dispatcher.HandleDispatchJob)
+		if err := runner.Schedule(webhook.DispatchJobKind, "@every "+cfg.Webhook.PollInterval.String(), webhook.DispatchJobKind, nil); err != nil {
+			return nil, err
+		}
+	}
+	return runner, nil
+}
+
+// setupMailer creates the mail sender selected by the config.
+func setupMailer(logger *slog.Logger, cfg config.MailConfig) (mail.Sender, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return mail.NewLogSender(logger), nil
+	case "file":
+		if cfg.FileDir == "" {
+			return nil, errors.New("mail file_dir is required for the file driver")
+		}
+		return mail.NewFileSender(cfg.FileDir, cfg.From), nil
+	case "smtp":
+		return mail.NewSMTPSender(mail.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.From,
+			TLSMode:  cfg.SMTP.TLS,
+		})
+	default:
+		return nil, errors.Newf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// acceptInvitationsFunc accepts the pending invitations of a GitHub login's verified emails.
+type acceptInvitationsFunc func(ctx context.Context, primaryEmail string, verifiedEmails []string, actor string) (int, error)
+
 func createLoginHandler(
 	logger *slog.Logger,
 	githubClient *oauth.GitHubClient,
@@ -221,6 +468,7 @@ func createCallbackHandler(
 	stateStore session.Store,
 	frontendURL string,
 	sessionTTL time.Duration,
+	acceptInvitations acceptInvitationsFunc,
 ) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
@@ -269,6 +517,20 @@ func createCallbackHandler(
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "not authorized: not a member of allowed organizations"})
 		}
 
+		// Accept invitations sent to any verified email of the account. Login proceeds even if
+		// this fails; the invitee can still accept with the emailed link. Without the verified
+		// emails nothing is accepted, since no other address of the account is proven.
+		if verifiedEmails, err := githubClient.GetVerifiedEmails(ctx, token); err != nil {
+			logger.WarnContext(ctx, "failed to get verified emails, skipping invitations", slog.String("error", err.Error()))
+		} else {
+			accepted, err := acceptInvitations(ctx, ghUser.Email, verifiedEmails, fmt.Sprintf("%d", ghUser.ID))
+			if err != nil {
+				logger.WarnContext(ctx, "failed to accept invitations", slog.String("username", ghUser.Login), slog.String("error", err.Error()))
+			} else if accepted > 0 {
+				logger.InfoContext(ctx, "accepted invitations", slog.String("username", ghUser.Login), slog.Int("count", accepted))
+			}
+		}
+
 		// Get team memberships
 		teams, err := githubClient.GetTeamMemberships(ctx, token)
 		if err != nil {
@@ -332,36 +594,110 @@ func createCallbackHandler(
 	}
 }
 
+// Start serves requests until ctx is cancelled and then shuts the server down.
 func (s *Server) Start(ctx context.Context) error {
-	// Start server
-	wg := new(sync.WaitGroup)
+	srv, err := s.newHTTPServer()
+	if err != nil {
+		s.runCleanups(context.WithoutCancel(ctx), s.cfg.Shutdown.CleanupTimeout)
+		return err
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(srv)
+	}()
+
+	if s.jobRunner != nil {
+		s.jobRunner.Start(ctx)
+	}
+	s.health.MarkStarted()
+	s.logger.InfoContext(ctx, "server started", slog.String("addr", srv.Addr), slog.Bool("tls", srv.TLSConfig != nil))
+
+	reloadCtx, stopReloading := context.WithCancel(ctx)
+	reloadDone := s.startReloading(reloadCtx)
+
+	var startErr error
+	select {
+	case <-ctx.Done():
+		s.logger.InfoContext(ctx, "shutting down the server")
+	case err := <-serveErr:
+		// The server stopped by itself, e.g. because the address is in use.
+		startErr = errors.Wrapf(err, "failed to serve")
+		serveErr = nil
+	}
+	stopReloading()
+	<-reloadDone
+	return errors.CombineErrors(startErr, s.shutdown(context.WithoutCancel(ctx), srv, serveErr))
+}
+
+// shutdown stops the server in order: readiness starts failing, requests are still served for
+// the drain period, event streams are ended and other in-flight requests are given the shutdown
+// timeout to finish, the job runner stops and finally the cleanups run. serveErr is nil if the server is no longer running.
+func (s *Server) shutdown(ctx context.Context, srv *http.Server, serveErr <-chan error) error {
+	settings := s.cfg.Shutdown
+	s.health.MarkDraining()
+
+	var result error
+	if serveErr != nil {
+		if settings.DrainPeriod > 0 {
+			s.logger.InfoContext(ctx, "draining connections", slog.Duration("drain_period", settings.DrainPeriod))
+			time.Sleep(settings.DrainPeriod)
+		}
 
-	wg.Add(1)
-	go startAPIServer(ctx, s.logger, s.e, s.cfg, wg)
+		shutdownCtx, cancel := context.WithTimeout(ctx, settings.Timeout)
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			result = errors.Wrapf(err, "failed to shutdown server")
+			// Requests that did not finish in time are cut off.
+			if err := srv.Close(); err != nil {
+				s.logger.ErrorContext(ctx, "failed to close server", slog.String("error", err.Error()))
+			}
+		}
+		cancel()
+		if err := <-serveErr; err != nil {
+			result = errors.CombineErrors(result, errors.Wrapf(err, "failed to serve"))
+		}
+	}
 
-	// Wait for interrupt signal to gracefully shut down the server with a timeout of 10 seconds.
-	<-ctx.Done()
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := s.e.Shutdown(ctx); err != nil {
-		return errors.Wrapf(err, "failed to shutdown server")
+	// Jobs must finish before the cleanups close the database pool.
+	if s.jobRunner != nil {
+		stopCtx, cancel := context.WithTimeout(ctx, settings.Timeout)
+		s.jobRunner.Stop(stopCtx)
+		cancel()
 	}
 
-	wg.Wait()
+	s.runCleanups(ctx, settings.CleanupTimeout)
+	s.logger.InfoContext(ctx, "server stopped")
+	return result
+}
 
-	for _, cleanup := range s.cleanups {
-		cleanup(ctx)
+// runCleanups runs the cleanups in reverse order, so that every resource is released after the
+// resources set up later, which may depend on it. A step that does not return within timeout is
+// abandoned so that the remaining steps still run.
+func (s *Server) runCleanups(ctx context.Context, timeout time.Duration) {
+	for i := len(s.cleanups) - 1; i >= 0; i-- {
+		c := s.cleanups[i]
+		stepCtx, cancel := context.WithTimeout(ctx, timeout)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.fn(stepCtx)
+		}()
+		select {
+		case <-done:
+		case <-stepCtx.Done():
+			s.logger.WarnContext(ctx, "cleanup did not finish in time", slog.String("step", c.name), slog.Duration("timeout", timeout))
+		}
+		cancel()
 	}
-	return nil
 }
 
 func initAdminServerConfig(
 	ctx context.Context,
 	logger *slog.Logger,
 	telemetryCfg config.TelemetryConfig,
-) ([]adminv1alpha1generated.ServerOption, []func(context.Context), error) {
+) ([]adminv1alpha1generated.ServerOption, []cleanup, error) {
 	var opts []adminv1alpha1generated.ServerOption
-	var cleanups []func(context.Context)
+	var cleanups []cleanup
 
 	// If telemetry is disabled, return empty options
 	if !telemetryCfg.Enabled {
@@ -398,11 +734,11 @@ func initAdminServerConfig(
 		sdktrace.WithResource(res),
 		sdktrace.WithSyncer(traceExporter),
 	)
-	cleanups = append(cleanups, func(ctx context.Context) {
+	cleanups = append(cleanups, cleanup{name: "tracer provider", fn: func(ctx context.Context) {
 		if err := tp.Shutdown(ctx); err != nil {
 			logger.ErrorContext(ctx, "failed to shutdown TracerProvider", slog.String("error", err.Error()))
 		}
-	})
+	}})
 
 	otel.SetTracerProvider(tp)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
@@ -417,11 +753,11 @@ func initAdminServerConfig(
 		sdkmetric.WithResource(res),
 		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(meterExporter)),
 	)
-	cleanups = append(cleanups, func(ctx context.Context) {
+	cleanups = append(cleanups, cleanup{name: "meter provider", fn: func(ctx context.Context) {
 		if err := mp.Shutdown(ctx); err != nil {
 			logger.ErrorContext(ctx, "failed to shutdown MeterProvider", slog.String("error", err.Error()))
 		}
-	})
+	}})
 	otel.SetMeterProvider(mp)
 	opts = append(opts, adminv1alpha1generated.WithMeterProvider(mp))
 
@@ -440,45 +776,91 @@ func newResource() (*resource.Resource, error) {
 	return r, nil
 }
 
-func startAPIServer(ctx context.Context, logger *slog.Logger, e *echo.Echo, cfg config.Config, wg *sync.WaitGroup) {
-	defer wg.Done()
-	addr := net.JoinHostPort(cfg.Addr, cfg.Port)
-
-	if !cfg.TLS.Enabled {
-		if err := e.Start(addr); err != nil && err != http.ErrServerClosed {
-			logger.ErrorContext(ctx, "shutting down the server", slog.String("addr", addr), slog.String("port", cfg.Port), slog.String("error", err.Error()))
-		}
-		return
+// newHTTPServer creates the server for the Echo instance. It serves TLS if TLS is enabled.
+func (s *Server) newHTTPServer() (*http.Server, error) {
+	srv := &http.Server{
+		Addr:    net.JoinHostPort(s.cfg.Addr, s.cfg.Port),
+		Handler: s.e,
+	}
+	for _, f := range s.onShutdown {
+		srv.RegisterOnShutdown(f)
+	}
+	if !s.cfg.TLS.Enabled {
+		return srv, nil
 	}
 
-	// Setup TLS with mTLS
-	tlsConfig, err := setupTLSConfig(cfg.TLS)
+	certs, err := reload.NewCertificateReloader(s.cfg.TLS.CertFile, s.cfg.TLS.KeyFile)
 	if err != nil {
-		logger.ErrorContext(ctx, "failed to setup TLS config", slog.String("error", err.Error()))
-		return
+		return nil, errors.Wrapf(err, "failed to load TLS certificate")
 	}
-
-	server := &http.Server{
-		Addr:      addr,
-		TLSConfig: tlsConfig,
-		Handler:   e,
+	tlsConfig, err := setupTLSConfig(s.cfg.TLS, certs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to setup TLS config")
 	}
+	s.certs = certs
+	srv.TLSConfig = tlsConfig
+	return srv, nil
+}
 
-	if err := server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
-		logger.ErrorContext(ctx, "shutting down the server", slog.String("addr", addr), slog.String("port", cfg.Port), slog.String("error", err.Error()))
+// serve listens and serves until the server is shut down, which is not reported as an error.
+func serve(srv *http.Server) error {
+	var err error
+	if srv.TLSConfig != nil {
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
 	}
+	return err
 }
 
-func setupTLSConfig(tlsCfg config.TLSConfig) (*tls.Config, error) {
-	cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+// setupTLSConfig serves the certificate of certs and, if client authentication is enabled,
+// loads the CA bundle that client certificates are verified against.
+func setupTLSConfig(tlsCfg config.TLSConfig, certs *reload.CertificateReloader) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		GetCertificate: certs.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+
+	clientAuth, err := clientcert.ClientAuthType(tlsCfg.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	if clientAuth == tls.NoClientCert {
+		if len(tlsCfg.ClientCertPaths) > 0 || len(tlsCfg.ClientCertIdentities) > 0 {
+			return nil, errors.Newf("tls client_auth must be %s or %s to use client certificates", clientcert.ModeOptional, clientcert.ModeRequire)
+		}
+		return tlsConfig, nil
+	}
+	if tlsCfg.ClientCAFile == "" {
+		return nil, errors.Newf("tls client_ca_file is required when client_auth is %s", tlsCfg.ClientAuth)
+	}
+	pool, err := clientcert.LoadCAPool(tlsCfg.ClientCAFile)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to load X509 key pair")
+		return nil, err
 	}
+	tlsConfig.ClientAuth = clientAuth
+	tlsConfig.ClientCAs = pool
+	return tlsConfig, nil
+}
 
-	return &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		MinVersion:   tls.VersionTLS12,
-	}, nil
+// setupClientCertIdentities converts and validates the client certificate identities.
+func setupClientCertIdentities(cfg []config.ClientCertIdentity) ([]clientcert.Identity, error) {
+	identities := make([]clientcert.Identity, 0, len(cfg))
+	for _, c := range cfg {
+		identity := clientcert.Identity{
+			Subject:          c.Subject,
+			SAN:              c.SAN,
+			ServiceAccountID: c.ServiceAccountID,
+		}
+		if err := identity.Validate(); err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, nil
 }
 
 func setupCORSConfig(cfg config.Config) echomiddleware.CORSConfig {
@@ -497,3 +879,51 @@ func setupCORSConfig(cfg config.Config) echomiddleware.CORSConfig {
 	}
 	return corsConfig
 }
+
+// setupIPExtractor returns how the client IP is found. Echo trusts X-Forwarded-For and
+// X-Real-IP from anyone by default, which would let clients pick their IP and escape the per IP
+// rate limit, so the headers are only read from the trusted proxies.
+func setupIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse trusted proxy %q", cidr)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// parsePrefixes parses CIDR ranges of the config.
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %q", cidr)
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, nil
+}
+
+func setupRateLimitPolicy(cfg config.RateLimitConfig) ratelimit.Policy {
+	toRule := func(r config.RateLimitRule, _ int) ratelimit.Rule {
+		return ratelimit.Rule{
+			Name:       r.Name,
+			PathPrefix: r.PathPrefix,
+			Limit:      r.Limit,
+			Window:     r.Window,
+		}
+	}
+
+	return ratelimit.Policy{
+		Rules:          lo.Map(cfg.Groups, toRule),
+		Default:        toRule(cfg.Default, 0),
+		BypassPrefixes: cfg.BypassPaths,
+	}
+}
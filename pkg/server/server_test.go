@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetupIPExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		want           string
+	}{
+		{"no trusted proxies ignores the header", nil, "10.0.0.2:1234", "10.0.0.2"},
+		{"trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.2:1234", "203.0.113.7"},
+		{"untrusted proxy", []string{"10.0.0.0/8"}, "192.0.2.1:1234", "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			extract, err := setupIPExtractor(tt.trustedProxies)
+			if err != nil {
+				t.Fatalf("setupIPExtractor() error = %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			if got := extract(req); got != tt.want {
+				t.Errorf("client IP = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := setupIPExtractor([]string{"10.0.0.1"}); err == nil {
+		t.Error("setupIPExtractor() should fail for an address that is not a CIDR range")
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/tacokumo/admin-api/internal/redistest"
+	"github.com/tacokumo/admin-api/pkg/config"
+	"github.com/tacokumo/admin-api/pkg/events"
+	"github.com/tacokumo/admin-api/pkg/health"
+)
+
+func TestShutdownEndsStreams(t *testing.T) {
+	t.Parallel()
+
+	// XREAD waits for its whole BLOCK, like Redis does when nothing is published, however
+	// the client's context is cancelled.
+	redis := redistest.NewServer(t)
+	redis.Handle("XREAD", func(args []string) any {
+		for i := 0; i+1 < len(args); i++ {
+			if strings.EqualFold(args[i], "BLOCK") {
+				ms, _ := strconv.Atoi(args[i+1])
+				time.Sleep(time.Duration(ms) * time.Millisecond)
+			}
+		}
+		return nil
+	})
+	stream := events.NewRedisStream(redis.Client(t), redis.Client(t), 0)
+
+	streams, closeStreams := context.WithCancel(context.Background())
+	t.Cleanup(closeStreams)
+
+	timeout := 5 * time.Second
+	e := echo.New()
+	e.GET("/events", func(c echo.Context) error {
+		ctx, cancel := context.WithCancel(c.Request().Context())
+		defer cancel()
+		defer context.AfterFunc(streams, cancel)()
+
+		c.Response().WriteHeader(http.StatusOK)
+		c.Response().Flush()
+		for {
+			// Waits longer than the shutdown timeout, as the heartbeat interval of the events endpoint does.
+			if _, err := stream.Read(ctx, "0-0", 3*timeout); err != nil {
+				return nil
+			}
+		}
+	})
+
+	s := &Server{
+		logger: slog.New(slog.DiscardHandler),
+		cfg: config.Config{Shutdown: config.ShutdownConfig{
+			DrainPeriod:    -1,
+			Timeout:        timeout,
+			CleanupTimeout: time.Second,
+		}},
+		e:          e,
+		health:     health.NewRegistry(health.Config{}),
+		onShutdown: []func(){closeStreams},
+	}
+	srv, err := s.newHTTPServer()
+	if err != nil {
+		t.Fatalf("newHTTPServer() error = %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		err := srv.Serve(ln)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		serveErr <- err
+	}()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/events")
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		_, _ = bufio.NewReader(resp.Body).ReadString('\n')
+	}()
+
+	start := time.Now()
+	if err := s.shutdown(context.Background(), srv, serveErr); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= timeout {
+		t.Errorf("shutdown took %v, want less than the timeout %v", elapsed, timeout)
+	}
+	select {
+	case <-streamDone:
+	case <-time.After(time.Second):
+		t.Errorf("stream is still open after shutdown")
+	}
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ErrDestinationNotAllowed is returned when a webhook URL resolves to an address that is not
+// allowed, such as a loopback or private address.
+var ErrDestinationNotAllowed = errors.New("webhook destination is not allowed")
+
+// internalPrefixes are the ranges that are not reachable from the internet, besides the ones the
+// netip.Addr methods cover. Carrier-grade NAT is often used for cluster networks.
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// allowedDestination reports whether the dispatcher may connect to addr. Any project editor
+// chooses webhook URLs, so internal addresses, such as cloud metadata endpoints at
+// 169.254.169.254, are only reachable when they are in allowed.
+func allowedDestination(addr netip.Addr, allowed []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, p := range allowed {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, p := range internalPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// newTransport returns a transport that only connects to allowed destinations. The check runs
+// on the resolved address of every connection, so that host names resolving to internal
+// addresses are caught as well. Proxies are not used, as they would be checked instead of the
+// receivers.
+func newTransport(allowed []netip.Prefix) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return errors.Wrapf(err, "failed to parse webhook destination %s", address)
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil {
+				return errors.Wrapf(err, "failed to parse webhook destination %s", address)
+			}
+			if !allowedDestination(addr, allowed) {
+				return errors.Wrapf(ErrDestinationNotAllowed, "%s", addr)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+)
+
+func TestAllowedDestination(t *testing.T) {
+	t.Parallel()
+
+	allowed := []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"203.0.113.10", true},
+		{"2001:db8::1", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"10.1.2.3", true},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := allowedDestination(netip.MustParseAddr(tt.addr), allowed); got != tt.want {
+			t.Errorf("allowedDestination(%s) = %t, want %t", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestTransportRefusesInternalDestinations(t *testing.T) {
+	t.Parallel()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+
+	client := &http.Client{Transport: newTransport(nil)}
+	_, err := client.Get(receiver.URL)
+	if !errors.Is(err, ErrDestinationNotAllowed) {
+		t.Errorf("Get() error = %v, want %v", err, ErrDestinationNotAllowed)
+	}
+
+	client = &http.Client{Transport: newTransport([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")})}
+	resp, err := client.Get(receiver.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v, want the allowed network to be reachable", err)
+	}
+	_ = resp.Body.Close()
+}
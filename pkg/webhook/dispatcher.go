@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tacokumo/admin-api/pkg/db/admindb"
+	"github.com/tacokumo/admin-api/pkg/jobs"
+)
+
+const (
+	defaultBatchSize   = 20
+	defaultMaxAttempts = 8
+	defaultTimeout     = 10 * time.Second
+	defaultBackoffBase = 30 * time.Second
+	defaultBackoffMax  = 6 * time.Hour
+
+	// maxResponseBodySize bounds how much of a receiver's response is read before the connection is closed.
+	maxResponseBodySize = 64 << 10
+	// maxErrorLength bounds the error message stored with a failed attempt.
+	maxErrorLength = 1024
+	userAgent      = "tacokumo-admin-webhook/1.0"
+)
+
+// Config controls the dispatcher. Zero values fall back to defaults.
+type Config struct {
+	// BatchSize is the number of deliveries sent per dispatch.
+	BatchSize int
+	// MaxAttempts is the number of attempts after which a delivery is marked dead.
+	MaxAttempts int
+	// Timeout is the timeout of a single HTTP request.
+	Timeout     time.Duration
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// AllowedNetworks are internal networks that deliveries may be sent to. Loopback, private
+	// and link-local addresses are refused unless they are in one of them.
+	AllowedNetworks []netip.Prefix
+}
+
+func (c Config) withDefaults() Config {
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = defaultMaxAttempts
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.BackoffBase <= 0 {
+		c.BackoffBase = defaultBackoffBase
+	}
+	if c.BackoffMax <= 0 {
+		c.BackoffMax = defaultBackoffMax
+	}
+	return c
+}
+
+// Dispatcher delivers queued webhook deliveries. It is run periodically as a job
+// (see HandleDispatchJob). Several replicas can dispatch at the same time; each delivery
+// is leased to a single dispatcher while it is being sent.
+type Dispatcher struct {
+	logger  *slog.Logger
+	queries *admindb.Queries
+	client  *http.Client
+	cfg     Config
+}
+
+func NewDispatcher(logger *slog.Logger, queries *admindb.Queries, cfg Config) *Dispatcher {
+	cfg = cfg.withDefaults()
+	return &Dispatcher{
+		logger:  logger.With(slog.String("component", "webhook-dispatcher")),
+		queries: queries,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: newTransport(cfg.AllowedNetworks),
+			// Redirects are reported as failures instead of being followed to another host.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg: cfg,
+	}
+}
+
+// DispatchJobKind is the kind of the scheduled job that dispatches due deliveries.
+const DispatchJobKind = "webhooks.dispatch"
+
+// HandleDispatchJob is the jobs.Handler of DispatchJobKind.
+func (d *Dispatcher) HandleDispatchJob(ctx context.Context, _ jobs.Job) error {
+	return d.DispatchDue(ctx)
+}
+
+// DispatchDue sends one batch of due deliveries and records the outcome of each.
+func (d *Dispatcher) DispatchDue(ctx context.Context) error {
+	// The lease must outlive the whole batch so that no other dispatcher picks up
+	// a delivery that is still being sent.
+	lease := time.Duration(d.cfg.BatchSize)*d.cfg.Timeout + time.Minute
+	rows, err := d.queries.ClaimDueWebhookDeliveries(ctx, admindb.ClaimDueWebhookDeliveriesParams{
+		Lease:     pgtype.Interval{Microseconds: lease.Microseconds(), Valid: true},
+		BatchSize: int32(d.cfg.BatchSize),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to claim due webhook deliveries")
+	}
+
+	for _, row := range rows {
+		if err := d.deliver(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, row admindb.ClaimDueWebhookDeliveriesRow) error {
+	statusCode, sendErr := d.send(ctx, row)
+	code := pgtype.Int4{}
+	if statusCode != 0 {
+		code = pgtype.Int4{Int32: int32(statusCode), Valid: true}
+	}
+
+	if sendErr == nil {
+		if err := d.queries.MarkWebhookDeliverySucceeded(ctx, admindb.MarkWebhookDeliverySucceededParams{
+			ID:             row.ID,
+			LastStatusCode: code,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to mark webhook delivery as succeeded")
+		}
+		return nil
+	}
+
+	attempts := int(row.Attempts) + 1
+	status := StatusPending
+	if attempts >= d.cfg.MaxAttempts {
+		status = StatusDead
+	}
+	next := time.Now().Add(jobs.Backoff(attempts, d.cfg.BackoffBase, d.cfg.BackoffMax))
+
+	message := sendErr.Error()
+	if len(message) > maxErrorLength {
+		message = message[:maxErrorLength]
+	}
+	d.logger.WarnContext(ctx, "webhook delivery failed",
+		slog.String("delivery_id", row.DisplayID.String()),
+		slog.Int("attempts", attempts),
+		slog.String("status", status),
+		slog.String("error", message),
+	)
+
+	if err := d.queries.MarkWebhookDeliveryFailed(ctx, admindb.MarkWebhookDeliveryFailedParams{
+		ID:             row.ID,
+		Status:         status,
+		LastStatusCode: code,
+		LastError:      pgtype.Text{String: message, Valid: true},
+		NextAttemptAt:  pgtype.Timestamptz{Time: next, Valid: true},
+	}); err != nil {
+		return errors.Wrapf(err, "failed to mark webhook delivery as failed")
+	}
+	return nil
+}
+
+// send posts the payload and returns the response status code, which is 0 if no response was received.
+func (d *Dispatcher) send(ctx context.Context, row admindb.ClaimDueWebhookDeliveriesRow) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, row.Url, bytes.NewReader(row.Payload))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, row.EventType)
+	req.Header.Set(HeaderDelivery, row.DisplayID.String())
+	req.Header.Set(HeaderSignature, Sign(row.Secret, time.Now(), row.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to send webhook request")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBodySize))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, errors.Newf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/tacokumo/admin-api/pkg/events"
+)
+
+const (
+	// HeaderEvent carries the event type of a delivery, e.g. "project.created".
+	HeaderEvent = "X-Tacokumo-Event"
+	// HeaderDelivery carries the delivery ID. Redeliveries get a new ID.
+	HeaderDelivery = "X-Tacokumo-Delivery"
+	// HeaderSignature carries the signature in the "t=<unix seconds>,v1=<hex hmac>" form.
+	HeaderSignature = "X-Tacokumo-Signature"
+
+	// SecretPrefix marks generated webhook secrets.
+	SecretPrefix = "whsec_"
+)
+
+// Delivery statuses stored in webhook_deliveries.status.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	// StatusDead is set once all retries are exhausted. Dead deliveries are only sent again
+	// when they are redelivered manually.
+	StatusDead = "dead"
+)
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	Type string `json:"type"`
+	events.Event
+}
+
+// NewPayload wraps an event for delivery.
+func NewPayload(event events.Event) Payload {
+	return Payload{
+		Type:  event.Type(),
+		Event: event,
+	}
+}
+
+// GenerateSecret returns a new random signing secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate random bytes")
+	}
+	return SecretPrefix + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+// The HMAC-SHA256 is computed over "<unix seconds>.<body>" so that a captured request
+// cannot be replayed with a different timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, computeMAC(secret, t, body))
+}
+
+// Verify checks a signature header produced by Sign. Receivers should also reject
+// timestamps that are too old.
+func Verify(secret string, header string, body []byte) (time.Time, bool) {
+	var t, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || sig == "" {
+		return time.Time{}, false
+	}
+	expected := computeMAC(secret, t, body)
+	return time.Unix(unix, 0), hmac.Equal([]byte(sig), []byte(expected))
+}
+
+func computeMAC(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// MatchEventType reports whether a subscription with the given event type filters
+// receives eventType. Filters are exact types or "<kind>.*"; no filters match everything.
+func MatchEventType(filters []string, eventType string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	kind, _, _ := strings.Cut(eventType, ".")
+	return slices.Contains(filters, eventType) || slices.Contains(filters, kind+".*")
+}
+
+// ValidateEventType checks that a subscription filter names a known kind and action.
+func ValidateEventType(filter string) error {
+	kind, action, ok := strings.Cut(filter, ".")
+	if !ok {
+		return errors.Newf("invalid event type %q: must be <kind>.<action> or <kind>.*", filter)
+	}
+	if !slices.Contains(events.Kinds, events.Kind(kind)) {
+		return errors.Newf("invalid event type %q: unknown kind %q", filter, kind)
+	}
+	if action != "*" && !slices.Contains(events.Actions, events.Action(action)) {
+		return errors.Newf("invalid event type %q: unknown action %q", filter, action)
+	}
+	return nil
+}
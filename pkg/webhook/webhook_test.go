@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/tacokumo/admin-api/pkg/events"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"type":"project.created"}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign("whsec_test", now, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		want   bool
+	}{
+		{name: "valid signature", secret: "whsec_test", header: header, body: body, want: true},
+		{name: "wrong secret", secret: "whsec_other", header: header, body: body, want: false},
+		{name: "tampered body", secret: "whsec_test", header: header, body: []byte(`{"type":"project.deleted"}`), want: false},
+		{name: "tampered timestamp", secret: "whsec_test", header: "t=1700000001," + header[len("t=1700000000,"):], body: body, want: false},
+		{name: "malformed header", secret: "whsec_test", header: "garbage", body: body, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ts, ok := Verify(tt.secret, tt.header, tt.body)
+			if ok != tt.want {
+				t.Fatalf("Verify() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && !ts.Equal(now) {
+				t.Errorf("Verify() timestamp = %v, want %v", ts, now)
+			}
+		})
+	}
+}
+
+func TestMatchEventType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		filters   []string
+		eventType string
+		want      bool
+	}{
+		{name: "no filters match everything", filters: nil, eventType: "role.deleted", want: true},
+		{name: "exact match", filters: []string{"project.created"}, eventType: "project.created", want: true},
+		{name: "wildcard match", filters: []string{"role.*"}, eventType: "role.updated", want: true},
+		{name: "different action", filters: []string{"project.created"}, eventType: "project.deleted", want: false},
+		{name: "different kind", filters: []string{"role.*"}, eventType: "usergroup.updated", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := MatchEventType(tt.filters, tt.eventType); got != tt.want {
+				t.Errorf("MatchEventType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateEventType(t *testing.T) {
+	t.Parallel()
+
+	for _, filter := range []string{"project.created", "usergroup_member.*", "role_assignment.deleted"} {
+		if err := ValidateEventType(filter); err != nil {
+			t.Errorf("ValidateEventType(%q) returned error: %v", filter, err)
+		}
+	}
+	for _, filter := range []string{"project", "project.archived", "team.created", "*"} {
+		if err := ValidateEventType(filter); err == nil {
+			t.Errorf("ValidateEventType(%q) = nil, want error", filter)
+		}
+	}
+}
+
+func TestPayloadJSON(t *testing.T) {
+	t.Parallel()
+
+	payload := NewPayload(events.Event{
+		ID:         "1-0",
+		Kind:       events.KindProject,
+		Action:     events.ActionCreated,
+		ResourceID: "p1",
+		ProjectID:  "p1",
+	})
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v", err)
+	}
+	if got["type"] != "project.created" || got["resourceId"] != "p1" || got["id"] != "1-0" {
+		t.Errorf("unexpected payload: %s", data)
+	}
+}
@@ -16,13 +16,48 @@ generate_api_server_cert() {
     -addext "subjectAltName=DNS:localhost,IP:127.0.0.1"
 }
 
+generate_client_ca() {
+  echo "Generating client CA (for mTLS)..."
+  openssl genrsa -out "${CERTS_DIR}/client-ca.key" 2048
+  openssl req -new -x509 -key "${CERTS_DIR}/client-ca.key" \
+    -out "${CERTS_DIR}/client-ca.crt" \
+    -days 365 \
+    -subj "/C=JP/ST=Tokyo/L=Tokyo/O=TacokumoAPI/CN=Tacokumo Dev Client CA" \
+    -addext "basicConstraints=critical,CA:TRUE" \
+    -addext "keyUsage=critical,keyCertSign,cRLSign"
+}
+
+generate_client_cert() {
+  local name="$1"
+  echo "Generating client certificate for ${name} (for mTLS)..."
+  openssl genrsa -out "${CERTS_DIR}/${name}.key" 2048
+  openssl req -new -key "${CERTS_DIR}/${name}.key" \
+    -out "${CERTS_DIR}/${name}.csr" \
+    -subj "/C=JP/ST=Tokyo/L=Tokyo/O=TacokumoAPI/CN=${name}"
+  openssl x509 -req -in "${CERTS_DIR}/${name}.csr" \
+    -CA "${CERTS_DIR}/client-ca.crt" -CAkey "${CERTS_DIR}/client-ca.key" -CAcreateserial \
+    -out "${CERTS_DIR}/${name}.crt" \
+    -days 365 \
+    -extfile <(printf "subjectAltName=URI:spiffe://tacokumo.local/%s\nextendedKeyUsage=clientAuth\n" "${name}")
+  rm -f "${CERTS_DIR}/${name}.csr"
+}
+
 mkdir -p "${CERTS_DIR}"
 
 # Generate API server certificate
 generate_api_server_cert
 
+# Generate client CA and a client certificate for service-to-service requests
+generate_client_ca
+generate_client_cert "dev-service"
+
 echo "Certificates generated successfully in ${CERTS_DIR}"
 echo ""
 echo "API server certificates (HTTPS):"
 echo "  - ${CERTS_DIR}/api-server.key"
 echo "  - ${CERTS_DIR}/api-server.crt"
+echo ""
+echo "Client certificates (mTLS):"
+echo "  - ${CERTS_DIR}/client-ca.crt (tls.client_ca_file)"
+echo "  - ${CERTS_DIR}/dev-service.key"
+echo "  - ${CERTS_DIR}/dev-service.crt (SAN spiffe://tacokumo.local/dev-service)"
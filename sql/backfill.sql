@@ -0,0 +1,45 @@
+-- スキーマ適用後に実行するデータ移行 (make migrateで実行される)
+-- 何度実行しても同じ結果になるように書くこと
+
+BEGIN;
+
+-- kindの導入前に作成されたプロジェクトはすべてpersonalになっているが､
+-- オーナーが1人ではないものやユーザグループを持つものはpersonalの制約を満たさない｡
+-- これらはsharedに変換し､既存のオーナーをメンバーとするオーナーグループを作成する｡
+-- オーナーがいないプロジェクトのオーナーグループは空になるため､管理者がメンバーを追加する必要がある｡
+CREATE TEMPORARY TABLE legacy_personal_projects ON COMMIT DROP AS
+SELECT
+  p.id,
+  -- 既存のユーザグループと名前が衝突する場合は､プロジェクトIDを付けた名前にする
+  CASE
+    WHEN EXISTS (SELECT 1 FROM tacokumo_admin.usergroups g WHERE g.project_id = p.id AND g.name = 'owners')
+    THEN 'owners-' || p.display_id::TEXT
+    ELSE 'owners'
+  END AS group_name
+FROM tacokumo_admin.projects p
+WHERE p.kind = 'personal'
+  AND (
+    (SELECT COUNT(*) FROM tacokumo_admin.project_owners o WHERE o.project_id = p.id) <> 1
+    OR EXISTS (SELECT 1 FROM tacokumo_admin.usergroups g WHERE g.project_id = p.id)
+  );
+
+INSERT INTO tacokumo_admin.usergroups (project_id, name, description)
+SELECT l.id, l.group_name, 'Owners of the project'
+FROM legacy_personal_projects l;
+
+INSERT INTO tacokumo_admin.user_usergroups_relations (user_id, usergroup_id)
+SELECT o.user_id, g.id
+FROM legacy_personal_projects l
+JOIN tacokumo_admin.project_owners o ON o.project_id = l.id
+JOIN tacokumo_admin.usergroups g ON g.project_id = l.id AND g.name = l.group_name;
+
+INSERT INTO tacokumo_admin.project_owner_groups (project_id, usergroup_id)
+SELECT l.id, g.id
+FROM legacy_personal_projects l
+JOIN tacokumo_admin.usergroups g ON g.project_id = l.id AND g.name = l.group_name;
+
+UPDATE tacokumo_admin.projects
+SET kind = 'shared', version = version + 1, updated_at = NOW()
+WHERE id IN (SELECT id FROM legacy_personal_projects);
+
+COMMIT;
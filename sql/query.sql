@@ -5,58 +5,165 @@ SELECT 1;
 INSERT INTO tacokumo_admin.projects (name, description, kind) VALUES ($1, $2, $3);
 
 -- name: ListProjectsWithPagination :many
-SELECT id, display_id, name, description, kind, created_at, updated_at
+-- The label_* arguments come from a label selector (see pkg/labels); NULL skips the requirement.
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
 FROM tacokumo_admin.projects
+WHERE (@include_archived::BOOLEAN OR archived_at IS NULL)
+  AND (sqlc.narg('label_equals')::JSONB IS NULL OR labels @> sqlc.narg('label_equals')::JSONB)
+  AND (sqlc.narg('label_exists')::TEXT[] IS NULL OR labels ?& sqlc.narg('label_exists')::TEXT[])
+  AND (sqlc.narg('label_not_exists')::TEXT[] IS NULL OR NOT (labels ?| sqlc.narg('label_not_exists')::TEXT[]))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE(sqlc.narg('label_in')::JSONB, '[]')) r
+    WHERE NOT COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE(sqlc.narg('label_not_in')::JSONB, '[]')) r
+    WHERE COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 
 -- name: GetProjectByDisplayID :one
-SELECT id, display_id, name, description, kind, created_at, updated_at
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
 FROM tacokumo_admin.projects
 WHERE display_id = $1;
 
+-- name: GetProjectByID :one
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+FROM tacokumo_admin.projects
+WHERE id = $1;
+
 -- name: GetProjectByName :one
-SELECT id, display_id, name, description, kind, created_at, updated_at
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
 FROM tacokumo_admin.projects
 WHERE name = $1;
 
--- name: UpdateProject :exec
+-- name: UpdateProject :execrows
+-- expected_versions is the list of versions from If-Match; NULL skips the check.
+-- kind, labels and annotations are left unchanged when NULL.
 UPDATE tacokumo_admin.projects
-SET (name, description, updated_at) = ($2, $3, NOW())
-WHERE display_id = $1;
+SET name = $2,
+    description = $3,
+    kind = COALESCE(sqlc.narg('kind'), kind),
+    labels = COALESCE(sqlc.narg('labels')::JSONB, labels),
+    annotations = COALESCE(sqlc.narg('annotations')::JSONB, annotations),
+    version = version + 1,
+    updated_at = NOW()
+WHERE display_id = $1
+  AND (sqlc.narg('expected_versions')::BIGINT[] IS NULL OR version = ANY(sqlc.narg('expected_versions')::BIGINT[]));
+
+-- name: LockProjectForChange :one
+-- Takes a key share lock on the project row for the rest of the transaction. It conflicts with
+-- LockProjectForArchival but not with updates of the row, so changes of a project run
+-- concurrently with each other but not with its archival.
+SELECT archived_at
+FROM tacokumo_admin.projects
+WHERE id = $1
+FOR KEY SHARE;
+
+-- name: LockProjectForArchival :exec
+-- Waits for the changes that hold LockProjectForChange and keeps new ones waiting until the
+-- transaction ends. ArchiveProject alone takes a weaker lock that does not conflict with them.
+SELECT id
+FROM tacokumo_admin.projects
+WHERE display_id = $1
+FOR UPDATE;
+
+-- name: ArchiveProject :execrows
+-- expected_versions is the list of versions from If-Match; NULL skips the check.
+UPDATE tacokumo_admin.projects
+SET archived_at = NOW(),
+    archived_by = $2,
+    version = version + 1,
+    updated_at = NOW()
+WHERE display_id = $1
+  AND archived_at IS NULL
+  AND (sqlc.narg('expected_versions')::BIGINT[] IS NULL OR version = ANY(sqlc.narg('expected_versions')::BIGINT[]));
+
+-- name: UnarchiveProject :execrows
+-- expected_versions is the list of versions from If-Match; NULL skips the check.
+UPDATE tacokumo_admin.projects
+SET archived_at = NULL,
+    archived_by = NULL,
+    version = version + 1,
+    updated_at = NOW()
+WHERE display_id = $1
+  AND archived_at IS NOT NULL
+  AND (sqlc.narg('expected_versions')::BIGINT[] IS NULL OR version = ANY(sqlc.narg('expected_versions')::BIGINT[]));
+
+-- name: DeleteArchivedProjectsBefore :many
+-- Deleting a project cascades to its roles, user groups, memberships and webhooks.
+DELETE FROM tacokumo_admin.projects
+WHERE archived_at < $1
+RETURNING display_id;
 
--- name: CreateRole :exec
-INSERT INTO tacokumo_admin.roles (project_id, name, description) VALUES ($1, $2, $3);
+-- name: DeleteProjectOwners :exec
+DELETE FROM tacokumo_admin.project_owners
+WHERE project_id = $1;
+
+-- name: AddProjectOwner :exec
+INSERT INTO tacokumo_admin.project_owners (project_id, user_id) VALUES ($1, $2)
+ON CONFLICT (project_id, user_id) DO NOTHING;
+
+-- name: CreateRole :one
+INSERT INTO tacokumo_admin.roles (project_id, name, description) VALUES ($1, $2, $3)
+RETURNING display_id;
 
 -- name: GetRoleByDisplayID :one
-SELECT id, display_id, project_id, name, description, created_at, updated_at
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
 FROM tacokumo_admin.roles
 WHERE project_id = $1 AND display_id = $2;
 
 -- name: ListRolesWithPagination :many
-SELECT id, display_id, project_id, name, description, created_at, updated_at
+-- The label_* arguments come from a label selector (see pkg/labels); NULL skips the requirement.
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
 FROM tacokumo_admin.roles
 WHERE project_id = $1
+  AND (sqlc.narg('label_equals')::JSONB IS NULL OR labels @> sqlc.narg('label_equals')::JSONB)
+  AND (sqlc.narg('label_exists')::TEXT[] IS NULL OR labels ?& sqlc.narg('label_exists')::TEXT[])
+  AND (sqlc.narg('label_not_exists')::TEXT[] IS NULL OR NOT (labels ?| sqlc.narg('label_not_exists')::TEXT[]))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE(sqlc.narg('label_in')::JSONB, '[]')) r
+    WHERE NOT COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE(sqlc.narg('label_not_in')::JSONB, '[]')) r
+    WHERE COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
--- name: UpdateRole :exec
+-- name: UpdateRole :execrows
+-- labels and annotations are left unchanged when NULL.
 UPDATE tacokumo_admin.roles
-SET (name, description, updated_at) = ($3, $4, NOW())
-WHERE project_id = $1 AND display_id = $2;
+SET name = $3,
+    description = $4,
+    labels = COALESCE(sqlc.narg('labels')::JSONB, labels),
+    annotations = COALESCE(sqlc.narg('annotations')::JSONB, annotations),
+    version = version + 1,
+    updated_at = NOW()
+WHERE project_id = $1 AND display_id = $2
+  AND (sqlc.narg('expected_versions')::BIGINT[] IS NULL OR version = ANY(sqlc.narg('expected_versions')::BIGINT[]));
 
--- name: CreateUserGroup :exec
-INSERT INTO tacokumo_admin.usergroups (project_id, name, description) VALUES ($1, $2, $3);
+-- name: CreateUserGroup :one
+INSERT INTO tacokumo_admin.usergroups (project_id, name, description) VALUES ($1, $2, $3)
+RETURNING display_id;
 
 -- name: GetUserGroupByDisplayID :one
-SELECT id, display_id, project_id, name, description, created_at, updated_at
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
 FROM tacokumo_admin.usergroups
 WHERE project_id = $1 AND display_id = $2;
 
 -- name: ListUserGroupsWithPagination :many
-SELECT id, display_id, project_id, name, description, created_at, updated_at
+-- The label_* arguments come from a label selector (see pkg/labels); NULL skips the requirement.
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
 FROM tacokumo_admin.usergroups
 WHERE project_id = $1
+  AND (sqlc.narg('label_equals')::JSONB IS NULL OR labels @> sqlc.narg('label_equals')::JSONB)
+  AND (sqlc.narg('label_exists')::TEXT[] IS NULL OR labels ?& sqlc.narg('label_exists')::TEXT[])
+  AND (sqlc.narg('label_not_exists')::TEXT[] IS NULL OR NOT (labels ?| sqlc.narg('label_not_exists')::TEXT[]))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE(sqlc.narg('label_in')::JSONB, '[]')) r
+    WHERE NOT COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
+  AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(COALESCE(sqlc.narg('label_not_in')::JSONB, '[]')) r
+    WHERE COALESCE(r->'values' ? (labels->>(r->>'key')), FALSE))
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
@@ -71,10 +178,25 @@ SELECT u.id,
   WHERE uur.usergroup_id = $1
   ORDER BY u.created_at DESC;
 
--- name: UpdateUserGroup :exec
+-- name: UpdateUserGroup :execrows
+-- labels and annotations are left unchanged when NULL.
 UPDATE tacokumo_admin.usergroups
-SET (name, description, updated_at) = ($3, $4, NOW())
-WHERE project_id = $1 AND display_id = $2;
+SET name = $3,
+    description = $4,
+    labels = COALESCE(sqlc.narg('labels')::JSONB, labels),
+    annotations = COALESCE(sqlc.narg('annotations')::JSONB, annotations),
+    version = version + 1,
+    updated_at = NOW()
+WHERE project_id = $1 AND display_id = $2
+  AND (sqlc.narg('expected_versions')::BIGINT[] IS NULL OR version = ANY(sqlc.narg('expected_versions')::BIGINT[]));
+
+-- name: DeleteUserGroupMembers :exec
+DELETE FROM tacokumo_admin.user_usergroups_relations
+WHERE usergroup_id = $1;
+
+-- name: AddUserToUserGroup :exec
+INSERT INTO tacokumo_admin.user_usergroups_relations (user_id, usergroup_id) VALUES ($1, $2)
+ON CONFLICT (user_id, usergroup_id) DO NOTHING;
 
 -- name: CreateUser :exec
 INSERT INTO tacokumo_admin.users (email) VALUES ($1);
@@ -93,4 +215,773 @@ LIMIT $1 OFFSET $2;
 -- name: UpdateUser :exec
 UPDATE tacokumo_admin.users
 SET (email, updated_at) = ($2, NOW())
-WHERE display_id = $1; 
\ No newline at end of file
+WHERE display_id = $1; 
+
+-- name: GetUserByDisplayID :one
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+WHERE display_id = $1;
+
+-- name: GetUserByID :one
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+WHERE id = $1;
+
+-- name: CreateServiceAccount :one
+INSERT INTO tacokumo_admin.service_accounts (project_id, name, description, owner_user_id)
+VALUES ($1, $2, $3, $4)
+RETURNING display_id;
+
+-- name: GetServiceAccountByDisplayID :one
+SELECT sa.id,
+      sa.display_id,
+      sa.project_id,
+      p.display_id AS project_display_id,
+      sa.name,
+      sa.description,
+      sa.owner_user_id,
+      u.display_id AS owner_display_id,
+      sa.disabled,
+      sa.created_at,
+      sa.updated_at
+  FROM tacokumo_admin.service_accounts sa
+  LEFT JOIN tacokumo_admin.projects p ON sa.project_id = p.id
+  LEFT JOIN tacokumo_admin.users u ON sa.owner_user_id = u.id
+  WHERE sa.display_id = $1;
+
+-- name: ListServiceAccountsWithPagination :many
+SELECT sa.id,
+      sa.display_id,
+      sa.project_id,
+      p.display_id AS project_display_id,
+      sa.name,
+      sa.description,
+      sa.owner_user_id,
+      u.display_id AS owner_display_id,
+      sa.disabled,
+      sa.created_at,
+      sa.updated_at
+  FROM tacokumo_admin.service_accounts sa
+  LEFT JOIN tacokumo_admin.projects p ON sa.project_id = p.id
+  LEFT JOIN tacokumo_admin.users u ON sa.owner_user_id = u.id
+  WHERE sqlc.narg('project_id')::BIGINT IS NULL OR sa.project_id = sqlc.narg('project_id')::BIGINT
+  ORDER BY sa.created_at DESC
+  LIMIT $1 OFFSET $2;
+
+-- name: UpdateServiceAccount :execrows
+UPDATE tacokumo_admin.service_accounts
+SET (name, description, owner_user_id, disabled, updated_at) = ($2, $3, $4, $5, NOW())
+WHERE display_id = $1;
+
+-- name: DeleteServiceAccount :execrows
+DELETE FROM tacokumo_admin.service_accounts
+WHERE display_id = $1;
+
+-- name: CreateServiceAccountToken :one
+INSERT INTO tacokumo_admin.service_account_tokens (service_account_id, description, token_prefix, token_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, display_id, service_account_id, description, token_prefix, token_hash, expires_at, last_used_at, revoked_at, created_at, updated_at;
+
+-- name: ListServiceAccountTokens :many
+SELECT id, display_id, service_account_id, description, token_prefix, token_hash, expires_at, last_used_at, revoked_at, created_at, updated_at
+FROM tacokumo_admin.service_account_tokens
+WHERE service_account_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeServiceAccountToken :execrows
+UPDATE tacokumo_admin.service_account_tokens
+SET (revoked_at, updated_at) = (NOW(), NOW())
+WHERE service_account_id = $1 AND display_id = $2 AND revoked_at IS NULL;
+
+-- name: GetServiceAccountByTokenHash :one
+SELECT sa.id,
+      sa.display_id,
+      sa.project_id,
+      sa.name,
+      sa.disabled,
+      t.id AS token_id,
+      t.expires_at,
+      t.revoked_at
+  FROM tacokumo_admin.service_account_tokens t
+  INNER JOIN tacokumo_admin.service_accounts sa ON t.service_account_id = sa.id
+  WHERE t.token_hash = $1;
+
+-- name: TouchServiceAccountToken :exec
+UPDATE tacokumo_admin.service_account_tokens
+SET last_used_at = NOW()
+WHERE id = $1;
+
+-- name: AddServiceAccountToUserGroup :execrows
+INSERT INTO tacokumo_admin.service_account_usergroups_relations (service_account_id, usergroup_id)
+VALUES ($1, $2)
+ON CONFLICT (service_account_id, usergroup_id) DO NOTHING;
+
+-- name: RemoveServiceAccountFromUserGroup :execrows
+DELETE FROM tacokumo_admin.service_account_usergroups_relations
+WHERE service_account_id = $1 AND usergroup_id = $2;
+
+-- name: AssignRoleToServiceAccount :execrows
+INSERT INTO tacokumo_admin.service_account_role_relations (service_account_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT (service_account_id, role_id) DO NOTHING;
+
+-- name: UnassignRoleFromServiceAccount :execrows
+DELETE FROM tacokumo_admin.service_account_role_relations
+WHERE service_account_id = $1 AND role_id = $2;
+
+-- name: ListServiceAccountUserGroups :many
+SELECT ug.id, ug.display_id, ug.project_id, ug.name, ug.description, ug.version, ug.created_at, ug.updated_at, ug.labels, ug.annotations
+  FROM tacokumo_admin.usergroups ug
+  INNER JOIN tacokumo_admin.service_account_usergroups_relations r ON ug.id = r.usergroup_id
+  WHERE r.service_account_id = $1
+  ORDER BY ug.created_at DESC;
+
+-- name: ListServiceAccountRoles :many
+SELECT ro.id, ro.display_id, ro.project_id, ro.name, ro.description, ro.version, ro.created_at, ro.updated_at, ro.labels, ro.annotations
+  FROM tacokumo_admin.roles ro
+  INNER JOIN tacokumo_admin.service_account_role_relations r ON ro.id = r.role_id
+  WHERE r.service_account_id = $1
+  ORDER BY ro.created_at DESC;
+
+-- name: CreateWebhookSubscription :one
+INSERT INTO tacokumo_admin.webhook_subscriptions (project_id, url, description, event_types, secret)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at;
+
+-- name: GetWebhookSubscriptionByDisplayID :one
+SELECT id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+FROM tacokumo_admin.webhook_subscriptions
+WHERE project_id = $1 AND display_id = $2;
+
+-- name: ListWebhookSubscriptionsWithPagination :many
+SELECT id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at
+FROM tacokumo_admin.webhook_subscriptions
+WHERE project_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListActiveWebhookSubscriptionsByProject :many
+SELECT ws.id, ws.display_id, ws.project_id, ws.url, ws.description, ws.event_types, ws.secret, ws.disabled, ws.created_at, ws.updated_at
+FROM tacokumo_admin.webhook_subscriptions ws
+INNER JOIN tacokumo_admin.projects p ON p.id = ws.project_id
+WHERE p.display_id = $1 AND NOT ws.disabled;
+
+-- name: UpdateWebhookSubscription :execrows
+-- The secret is only changed by RotateWebhookSecret, so that an update cannot restore a secret
+-- that was rotated after it was read.
+UPDATE tacokumo_admin.webhook_subscriptions
+SET (url, description, event_types, disabled, updated_at) = ($3, $4, $5, $6, NOW())
+WHERE project_id = $1 AND display_id = $2;
+
+-- name: RotateWebhookSecret :one
+UPDATE tacokumo_admin.webhook_subscriptions
+SET secret = $3, updated_at = NOW()
+WHERE project_id = $1 AND display_id = $2
+RETURNING id, display_id, project_id, url, description, event_types, secret, disabled, created_at, updated_at;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM tacokumo_admin.webhook_subscriptions
+WHERE project_id = $1 AND display_id = $2;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO tacokumo_admin.webhook_deliveries (subscription_id, event_type, payload)
+VALUES ($1, $2, $3)
+RETURNING id, display_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at;
+
+-- name: GetWebhookDeliveryByDisplayID :one
+SELECT id, display_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+FROM tacokumo_admin.webhook_deliveries
+WHERE subscription_id = $1 AND display_id = $2;
+
+-- name: ListWebhookDeliveriesWithPagination :many
+-- status filters by delivery status; NULL returns all deliveries.
+SELECT id, display_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+FROM tacokumo_admin.webhook_deliveries
+WHERE subscription_id = $1
+  AND (sqlc.narg('status')::VARCHAR IS NULL OR status = sqlc.narg('status')::VARCHAR)
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ClaimDueWebhookDeliveries :many
+-- Leases up to batch_size due deliveries by pushing next_attempt_at forward, so that other
+-- replicas skip them while they are being delivered. Deliveries of disabled webhooks stay
+-- pending until the webhook is enabled again.
+UPDATE tacokumo_admin.webhook_deliveries d
+SET next_attempt_at = NOW() + sqlc.arg('lease')::INTERVAL, updated_at = NOW()
+FROM tacokumo_admin.webhook_subscriptions ws
+WHERE d.subscription_id = ws.id
+  AND NOT ws.disabled
+  AND d.id IN (
+    SELECT due.id FROM tacokumo_admin.webhook_deliveries due
+    INNER JOIN tacokumo_admin.webhook_subscriptions dws ON dws.id = due.subscription_id
+    WHERE due.status = 'pending' AND due.next_attempt_at <= NOW() AND NOT dws.disabled
+    ORDER BY due.next_attempt_at
+    LIMIT sqlc.arg('batch_size')::INT
+    FOR UPDATE OF due SKIP LOCKED
+  )
+RETURNING d.id, d.display_id, d.event_type, d.payload, d.attempts, ws.url, ws.secret;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE tacokumo_admin.webhook_deliveries
+SET status = 'succeeded',
+    attempts = attempts + 1,
+    last_attempt_at = NOW(),
+    last_status_code = $2,
+    last_error = NULL,
+    delivered_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+-- status is 'pending' to retry at next_attempt_at, or 'dead' once retries are exhausted.
+UPDATE tacokumo_admin.webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    last_attempt_at = NOW(),
+    last_status_code = $3,
+    last_error = $4,
+    next_attempt_at = $5,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: EnqueueJob :one
+-- Returns no rows when a job with the same unique_key already exists.
+INSERT INTO tacokumo_admin.jobs (kind, payload, max_attempts, run_at, unique_key)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (unique_key) DO NOTHING
+RETURNING id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at;
+
+-- name: ClaimJobs :many
+-- Leases runnable jobs to a worker. Running jobs whose lease expired are picked up again,
+-- which covers workers that died while running a job.
+UPDATE tacokumo_admin.jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_by = sqlc.arg('worker'),
+    locked_until = NOW() + sqlc.arg('lease')::INTERVAL,
+    started_at = NOW(),
+    updated_at = NOW()
+WHERE id IN (
+  SELECT id FROM tacokumo_admin.jobs
+  WHERE kind = ANY(sqlc.arg('kinds')::TEXT[])
+    AND ((status = 'pending' AND run_at <= NOW()) OR (status = 'running' AND locked_until < NOW()))
+  ORDER BY run_at
+  LIMIT sqlc.arg('batch_size')::INT
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at;
+
+-- name: CompleteJob :exec
+UPDATE tacokumo_admin.jobs
+SET status = 'succeeded', locked_by = NULL, locked_until = NULL, last_error = NULL, finished_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND locked_by = $2;
+
+-- name: RetryJob :exec
+UPDATE tacokumo_admin.jobs
+SET status = 'pending', locked_by = NULL, locked_until = NULL, last_error = $3, run_at = $4, updated_at = NOW()
+WHERE id = $1 AND locked_by = $2;
+
+-- name: FailJob :exec
+UPDATE tacokumo_admin.jobs
+SET status = 'failed', locked_by = NULL, locked_until = NULL, last_error = $3, finished_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND locked_by = $2;
+
+-- name: GetJobByDisplayID :one
+SELECT id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
+FROM tacokumo_admin.jobs
+WHERE display_id = $1;
+
+-- name: ListJobsWithPagination :many
+-- status and kind filter the jobs; NULL matches all.
+SELECT id, display_id, kind, payload, status, attempts, max_attempts, run_at, locked_by, locked_until, unique_key, last_error, started_at, finished_at, created_at, updated_at
+FROM tacokumo_admin.jobs
+WHERE (sqlc.narg('status')::VARCHAR IS NULL OR status = sqlc.narg('status')::VARCHAR)
+  AND (sqlc.narg('kind')::VARCHAR IS NULL OR kind = sqlc.narg('kind')::VARCHAR)
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountJobsByStatus :many
+SELECT kind, status, COUNT(*) AS count
+FROM tacokumo_admin.jobs
+GROUP BY kind, status
+ORDER BY kind, status;
+
+-- name: RequeueFailedJob :execrows
+UPDATE tacokumo_admin.jobs
+SET status = 'pending', attempts = 0, run_at = NOW(), last_error = NULL, finished_at = NULL, updated_at = NOW()
+WHERE display_id = $1 AND status = 'failed';
+
+-- name: DeleteFinishedJobsBefore :execrows
+DELETE FROM tacokumo_admin.jobs
+WHERE status IN ('succeeded', 'failed') AND finished_at < $1;
+
+-- name: DeleteUserByDisplayID :execrows
+DELETE FROM tacokumo_admin.users
+WHERE display_id = $1;
+
+-- name: DeleteUserGroupByDisplayID :execrows
+DELETE FROM tacokumo_admin.usergroups
+WHERE project_id = $1 AND display_id = $2;
+
+-- name: RemoveUserFromUserGroup :execrows
+DELETE FROM tacokumo_admin.user_usergroups_relations
+WHERE user_id = $1 AND usergroup_id = $2;
+
+-- name: ListSCIMUsers :many
+-- Patterns are ILIKE patterns (LIKE for the case exact external_id); NULL matches all.
+SELECT u.id, u.display_id, u.email, u.created_at, u.updated_at,
+       su.external_id, COALESCE(su.display_name, '')::VARCHAR AS display_name, COALESCE(su.active, TRUE)::BOOLEAN AS active,
+       GREATEST(u.updated_at, su.updated_at)::TIMESTAMPTZ AS last_modified
+FROM tacokumo_admin.users u
+LEFT JOIN tacokumo_admin.scim_users su ON su.user_id = u.id
+WHERE (sqlc.narg('display_id')::UUID IS NULL OR u.display_id = sqlc.narg('display_id')::UUID)
+  AND (sqlc.narg('email_pattern')::VARCHAR IS NULL OR u.email ILIKE sqlc.narg('email_pattern')::VARCHAR)
+  AND (sqlc.narg('external_id_pattern')::VARCHAR IS NULL OR su.external_id LIKE sqlc.narg('external_id_pattern')::VARCHAR)
+  AND (sqlc.narg('display_name_pattern')::VARCHAR IS NULL OR su.display_name ILIKE sqlc.narg('display_name_pattern')::VARCHAR)
+ORDER BY u.id
+LIMIT $1 OFFSET $2;
+
+-- name: CountSCIMUsers :one
+SELECT COUNT(*)
+FROM tacokumo_admin.users u
+LEFT JOIN tacokumo_admin.scim_users su ON su.user_id = u.id
+WHERE (sqlc.narg('display_id')::UUID IS NULL OR u.display_id = sqlc.narg('display_id')::UUID)
+  AND (sqlc.narg('email_pattern')::VARCHAR IS NULL OR u.email ILIKE sqlc.narg('email_pattern')::VARCHAR)
+  AND (sqlc.narg('external_id_pattern')::VARCHAR IS NULL OR su.external_id LIKE sqlc.narg('external_id_pattern')::VARCHAR)
+  AND (sqlc.narg('display_name_pattern')::VARCHAR IS NULL OR su.display_name ILIKE sqlc.narg('display_name_pattern')::VARCHAR);
+
+-- name: UpsertSCIMUser :exec
+INSERT INTO tacokumo_admin.scim_users (user_id, external_id, display_name, active)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET external_id = EXCLUDED.external_id, display_name = EXCLUDED.display_name, active = EXCLUDED.active, updated_at = NOW();
+
+-- name: ListSCIMGroups :many
+SELECT g.id, g.display_id, g.project_id, g.name, g.description, g.version, g.created_at, g.updated_at,
+       sg.external_id
+FROM tacokumo_admin.usergroups g
+LEFT JOIN tacokumo_admin.scim_groups sg ON sg.usergroup_id = g.id
+WHERE g.project_id = $1
+  AND (sqlc.narg('display_id')::UUID IS NULL OR g.display_id = sqlc.narg('display_id')::UUID)
+  AND (sqlc.narg('name_pattern')::VARCHAR IS NULL OR g.name ILIKE sqlc.narg('name_pattern')::VARCHAR)
+  AND (sqlc.narg('external_id_pattern')::VARCHAR IS NULL OR sg.external_id LIKE sqlc.narg('external_id_pattern')::VARCHAR)
+ORDER BY g.id
+LIMIT $2 OFFSET $3;
+
+-- name: CountSCIMGroups :one
+SELECT COUNT(*)
+FROM tacokumo_admin.usergroups g
+LEFT JOIN tacokumo_admin.scim_groups sg ON sg.usergroup_id = g.id
+WHERE g.project_id = $1
+  AND (sqlc.narg('display_id')::UUID IS NULL OR g.display_id = sqlc.narg('display_id')::UUID)
+  AND (sqlc.narg('name_pattern')::VARCHAR IS NULL OR g.name ILIKE sqlc.narg('name_pattern')::VARCHAR)
+  AND (sqlc.narg('external_id_pattern')::VARCHAR IS NULL OR sg.external_id LIKE sqlc.narg('external_id_pattern')::VARCHAR);
+
+-- name: UpsertSCIMGroup :exec
+INSERT INTO tacokumo_admin.scim_groups (usergroup_id, external_id)
+VALUES ($1, $2)
+ON CONFLICT (usergroup_id) DO UPDATE
+SET external_id = EXCLUDED.external_id, updated_at = NOW();
+
+-- name: GetUserByEmailFold :one
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+WHERE lower(email) = lower(@email::VARCHAR);
+
+-- name: AssignRoleToUser :execrows
+INSERT INTO tacokumo_admin.user_role_relations (user_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, role_id) DO NOTHING;
+
+-- name: CreateInvitation :one
+INSERT INTO tacokumo_admin.invitations (project_id, email, token_hash, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at;
+
+-- name: AddInvitationRole :exec
+INSERT INTO tacokumo_admin.invitation_roles (invitation_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT (invitation_id, role_id) DO NOTHING;
+
+-- name: AddInvitationUserGroup :exec
+INSERT INTO tacokumo_admin.invitation_usergroups (invitation_id, usergroup_id)
+VALUES ($1, $2)
+ON CONFLICT (invitation_id, usergroup_id) DO NOTHING;
+
+-- name: ListInvitationRoles :many
+SELECT ro.id, ro.display_id, ro.project_id, ro.name, ro.description, ro.version, ro.created_at, ro.updated_at, ro.labels, ro.annotations
+  FROM tacokumo_admin.roles ro
+  INNER JOIN tacokumo_admin.invitation_roles r ON ro.id = r.role_id
+  WHERE r.invitation_id = $1
+  ORDER BY ro.created_at DESC;
+
+-- name: ListInvitationUserGroups :many
+SELECT ug.id, ug.display_id, ug.project_id, ug.name, ug.description, ug.version, ug.created_at, ug.updated_at, ug.labels, ug.annotations
+  FROM tacokumo_admin.usergroups ug
+  INNER JOIN tacokumo_admin.invitation_usergroups r ON ug.id = r.usergroup_id
+  WHERE r.invitation_id = $1
+  ORDER BY ug.created_at DESC;
+
+-- name: GetInvitationByDisplayID :one
+SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+FROM tacokumo_admin.invitations
+WHERE project_id = $1 AND display_id = $2;
+
+-- name: ListInvitationsWithPagination :many
+-- status filters by invitation status; 'expired' matches pending invitations past their expiry.
+SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+FROM tacokumo_admin.invitations
+WHERE project_id = $1
+  AND (sqlc.narg('status')::VARCHAR IS NULL
+    OR (sqlc.narg('status')::VARCHAR = 'expired' AND status = 'pending' AND expires_at <= NOW())
+    OR (sqlc.narg('status')::VARCHAR = 'pending' AND status = 'pending' AND expires_at > NOW())
+    OR (sqlc.narg('status')::VARCHAR NOT IN ('pending', 'expired') AND status = sqlc.narg('status')::VARCHAR))
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetPendingInvitationByTokenHash :one
+SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+FROM tacokumo_admin.invitations
+WHERE token_hash = $1 AND status = 'pending'
+FOR UPDATE;
+
+-- name: ListPendingInvitationsByEmails :many
+SELECT id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at
+FROM tacokumo_admin.invitations
+WHERE email = ANY(@emails::VARCHAR[]) AND status = 'pending' AND expires_at > NOW()
+  -- Invitations to archived projects stay pending until the project is unarchived.
+  AND project_id NOT IN (SELECT id FROM tacokumo_admin.projects WHERE archived_at IS NOT NULL)
+ORDER BY created_at
+FOR UPDATE;
+
+-- name: MarkInvitationAccepted :execrows
+UPDATE tacokumo_admin.invitations
+SET (status, accepted_at, accepted_user_id, updated_at) = ('accepted', NOW(), $2, NOW())
+WHERE id = $1 AND status = 'pending';
+
+-- name: RevokeInvitation :execrows
+UPDATE tacokumo_admin.invitations
+SET (status, updated_at) = ('revoked', NOW())
+WHERE project_id = $1 AND display_id = $2 AND status = 'pending';
+
+-- name: RevokePendingInvitationsForEmail :execrows
+UPDATE tacokumo_admin.invitations
+SET (status, updated_at) = ('revoked', NOW())
+WHERE project_id = $1 AND email = $2 AND status = 'pending';
+
+-- name: RotateInvitationToken :one
+UPDATE tacokumo_admin.invitations
+SET (token_hash, expires_at, updated_at) = ($3, $4, NOW())
+WHERE project_id = $1 AND display_id = $2 AND status = 'pending'
+RETURNING id, display_id, project_id, email, token_hash, status, invited_by, expires_at, accepted_at, accepted_user_id, created_at, updated_at;
+
+-- name: GetProjectComposition :one
+-- Counts what the kind rules of a project are checked against.
+SELECT p.kind,
+      (SELECT COUNT(*) FROM tacokumo_admin.project_owners po WHERE po.project_id = p.id) AS owner_count,
+      (SELECT COUNT(*) FROM tacokumo_admin.project_owner_groups pog WHERE pog.project_id = p.id) AS owner_group_count,
+      (SELECT COUNT(*) FROM tacokumo_admin.usergroups ug WHERE ug.project_id = p.id) AS usergroup_count
+  FROM tacokumo_admin.projects p
+  WHERE p.id = $1;
+
+-- name: ListProjectOwners :many
+SELECT u.id, u.display_id, u.email, u.created_at, u.updated_at
+  FROM tacokumo_admin.users u
+  INNER JOIN tacokumo_admin.project_owners po ON u.id = po.user_id
+  WHERE po.project_id = $1
+  ORDER BY po.created_at;
+
+-- name: ListProjectOwnerGroups :many
+SELECT ug.id, ug.display_id, ug.project_id, ug.name, ug.description, ug.version, ug.created_at, ug.updated_at, ug.labels, ug.annotations
+  FROM tacokumo_admin.usergroups ug
+  INNER JOIN tacokumo_admin.project_owner_groups pog ON ug.id = pog.usergroup_id
+  WHERE pog.project_id = $1
+  ORDER BY pog.created_at;
+
+-- name: DeleteProjectOwnerGroups :exec
+DELETE FROM tacokumo_admin.project_owner_groups
+WHERE project_id = $1;
+
+-- name: AddProjectOwnerGroup :exec
+INSERT INTO tacokumo_admin.project_owner_groups (project_id, usergroup_id) VALUES ($1, $2)
+ON CONFLICT (project_id, usergroup_id) DO NOTHING;
+
+-- name: ListProjectIDsOwnedByUser :many
+SELECT project_id
+  FROM tacokumo_admin.project_owners
+  WHERE user_id = $1;
+
+-- name: ListProjectUserGroupMembers :many
+-- Lists the distinct users that are members of any user group of a project.
+SELECT DISTINCT u.id, u.display_id, u.email, u.created_at, u.updated_at
+  FROM tacokumo_admin.users u
+  INNER JOIN tacokumo_admin.user_usergroups_relations uur ON u.id = uur.user_id
+  INNER JOIN tacokumo_admin.usergroups ug ON ug.id = uur.usergroup_id
+  WHERE ug.project_id = $1;
+
+-- name: CountProjectUserGroupServiceAccounts :one
+SELECT COUNT(DISTINCT r.service_account_id)
+  FROM tacokumo_admin.service_account_usergroups_relations r
+  INNER JOIN tacokumo_admin.usergroups ug ON ug.id = r.usergroup_id
+  WHERE ug.project_id = $1;
+
+-- name: CopyUserGroupRolesToUser :many
+-- Assigns the roles a user holds through the user groups of a project to the user directly,
+-- returning the display IDs of the newly assigned roles.
+WITH inserted AS (
+  INSERT INTO tacokumo_admin.user_role_relations (user_id, role_id)
+  SELECT DISTINCT uur.user_id, ugr.role_id
+    FROM tacokumo_admin.usergroup_role_relations ugr
+    INNER JOIN tacokumo_admin.usergroups ug ON ug.id = ugr.usergroup_id
+    INNER JOIN tacokumo_admin.user_usergroups_relations uur ON uur.usergroup_id = ug.id
+    WHERE ug.project_id = $1 AND uur.user_id = $2
+  ON CONFLICT (user_id, role_id) DO NOTHING
+  RETURNING role_id
+)
+SELECT ro.display_id
+  FROM tacokumo_admin.roles ro
+  INNER JOIN inserted i ON ro.id = i.role_id;
+
+-- name: DeleteUserGroupsByProject :many
+DELETE FROM tacokumo_admin.usergroups
+WHERE project_id = $1
+RETURNING display_id;
+
+-- name: IsProjectOwner :one
+SELECT EXISTS (
+  SELECT 1 FROM tacokumo_admin.project_owners
+  WHERE project_id = $1 AND user_id = $2
+);
+
+-- name: DeleteProjectOwner :execrows
+DELETE FROM tacokumo_admin.project_owners
+WHERE project_id = $1 AND user_id = $2;
+
+-- name: ExpireProjectTransfers :execrows
+-- Marks pending transfers past their expiry as expired so that a new transfer can be started.
+UPDATE tacokumo_admin.project_transfers
+SET (status, updated_at) = ('expired', NOW())
+WHERE project_id = $1 AND status = 'pending' AND expires_at <= NOW();
+
+-- name: CreateProjectTransfer :one
+INSERT INTO tacokumo_admin.project_transfers (project_id, from_user_id, to_user_id, initiated_by, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at;
+
+-- name: GetProjectTransferByDisplayID :one
+SELECT id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+FROM tacokumo_admin.project_transfers
+WHERE project_id = $1 AND display_id = $2;
+
+-- name: GetProjectTransferByDisplayIDForUpdate :one
+SELECT id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+FROM tacokumo_admin.project_transfers
+WHERE project_id = $1 AND display_id = $2
+FOR UPDATE;
+
+-- name: ListProjectTransfersWithPagination :many
+-- status filters by transfer status; 'expired' also matches pending transfers past their expiry.
+SELECT id, display_id, project_id, from_user_id, to_user_id, status, initiated_by, expires_at, completed_at, created_at, updated_at
+FROM tacokumo_admin.project_transfers
+WHERE project_id = $1
+  AND (sqlc.narg('status')::VARCHAR IS NULL
+    OR (sqlc.narg('status')::VARCHAR = 'expired' AND (status = 'expired' OR (status = 'pending' AND expires_at <= NOW())))
+    OR (sqlc.narg('status')::VARCHAR = 'pending' AND status = 'pending' AND expires_at > NOW())
+    OR (sqlc.narg('status')::VARCHAR NOT IN ('pending', 'expired') AND status = sqlc.narg('status')::VARCHAR))
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CompleteProjectTransfer :execrows
+UPDATE tacokumo_admin.project_transfers
+SET status = $2,
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'pending';
+
+-- name: CreateProjectTransferAuditLog :exec
+INSERT INTO tacokumo_admin.project_transfer_audit_logs (project_display_id, transfer_display_id, action, from_user_display_id, to_user_display_id, actor)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListProjectTransferAuditLogs :many
+SELECT id, project_display_id, transfer_display_id, action, from_user_display_id, to_user_display_id, actor, created_at
+FROM tacokumo_admin.project_transfer_audit_logs
+WHERE project_display_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListRolesByProject :many
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+FROM tacokumo_admin.roles
+WHERE project_id = $1
+ORDER BY created_at, id;
+
+-- name: ListUserGroupsByProject :many
+SELECT id, display_id, project_id, name, description, version, created_at, updated_at, labels, annotations
+FROM tacokumo_admin.usergroups
+WHERE project_id = $1
+ORDER BY created_at, id;
+
+-- name: ListRoleAttributeNames :many
+SELECT ra.name
+  FROM tacokumo_admin.role_attributes ra
+  INNER JOIN tacokumo_admin.role_attributes_relations rar ON ra.id = rar.role_attribute_id
+  WHERE rar.role_id = $1
+  ORDER BY ra.name;
+
+-- name: AddRoleAttributeByName :execrows
+-- Affects no rows when the attribute is not defined.
+INSERT INTO tacokumo_admin.role_attributes_relations (role_id, role_attribute_id)
+SELECT $1, ra.id FROM tacokumo_admin.role_attributes ra WHERE ra.name = $2
+ON CONFLICT (role_id, role_attribute_id) DO NOTHING;
+
+-- name: ListUserGroupRoleNames :many
+SELECT ro.name
+  FROM tacokumo_admin.roles ro
+  INNER JOIN tacokumo_admin.usergroup_role_relations ugr ON ro.id = ugr.role_id
+  WHERE ugr.usergroup_id = $1
+  ORDER BY ro.name;
+
+-- name: AssignRoleToUserGroup :exec
+INSERT INTO tacokumo_admin.usergroup_role_relations (usergroup_id, role_id) VALUES ($1, $2)
+ON CONFLICT (usergroup_id, role_id) DO NOTHING;
+
+-- name: CreateProjectTemplate :one
+INSERT INTO tacokumo_admin.project_templates (name, description, kind, spec, source_project_id, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING display_id;
+
+-- name: GetProjectTemplateByDisplayID :one
+SELECT id, display_id, name, description, kind, spec, source_project_id, created_by, created_at, updated_at
+FROM tacokumo_admin.project_templates
+WHERE display_id = $1;
+
+-- name: ListProjectTemplatesWithPagination :many
+SELECT id, display_id, name, description, kind, spec, source_project_id, created_by, created_at, updated_at
+FROM tacokumo_admin.project_templates
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: DeleteProjectTemplate :execrows
+DELETE FROM tacokumo_admin.project_templates
+WHERE display_id = $1;
+
+-- name: ListProjectsByAnnotation :many
+-- Lists the projects that have the annotation key regardless of its value.
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+FROM tacokumo_admin.projects
+WHERE annotations ? sqlc.arg('key')::TEXT
+ORDER BY name;
+
+-- name: DeleteProjectByID :exec
+-- Deleting a project cascades to its roles, user groups, memberships and webhooks.
+DELETE FROM tacokumo_admin.projects
+WHERE id = $1;
+
+-- name: DeleteRoleByID :exec
+DELETE FROM tacokumo_admin.roles
+WHERE id = $1;
+
+-- name: RemoveRoleAttributeByName :exec
+DELETE FROM tacokumo_admin.role_attributes_relations rar
+USING tacokumo_admin.role_attributes ra
+WHERE rar.role_attribute_id = ra.id AND rar.role_id = $1 AND ra.name = $2;
+
+-- name: UnassignRoleFromUserGroup :exec
+DELETE FROM tacokumo_admin.usergroup_role_relations
+WHERE usergroup_id = $1 AND role_id = $2;
+
+-- name: RemoveProjectOwnerGroup :exec
+DELETE FROM tacokumo_admin.project_owner_groups
+WHERE project_id = $1 AND usergroup_id = $2;
+
+-- name: ListAllUsers :many
+SELECT id, display_id, email, created_at, updated_at
+FROM tacokumo_admin.users
+ORDER BY id;
+
+-- name: ListAllProjects :many
+-- Lists every project including archived ones.
+SELECT id, display_id, name, description, kind, version, created_at, updated_at, archived_at, archived_by, labels, annotations
+FROM tacokumo_admin.projects
+ORDER BY id;
+
+-- name: ListRoleAttributes :many
+SELECT id, name, description, created_at, updated_at
+FROM tacokumo_admin.role_attributes
+ORDER BY name;
+
+-- name: ListRoleUsers :many
+-- Lists the users a role is assigned to directly.
+SELECT u.id, u.display_id, u.email, u.created_at, u.updated_at
+  FROM tacokumo_admin.users u
+  INNER JOIN tacokumo_admin.user_role_relations urr ON u.id = urr.user_id
+  WHERE urr.role_id = $1
+  ORDER BY u.id;
+
+-- name: ImportUser :one
+-- Creates the user with the given display ID, or updates its email.
+INSERT INTO tacokumo_admin.users (display_id, email) VALUES ($1, $2)
+ON CONFLICT (display_id) DO UPDATE SET email = EXCLUDED.email, updated_at = NOW()
+RETURNING id;
+
+-- name: ImportRoleAttribute :exec
+INSERT INTO tacokumo_admin.role_attributes (name, description) VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description, updated_at = NOW();
+
+-- name: ImportProject :one
+-- Creates the project with the given display ID, or overwrites it.
+INSERT INTO tacokumo_admin.projects (display_id, name, description, kind, archived_at, archived_by, labels, annotations)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (display_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  description = EXCLUDED.description,
+  kind = EXCLUDED.kind,
+  archived_at = EXCLUDED.archived_at,
+  archived_by = EXCLUDED.archived_by,
+  labels = EXCLUDED.labels,
+  annotations = EXCLUDED.annotations,
+  version = tacokumo_admin.projects.version + 1,
+  updated_at = NOW()
+RETURNING id;
+
+-- name: ImportRole :one
+-- Creates the role with the given display ID in the project, or overwrites it.
+INSERT INTO tacokumo_admin.roles (display_id, project_id, name, description, labels, annotations)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (project_id, display_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  description = EXCLUDED.description,
+  labels = EXCLUDED.labels,
+  annotations = EXCLUDED.annotations,
+  version = tacokumo_admin.roles.version + 1,
+  updated_at = NOW()
+RETURNING id;
+
+-- name: ImportUserGroup :one
+-- Creates the user group with the given display ID in the project, or overwrites it.
+INSERT INTO tacokumo_admin.usergroups (display_id, project_id, name, description, labels, annotations)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (project_id, display_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  description = EXCLUDED.description,
+  labels = EXCLUDED.labels,
+  annotations = EXCLUDED.annotations,
+  version = tacokumo_admin.usergroups.version + 1,
+  updated_at = NOW()
+RETURNING id;
+
+-- name: DeleteRoleAttributeRelations :exec
+DELETE FROM tacokumo_admin.role_attributes_relations
+WHERE role_id = $1;
+
+-- name: DeleteRoleUsers :exec
+DELETE FROM tacokumo_admin.user_role_relations
+WHERE role_id = $1;
+
+-- name: DeleteUserGroupRoles :exec
+DELETE FROM tacokumo_admin.usergroup_role_relations
+WHERE usergroup_id = $1;
+
+-- name: DeleteUserGroupByID :exec
+DELETE FROM tacokumo_admin.usergroups
+WHERE id = $1;
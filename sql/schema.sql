@@ -7,13 +7,25 @@ CREATE TABLE tacokumo_admin.projects (
   display_id UUID NOT NULL DEFAULT uuidv7(), -- 外部に公開するプロジェクトID
   name VARCHAR(64) NOT NULL, -- プロジェクト名
   description VARCHAR(256) NOT NULL, -- プロジェクトの説明 
-  kind VARCHAR(32) NOT NULL DEFAULT 'personal', -- プロジェクトの種類 (将来的に複数種類をサポートする場合に備えて)
+  kind VARCHAR(32) NOT NULL DEFAULT 'personal', -- プロジェクトの種類 (personal | shared)｡種類ごとのオーナーの制約はpkg/projectで検証する
+  version BIGINT NOT NULL DEFAULT 1, -- 更新のたびにインクリメントされる行バージョン (ETagに利用)
   created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  archived_at TIMESTAMPTZ, -- アーカイブされた日時｡アーカイブ中のプロジェクトは読み取り専用で､保持期間を過ぎると削除される
+  archived_by VARCHAR(256), -- アーカイブしたプリンシパル
+  labels JSONB NOT NULL DEFAULT '{}', -- ラベル (Kubernetes形式のキーと値)｡ラベルセレクタでの絞り込みに利用する
+  annotations JSONB NOT NULL DEFAULT '{}', -- アノテーション (絞り込みには利用しない任意のキーと値)
   UNIQUE(display_id), -- display_idはユニーク
   UNIQUE (name) -- プロジェクト名はユニーク
 );
 
+-- 保持期間を過ぎたアーカイブ済みプロジェクトの削除に利用する
+CREATE INDEX projects_archived_at_idx ON tacokumo_admin.projects (archived_at) WHERE archived_at IS NOT NULL;
+
+-- ラベルセレクタの等価条件(@>)と存在条件(?&)に利用する
+-- jsonb_path_opsは存在演算子を扱えないため､デフォルトのjsonb_opsを使う
+CREATE INDEX projects_labels_idx ON tacokumo_admin.projects USING GIN (labels);
+
 -- ユーザ情報を保持するテーブル
 CREATE TABLE tacokumo_admin.users (
   id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
@@ -63,12 +75,18 @@ CREATE TABLE tacokumo_admin.roles (
   project_id BIGINT NOT NULL REFERENCES tacokumo_admin.projects(id) ON DELETE CASCADE,
   name VARCHAR(32) NOT NULL, -- ロール名 (例: admin, editor, viewer)
   description VARCHAR(256) NOT NULL, -- ロールの説明
+  version BIGINT NOT NULL DEFAULT 1, -- 更新のたびにインクリメントされる行バージョン (ETagに利用)
   created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  labels JSONB NOT NULL DEFAULT '{}', -- ラベル (Kubernetes形式のキーと値)｡ラベルセレクタでの絞り込みに利用する
+  annotations JSONB NOT NULL DEFAULT '{}', -- アノテーション (絞り込みには利用しない任意のキーと値)
   UNIQUE(project_id, display_id), -- display_idはプロジェクト内でユニーク
   UNIQUE (project_id, name) -- ロール名はプロジェクト内でユニーク
 );
 
+-- ラベルセレクタの等価条件(@>)と存在条件(?&)に利用する (projects_labels_idxを参照)
+CREATE INDEX roles_labels_idx ON tacokumo_admin.roles USING GIN (labels);
+
 -- ロールに関連付けられる属性 (例: 権限の詳細設定)
 -- 現状事前定義された属性しか挿入されないため､あくまでも実装上の都合でテーブルを作成しているだけ
 CREATE TABLE tacokumo_admin.role_attributes (
@@ -97,12 +115,18 @@ CREATE TABLE tacokumo_admin.usergroups (
   project_id BIGINT NOT NULL REFERENCES tacokumo_admin.projects(id) ON DELETE CASCADE,
   name VARCHAR(64) NOT NULL, -- ユーザグループ名
   description VARCHAR(256) NOT NULL, -- ユーザグループの説明
+  version BIGINT NOT NULL DEFAULT 1, -- 更新のたびにインクリメントされる行バージョン (ETagに利用)
   created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  labels JSONB NOT NULL DEFAULT '{}', -- ラベル (Kubernetes形式のキーと値)｡ラベルセレクタでの絞り込みに利用する
+  annotations JSONB NOT NULL DEFAULT '{}', -- アノテーション (絞り込みには利用しない任意のキーと値)
   UNIQUE(project_id, display_id), -- display_idはプロジェクト内でユニーク
   UNIQUE (project_id, name) -- ユーザグループ名はプロジェクト内でユニーク
 );
 
+-- ラベルセレクタの等価条件(@>)と存在条件(?&)に利用する (projects_labels_idxを参照)
+CREATE INDEX usergroups_labels_idx ON tacokumo_admin.usergroups USING GIN (labels);
+
 -- ユーザとユーザグループの多対多の関係を管理する中間テーブル
 CREATE TABLE tacokumo_admin.user_usergroups_relations (
   id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
@@ -131,4 +155,239 @@ CREATE TABLE tacokumo_admin.usergroup_role_relations (
   created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
   UNIQUE (usergroup_id, role_id) -- 同じユーザグループとロール
-);
\ No newline at end of file
+);
+-- ボットやデプロイパイプラインなど､人間以外の主体を表すサービスアカウント
+CREATE TABLE tacokumo_admin.service_accounts (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  display_id UUID NOT NULL DEFAULT uuidv7(), -- 外部に公開するサービスアカウントID
+  project_id BIGINT REFERENCES tacokumo_admin.projects(id) ON DELETE CASCADE, -- NULLの場合はグローバルなサービスアカウント
+  name VARCHAR(64) NOT NULL, -- サービスアカウント名
+  description VARCHAR(256) NOT NULL, -- サービスアカウントの説明
+  owner_user_id BIGINT REFERENCES tacokumo_admin.users(id) ON DELETE SET NULL, -- 管理責任者となるユーザ
+  disabled BOOLEAN NOT NULL DEFAULT FALSE, -- 無効化されたサービスアカウントは認証できない
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(display_id), -- display_idはユニーク
+  UNIQUE NULLS NOT DISTINCT (project_id, name) -- サービスアカウント名はプロジェクト内(グローバルの場合はグローバル内)でユニーク
+);
+
+-- サービスアカウントが認証に利用するAPIトークン
+-- トークンそのものは保存せず､SHA-256ハッシュのみを保持する
+CREATE TABLE tacokumo_admin.service_account_tokens (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  display_id UUID NOT NULL DEFAULT uuidv7(), -- 外部に公開するトークンID
+  service_account_id BIGINT NOT NULL REFERENCES tacokumo_admin.service_accounts(id) ON DELETE CASCADE,
+  description VARCHAR(256) NOT NULL, -- トークンの用途
+  token_prefix VARCHAR(16) NOT NULL, -- トークンを識別するための先頭部分
+  token_hash VARCHAR(64) NOT NULL, -- トークンのSHA-256ハッシュ(hex)
+  expires_at TIMESTAMPTZ, -- NULLの場合は無期限
+  last_used_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(display_id), -- display_idはユニーク
+  UNIQUE (token_hash) -- トークンハッシュはユニーク
+);
+
+-- サービスアカウントとユーザグループの多対多の関係を管理する中間テーブル
+CREATE TABLE tacokumo_admin.service_account_usergroups_relations (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  service_account_id BIGINT NOT NULL REFERENCES tacokumo_admin.service_accounts(id) ON DELETE CASCADE,
+  usergroup_id BIGINT NOT NULL REFERENCES tacokumo_admin.usergroups(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (service_account_id, usergroup_id) -- 同じサービスアカウントとユーザグループの組み合わせはユニーク
+);
+
+-- サービスアカウントに割り当てられたロールを管理するテーブル
+CREATE TABLE tacokumo_admin.service_account_role_relations (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  service_account_id BIGINT NOT NULL REFERENCES tacokumo_admin.service_accounts(id) ON DELETE CASCADE,
+  role_id BIGINT NOT NULL REFERENCES tacokumo_admin.roles(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (service_account_id, role_id) -- 同じサービスアカウントとロール
+);
+
+-- プロジェクトの変更を外部に通知するWebhookの購読設定
+CREATE TABLE tacokumo_admin.webhook_subscriptions (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  display_id UUID NOT NULL DEFAULT uuidv7(), -- 外部に公開するWebhook ID
+  project_id BIGINT NOT NULL REFERENCES tacokumo_admin.projects(id) ON DELETE CASCADE,
+  url VARCHAR(2048) NOT NULL, -- 配信先URL
+  description VARCHAR(256) NOT NULL,
+  event_types TEXT[] NOT NULL DEFAULT '{}', -- 配信するイベント種別 (例: project.updated, role.*)｡空の場合はすべて
+  secret VARCHAR(128) NOT NULL, -- HMAC-SHA256署名に使う共有シークレット
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(display_id) -- display_idはユニーク
+);
+
+-- Webhookの配信キュー兼配信履歴 (outbox)
+-- バックグラウンドワーカーがpendingの行を取り出して配信し､結果を記録する
+CREATE TABLE tacokumo_admin.webhook_deliveries (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  display_id UUID NOT NULL DEFAULT uuidv7(), -- 外部に公開する配信ID (X-Tacokumo-Deliveryヘッダにも利用)
+  subscription_id BIGINT NOT NULL REFERENCES tacokumo_admin.webhook_subscriptions(id) ON DELETE CASCADE,
+  event_type VARCHAR(64) NOT NULL, -- イベント種別 (例: project.created)
+  payload JSONB NOT NULL, -- 配信するリクエストボディ
+  status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending | succeeded | dead
+  attempts INT NOT NULL DEFAULT 0, -- 配信を試行した回数
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- 次に配信を試行する時刻 (ワーカーが取り出す際のリースにも利用)
+  last_attempt_at TIMESTAMPTZ,
+  last_status_code INT, -- 最後の試行で受け取ったHTTPステータス
+  last_error TEXT, -- 最後の試行のエラー内容
+  delivered_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(display_id) -- display_idはユニーク
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON tacokumo_admin.webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_subscription_idx ON tacokumo_admin.webhook_deliveries (subscription_id, created_at DESC);
+
+-- バックグラウンドジョブのキュー
+-- ワーカーは FOR UPDATE SKIP LOCKED で行をリースして実行する
+CREATE TABLE tacokumo_admin.jobs (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  display_id UUID NOT NULL DEFAULT uuidv7(), -- 外部に公開するジョブID
+  kind VARCHAR(64) NOT NULL, -- ジョブの種類 (例: webhooks.dispatch)
+  payload JSONB NOT NULL DEFAULT '{}', -- ジョブの引数
+  status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending | running | succeeded | failed
+  attempts INT NOT NULL DEFAULT 0, -- 実行を開始した回数
+  max_attempts INT NOT NULL DEFAULT 5, -- この回数失敗するとfailedになる
+  run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- 実行予定時刻
+  locked_by VARCHAR(128), -- 実行中のワーカー
+  locked_until TIMESTAMPTZ, -- リースの期限｡期限切れのrunningは他のワーカーが再実行する
+  unique_key VARCHAR(256), -- 重複登録を防ぐキー (スケジュール実行では スケジュール名@予定時刻)
+  last_error TEXT,
+  started_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(display_id), -- display_idはユニーク
+  UNIQUE(unique_key) -- NULLは重複可能
+);
+
+CREATE INDEX jobs_runnable_idx ON tacokumo_admin.jobs (run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX jobs_finished_idx ON tacokumo_admin.jobs (finished_at) WHERE status IN ('succeeded', 'failed');
+
+-- SCIMでIdPからプロビジョニングされたユーザの追加情報
+-- ユーザ本体はusersテーブルで管理し､userNameはemailに対応する
+CREATE TABLE tacokumo_admin.scim_users (
+  user_id BIGINT PRIMARY KEY REFERENCES tacokumo_admin.users(id) ON DELETE CASCADE,
+  external_id VARCHAR(256), -- IdP側のID
+  display_name VARCHAR(256) NOT NULL DEFAULT '', -- IdP側の表示名
+  active BOOLEAN NOT NULL DEFAULT TRUE, -- IdPで無効化されたユーザはfalse
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- SCIMでIdPからプロビジョニングされたユーザグループの追加情報
+CREATE TABLE tacokumo_admin.scim_groups (
+  usergroup_id BIGINT PRIMARY KEY REFERENCES tacokumo_admin.usergroups(id) ON DELETE CASCADE,
+  external_id VARCHAR(256), -- IdP側のID
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- プロジェクトへの招待
+-- トークンそのものは保存せず､SHA-256ハッシュのみを保持する
+CREATE TABLE tacokumo_admin.invitations (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  display_id UUID NOT NULL DEFAULT uuidv7(), -- 外部に公開する招待ID
+  project_id BIGINT NOT NULL REFERENCES tacokumo_admin.projects(id) ON DELETE CASCADE,
+  email VARCHAR(256) NOT NULL, -- 招待先のメールアドレス (小文字に正規化して保存する)
+  token_hash VARCHAR(64) NOT NULL, -- 招待トークンのSHA-256ハッシュ(hex)
+  status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending | accepted | revoked (期限切れはexpires_atで判定する)
+  invited_by VARCHAR(256) NOT NULL, -- 招待したプリンシパル
+  expires_at TIMESTAMPTZ NOT NULL,
+  accepted_at TIMESTAMPTZ,
+  accepted_user_id BIGINT REFERENCES tacokumo_admin.users(id) ON DELETE SET NULL, -- 招待を受け入れたユーザ
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(display_id), -- display_idはユニーク
+  UNIQUE (token_hash) -- トークンハッシュはユニーク
+);
+
+-- 同じメールアドレスへの有効な招待はプロジェクト内でひとつまで
+CREATE UNIQUE INDEX invitations_pending_email_idx ON tacokumo_admin.invitations (project_id, email) WHERE status = 'pending';
+
+-- 招待を受け入れたときに割り当てるロール
+CREATE TABLE tacokumo_admin.invitation_roles (
+  invitation_id BIGINT NOT NULL REFERENCES tacokumo_admin.invitations(id) ON DELETE CASCADE,
+  role_id BIGINT NOT NULL REFERENCES tacokumo_admin.roles(id) ON DELETE CASCADE,
+  PRIMARY KEY (invitation_id, role_id)
+);
+
+-- 招待を受け入れたときに追加するユーザグループ
+CREATE TABLE tacokumo_admin.invitation_usergroups (
+  invitation_id BIGINT NOT NULL REFERENCES tacokumo_admin.invitations(id) ON DELETE CASCADE,
+  usergroup_id BIGINT NOT NULL REFERENCES tacokumo_admin.usergroups(id) ON DELETE CASCADE,
+  PRIMARY KEY (invitation_id, usergroup_id)
+);
+
+-- 共有(shared)プロジェクトのオーナーとなるユーザグループ
+-- sharedプロジェクトは少なくともひとつのオーナーグループを持つ
+-- ユーザグループの削除でオーナーグループもカスケード削除されるため､ユーザグループを削除する処理はpkg/projectのCheckで制約を検証すること
+CREATE TABLE tacokumo_admin.project_owner_groups (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  project_id BIGINT NOT NULL REFERENCES tacokumo_admin.projects(id) ON DELETE CASCADE,
+  usergroup_id BIGINT NOT NULL REFERENCES tacokumo_admin.usergroups(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (project_id, usergroup_id) -- 同じプロジェクトとユーザグループの組み合わせはユニーク
+);
+
+-- プロジェクトのオーナー移譲を保持するテーブル
+-- 移譲先のユーザが期限内に承認すると､project_ownersの移譲元が移譲先に置き換えられる
+CREATE TABLE tacokumo_admin.project_transfers (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  display_id UUID NOT NULL DEFAULT uuidv7(), -- 外部に公開する移譲ID
+  project_id BIGINT NOT NULL REFERENCES tacokumo_admin.projects(id) ON DELETE CASCADE,
+  from_user_id BIGINT NOT NULL REFERENCES tacokumo_admin.users(id) ON DELETE CASCADE, -- 移譲元のオーナー
+  to_user_id BIGINT NOT NULL REFERENCES tacokumo_admin.users(id) ON DELETE CASCADE, -- 移譲先のユーザ
+  status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending | accepted | cancelled | expired (期限切れはexpires_atでも判定する)
+  initiated_by VARCHAR(256) NOT NULL, -- 移譲を開始したプリンシパル
+  expires_at TIMESTAMPTZ NOT NULL,
+  completed_at TIMESTAMPTZ, -- 承認または取り消しされた日時
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(display_id) -- display_idはユニーク
+);
+
+-- 保留中の移譲はプロジェクトごとにひとつまで
+CREATE UNIQUE INDEX project_transfers_pending_idx ON tacokumo_admin.project_transfers (project_id) WHERE status = 'pending';
+
+-- オーナー移譲の監査ログ
+-- プロジェクトやユーザが削除されても残るように､外部キーではなくdisplay_idで記録する
+CREATE TABLE tacokumo_admin.project_transfer_audit_logs (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  project_display_id UUID NOT NULL,
+  transfer_display_id UUID NOT NULL,
+  action VARCHAR(16) NOT NULL, -- initiated | accepted | cancelled
+  from_user_display_id UUID NOT NULL,
+  to_user_display_id UUID NOT NULL,
+  actor VARCHAR(256) NOT NULL, -- 操作したプリンシパル
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX project_transfer_audit_logs_project_idx ON tacokumo_admin.project_transfer_audit_logs (project_display_id, created_at);
+
+-- プロジェクトのロールとユーザグループの構成を保存したテンプレート
+-- メンバーは含まず､ロール･ロール属性･ユーザグループとそのロール割り当てのみを保持する
+CREATE TABLE tacokumo_admin.project_templates (
+  id BIGSERIAL PRIMARY KEY, -- ひとまず主キーはBIGSERIALで、UUIDv7に移行することもあるかもしれない
+  display_id UUID NOT NULL DEFAULT uuidv7(), -- 外部に公開するテンプレートID
+  name VARCHAR(64) NOT NULL, -- テンプレート名
+  description VARCHAR(256) NOT NULL, -- テンプレートの説明
+  kind VARCHAR(32) NOT NULL, -- テンプレートから作成されるプロジェクトの種類 (personal | shared)
+  spec JSONB NOT NULL, -- ロールとユーザグループの構成 (pkg/project.Templateの形式)
+  source_project_id BIGINT REFERENCES tacokumo_admin.projects(id) ON DELETE SET NULL, -- 保存元のプロジェクト
+  created_by VARCHAR(256) NOT NULL, -- テンプレートを作成したプリンシパル
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(display_id), -- display_idはユニーク
+  UNIQUE (name) -- テンプレート名はユニーク
+);